@@ -15,5 +15,10 @@ func GetBundleCmd(config *config.Config) *cobra.Command {
 		},
 	}
 	bundleCmd.AddCommand(getGenerateCmd(config))
+	bundleCmd.AddCommand(getListCmd())
+	bundleCmd.AddCommand(getInfoCmd())
+	bundleCmd.AddCommand(getVerifyCmd())
+	bundleCmd.AddCommand(getSignCmd())
+	bundleCmd.AddCommand(getPruneCmd(config))
 	return bundleCmd
 }
@@ -1,29 +1,85 @@
 package bundle
 
 import (
-	"github.com/crc-org/crc/v2/pkg/crc/config"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/crc-org/crc/v2/pkg/compress"
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/gpg"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
+	"github.com/crc-org/crc/v2/pkg/crc/validation"
 	"github.com/spf13/cobra"
 )
 
-func getGenerateCmd(config *config.Config) *cobra.Command {
-	var forceStop bool
+func getGenerateCmd(config *crcConfig.Config) *cobra.Command {
+	var generateConfig types.GenerateBundleConfig
+	var signKeyPath, compressionFormat, instanceName string
 	generateCmd := &cobra.Command{
 		Use:   "generate",
-		Short: "Generate a custom bundle from the running OpenShift cluster",
-		Long:  "Generate a custom bundle from the running OpenShift cluster",
-		RunE: func(_ *cobra.Command, _ []string) error {
-			return runGenerate(config, forceStop)
+		Short: "Generate a custom bundle from the OpenShift cluster",
+		Long: `Generate a custom bundle from the OpenShift cluster.
+The pull secret is removed from the cluster and the instance is stopped to copy its disk image, a stopped
+instance is started first. The instance is then started to restore its pull secret, and it is stopped again
+unless it was running and --keep-running-state is used.`,
+		Example: `  crc bundle generate --name my-app --output ~/bundles --compression-level 19 --keep-running-state
+  crc bundle generate --compression-format xz`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// the passphrase of the key is asked before the long generation
+			var signingKey *gpg.SigningKey
+			if signKeyPath != "" {
+				var err error
+				if signingKey, err = readSigningKey(signKeyPath); err != nil {
+					return err
+				}
+			}
+			if err := validation.ValidateInstanceName(instanceName); err != nil {
+				return err
+			}
+			generateConfig.Compression.Format = compress.Format(compressionFormat)
+			// the configuration used to start the instance when it is
+			// stopped, and to start it again with --keep-running-state
+			generateConfig.StartConfig = machine.NewStartConfig(config, cluster.NewInteractivePullSecretLoader(config))
+			return runGenerate(cmd.Context(), os.Stdout, machine.NewClient(instanceName, logging.IsDebug(), config), generateConfig, signingKey)
 		},
 	}
-	generateCmd.PersistentFlags().BoolVarP(&forceStop, "force-stop", "f", false, "Forcefully stop the instance")
+	generateCmd.PersistentFlags().BoolVarP(&generateConfig.ForceStop, "force-stop", "f", false, "Forcefully stop the instance")
+	generateCmd.Flags().StringVar(&generateConfig.Name, "name", "", "Suffix of the bundle name, a timestamp by default")
+	generateCmd.Flags().StringVar(&instanceName, "instance", constants.DefaultName, "Name of the instance the bundle is generated from")
+	generateCmd.Flags().StringVar(&generateConfig.OutputDir, "output", "", "Directory where the bundle is written, the current directory by default")
+	generateCmd.Flags().StringVar(&compressionFormat, "compression-format", string(compress.Zstd), "Compression format of the bundle, zstd, or xz and gzip for compatibility with other tools, the bundle keeps the .crcbundle extension")
+	generateCmd.Flags().IntVar(&generateConfig.Compression.Level, "compression-level", 0, "Compression level of the bundle, from 1 (fastest) to 22 for zstd or 9 for xz and gzip (smallest), 0 for the default level")
+	generateCmd.Flags().IntVar(&generateConfig.Compression.Concurrency, "compression-threads", 0, "Number of threads compressing the bundle, 0 to use all the CPUs, xz compression uses a single thread")
+	generateCmd.Flags().BoolVar(&generateConfig.Delta, "delta", false, "Generate a delta bundle with only the changes made to the bundle of the instance, it can only be used when this bundle is in the cache")
+	generateCmd.Flags().StringVar(&signKeyPath, "sign-key", "", "Armored OpenPGP private key file used to sign the bundle")
+	generateCmd.Flags().BoolVar(&generateConfig.KeepRunningState, "keep-running-state", false, "Start the instance again once the bundle is generated if it was running")
 	return generateCmd
 }
 
-func runGenerate(config *config.Config, forceStop bool) error {
-	client := machine.NewClient(constants.DefaultName, logging.IsDebug(), config)
-
-	return client.GenerateBundle(forceStop)
+func runGenerate(ctx context.Context, writer io.Writer, client machine.Client, generateConfig types.GenerateBundleConfig, signingKey *gpg.SigningKey) error {
+	result, err := client.GenerateBundle(ctx, generateConfig)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "Bundle is generated in %s (tar archive compressed with %s)\n", result.Path, result.CompressionFormat)
+	fmt.Fprintf(writer, "sha256sum of the bundle: %s\n", result.Sha256sum)
+	if signingKey != nil {
+		if err := runSign(writer, result.Path, signingKey); err != nil {
+			return err
+		}
+	}
+	if result.Restarted {
+		fmt.Fprintln(writer, "The instance is running again with its pull secret")
+	} else {
+		fmt.Fprintln(writer, "The instance is stopped with its pull secret")
+	}
+	fmt.Fprintf(writer, "Use 'crc start -b %s' with a new instance to use this bundle\n", result.Path)
+	return nil
 }
@@ -0,0 +1,50 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/compress"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/fakemachine"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunGenerate(t *testing.T) {
+	out := new(bytes.Buffer)
+	outputDir := t.TempDir()
+	require.NoError(t, runGenerate(context.Background(), out, fakemachine.NewClient(), types.GenerateBundleConfig{
+		Name:             "my-app",
+		OutputDir:        outputDir,
+		KeepRunningState: true,
+	}, nil))
+	bundlePath := filepath.Join(outputDir, "crc_libvirt_4.6.1_my-app.crcbundle")
+	assert.Equal(t, "Bundle is generated in "+bundlePath+" (tar archive compressed with zstd)\n"+
+		"sha256sum of the bundle: 245a0e5acd4f09000a9a5f37d731082ed1cf3fdcad1b5320cbe9b153c9fd82a4\n"+
+		"The instance is running again with its pull secret\n"+
+		"Use 'crc start -b "+bundlePath+"' with a new instance to use this bundle\n", out.String())
+}
+
+func TestRunGenerateXz(t *testing.T) {
+	out := new(bytes.Buffer)
+	require.NoError(t, runGenerate(context.Background(), out, fakemachine.NewClient(), types.GenerateBundleConfig{
+		Name:        "my-app",
+		Compression: compress.Options{Format: compress.Xz},
+	}, nil))
+	assert.Contains(t, out.String(), "Bundle is generated in crc_libvirt_4.6.1_my-app.crcbundle (tar archive compressed with xz)\n")
+}
+
+func TestRunGenerateStopped(t *testing.T) {
+	out := new(bytes.Buffer)
+	require.NoError(t, runGenerate(context.Background(), out, fakemachine.NewClient(), types.GenerateBundleConfig{Name: "my-app"}, nil))
+	assert.Contains(t, out.String(), "The instance is stopped with its pull secret\n")
+}
+
+func TestRunGenerateFailing(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runGenerate(context.Background(), out, fakemachine.NewFailingClient(), types.GenerateBundleConfig{}, nil), "bundle generation failed")
+	assert.Empty(t, out.String())
+}
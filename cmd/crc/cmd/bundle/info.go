@@ -0,0 +1,27 @@
+package bundle
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
+	"github.com/spf13/cobra"
+)
+
+func getInfoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "info NAME",
+		Short: "Display the metadata of a bundle",
+		Long:  "Display the metadata of a bundle from the cache directory",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			bundleInfo, err := bundle.Get(args[0])
+			if err != nil {
+				return err
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(bundleInfo)
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package bundle
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+func getListCmd() *cobra.Command {
+	var outputFormat string
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the bundles available in the cache directory",
+		Long:  "List the bundles which have been extracted to the cache directory",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			bundles, err := bundle.List()
+			if err != nil {
+				return err
+			}
+			return runList(os.Stdout, bundles, outputFormat)
+		},
+	}
+	output.AddFormatFlag(listCmd, &outputFormat)
+	return listCmd
+}
+
+type bundleListItem struct {
+	Name      string    `json:"name"`
+	Preset    string    `json:"preset"`
+	Version   string    `json:"version"`
+	Driver    string    `json:"driver"`
+	Size      int64     `json:"size"`
+	BuildTime time.Time `json:"buildTime,omitempty"`
+}
+
+type bundleListResult struct {
+	Bundles []bundleListItem `json:"bundles"`
+}
+
+func runList(writer io.Writer, bundles []bundle.CrcBundleInfo, outputFormat string) error {
+	result := &bundleListResult{
+		Bundles: []bundleListItem{},
+	}
+	for i := range bundles {
+		bundleInfo := &bundles[i]
+		size, err := bundleInfo.GetSize()
+		if err != nil {
+			return err
+		}
+		// the build time is informational, bundles with an invalid one are still listed
+		buildTime, _ := bundleInfo.GetBundleBuildTime()
+		result.Bundles = append(result.Bundles, bundleListItem{
+			Name:      bundleInfo.GetBundleNameWithoutExtension(),
+			Preset:    bundleInfo.GetBundleType().String(),
+			Version:   bundleInfo.GetVersion(),
+			Driver:    bundleInfo.DriverInfo.Name,
+			Size:      size,
+			BuildTime: buildTime,
+		})
+	}
+	return output.Render(result, writer, outputFormat)
+}
+
+func (r *bundleListResult) PrettyPrintTo(writer io.Writer) error {
+	if len(r.Bundles) == 0 {
+		_, err := fmt.Fprintln(writer, "No bundles")
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPRESET\tVERSION\tDRIVER\tSIZE\tBUILD TIME")
+	for _, b := range r.Bundles {
+		buildTime := ""
+		if !b.BuildTime.IsZero() {
+			buildTime = b.BuildTime.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", b.Name, b.Preset, b.Version, b.Driver, units.HumanSize(float64(b.Size)), buildTime)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,21 @@
+package bundle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPlainEmpty(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runList(out, nil, ""))
+	assert.Equal(t, "No bundles\n", out.String())
+}
+
+func TestListJSONEmpty(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runList(out, nil, output.JSONFormat))
+	assert.JSONEq(t, `{"bundles": []}`, out.String())
+}
@@ -0,0 +1,51 @@
+package bundle
+
+import (
+	"errors"
+	"fmt"
+
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
+	"github.com/spf13/cobra"
+)
+
+func getPruneCmd(config *crcConfig.Config) *cobra.Command {
+	var keep int
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove old bundles from the cache directory",
+		Long:  "Remove old bundles from the cache directory, keeping the most recent ones for each preset. Bundles used by existing instances and the bundle of the 'bundle' setting are never removed.",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return runPrune(config, keep)
+		},
+	}
+	pruneCmd.Flags().IntVar(&keep, "keep", 1, "Number of bundles to keep for each preset")
+	return pruneCmd
+}
+
+func runPrune(config crcConfig.Storage, keep int) error {
+	if keep < 0 {
+		return errors.New("--keep cannot be negative")
+	}
+	inUse, err := machine.ListInstanceBundles()
+	if err != nil {
+		return err
+	}
+	configuredBundle, err := bundle.GetBundleNameFromURI(config.Get(crcConfig.Bundle).AsString())
+	if err != nil {
+		return fmt.Errorf("cannot determine the bundle of the 'bundle' setting: %w", err)
+	}
+	removed, err := bundle.Prune(keep, append(inUse, configuredBundle))
+	for _, name := range removed {
+		fmt.Printf("Removed bundle %s\n", name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		fmt.Println("No bundle to remove")
+	}
+	return nil
+}
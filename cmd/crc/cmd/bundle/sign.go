@@ -0,0 +1,61 @@
+package bundle
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/crc-org/crc/v2/pkg/crc/gpg"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
+	crcTerminal "github.com/crc-org/crc/v2/pkg/os/terminal"
+	"github.com/spf13/cobra"
+)
+
+func getSignCmd() *cobra.Command {
+	var keyPath string
+	signCmd := &cobra.Command{
+		Use:   "sign BUNDLE",
+		Short: "Sign a bundle file",
+		Long: `Write the detached OpenPGP signature of a bundle file next to it, in BUNDLE.sig.
+The signature must be distributed with the bundle, it is checked with the trusted-bundle-keys when enforce-bundle-signature is set.`,
+		Example: "  crc bundle sign crc_libvirt_4.16.0_amd64_team.crcbundle --key team-signing-key.asc",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			key, err := readSigningKey(keyPath)
+			if err != nil {
+				return err
+			}
+			return runSign(os.Stdout, args[0], key)
+		},
+	}
+	signCmd.Flags().StringVar(&keyPath, "key", "", "Armored OpenPGP private key file used to sign the bundle")
+	_ = signCmd.MarkFlagRequired("key")
+	return signCmd
+}
+
+func runSign(writer io.Writer, bundlePath string, key *gpg.SigningKey) error {
+	signaturePath, err := bundle.Sign(bundlePath, key)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "Signature of the bundle written to %s\n", signaturePath)
+	return err
+}
+
+// readSigningKey reads the private key used to sign bundles, the passphrase
+// of an encrypted key is asked in the terminal
+func readSigningKey(path string) (*gpg.SigningKey, error) {
+	return gpg.ReadSigningKey(path, func() ([]byte, error) {
+		if !crcTerminal.IsRunningInTerminal() {
+			return nil, fmt.Errorf("%w, it can only be asked in a terminal", gpg.ErrPassphraseRequired)
+		}
+		var passphrase string
+		if err := survey.AskOne(&survey.Password{
+			Message: fmt.Sprintf("Passphrase of %s", path),
+		}, &passphrase); err != nil {
+			return nil, err
+		}
+		return []byte(passphrase), nil
+	})
+}
@@ -0,0 +1,45 @@
+package bundle
+
+import (
+	"fmt"
+
+	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
+	"github.com/spf13/cobra"
+)
+
+func getVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify [NAME...]",
+		Short: "Verify the integrity of the bundles",
+		Long:  "Check the sha256sum of the disk images and files of the bundles against their metadata. All the bundles of the cache directory are verified when no name is given.",
+		RunE: func(_ *cobra.Command, args []string) error {
+			names := args
+			if len(names) == 0 {
+				bundles, err := bundle.List()
+				if err != nil {
+					return err
+				}
+				for _, bundleInfo := range bundles {
+					names = append(names, bundleInfo.GetBundleName())
+				}
+			}
+			return runVerify(names)
+		},
+	}
+}
+
+func runVerify(names []string) error {
+	failed := 0
+	for _, name := range names {
+		if err := bundle.Verify(name); err != nil {
+			fmt.Println(err)
+			failed++
+			continue
+		}
+		fmt.Printf("bundle %s is valid\n", bundle.GetBundleNameWithoutExtension(name))
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d bundle(s) failed verification", failed)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{certsStatusCmd, certsRotateCmd} {
+		addOutputFormatFlag(cmd)
+		addInstanceNameFlag(cmd)
+		certsCmd.AddCommand(cmd)
+	}
+	rootCmd.AddCommand(certsCmd)
+}
+
+var certsCmd = &cobra.Command{
+	Use:   "certs SUBCOMMAND [flags]",
+	Short: "Manage the certificates of the instance",
+	Long:  "Inspect and rotate the certificates of the running OpenShift instance",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+var certsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List the certificates of the instance",
+	Long:  "List the kubelet, kube-apiserver, aggregator and kubeconfig client certificates with their issuer and expiry date",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return runCertsStatus(os.Stdout, newMachine(), outputFormat)
+	},
+}
+
+var certsRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Rotate the certificates of the instance",
+	Long: "Issue a new kubeconfig client certificate and make kubelet renew its client and serving certificates, " +
+		"even when they did not expire yet. The kube-apiserver certificates are rotated by the cluster itself.",
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return runCertsRotate(context.Background(), os.Stdout, newMachine(), outputFormat)
+	},
+}
+
+func runCertsStatus(writer io.Writer, client machine.Client, outputFormat string) error {
+	certs, err := client.CertificatesStatus()
+	result := &certsStatusResult{
+		Success:      err == nil,
+		Error:        crcErrors.ToSerializableError(err),
+		Certificates: []certificateInfo{},
+	}
+	for _, cert := range certs {
+		result.Certificates = append(result.Certificates, certificateInfo{
+			Name:      cert.Name,
+			Path:      cert.Path,
+			Subject:   cert.Subject,
+			Issuer:    cert.Issuer,
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+			Expired:   cert.Expired(),
+			Missing:   cert.Missing,
+		})
+	}
+	return output.Render(result, writer, outputFormat)
+}
+
+func runCertsRotate(ctx context.Context, writer io.Writer, client machine.Client, outputFormat string) error {
+	err := client.RotateCertificates(ctx)
+	return output.Render(&certsRotateResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+	}, writer, outputFormat)
+}
+
+type certificateInfo struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+	Expired   bool      `json:"expired"`
+	Missing   bool      `json:"missing,omitempty"`
+}
+
+type certsStatusResult struct {
+	Success      bool                         `json:"success"`
+	Error        *crcErrors.SerializableError `json:"error,omitempty"`
+	Certificates []certificateInfo            `json:"certificates"`
+}
+
+func (s *certsStatusResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tEXPIRES\tSTATUS\tISSUER")
+	for _, cert := range s.Certificates {
+		if cert.Missing {
+			fmt.Fprintf(w, "%s\t-\tmissing\t-\n", cert.Name)
+			continue
+		}
+		status := "valid"
+		if cert.Expired {
+			status = "expired"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", cert.Name, cert.NotAfter.Format(time.RFC3339), status, cert.Issuer)
+	}
+	return w.Flush()
+}
+
+type certsRotateResult struct {
+	Success bool                         `json:"success"`
+	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+}
+
+func (s *certsRotateResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprintln(writer, "Certificates rotated")
+	return err
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertsStatusPlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runCertsStatus(out, fakemachine.NewClient(), ""))
+	assert.Equal(t, `NAME             EXPIRES                STATUS    ISSUER
+kubelet-client   2024-02-01T00:00:00Z   expired   CN=kube-csr-signer
+kubelet-server   -                      missing   -
+`, out.String())
+}
+
+func TestCertsStatusJSONSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runCertsStatus(out, fakemachine.NewClient(), output.JSONFormat))
+	assert.JSONEq(t, `{
+  "success": true,
+  "certificates": [
+    {
+      "name": "kubelet-client",
+      "path": "/var/lib/kubelet/pki/kubelet-client-current.pem",
+      "subject": "CN=system:node:crc,O=system:nodes",
+      "issuer": "CN=kube-csr-signer",
+      "notBefore": "2024-01-01T00:00:00Z",
+      "notAfter": "2024-02-01T00:00:00Z",
+      "expired": true
+    },
+    {
+      "name": "kubelet-server",
+      "path": "/var/lib/kubelet/pki/kubelet-server-current.pem",
+      "subject": "",
+      "issuer": "",
+      "notBefore": "0001-01-01T00:00:00Z",
+      "notAfter": "0001-01-01T00:00:00Z",
+      "expired": false,
+      "missing": true
+    }
+  ]
+}`, out.String())
+}
+
+func TestCertsStatusJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runCertsStatus(out, fakemachine.NewFailingClient(), output.JSONFormat))
+	assert.JSONEq(t, `{"success": false, "certificates": [], "error": "certificates status failed"}`, out.String())
+}
+
+func TestCertsRotatePlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runCertsRotate(context.Background(), out, fakemachine.NewClient(), ""))
+	assert.Equal(t, "Certificates rotated\n", out.String())
+}
+
+func TestCertsRotateJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runCertsRotate(context.Background(), out, fakemachine.NewFailingClient(), output.JSONFormat))
+	assert.JSONEq(t, `{"success": false, "error": "certificates rotation failed"}`, out.String())
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/preflight"
 	"github.com/spf13/cobra"
@@ -26,7 +27,7 @@ var cleanupCmd = &cobra.Command{
 
 func runCleanup() error {
 	err := preflight.CleanUpHost()
-	return render(&cleanupResult{
+	return output.Render(&cleanupResult{
 		Success: err == nil,
 		Error:   crcErrors.ToSerializableError(err),
 	}, os.Stdout, outputFormat)
@@ -37,7 +38,7 @@ type cleanupResult struct {
 	Error   *crcErrors.SerializableError `json:"error,omitempty"`
 }
 
-func (s *cleanupResult) prettyPrintTo(writer io.Writer) error {
+func (s *cleanupResult) PrettyPrintTo(writer io.Writer) error {
 	if s.Error != nil {
 		return s.Error
 	}
@@ -13,7 +13,7 @@ import (
 )
 
 const (
-	DefaultConfigViewFormat = "- {{.ConfigKey | printf \"%-38s\"}}: {{.ConfigValue}}"
+	DefaultConfigViewFormat = "- {{.ConfigKey | printf \"%-38s\"}}: {{.ConfigValue}} ({{.ConfigSource}})"
 )
 
 var (
@@ -24,19 +24,27 @@ var (
 type configViewTemplate struct {
 	ConfigKey   string
 	ConfigValue interface{}
+	// ConfigSource is the configuration layer of the value, "system, locked"
+	// for the keys locked by the system configuration file
+	ConfigSource config.Source
 }
 
 func configViewCmd(config config.Storage) *cobra.Command {
 	configViewCmd := &cobra.Command{
 		Use:   "view",
 		Short: "Display all assigned crc configuration properties",
-		Long:  `Displays all assigned crc configuration properties and their values.`,
+		Long: `Displays all assigned crc configuration properties, their values and where they come from.
+The values of the system configuration file (/etc/crc/config.json, or %ProgramData%\crc\config.json on Windows)
+are overridden by the user configuration file (~/.crc/crc.json), then by the .crc.json file of the current
+directory when CRC_ENABLE_PROJECT_CONFIG=true, the environment variables and the flags, except for the keys
+listed in the 'locked-keys' array of the system configuration file. Only the preset, cpus, memory, disk-size,
+persistent-volume-size and enable-cluster-monitoring keys are read from the .crc.json file.`,
 		RunE: func(_ *cobra.Command, _ []string) error {
 			tmpl, err := determineTemplate(configViewFormat)
 			if err != nil {
 				return err
 			}
-			return runConfigView(config.AllConfigs(), tmpl, os.Stdout)
+			return runConfigView(config, tmpl, os.Stdout)
 		},
 	}
 	configViewCmd.Flags().StringVar(&configViewFormat, "format", DefaultConfigViewFormat,
@@ -53,16 +61,20 @@ func determineTemplate(tempFormat string) (*template.Template, error) {
 	return tmpl, nil
 }
 
-func runConfigView(cfg map[string]config.SettingValue, tmpl *template.Template, writer io.Writer) error {
+func runConfigView(cfg config.Storage, tmpl *template.Template, writer io.Writer) error {
 	var lines []string
-	for k, v := range cfg {
+	for k, v := range cfg.AllConfigs() {
 		if v.IsDefault {
 			continue
 		}
 		if v.IsSecret && !showSecrets {
 			continue
 		}
-		viewTmplt := configViewTemplate{k, v.AsString()}
+		source, err := cfg.Source(k)
+		if err != nil {
+			return err
+		}
+		viewTmplt := configViewTemplate{k, v.AsString(), source}
 		var buffer bytes.Buffer
 		if err := tmpl.Execute(&buffer, viewTmplt); err != nil {
 			return err
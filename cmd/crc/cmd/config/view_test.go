@@ -0,0 +1,39 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunConfigView(t *testing.T) {
+	dir := t.TempDir()
+	systemConfigFile := filepath.Join(dir, "config.json")
+	configFile := filepath.Join(dir, "crc.json")
+	projectConfigFile := filepath.Join(dir, ".crc.json")
+	require.NoError(t, os.WriteFile(systemConfigFile, []byte(`{"consent-telemetry": "no", "http-proxy": "http://proxy.example.com:3128", "locked-keys": ["http-proxy"]}`), 0600))
+	require.NoError(t, os.WriteFile(configFile, []byte(`{"cpus": 6, "memory": 12000}`), 0600))
+	require.NoError(t, os.WriteFile(projectConfigFile, []byte(`{"memory": 16000}`), 0600))
+	t.Setenv("CRC_DISK_SIZE", "50")
+
+	storage, err := config.NewLayeredViperStorage(systemConfigFile, configFile, projectConfigFile, "CRC")
+	require.NoError(t, err)
+	cfg := config.New(storage, config.NewEmptyInMemorySecretStorage())
+	config.RegisterSettings(cfg)
+
+	tmpl, err := determineTemplate(DefaultConfigViewFormat)
+	require.NoError(t, err)
+	out := new(bytes.Buffer)
+	require.NoError(t, runConfigView(cfg, tmpl, out))
+	assert.Equal(t, `- consent-telemetry                     : no (system)
+- cpus                                  : 6 (user)
+- disk-size                             : 50 (env)
+- http-proxy                            : http://proxy.example.com:3128 (system, locked)
+- memory                                : 16000 (project)
+`, out.String())
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	"github.com/crc-org/crc/v2/pkg/crc/preset"
 
 	"github.com/crc-org/crc/v2/pkg/crc/api/client"
@@ -25,6 +26,7 @@ func init() {
 	addOutputFormatFlag(consoleCmd)
 	consoleCmd.Flags().BoolVar(&consolePrintURL, "url", false, "Print the URL for the OpenShift Web Console")
 	consoleCmd.Flags().BoolVar(&consolePrintCredentials, "credentials", false, "Print the credentials for the OpenShift Web Console")
+	addInstanceNameFlag(consoleCmd)
 	rootCmd.AddCommand(consoleCmd)
 }
 
@@ -35,7 +37,7 @@ var consoleCmd = &cobra.Command{
 	Short:   "Open the OpenShift Web Console in the default browser",
 	Long:    `Open the OpenShift Web Console in the default browser or print its URL or credentials`,
 	RunE: func(_ *cobra.Command, _ []string) error {
-		return runConsole(os.Stdout, daemonclient.New(), consolePrintURL, consolePrintCredentials, outputFormat)
+		return runConsole(os.Stdout, daemonclient.NewForInstance(instanceName), consolePrintURL, consolePrintCredentials, outputFormat)
 	},
 }
 
@@ -49,7 +51,7 @@ func runConsole(writer io.Writer, client *daemonclient.Client, consolePrintURL,
 	if err == nil && result.ClusterConfig.ClusterType == preset.Microshift {
 		err = fmt.Errorf("error : this option is only supported for %s and %s preset", preset.OpenShift, preset.OKD)
 	}
-	return render(&consoleResult{
+	return output.Render(&consoleResult{
 		Success:                 err == nil,
 		state:                   toState(result),
 		ClusterConfig:           toConsoleClusterConfig(result),
@@ -68,7 +70,7 @@ type consoleResult struct {
 	consolePrintCredentials bool
 }
 
-func (s *consoleResult) prettyPrintTo(writer io.Writer) error {
+func (s *consoleResult) PrettyPrintTo(writer io.Writer) error {
 	if s.Error != nil {
 		return s.Error
 	}
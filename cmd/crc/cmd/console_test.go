@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	"github.com/crc-org/crc/v2/pkg/crc/preset"
 
 	apiTypes "github.com/crc-org/crc/v2/pkg/crc/api/client"
@@ -107,13 +108,13 @@ func TestConsoleJSONSuccess(t *testing.T) {
 	 }
 	}`, fakemachine.DummyClusterConfig.ClusterCACert, fakemachine.DummyClusterConfig.WebConsoleURL, fakemachine.DummyClusterConfig.ClusterAPI, fakemachine.DummyClusterConfig.KubeAdminPass, fakemachine.DummyClusterConfig.DeveloperPass)
 	out := new(bytes.Buffer)
-	assert.NoError(t, runConsole(out, setUpClientForConsole(t), false, false, jsonFormat))
+	assert.NoError(t, runConsole(out, setUpClientForConsole(t), false, false, output.JSONFormat))
 	assert.JSONEq(t, expectedJSONOut, out.String())
 }
 
 func TestConsoleJSONError(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runConsole(out, setUpFailingClientForConsole(t), false, false, jsonFormat))
+	assert.NoError(t, runConsole(out, setUpFailingClientForConsole(t), false, false, output.JSONFormat))
 	assert.JSONEq(t, `{"error":"console failed", "success":false}`, out.String())
 }
 
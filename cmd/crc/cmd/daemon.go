@@ -15,6 +15,7 @@ import (
 	"syscall"
 	"time"
 
+	networkclient "github.com/containers/gvisor-tap-vsock/pkg/client"
 	"github.com/containers/gvisor-tap-vsock/pkg/types"
 	"github.com/containers/gvisor-tap-vsock/pkg/virtualnetwork"
 	"github.com/crc-org/crc/v2/pkg/crc/adminhelper"
@@ -24,7 +25,11 @@ import (
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/crc-org/crc/v2/pkg/crc/daemonclient"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/crc-org/crc/v2/pkg/crc/metrics"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 	"github.com/crc-org/crc/v2/pkg/fileserver/fs9p"
 	"github.com/crc-org/machine/libmachine/drivers"
 	"github.com/docker/go-units"
@@ -41,7 +46,7 @@ var (
 
 func init() {
 	daemonVersionSupplier = func() (client.VersionResult, error) {
-		return daemonclient.New().APIClient.Version()
+		return daemonclient.NewLocal().APIClient.Version()
 	}
 	daemonCmd.Flags().BoolVar(&watchdog, "watchdog", false, "Monitor stdin and shutdown the daemon if stdin is closed")
 	rootCmd.AddCommand(daemonCmd)
@@ -83,58 +88,8 @@ func createNewVirtualNetworkConfig(providedConfig *crcConfig.Config) types.Confi
 		Subnet:            "192.168.127.0/24",
 		GatewayIP:         constants.VSockGateway,
 		GatewayMacAddress: "5a:94:ef:e4:0c:dd",
-		DHCPStaticLeases: map[string]string{
-			"192.168.127.2": constants.VsockMacAddress,
-		},
-		DNS: []types.Zone{
-			{
-				Name:      "apps-crc.testing.",
-				DefaultIP: net.ParseIP("192.168.127.2"),
-			},
-			{
-				Name: "crc.testing.",
-				Records: []types.Record{
-					{
-						Name: "host",
-						IP:   net.ParseIP(hostVirtualIP),
-					},
-					{
-						Name: "gateway",
-						IP:   net.ParseIP("192.168.127.1"),
-					},
-					{
-						Name: "api",
-						IP:   net.ParseIP("192.168.127.2"),
-					},
-					{
-						Name: "api-int",
-						IP:   net.ParseIP("192.168.127.2"),
-					},
-					{
-						Regexp: regexp.MustCompile("crc-(.*?)-master-0"),
-						IP:     net.ParseIP("192.168.126.11"),
-					},
-				},
-			},
-			{
-				Name: "containers.internal.",
-				Records: []types.Record{
-					{
-						Name: "gateway",
-						IP:   net.ParseIP(hostVirtualIP),
-					},
-				},
-			},
-			{
-				Name: "docker.internal.",
-				Records: []types.Record{
-					{
-						Name: "gateway",
-						IP:   net.ParseIP(hostVirtualIP),
-					},
-				},
-			},
-		},
+		DHCPStaticLeases:  network.UserNetworkLeases(),
+		DNS:               network.AddDNSRecords(defaultDNSZones(), crcConfig.GetDNSRecords(providedConfig)),
 		Protocol:          types.HyperKitProtocol,
 		GatewayVirtualIPs: []string{hostVirtualIP},
 	}
@@ -148,11 +103,69 @@ func createNewVirtualNetworkConfig(providedConfig *crcConfig.Config) types.Confi
 	return virtualNetworkConfig
 }
 
+// defaultDNSZones are the zones of the DNS server of the virtual network,
+// without the records of the configuration
+func defaultDNSZones() []types.Zone {
+	return []types.Zone{
+		{
+			Name:      "apps-crc.testing.",
+			DefaultIP: net.ParseIP("192.168.127.2"),
+		},
+		{
+			Name: "crc.testing.",
+			Records: []types.Record{
+				{
+					Name: "host",
+					IP:   net.ParseIP(hostVirtualIP),
+				},
+				{
+					Name: "gateway",
+					IP:   net.ParseIP("192.168.127.1"),
+				},
+				{
+					Name: "api",
+					IP:   net.ParseIP("192.168.127.2"),
+				},
+				{
+					Name: "api-int",
+					IP:   net.ParseIP("192.168.127.2"),
+				},
+				{
+					Regexp: regexp.MustCompile("crc-(.*?)-master-0"),
+					IP:     net.ParseIP("192.168.126.11"),
+				},
+			},
+		},
+		{
+			Name: "containers.internal.",
+			Records: []types.Record{
+				{
+					Name: "gateway",
+					IP:   net.ParseIP(hostVirtualIP),
+				},
+			},
+		},
+		{
+			Name: "docker.internal.",
+			Records: []types.Record{
+				{
+					Name: "gateway",
+					IP:   net.ParseIP(hostVirtualIP),
+				},
+			},
+		},
+	}
+}
+
 func run(configuration *types.Configuration) error {
 	vn, err := virtualnetwork.New(configuration)
 	if err != nil {
 		return err
 	}
+	networkClient := inProcessNetworkClient(vn)
+	if err := exposePortForwards(networkClient, crcConfig.GetPortForwards(config)); err != nil {
+		logging.Warnf("Failed to reapply port forwards: %v", err)
+	}
 
 	errCh := make(chan error)
 
@@ -161,23 +174,48 @@ func run(configuration *types.Configuration) error {
 		return err
 	}
 
-	go func() {
-		if listener == nil {
-			return
-		}
+	remoteListener, remoteServer, err := remoteAPIListener(config)
+	if err != nil {
+		return err
+	}
+
+	if listener != nil || remoteListener != nil {
 		mux := http.NewServeMux()
 		mux.Handle("/network/", interceptResponseBodyMiddleware(http.StripPrefix("/network", vn.Mux()), logResponseBodyConditionally))
-		machineClient := newMachine()
-		mux.Handle("/api/", interceptResponseBodyMiddleware(http.StripPrefix("/api", api.NewMux(config, machineClient, logging.Memory, segmentClient)), logResponseBodyConditionally))
-		mux.Handle("/events", interceptResponseBodyMiddleware(http.StripPrefix("/events", events.NewEventServer(machineClient)), logResponseBodyConditionally))
-		s := &http.Server{
-			Handler:           handlers.LoggingHandler(os.Stderr, mux),
-			ReadHeaderTimeout: 10 * time.Second,
+		instances := machine.NewInstances(newMachine(), machine.NewSynchronizedClientFactory(logging.IsDebug(), config))
+		collector := metrics.NewCollector(instances, func() (uint64, uint64) {
+			return vn.BytesSent(), vn.BytesReceived()
+		})
+		defer collector.Close()
+		apiHandler := collector.Middleware(interceptResponseBodyMiddleware(http.StripPrefix("/api", api.NewMux(config, instances, network.NewDNS(networkClient, defaultDNSZones()), logging.Memory, segmentClient)), logResponseBodyConditionally))
+		eventsHandler := interceptResponseBodyMiddleware(http.StripPrefix("/events", events.NewEventServer(instances)), logResponseBodyConditionally)
+		mux.Handle("/api/", apiHandler)
+		mux.Handle("/events", eventsHandler)
+		mux.Handle("/metrics", collector)
+
+		if listener != nil {
+			go func() {
+				s := &http.Server{
+					Handler:           handlers.LoggingHandler(os.Stderr, mux),
+					ReadHeaderTimeout: 10 * time.Second,
+				}
+				if err := s.Serve(listener); err != nil {
+					errCh <- errors.Wrap(err, "api http.Serve failed")
+				}
+			}()
 		}
-		if err := s.Serve(listener); err != nil {
-			errCh <- errors.Wrap(err, "api http.Serve failed")
+		if remoteListener != nil {
+			go func() {
+				s := &http.Server{
+					Handler:           handlers.LoggingHandler(os.Stderr, remoteServer.Authenticate(remoteAPIMux(apiHandler, eventsHandler))),
+					ReadHeaderTimeout: 10 * time.Second,
+				}
+				if err := s.Serve(remoteListener); err != nil {
+					errCh <- errors.Wrap(err, "remote api http.Serve failed")
+				}
+			}()
 		}
-	}()
+	}
 
 	ln, err := vn.Listen("tcp", net.JoinHostPort(configuration.GatewayIP, "80"))
 	if err != nil {
@@ -211,27 +249,29 @@ func run(configuration *types.Configuration) error {
 		}
 	}()
 
-	go func() {
-		var oldCancel context.CancelFunc
-		for {
-			ctx, cancel := context.WithCancel(context.Background())
-			conn, err := unixgramListener(ctx, vn)
-			if err != nil && errors.Is(err, drivers.ErrNotImplemented) {
-				cancel()
-				break
-			}
-			if err != nil && !errors.Is(err, net.ErrClosed) {
-				logging.Errorf("unixgramListener error: %v", err)
-			}
-
-			if oldCancel != nil {
-				logging.Warnf("New connection to %s. Closing old connection", conn.LocalAddr().String())
-				oldCancel()
+	for index := 0; index < network.MaxInstances; index++ {
+		go func(index int) {
+			var oldCancel context.CancelFunc
+			for {
+				ctx, cancel := context.WithCancel(context.Background())
+				conn, err := unixgramListener(ctx, vn, index)
+				if err != nil && errors.Is(err, drivers.ErrNotImplemented) {
+					cancel()
+					break
+				}
+				if err != nil && !errors.Is(err, net.ErrClosed) {
+					logging.Errorf("unixgramListener error: %v", err)
+				}
+
+				if oldCancel != nil {
+					logging.Warnf("New connection to %s. Closing old connection", conn.LocalAddr().String())
+					oldCancel()
+				}
+				oldCancel = cancel
+				time.Sleep(1 * time.Second)
 			}
-			oldCancel = cancel
-			time.Sleep(1 * time.Second)
-		}
-	}()
+		}(index)
+	}
 
 	vsockListener, err := vsockListener()
 	if err != nil {
@@ -250,53 +290,33 @@ func run(configuration *types.Configuration) error {
 		}
 	}()
 
-	// 9p home directory sharing
+	// 9p directory sharing
 	if runtime.GOOS == "windows" && config.Get(crcConfig.EnableSharedDirs).AsBool() {
-		// 9p over hvsock
-		listener9pHvsock, err := fs9p.GetHvsockListener(constants.Plan9HvsockGUID)
-		if err != nil {
-			return err
-		}
-		server9pHvsock, err := fs9p.New9pServer(listener9pHvsock, constants.GetHomeDir())
-		if err != nil {
-			return err
-		}
-		if err := server9pHvsock.Start(); err != nil {
-			return err
-		}
-		defer func() {
-			if err := server9pHvsock.Stop(); err != nil {
-				logging.Warnf("error stopping 9p server (hvsock): %v", err)
+		for i, export := range plan9Exports(config) {
+			if i == 0 {
+				// 9p over hvsock, only available for the first directory
+				listener9pHvsock, err := fs9p.GetHvsockListener(constants.Plan9HvsockGUID)
+				if err != nil {
+					return err
+				}
+				stop, err := start9pServer(listener9pHvsock, export, "hvsock")
+				if err != nil {
+					return err
+				}
+				defer stop()
 			}
-		}()
-		go func() {
-			if err := server9pHvsock.WaitForError(); err != nil {
-				logging.Errorf("9p server (hvsock) error: %v", err)
-			}
-		}()
 
-		// 9p over TCP (as a backup)
-		listener9pTCP, err := vn.Listen("tcp", net.JoinHostPort(configuration.GatewayIP, fmt.Sprintf("%d", constants.Plan9TcpPort)))
-		if err != nil {
-			return err
-		}
-		server9pTCP, err := fs9p.New9pServer(listener9pTCP, constants.GetHomeDir())
-		if err != nil {
-			return err
-		}
-		if err := server9pTCP.Start(); err != nil {
-			return err
-		}
-		defer func() {
-			if err := server9pTCP.Stop(); err != nil {
-				logging.Warnf("error stopping 9p server (tcp): %v", err)
+			// 9p over TCP, as a backup for the first directory
+			listener9pTCP, err := vn.Listen("tcp", net.JoinHostPort(configuration.GatewayIP, fmt.Sprintf("%d", constants.Plan9TcpPort+i)))
+			if err != nil {
+				return err
 			}
-		}()
-		go func() {
-			if err := server9pTCP.WaitForError(); err != nil {
-				logging.Errorf("9p server (tcp) error: %v", err)
+			stop, err := start9pServer(listener9pTCP, export, "tcp")
+			if err != nil {
+				return err
 			}
-		}()
+			defer stop()
+		}
 	}
 
 	startupDone()
@@ -338,6 +358,46 @@ type HostsFileEditor interface {
 
 type adminHelperHostsFileEditor struct{}
 
+// plan9Exports returns the directories shared over 9p, in the order of the
+// shared directories of the VM
+func plan9Exports(config crcConfig.Storage) []fs9p.Export {
+	sharedDirs := crcConfig.GetSharedDirs(config)
+	if len(sharedDirs) == 0 {
+		return []fs9p.Export{{Name: "dir0", Dir: constants.GetHomeDir()}}
+	}
+	var exports []fs9p.Export
+	for i, dir := range sharedDirs {
+		exports = append(exports, fs9p.Export{
+			Name:     fmt.Sprintf("dir%d", i),
+			Dir:      dir.Source,
+			ReadOnly: dir.ReadOnly,
+		})
+	}
+	return exports
+}
+
+// start9pServer serves export on listener, it returns the function stopping
+// the server
+func start9pServer(listener net.Listener, export fs9p.Export, transport string) (func(), error) {
+	server, err := fs9p.NewMultiExport9pServer(listener, []fs9p.Export{export})
+	if err != nil {
+		return nil, err
+	}
+	if err := server.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := server.WaitForError(); err != nil {
+			logging.Errorf("9p server (%s) error: %v", transport, err)
+		}
+	}()
+	return func() {
+		if err := server.Stop(); err != nil {
+			logging.Warnf("error stopping 9p server (%s): %v", transport, err)
+		}
+	}, nil
+}
+
 func (adminHelperHostsFileEditor) Add(ip string, hostnames ...string) error {
 	return adminhelper.AddToHostsFile(ip, hostnames...)
 }
@@ -373,6 +433,76 @@ func gatewayAPIMux(cfg *crcConfig.Config, hostsEditor HostsFileEditor) *http.Ser
 	return mux
 }
 
+// inProcessNetworkClient returns a client of the services API of the virtual
+// network which does not go through the daemon socket
+func inProcessNetworkClient(vn *virtualnetwork.VirtualNetwork) *networkclient.Client {
+	return networkclient.New(&http.Client{
+		Transport: handlerTransport{handler: vn.ServicesMux()},
+	}, "http://virtualnetwork")
+}
+
+// exposePortForwards forwards the ports configured with 'crc port-forward'
+// using the expose API of the virtual network
+func exposePortForwards(networkClient *networkclient.Client, forwards []network.PortForward) error {
+	var mErr crcErrors.MultiError
+	for _, forward := range forwards {
+		logging.Debugf("Forwarding port %s", forward)
+		if err := networkClient.Expose(forward.ExposeRequest()); err != nil {
+			mErr.Collect(errors.Wrapf(err, "failed to forward port %s", forward))
+		}
+	}
+	if len(mErr.Errors) == 0 {
+		return nil
+	}
+	return mErr
+}
+
+// handlerTransport is a http.RoundTripper serving the requests in-process
+// with handler
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	w := &bufferedResponseWriter{header: http.Header{}}
+	t.handler.ServeHTTP(w, req)
+	w.WriteHeader(http.StatusOK)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", w.code, http.StatusText(w.code)),
+		StatusCode:    w.code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.header,
+		Body:          io.NopCloser(&w.body),
+		ContentLength: int64(w.body.Len()),
+		Request:       req,
+	}, nil
+}
+
+// bufferedResponseWriter keeps in memory the response written by the
+// handler of handlerTransport
+type bufferedResponseWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(data)
+}
+
 func networkAPIMux(vn *virtualnetwork.VirtualNetwork) *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.Handle("/", vn.Mux())
@@ -398,7 +528,7 @@ func acceptJSONStringArray(w http.ResponseWriter, r *http.Request, fun func(host
 
 func logResponseBodyConditionally(statusCode int, buffer *bytes.Buffer, r *http.Request) {
 	responseBody := buffer.String()
-	if statusCode != http.StatusOK && responseBody != "" {
+	if (statusCode < 200 || statusCode > 299) && responseBody != "" {
 		log.Errorf("[%s] \"%s %s\" Response Body: %s\n", time.Now().Format("02/Jan/2006:15:04:05 -0700"),
 			r.Method, r.URL.Path, buffer.String())
 	}
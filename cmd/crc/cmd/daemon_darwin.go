@@ -41,17 +41,20 @@ func httpListener() (net.Listener, error) {
 	return ln, nil
 }
 
-func unixgramListener(ctx context.Context, vn *virtualnetwork.VirtualNetwork) (*net.UnixConn, error) {
-	_ = os.Remove(constants.UnixgramSocketPath)
-	conn, err := transport.ListenUnixgram(fmt.Sprintf("unixgram://%v", constants.UnixgramSocketPath))
+// unixgramListener accepts the connection of the VM of the instance with the
+// given address index, each instance has its own socket
+func unixgramListener(ctx context.Context, vn *virtualnetwork.VirtualNetwork, index int) (*net.UnixConn, error) {
+	socketPath := constants.GetUnixgramSocketPath(index)
+	_ = os.Remove(socketPath)
+	conn, err := transport.ListenUnixgram(fmt.Sprintf("unixgram://%v", socketPath))
 	if err != nil {
 		return conn, errors.Wrap(err, "failed to listen unixgram")
 	}
-	if err = constants.EnsureSocketFilesPermissions(constants.UnixgramSocketPath); err != nil {
+	if err = constants.EnsureSocketFilesPermissions(socketPath); err != nil {
 		_ = conn.Close()
 		return nil, errors.Wrap(err, "failed to set permissions for unixgram socket")
 	}
-	logging.Infof("listening on %s", constants.UnixgramSocketPath)
+	logging.Infof("listening on %s", socketPath)
 	vfkitConn, err := transport.AcceptVfkit(conn)
 	if err != nil {
 		return conn, errors.Wrap(err, "failed to accept vfkit connection")
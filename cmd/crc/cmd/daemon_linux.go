@@ -134,7 +134,7 @@ func httpListener() (net.Listener, error) {
 	return ln, nil
 }
 
-func unixgramListener(_ context.Context, _ *virtualnetwork.VirtualNetwork) (*net.UnixConn, error) {
+func unixgramListener(_ context.Context, _ *virtualnetwork.VirtualNetwork, _ int) (*net.UnixConn, error) {
 	return nil, drivers.ErrNotImplemented
 }
 
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	"github.com/crc-org/crc/v2/pkg/crc/api/remote"
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/daemonclient"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var clientCredentialsOutputDir string
+
+func init() {
+	addOutputFormatFlag(daemonClientCredentialsCmd)
+	daemonClientCredentialsCmd.Flags().StringVar(&clientCredentialsOutputDir, "dir", "", "Directory to write the credentials to (default: ~/.crc/remote-api/clients/NAME)")
+	daemonCmd.AddCommand(daemonClientCredentialsCmd)
+	addOutputFormatFlag(daemonRevokeClientCredentialsCmd)
+	daemonCmd.AddCommand(daemonRevokeClientCredentialsCmd)
+}
+
+var daemonClientCredentialsCmd = &cobra.Command{
+	Use:   "client-credentials NAME",
+	Short: "Issue credentials for the remote API of the daemon",
+	Long: fmt.Sprintf(`Issue a client certificate called NAME for the remote API enabled with the '%s' setting.
+The credentials directory also contains the CA certificate and a bearer token which is only accepted with this
+client certificate. Issuing credentials again to NAME revokes its previous ones. Copy the directory to the
+remote host and set %s to https://ADDRESS and %s to the directory there.`,
+		crcConfig.RemoteAPIAddress, daemonclient.DaemonURLEnv, daemonclient.DaemonCredentialsEnv),
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		dir, err := issueClientCredentials(constants.RemoteAPIDir, args[0], clientCredentialsOutputDir)
+		return output.Render(&clientCredentialsResult{
+			Success:   err == nil,
+			Error:     crcErrors.ToSerializableError(err),
+			Directory: dir,
+		}, os.Stdout, outputFormat)
+	},
+}
+
+func issueClientCredentials(serverDir, name, outputDir string) (string, error) {
+	if name == "" || filepath.Base(name) != name {
+		return "", fmt.Errorf("invalid client name '%s'", name)
+	}
+	if outputDir == "" {
+		outputDir = filepath.Join(serverDir, "clients", name)
+	}
+	server, err := remote.LoadOrCreateServer(serverDir)
+	if err != nil {
+		return "", err
+	}
+	return outputDir, server.IssueClientCredentials(name, outputDir)
+}
+
+var daemonRevokeClientCredentialsCmd = &cobra.Command{
+	Use:   "revoke-client-credentials NAME",
+	Short: "Revoke the credentials issued for the remote API of the daemon",
+	Long:  "Revoke the client certificate and the bearer token issued to NAME, the daemon rejects their requests from then on.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		err := revokeClientCredentials(constants.RemoteAPIDir, args[0])
+		return output.Render(&revokeClientCredentialsResult{
+			Success: err == nil,
+			Error:   crcErrors.ToSerializableError(err),
+			Name:    args[0],
+		}, os.Stdout, outputFormat)
+	},
+}
+
+func revokeClientCredentials(serverDir, name string) error {
+	server, err := remote.LoadOrCreateServer(serverDir)
+	if err != nil {
+		return err
+	}
+	return server.RevokeClientCredentials(name)
+}
+
+// remoteAPIMux only serves the API and the events to remote clients, the
+// network and metrics endpoints stay local
+func remoteAPIMux(apiHandler, eventsHandler http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/api/", apiHandler)
+	mux.Handle("/events", eventsHandler)
+	return mux
+}
+
+// remoteAPIListener returns a nil listener when the remote API is disabled
+func remoteAPIListener(cfg crcConfig.Storage) (net.Listener, *remote.Server, error) {
+	address := cfg.Get(crcConfig.RemoteAPIAddress).AsString()
+	if address == "" {
+		return nil, nil, nil
+	}
+	server, err := remote.LoadOrCreateServer(constants.RemoteAPIDir)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to load the remote API credentials")
+	}
+	listener, err := server.Listen(address)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to listen on %s", address)
+	}
+	logging.Infof("listening on %s for remote API access", address)
+	return listener, server, nil
+}
+
+type clientCredentialsResult struct {
+	Success   bool                         `json:"success"`
+	Error     *crcErrors.SerializableError `json:"error,omitempty"`
+	Directory string                       `json:"directory,omitempty"`
+}
+
+func (s *clientCredentialsResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprintf(writer, "Client credentials written to %s\n", s.Directory)
+	return err
+}
+
+type revokeClientCredentialsResult struct {
+	Success bool                         `json:"success"`
+	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	Name    string                       `json:"name"`
+}
+
+func (s *revokeClientCredentialsResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprintf(writer, "Client credentials of %s revoked\n", s.Name)
+	return err
+}
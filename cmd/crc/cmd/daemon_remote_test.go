@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/crc/api/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueClientCredentials(t *testing.T) {
+	serverDir := t.TempDir()
+	dir, err := issueClientCredentials(serverDir, "ci", "")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(serverDir, "clients", "ci"), dir)
+	for _, file := range []string{remote.CACertFile, remote.ClientCertFile, remote.ClientKeyFile, remote.TokenFile} {
+		assert.FileExists(t, filepath.Join(dir, file))
+	}
+
+	_, err = issueClientCredentials(serverDir, "../ci", "")
+	assert.EqualError(t, err, "invalid client name '../ci'")
+}
+
+func TestRevokeClientCredentials(t *testing.T) {
+	serverDir := t.TempDir()
+	_, err := issueClientCredentials(serverDir, "ci", "")
+	require.NoError(t, err)
+	assert.NoError(t, revokeClientCredentials(serverDir, "ci"))
+	assert.EqualError(t, revokeClientCredentials(serverDir, "ci"), "no credentials were issued to client 'ci'")
+}
+
+func TestRemoteAPIMux(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux := remoteAPIMux(ok, ok)
+	for path, status := range map[string]int{
+		"/api/status":       http.StatusOK,
+		"/events":           http.StatusOK,
+		"/metrics":          http.StatusNotFound,
+		"/network/services": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, status, rec.Code, path)
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"errors"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +15,7 @@ import (
 	"github.com/containers/gvisor-tap-vsock/pkg/types"
 	"github.com/crc-org/crc/v2/pkg/crc/api/client"
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -56,7 +58,9 @@ func TestLogResponseBodyLogsNothingWhenResponseSuccessful(t *testing.T) {
 	}
 
 	// When
-	logResponseBodyConditionally(200, &responseBuffer, httpRequest)
+	for _, statusCode := range []int{http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent} {
+		logResponseBodyConditionally(statusCode, &responseBuffer, httpRequest)
+	}
 
 	// Then
 	assert.Equal(t, logBuffer.Len(), 0)
@@ -114,7 +118,7 @@ func TestCreateNewVirtualNetworkConfig(t *testing.T) {
 	assert.Equal(t, "5a:94:ef:e4:0c:dd", virtualNetworkConfig.GatewayMacAddress)
 	assert.Equal(t, types.Protocol("hyperkit"), virtualNetworkConfig.Protocol)
 
-	assert.Len(t, virtualNetworkConfig.DHCPStaticLeases, 1)
+	assert.Equal(t, network.UserNetworkLeases(), virtualNetworkConfig.DHCPStaticLeases)
 	assert.Equal(t, "5a:94:ef:e4:0c:ee", virtualNetworkConfig.DHCPStaticLeases["192.168.127.2"])
 
 	assert.Len(t, virtualNetworkConfig.DNS, 4)
@@ -142,6 +146,25 @@ func TestCreateNewVirtualNetworkConfig(t *testing.T) {
 	assert.Equal(t, net.ParseIP("192.168.127.254"), virtualNetworkConfig.DNS[3].Records[0].IP)
 }
 
+func TestCreateNewVirtualNetworkConfig_WhenDNSRecordsSet_ThenAddZones(t *testing.T) {
+	// Given
+	testCrcConfig := newTestConfig()
+	_, err := testCrcConfig.Set(crcConfig.DNSRecords, "registry.corp.test=192.168.127.254,db.crc.testing=192.168.127.3")
+	assert.NoError(t, err)
+
+	// When
+	virtualNetworkConfig := createNewVirtualNetworkConfig(testCrcConfig)
+
+	// Then
+	assert.Len(t, virtualNetworkConfig.DNS, 5)
+	assert.Equal(t, "corp.test.", virtualNetworkConfig.DNS[0].Name)
+	assert.Equal(t, "registry", virtualNetworkConfig.DNS[0].Records[0].Name)
+	assert.Equal(t, net.ParseIP("192.168.127.254"), virtualNetworkConfig.DNS[0].Records[0].IP)
+	assert.Equal(t, "crc.testing.", virtualNetworkConfig.DNS[2].Name)
+	assert.Equal(t, "db", virtualNetworkConfig.DNS[2].Records[0].Name)
+	assert.Equal(t, "host", virtualNetworkConfig.DNS[2].Records[1].Name)
+}
+
 func TestCreateNewVirtualNetworkConfig_WhenHostNetworkConfigSet_ThenSetNAT(t *testing.T) {
 	// Given
 	testCrcConfig := crcConfig.New(crcConfig.NewEmptyInMemoryStorage(), crcConfig.NewEmptyInMemorySecretStorage())
@@ -231,3 +254,31 @@ func TestGatewayAPIMux_HostsEndpointsRespectModifyHostsFile(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerTransport(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: handlerTransport{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == "/missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `"}`))
+		})},
+	}
+
+	res, err := httpClient.Get("http://services/forwarder/all")
+	assert.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "200 OK", res.Status)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.Equal(t, `{"path":"/forwarder/all"}`, string(body))
+
+	res, err = httpClient.Get("http://services/missing")
+	assert.NoError(t, err)
+	assert.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
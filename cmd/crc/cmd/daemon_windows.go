@@ -38,7 +38,7 @@ func checkIfDaemonIsRunning() (bool, error) {
 	return checkDaemonVersion()
 }
 
-func unixgramListener(_ context.Context, _ *virtualnetwork.VirtualNetwork) (*net.UnixConn, error) {
+func unixgramListener(_ context.Context, _ *virtualnetwork.VirtualNetwork, _ int) (*net.UnixConn, error) {
 	return nil, drivers.ErrNotImplemented
 }
 
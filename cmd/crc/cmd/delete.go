@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/input"
@@ -24,6 +25,7 @@ func init() {
 	addOutputFormatFlag(deleteCmd)
 	addForceFlag(deleteCmd)
 	rootCmd.AddCommand(deleteCmd)
+	addInstanceNameFlag(deleteCmd)
 }
 
 var deleteCmd = &cobra.Command{
@@ -31,7 +33,7 @@ var deleteCmd = &cobra.Command{
 	Short: "Delete the instance",
 	Long:  "Delete the instance",
 	RunE: func(_ *cobra.Command, _ []string) error {
-		return runDelete(os.Stdout, newMachine(), clearCache, constants.MachineCacheDir, outputFormat != jsonFormat, globalForce, outputFormat)
+		return runDelete(os.Stdout, newMachine(), clearCache, constants.MachineCacheDir, outputFormat != output.JSONFormat, globalForce, outputFormat)
 	},
 }
 
@@ -69,7 +71,7 @@ func deleteMachine(client machine.Client, clearCache bool, cacheDir string, inte
 
 func runDelete(writer io.Writer, client machine.Client, clearCache bool, cacheDir string, interactive, force bool, outputFormat string) error {
 	machineDeleted, err := deleteMachine(client, clearCache, cacheDir, interactive, force)
-	return render(&deleteResult{
+	return output.Render(&deleteResult{
 		Success:        err == nil,
 		Error:          crcErrors.ToSerializableError(err),
 		machineDeleted: machineDeleted,
@@ -82,7 +84,7 @@ type deleteResult struct {
 	machineDeleted bool
 }
 
-func (s *deleteResult) prettyPrintTo(writer io.Writer) error {
+func (s *deleteResult) PrettyPrintTo(writer io.Writer) error {
 	if s.Error != nil {
 		return s.Error
 	}
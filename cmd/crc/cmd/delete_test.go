@@ -5,6 +5,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/fakemachine"
 	"github.com/stretchr/testify/assert"
 )
@@ -35,7 +36,7 @@ func TestJSONDelete(t *testing.T) {
 	cacheDir := t.TempDir()
 
 	out := new(bytes.Buffer)
-	assert.NoError(t, runDelete(out, fakemachine.NewClient(), true, cacheDir, false, true, jsonFormat))
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), true, cacheDir, false, true, output.JSONFormat))
 	assert.JSONEq(t, `{"success": true}`, out.String())
 
 	_, err := os.Stat(cacheDir)
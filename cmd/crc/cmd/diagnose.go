@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/diagnose"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/spf13/cobra"
+)
+
+var diagnoseDir string
+
+func init() {
+	addOutputFormatFlag(diagnoseCmd)
+	addInstanceNameFlag(diagnoseCmd)
+	diagnoseCmd.Flags().StringVar(&diagnoseDir, "dir", ".", "Directory where the diagnostics archive is created")
+	rootCmd.AddCommand(diagnoseCmd)
+}
+
+var diagnoseCmd = &cobra.Command{
+	Use:   "diagnose",
+	Short: "Collect the diagnostics of the instance in an archive",
+	Long: "Collect the host information, the preflight checks report, the configuration, the logs, " +
+		"the state of the VM services and of the cluster operators in an archive to attach to bug reports. " +
+		"The secrets and the pull secret are redacted from the archive.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return runDiagnose(cmd.Context(), os.Stdout, config, newMachine(), cluster.NewNonInteractivePullSecretLoader(config, ""), diagnoseDir, outputFormat)
+	},
+}
+
+func runDiagnose(ctx context.Context, writer io.Writer, config crcConfig.Storage, client machine.Client, pullSecret cluster.PullSecretLoader, dir string, outputFormat string) error {
+	result, err := diagnose.Collect(ctx, config, client, pullSecret, dir)
+	diagnoseResult := &diagnoseResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+		Errors:  []string{},
+	}
+	if result != nil {
+		diagnoseResult.Path = result.Path
+		diagnoseResult.Errors = append(diagnoseResult.Errors, result.Errors...)
+	}
+	return output.Render(diagnoseResult, writer, outputFormat)
+}
+
+type diagnoseResult struct {
+	Success bool                         `json:"success"`
+	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	Path    string                       `json:"path,omitempty"`
+	// Errors lists the diagnostics which could not be collected
+	Errors []string `json:"errors"`
+}
+
+func (s *diagnoseResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	for _, err := range s.Errors {
+		if _, err := fmt.Fprintf(writer, "Cannot collect %s\n", err); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(writer, "Diagnostics written to %s\n", s.Path)
+	return err
+}
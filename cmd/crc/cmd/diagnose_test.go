@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosePlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, output.Render(&diagnoseResult{
+		Success: true,
+		Path:    "/tmp/crc-diagnostics-crc-20240101-000000.tar.zst",
+		Errors:  []string{"cluster/clusteroperators.txt: oc get clusteroperators failed"},
+	}, out, ""))
+	assert.Equal(t, `Cannot collect cluster/clusteroperators.txt: oc get clusteroperators failed
+Diagnostics written to /tmp/crc-diagnostics-crc-20240101-000000.tar.zst
+`, out.String())
+}
+
+func TestDiagnoseJSONSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, output.Render(&diagnoseResult{
+		Success: true,
+		Path:    "/tmp/crc-diagnostics-crc-20240101-000000.tar.zst",
+		Errors:  []string{},
+	}, out, output.JSONFormat))
+	assert.JSONEq(t, `{
+  "success": true,
+  "path": "/tmp/crc-diagnostics-crc-20240101-000000.tar.zst",
+  "errors": []
+}`, out.String())
+}
+
+func TestDiagnosePlainError(t *testing.T) {
+	out := new(bytes.Buffer)
+	err := output.Render(&diagnoseResult{
+		Error:  crcErrors.ToSerializableError(assert.AnError),
+		Errors: []string{},
+	}, out, "")
+	assert.EqualError(t, err, assert.AnError.Error())
+	assert.Empty(t, out.String())
+}
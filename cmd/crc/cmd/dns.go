@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	apiClient "github.com/crc-org/crc/v2/pkg/crc/api/client"
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/daemonclient"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{dnsAddCmd, dnsListCmd, dnsRemoveCmd} {
+		addOutputFormatFlag(cmd)
+		dnsCmd.AddCommand(cmd)
+	}
+	rootCmd.AddCommand(dnsCmd)
+}
+
+var dnsCmd = &cobra.Command{
+	Use:   "dns SUBCOMMAND [flags]",
+	Short: "Manage the DNS records of the virtual network",
+	Long: `Add, list and remove DNS records resolved by the DNS server of the user network mode.
+Records are saved in the configuration, and the running daemon is updated when possible.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+var dnsAddCmd = &cobra.Command{
+	Use:   "add HOSTNAME IP",
+	Short: "Add or update a DNS record",
+	Long: `Resolve HOSTNAME to the IPv4 address IP from the instance.
+A HOSTNAME starting with '*.' resolves all the names of its domain which have no record of their own.`,
+	Example: `  crc dns add registry.corp.test 192.168.127.254
+  crc dns add '*.apps.corp.test' 192.168.127.2`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		result, err := addDNSRecord(config, runningDaemonAPIClient(), args[0], args[1])
+		return output.Render(newDNSRecordResult(result, err, "Added DNS record"), os.Stdout, outputFormat)
+	},
+}
+
+var dnsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the DNS records",
+	Long:  "List the DNS records added with 'crc dns add'",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		records, err := listDNSRecords(config, runningDaemonAPIClient())
+		return output.Render(&dnsListResult{
+			Success: err == nil,
+			Error:   crcErrors.ToSerializableError(err),
+			Records: append([]network.DNSRecord{}, records...),
+		}, os.Stdout, outputFormat)
+	},
+}
+
+var dnsRemoveCmd = &cobra.Command{
+	Use:   "remove HOSTNAME",
+	Short: "Remove a DNS record",
+	Long:  "Remove a DNS record added with 'crc dns add'",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		result, err := removeDNSRecord(config, runningDaemonAPIClient(), args[0])
+		return output.Render(newDNSRecordResult(result, err, "Removed DNS record"), os.Stdout, outputFormat)
+	},
+}
+
+// runningDaemonAPIClient returns nil when the daemon is not running, the
+// configuration is then modified directly
+func runningDaemonAPIClient() apiClient.Client {
+	if _, err := daemonclient.GetVersionFromDaemonAPI(); err != nil {
+		logging.Debugf("DNS records will be applied when the daemon starts: %v", err)
+		return nil
+	}
+	return daemonclient.New().APIClient
+}
+
+func addDNSRecord(cfg *crcConfig.Config, client apiClient.Client, hostname, ip string) (apiClient.DNSRecordResult, error) {
+	if client != nil {
+		return client.AddDNSRecord(apiClient.DNSRecordRequest{Hostname: hostname, IP: ip})
+	}
+	record, err := network.NewDNSRecord(hostname, ip)
+	if err != nil {
+		return apiClient.DNSRecordResult{}, err
+	}
+	return apiClient.DNSRecordResult{Record: record}, crcConfig.SetDNSRecord(cfg, record)
+}
+
+func listDNSRecords(cfg *crcConfig.Config, client apiClient.Client) ([]network.DNSRecord, error) {
+	if client != nil {
+		result, err := client.DNSRecords()
+		return result.Records, err
+	}
+	return crcConfig.GetDNSRecords(cfg), nil
+}
+
+func removeDNSRecord(cfg *crcConfig.Config, client apiClient.Client, hostname string) (apiClient.DNSRecordResult, error) {
+	if client != nil {
+		return client.RemoveDNSRecord(hostname)
+	}
+	record, err := crcConfig.RemoveDNSRecord(cfg, hostname)
+	return apiClient.DNSRecordResult{Record: record}, err
+}
+
+type dnsRecordResult struct {
+	Success               bool                         `json:"success"`
+	Error                 *crcErrors.SerializableError `json:"error,omitempty"`
+	Record                *network.DNSRecord           `json:"record,omitempty"`
+	RequiresDaemonRestart bool                         `json:"requiresDaemonRestart"`
+	message               string
+}
+
+func newDNSRecordResult(result apiClient.DNSRecordResult, err error, message string) *dnsRecordResult {
+	if err != nil {
+		return &dnsRecordResult{
+			Error: crcErrors.ToSerializableError(err),
+		}
+	}
+	return &dnsRecordResult{
+		Success:               true,
+		Record:                &result.Record,
+		RequiresDaemonRestart: result.RequiresDaemonRestart,
+		message:               fmt.Sprintf("%s %s", message, result.Record),
+	}
+}
+
+func (s *dnsRecordResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if _, err := fmt.Fprintln(writer, s.message); err != nil {
+		return err
+	}
+	if s.RequiresDaemonRestart {
+		_, err := fmt.Fprintln(writer, "The change takes effect the next time the daemon starts")
+		return err
+	}
+	return nil
+}
+
+type dnsListResult struct {
+	Success bool                         `json:"success"`
+	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	Records []network.DNSRecord          `json:"records"`
+}
+
+func (s *dnsListResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if len(s.Records) == 0 {
+		_, err := fmt.Fprintln(writer, "No DNS records")
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "HOSTNAME\tIP")
+	for _, record := range s.Records {
+		fmt.Fprintf(w, "%s\t%s\n", record.Hostname, record.IP)
+	}
+	return w.Flush()
+}
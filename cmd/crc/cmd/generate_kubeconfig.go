@@ -10,6 +10,7 @@ import (
 
 func init() {
 	rootCmd.AddCommand(generateKubeconfigCmd)
+	addInstanceNameFlag(generateKubeconfigCmd)
 }
 
 var generateKubeconfigCmd = &cobra.Command{
@@ -35,7 +36,7 @@ func runGenerateKubeconfig() error {
 		return fmt.Errorf("the CRC instance is not running, cannot retrieve kubeconfig")
 	}
 
-	data, err := os.ReadFile(constants.KubeconfigFilePath)
+	data, err := os.ReadFile(constants.GetKubeconfigFilePath(instanceName))
 	if err != nil {
 		return fmt.Errorf("error reading kubeconfig: %w", err)
 	}
@@ -8,6 +8,7 @@ import (
 
 func init() {
 	rootCmd.AddCommand(ipCmd)
+	addInstanceNameFlag(ipCmd)
 }
 
 var ipCmd = &cobra.Command{
@@ -49,5 +49,6 @@ func runOcEnv(_ []string) error {
 
 func init() {
 	rootCmd.AddCommand(ocEnvCmd)
+	addInstanceNameFlag(ocEnvCmd)
 	ocEnvCmd.Flags().StringVar(&forceShell, "shell", "", "Set the environment for the specified shell: [fish, cmd, powershell, tcsh, bash, zsh]. Default is auto-detect.")
 }
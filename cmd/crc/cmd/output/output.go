@@ -0,0 +1,32 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+const JSONFormat = "json"
+
+func AddFormatFlag(cmd *cobra.Command, outputFormat *string) {
+	cmd.Flags().StringVarP(outputFormat, "output", "o", "", "Output format. One of: json")
+}
+
+type PrettyPrintable interface {
+	PrettyPrintTo(writer io.Writer) error
+}
+
+func Render(obj PrettyPrintable, writer io.Writer, outputFormat string) error {
+	switch outputFormat {
+	case JSONFormat:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(obj)
+	case "":
+		return obj.PrettyPrintTo(writer)
+	default:
+		return fmt.Errorf("invalid format: %s", outputFormat)
+	}
+}
@@ -64,7 +64,7 @@ func runPodmanEnv() error {
 	// Todo: This need to fixed by using named pipe for windows
 	// https://docs.docker.com/desktop/faqs/#how-do-i-connect-to-the-remote-docker-engine-api
 	if runtime.GOOS != "windows" {
-		fmt.Println(shell.GetEnvString(userShell, "DOCKER_HOST", fmt.Sprintf("unix://%s", constants.GetHostDockerSocketPath())))
+		fmt.Println(shell.GetEnvString(userShell, "DOCKER_HOST", fmt.Sprintf("unix://%s", constants.GetHostDockerSocketPath(instanceName))))
 	} else {
 		fmt.Println(shell.GetEnvString(userShell, "DOCKER_HOST", "npipe:////./pipe/crc-podman"))
 	}
@@ -80,5 +80,6 @@ func runPodmanEnv() error {
 func init() {
 	podmanEnvCmd.Flags().BoolVar(&root, "root", false, "Use root podman in the virtual machine")
 	podmanEnvCmd.Flags().StringVar(&forceShell, "shell", "", "Set the environment for the specified shell: [fish, cmd, powershell, tcsh, bash, zsh]. Default is auto-detect.")
+	addInstanceNameFlag(podmanEnvCmd)
 	rootCmd.AddCommand(podmanEnvCmd)
 }
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/daemonclient"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{portForwardAddCmd, portForwardListCmd, portForwardRemoveCmd} {
+		addOutputFormatFlag(cmd)
+		portForwardCmd.AddCommand(cmd)
+	}
+	rootCmd.AddCommand(portForwardCmd)
+}
+
+var portForwardCmd = &cobra.Command{
+	Use:   "port-forward SUBCOMMAND [flags]",
+	Short: "Manage additional port forwards to the instance",
+	Long: `Forward additional host ports to the instance when using the user network mode.
+Port forwards are saved in the configuration and applied again each time the daemon starts.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+var portForwardAddCmd = &cobra.Command{
+	Use:   "add HOSTPORT:[GUESTIP:]GUESTPORT[/tcp|udp]",
+	Short: "Forward a host port to the instance",
+	Long: `Forward a port of the host to a port of the instance, or of another address of the user mode network.
+GUESTIP defaults to the address of the instance and the protocol defaults to tcp.`,
+	Example: `  crc port-forward add 8080:80
+  crc port-forward add 5353:192.168.127.2:53/udp`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		forward, err := addPortForward(config, args[0], runningDaemonForwarder())
+		result := &portForwardResult{
+			Success: err == nil,
+			Error:   crcErrors.ToSerializableError(err),
+		}
+		if err == nil {
+			result.message = fmt.Sprintf("Forwarding port %s", forward)
+		}
+		return output.Render(result, os.Stdout, outputFormat)
+	},
+}
+
+var portForwardListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the additional port forwards",
+	Long:  "List the port forwards added with 'crc port-forward add'",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return output.Render(&portForwardListResult{
+			Success:      true,
+			PortForwards: append([]network.PortForward{}, crcConfig.GetPortForwards(config)...),
+		}, os.Stdout, outputFormat)
+	},
+}
+
+var portForwardRemoveCmd = &cobra.Command{
+	Use:   "remove HOSTPORT[/tcp|udp] | HOSTPORT:[GUESTIP:]GUESTPORT[/tcp|udp]",
+	Short: "Stop forwarding a host port to the instance",
+	Long:  "Remove a port forward added with 'crc port-forward add'",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		forward, err := removePortForward(config, args[0], runningDaemonForwarder())
+		result := &portForwardResult{
+			Success: err == nil,
+			Error:   crcErrors.ToSerializableError(err),
+		}
+		if err == nil {
+			result.message = fmt.Sprintf("Removed port forward %s", forward)
+		}
+		return output.Render(result, os.Stdout, outputFormat)
+	},
+}
+
+// portForwarder applies port forwards on the virtual network of the daemon
+type portForwarder interface {
+	Expose(req *types.ExposeRequest) error
+	Unexpose(req *types.UnexposeRequest) error
+}
+
+// runningDaemonForwarder returns nil when the daemon is not running, the
+// port forwards will then be applied when it starts
+func runningDaemonForwarder() portForwarder {
+	if _, err := daemonclient.GetVersionFromDaemonAPI(); err != nil {
+		logging.Debugf("Port forwards will be applied when the daemon starts: %v", err)
+		return nil
+	}
+	return daemonclient.New().NetworkClient
+}
+
+func addPortForward(cfg *crcConfig.Config, spec string, forwarder portForwarder) (network.PortForward, error) {
+	forward, err := network.ParsePortForward(spec)
+	if err != nil {
+		return network.PortForward{}, err
+	}
+	forwards := append(crcConfig.GetPortForwards(cfg), forward)
+	if _, err := cfg.Set(crcConfig.PortForwards, network.FormatPortForwards(forwards)); err != nil {
+		return network.PortForward{}, err
+	}
+	if forwarder != nil {
+		if err := forwarder.Expose(forward.ExposeRequest()); err != nil {
+			logging.Warnf("Failed to forward port %s, it will be forwarded the next time the daemon starts: %v", forward, err)
+		}
+	}
+	return forward, nil
+}
+
+func removePortForward(cfg *crcConfig.Config, arg string, forwarder portForwarder) (network.PortForward, error) {
+	matches, err := portForwardMatcher(arg)
+	if err != nil {
+		return network.PortForward{}, err
+	}
+	var (
+		removed network.PortForward
+		found   bool
+		kept    []network.PortForward
+	)
+	for _, forward := range crcConfig.GetPortForwards(cfg) {
+		if !found && matches(forward) {
+			removed, found = forward, true
+			continue
+		}
+		kept = append(kept, forward)
+	}
+	if !found {
+		return network.PortForward{}, fmt.Errorf("no port forward matches '%s'", arg)
+	}
+	if len(kept) == 0 {
+		_, err = cfg.Unset(crcConfig.PortForwards)
+	} else {
+		_, err = cfg.Set(crcConfig.PortForwards, network.FormatPortForwards(kept))
+	}
+	if err != nil {
+		return network.PortForward{}, err
+	}
+	if forwarder != nil {
+		if err := forwarder.Unexpose(removed.UnexposeRequest()); err != nil {
+			logging.Warnf("Failed to stop forwarding port %s: %v", removed, err)
+		}
+	}
+	return removed, nil
+}
+
+// portForwardMatcher accepts either a full port forward, or only its host
+// port and optional protocol
+func portForwardMatcher(arg string) (func(network.PortForward) bool, error) {
+	if strings.Contains(arg, ":") {
+		expected, err := network.ParsePortForward(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(forward network.PortForward) bool {
+			return forward == expected
+		}, nil
+	}
+	// reuse the port forward parser to validate the port and the protocol
+	port, protocol, hasProtocol := strings.Cut(arg, "/")
+	spec := port + ":" + port
+	if hasProtocol {
+		spec += "/" + protocol
+	}
+	expected, err := network.ParsePortForward(spec)
+	if err != nil {
+		return nil, err
+	}
+	return expected.SameHostPort, nil
+}
+
+type portForwardResult struct {
+	Success bool                         `json:"success"`
+	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	message string
+}
+
+func (s *portForwardResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprintln(writer, s.message)
+	return err
+}
+
+type portForwardListResult struct {
+	Success      bool                         `json:"success"`
+	Error        *crcErrors.SerializableError `json:"error,omitempty"`
+	PortForwards []network.PortForward        `json:"portForwards"`
+}
+
+func (s *portForwardListResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if len(s.PortForwards) == 0 {
+		_, err := fmt.Fprintln(writer, "No port forwards")
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PROTOCOL\tHOST PORT\tGUEST")
+	for _, forward := range s.PortForwards {
+		fmt.Fprintf(w, "%s\t%d\t%s:%d\n", forward.Protocol, forward.HostPort, forward.GuestIP, forward.GuestPort)
+	}
+	return w.Flush()
+}
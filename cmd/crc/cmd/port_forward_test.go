@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePortForwarder struct {
+	exposed   []types.ExposeRequest
+	unexposed []types.UnexposeRequest
+}
+
+func (f *fakePortForwarder) Expose(req *types.ExposeRequest) error {
+	f.exposed = append(f.exposed, *req)
+	return nil
+}
+
+func (f *fakePortForwarder) Unexpose(req *types.UnexposeRequest) error {
+	f.unexposed = append(f.unexposed, *req)
+	return nil
+}
+
+func TestAddPortForward(t *testing.T) {
+	cfg := newTestConfig()
+	forwarder := &fakePortForwarder{}
+
+	_, err := addPortForward(cfg, "8080:80", forwarder)
+	require.NoError(t, err)
+	_, err = addPortForward(cfg, "5353:192.168.127.3:53/udp", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "8080:192.168.127.2:80/tcp,5353:192.168.127.3:53/udp", cfg.Get(crcConfig.PortForwards).AsString())
+	assert.Equal(t, []types.ExposeRequest{{Protocol: types.TCP, Local: "127.0.0.1:8080", Remote: "192.168.127.2:80"}}, forwarder.exposed)
+
+	_, err = addPortForward(cfg, "8080:8081", forwarder)
+	assert.Error(t, err)
+	_, err = addPortForward(cfg, "80:80", forwarder)
+	assert.Error(t, err)
+	assert.Len(t, forwarder.exposed, 1)
+}
+
+func TestRemovePortForward(t *testing.T) {
+	cfg := newTestConfig()
+	_, err := cfg.Set(crcConfig.PortForwards, "8080:80,5353:53/udp,5353:53")
+	require.NoError(t, err)
+	forwarder := &fakePortForwarder{}
+
+	removed, err := removePortForward(cfg, "5353/udp", forwarder)
+	require.NoError(t, err)
+	assert.Equal(t, "5353:192.168.127.2:53/udp", removed.String())
+	assert.Equal(t, []types.UnexposeRequest{{Protocol: types.UDP, Local: "127.0.0.1:5353"}}, forwarder.unexposed)
+
+	_, err = removePortForward(cfg, "8080:81", forwarder)
+	assert.EqualError(t, err, "no port forward matches '8080:81'")
+	_, err = removePortForward(cfg, "8080:80", forwarder)
+	require.NoError(t, err)
+	_, err = removePortForward(cfg, "5353", forwarder)
+	require.NoError(t, err)
+	assert.True(t, cfg.Get(crcConfig.PortForwards).IsDefault)
+}
+
+func TestPortForwardListJSON(t *testing.T) {
+	cfg := newTestConfig()
+	_, err := cfg.Set(crcConfig.PortForwards, "8080:80")
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	require.NoError(t, output.Render(&portForwardListResult{Success: true, PortForwards: crcConfig.GetPortForwards(cfg)}, out, output.JSONFormat))
+	assert.JSONEq(t, `{"success": true, "portForwards": [{"protocol": "tcp", "hostPort": 8080, "guestIP": "192.168.127.2", "guestPort": 80}]}`, out.String())
+
+	out.Reset()
+	require.NoError(t, output.Render(&portForwardListResult{Success: true, PortForwards: crcConfig.GetPortForwards(cfg)}, out, ""))
+	assert.Equal(t, "PROTOCOL   HOST PORT   GUEST\ntcp        8080        192.168.127.2:80\n", out.String())
+}
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/crc-org/crc/v2/pkg/crc/preset"
+	"github.com/crc-org/crc/v2/pkg/crc/validation"
+	crcTerminal "github.com/crc-org/crc/v2/pkg/os/terminal"
+	"github.com/spf13/cobra"
+	"github.com/zalando/go-keyring"
+)
+
+var (
+	pullSecretUsername      string
+	pullSecretPasswordStdin bool
+)
+
+func init() {
+	pullSecretAddRegistryCmd.Flags().StringVarP(&pullSecretUsername, "username", "u", "", "Username for the registry")
+	pullSecretAddRegistryCmd.Flags().BoolVar(&pullSecretPasswordStdin, "password-stdin", false, "Read the password or token of the registry from stdin")
+	_ = pullSecretAddRegistryCmd.MarkFlagRequired("username")
+	for _, cmd := range []*cobra.Command{pullSecretShowCmd, pullSecretAddRegistryCmd, pullSecretRemoveRegistryCmd, pullSecretImportCmd} {
+		addOutputFormatFlag(cmd)
+		addInstanceNameFlag(cmd)
+		pullSecretCmd.AddCommand(cmd)
+	}
+	rootCmd.AddCommand(pullSecretCmd)
+}
+
+var pullSecretCmd = &cobra.Command{
+	Use:   "pull-secret SUBCOMMAND [flags]",
+	Short: "Manage the image pull secret",
+	Long: `Manage the registries of the image pull secret stored in the keyring.
+The changes are applied to the running instance without restarting it.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+var pullSecretShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "List the registries of the pull secret",
+	Long:  "List the registries with credentials in the pull secret, the passwords and tokens are never shown",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return runPullSecretShow(os.Stdout, config, outputFormat)
+	},
+}
+
+var pullSecretAddRegistryCmd = &cobra.Command{
+	Use:   "add-registry REGISTRY",
+	Short: "Add the credentials of a registry to the pull secret",
+	Long: `Add the credentials of a registry to the pull secret, they replace the existing credentials of the registry.
+The password is asked for when it is not read from stdin.`,
+	Example: `  crc pull-secret add-registry registry.example.com:5000 --username user
+  echo $TOKEN | crc pull-secret add-registry quay.io/myorg --username user --password-stdin`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		password, err := readRegistryPassword(args[0])
+		if err != nil {
+			return err
+		}
+		return runPullSecretUpdate(cmd.Context(), os.Stdout, config, newMachine(), func(pullSecret string) (string, error) {
+			if pullSecret == "" {
+				return "", errNoPullSecret
+			}
+			return cluster.AddPullSecretRegistry(pullSecret, args[0], pullSecretUsername, password)
+		}, outputFormat)
+	},
+}
+
+var pullSecretRemoveRegistryCmd = &cobra.Command{
+	Use:   "remove-registry REGISTRY",
+	Short: "Remove the credentials of a registry from the pull secret",
+	Long:  "Remove the credentials of a registry from the pull secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPullSecretUpdate(cmd.Context(), os.Stdout, config, newMachine(), func(pullSecret string) (string, error) {
+			if pullSecret == "" {
+				return "", errNoPullSecret
+			}
+			return cluster.RemovePullSecretRegistry(pullSecret, args[0])
+		}, outputFormat)
+	},
+}
+
+var pullSecretImportCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import the registries of a pull secret file",
+	Long: `Add the credentials of the registries of a pull secret or of a container tools auth file, such as
+~/.docker/config.json, to the pull secret. They replace the existing credentials of the same registries.
+The file becomes the pull secret when there is none yet.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		return runPullSecretUpdate(cmd.Context(), os.Stdout, config, newMachine(), importPullSecret(strings.TrimSpace(string(data))), outputFormat)
+	},
+}
+
+var errNoPullSecret = errors.New("there is no pull secret in the keyring, run 'crc start' or 'crc pull-secret import' first")
+
+func importPullSecret(imported string) func(string) (string, error) {
+	return func(pullSecret string) (string, error) {
+		if pullSecret == "" {
+			return imported, validation.ImagePullSecret(imported)
+		}
+		return cluster.MergePullSecrets(pullSecret, imported)
+	}
+}
+
+func readRegistryPassword(registry string) (string, error) {
+	if pullSecretPasswordStdin {
+		password, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(password)), nil
+	}
+	if !crcTerminal.IsRunningInTerminal() {
+		return "", errors.New("cannot ask for the password, use --password-stdin")
+	}
+	var password string
+	if err := survey.AskOne(&survey.Password{
+		Message: fmt.Sprintf("Password or token for %s", registry),
+	}, &password, survey.WithValidator(survey.Required)); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+// keyringPullSecret returns the pull secret stored in the keyring, which
+// crc pull-secret manages, it is empty when there is none
+func keyringPullSecret(config crcConfig.Storage) (string, error) {
+	if crcConfig.GetPreset(config) == preset.OKD {
+		return "", fmt.Errorf("the %s preset does not use a pull secret", preset.OKD.ForDisplay())
+	}
+	if path := config.Get(crcConfig.PullSecretFile).AsString(); path != "" {
+		return "", fmt.Errorf("the pull secret is read from %s, run 'crc config unset %s' to manage it with 'crc pull-secret'", path, crcConfig.PullSecretFile)
+	}
+	pullSecret, err := cluster.LoadFromKeyring()
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", nil
+	}
+	return pullSecret, err
+}
+
+func runPullSecretShow(writer io.Writer, config crcConfig.Storage, outputFormat string) error {
+	result := &pullSecretResult{
+		Registries: []pullSecretRegistry{},
+	}
+	pullSecret, err := cluster.NewNonInteractivePullSecretLoader(config, "").Value()
+	if err == nil {
+		result.Source = "keyring"
+		if path := config.Get(crcConfig.PullSecretFile).AsString(); path != "" {
+			result.Source = path
+		}
+		if crcConfig.GetPreset(config) == preset.OKD {
+			result.Source = preset.OKD.ForDisplay()
+		}
+		err = result.setRegistries(pullSecret)
+	}
+	result.Success = err == nil
+	result.Error = crcErrors.ToSerializableError(err)
+	return output.Render(result, writer, outputFormat)
+}
+
+// runPullSecretUpdate stores the pull secret returned by update in the
+// keyring and sets it in the instance when it is running
+func runPullSecretUpdate(ctx context.Context, writer io.Writer, config crcConfig.Storage, client machine.Client, update func(string) (string, error), outputFormat string) error {
+	result := &pullSecretResult{
+		Source:     "keyring",
+		Registries: []pullSecretRegistry{},
+	}
+	err := func() error {
+		pullSecret, err := keyringPullSecret(config)
+		if err != nil {
+			return err
+		}
+		pullSecret, err = update(pullSecret)
+		if err != nil {
+			return err
+		}
+		if err := cluster.StoreInKeyring(pullSecret); err != nil {
+			return err
+		}
+		if err := result.setRegistries(pullSecret); err != nil {
+			return err
+		}
+		if exists, err := client.Exists(); err != nil || !exists {
+			return err
+		}
+		running, err := client.IsRunning()
+		if err != nil || !running {
+			return err
+		}
+		if err := client.UpdatePullSecret(ctx, cluster.NewNonInteractivePullSecretLoader(config, "")); err != nil {
+			return fmt.Errorf("the pull secret is stored but cannot be set in the instance: %w", err)
+		}
+		result.InstanceUpdated = true
+		return nil
+	}()
+	result.Success = err == nil
+	result.Error = crcErrors.ToSerializableError(err)
+	result.updated = true
+	return output.Render(result, writer, outputFormat)
+}
+
+type pullSecretRegistry struct {
+	Name     string `json:"name"`
+	Username string `json:"username,omitempty"`
+}
+
+type pullSecretResult struct {
+	Success bool                         `json:"success"`
+	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	// Source is where the pull secret is read from
+	Source     string               `json:"source,omitempty"`
+	Registries []pullSecretRegistry `json:"registries"`
+	// InstanceUpdated is set when the running instance uses the new pull
+	// secret
+	InstanceUpdated bool `json:"instanceUpdated,omitempty"`
+	updated         bool
+}
+
+func (s *pullSecretResult) setRegistries(pullSecret string) error {
+	registries, err := cluster.PullSecretRegistries(pullSecret)
+	if err != nil {
+		return err
+	}
+	for _, registry := range registries {
+		s.Registries = append(s.Registries, pullSecretRegistry{
+			Name:     registry.Name,
+			Username: registry.Username,
+		})
+	}
+	return nil
+}
+
+func (s *pullSecretResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if s.updated {
+		if s.InstanceUpdated {
+			fmt.Fprintln(writer, "Pull secret updated in the keyring and in the running instance")
+		} else {
+			fmt.Fprintln(writer, "Pull secret updated in the keyring, the instance uses it when it is started")
+		}
+	} else {
+		fmt.Fprintf(writer, "Pull secret read from %s\n", s.Source)
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "REGISTRY\tUSERNAME")
+	for _, registry := range s.Registries {
+		username := registry.Username
+		if username == "" {
+			username = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", registry.Name, username)
+	}
+	return w.Flush()
+}
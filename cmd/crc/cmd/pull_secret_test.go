@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	testPullSecret = `{"auths":{"cloud.openshift.com":{"auth":"Y2xvdWQ6dG9rZW4="}}}`                                                        // #nosec G101
+	extraRegistry  = `{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNzd29yZA=="}}}`                                                   // #nosec G101
+	mergedSecret   = `{"auths":{"cloud.openshift.com":{"auth":"Y2xvdWQ6dG9rZW4="},"registry.example.com":{"auth":"dXNlcjpwYXNzd29yZA=="}}}` // #nosec G101
+)
+
+func TestPullSecretImport(t *testing.T) {
+	keyring.MockInit()
+	cfg := newTestConfig()
+	client := fakemachine.NewClient()
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runPullSecretUpdate(context.Background(), out, cfg, client, importPullSecret(testPullSecret), ""))
+	out.Reset()
+	assert.NoError(t, runPullSecretUpdate(context.Background(), out, cfg, client, importPullSecret(extraRegistry), ""))
+	assert.Equal(t, `Pull secret updated in the keyring and in the running instance
+REGISTRY               USERNAME
+cloud.openshift.com    cloud
+registry.example.com   user
+`, out.String())
+
+	stored, err := cluster.LoadFromKeyring()
+	require.NoError(t, err)
+	assert.JSONEq(t, mergedSecret, stored)
+	assert.JSONEq(t, mergedSecret, client.PullSecret)
+}
+
+func TestPullSecretAddAndRemoveRegistry(t *testing.T) {
+	keyring.MockInit()
+	cfg := newTestConfig()
+	require.NoError(t, cluster.StoreInKeyring(testPullSecret))
+	client := fakemachine.NewClient()
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runPullSecretUpdate(context.Background(), out, cfg, client, func(pullSecret string) (string, error) {
+		return cluster.AddPullSecretRegistry(pullSecret, "registry.example.com", "user", "password")
+	}, output.JSONFormat))
+	assert.JSONEq(t, `{
+  "success": true,
+  "source": "keyring",
+  "registries": [
+    {"name": "cloud.openshift.com", "username": "cloud"},
+    {"name": "registry.example.com", "username": "user"}
+  ],
+  "instanceUpdated": true
+}`, out.String())
+	assert.NotContains(t, out.String(), "password")
+
+	out.Reset()
+	assert.NoError(t, runPullSecretUpdate(context.Background(), out, cfg, client, func(pullSecret string) (string, error) {
+		return cluster.RemovePullSecretRegistry(pullSecret, "registry.example.com")
+	}, output.JSONFormat))
+	assert.JSONEq(t, testPullSecret, client.PullSecret)
+}
+
+func TestPullSecretUpdateFailingInstance(t *testing.T) {
+	keyring.MockInit()
+	cfg := newTestConfig()
+	require.NoError(t, cluster.StoreInKeyring(testPullSecret))
+
+	err := runPullSecretUpdate(context.Background(), new(bytes.Buffer), cfg, fakemachine.NewFailingClient(), importPullSecret(extraRegistry), "")
+	assert.EqualError(t, err, "the pull secret is stored but cannot be set in the instance: pull secret update failed")
+	stored, err := cluster.LoadFromKeyring()
+	require.NoError(t, err)
+	assert.JSONEq(t, mergedSecret, stored)
+}
+
+func TestPullSecretFromFile(t *testing.T) {
+	keyring.MockInit()
+	cfg := newTestConfig()
+	path := filepath.Join(t.TempDir(), "pull-secret.json")
+	require.NoError(t, os.WriteFile(path, []byte(testPullSecret), 0600))
+	_, err := cfg.Set(crcConfig.PullSecretFile, path)
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runPullSecretShow(out, cfg, ""))
+	assert.Equal(t, `Pull secret read from `+path+`
+REGISTRY              USERNAME
+cloud.openshift.com   cloud
+`, out.String())
+
+	err = runPullSecretUpdate(context.Background(), new(bytes.Buffer), cfg, fakemachine.NewClient(), importPullSecret(extraRegistry), "")
+	assert.EqualError(t, err, "the pull secret is read from "+path+", run 'crc config unset pull-secret-file' to manage it with 'crc pull-secret'")
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/crc-org/crc/v2/pkg/crc/preflight"
 	"github.com/crc-org/crc/v2/pkg/crc/segment"
 	"github.com/crc-org/crc/v2/pkg/crc/telemetry"
+	"github.com/crc-org/crc/v2/pkg/crc/validation"
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/util/exec"
 )
@@ -44,6 +46,7 @@ var rootCmd = &cobra.Command{
 
 var (
 	globalForce   bool
+	instanceName  = constants.DefaultName
 	viper         *crcConfig.ViperStorage
 	config        *crcConfig.Config
 	segmentClient *segment.Client
@@ -87,6 +90,10 @@ func runPrerun(cmd *cobra.Command) error {
 		logging.Debugf("%s", str)
 	}
 
+	if err := validation.ValidateInstanceName(instanceName); err != nil {
+		return err
+	}
+
 	if cmd != daemonCmd {
 		if err := constants.EnsureAdminHelperLogFileExists(); err != nil {
 			logging.Warn("Error creating admin helper log file: ", err.Error())
@@ -158,18 +165,37 @@ func setProxyDefaults() error {
 }
 
 func newConfig() (*crcConfig.Config, *crcConfig.ViperStorage, error) {
-	viper, err := crcConfig.NewViperStorage(constants.ConfigPath, constants.CrcEnvPrefix)
+	viper, err := crcConfig.NewLayeredViperStorage(constants.SystemConfigPath, constants.ConfigPath, projectConfigFile(), constants.CrcEnvPrefix)
 	if err != nil {
 		return nil, nil, err
 	}
+	// fail early rather than ignoring the keys locked in an unreadable
+	// system configuration file
+	if err := viper.Validate(); err != nil {
+		return nil, nil, err
+	}
 	cfg := crcConfig.New(viper, crcConfig.NewSecretStorage())
 	crcConfig.RegisterSettings(cfg)
 	preflight.RegisterSettings(cfg)
 	return cfg, viper, nil
 }
 
+// projectConfigFile returns the project configuration file of the current
+// directory when it is enabled with the CRC_ENABLE_PROJECT_CONFIG environment
+// variable, the directory crc is run from is not trusted otherwise
+func projectConfigFile() string {
+	if enabled, _ := strconv.ParseBool(os.Getenv(constants.ProjectConfigEnvVar)); !enabled {
+		return ""
+	}
+	return constants.ProjectConfigFile
+}
+
 func newMachine() machine.Client {
-	return machine.NewSynchronizedMachine(machine.NewClient(constants.DefaultName, logging.IsDebug(), config))
+	return machine.NewSynchronizedMachine(machine.NewClient(instanceName, logging.IsDebug(), config))
+}
+
+func addInstanceNameFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&instanceName, "name", constants.DefaultName, "Name of the instance to act on")
 }
 
 func addForceFlag(cmd *cobra.Command) {
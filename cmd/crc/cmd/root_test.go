@@ -4,9 +4,17 @@ import (
 	"os"
 	"testing"
 
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
 	"github.com/stretchr/testify/assert"
 )
 
+// newTestConfig returns an in-memory configuration with the crc settings
+func newTestConfig() *crcConfig.Config {
+	cfg := crcConfig.New(crcConfig.NewEmptyInMemoryStorage(), crcConfig.NewEmptyInMemorySecretStorage())
+	crcConfig.RegisterSettings(cfg)
+	return cfg
+}
+
 func TestCrcManPageGenerator_WhenInvoked_GeneratesManPagesForAllCrcSubCommands(t *testing.T) {
 	// Given
 	dir := t.TempDir()
@@ -24,7 +32,15 @@ func TestCrcManPageGenerator_WhenInvoked_GeneratesManPagesForAllCrcSubCommands(t
 	}
 	assert.ElementsMatch(t, []string{
 		"crc-bundle-generate.1",
+		"crc-bundle-info.1",
+		"crc-bundle-list.1",
+		"crc-bundle-prune.1",
+		"crc-bundle-sign.1",
+		"crc-bundle-verify.1",
 		"crc-bundle.1",
+		"crc-certs-rotate.1",
+		"crc-certs-status.1",
+		"crc-certs.1",
 		"crc-cleanup.1",
 		"crc-config-get.1",
 		"crc-config-set.1",
@@ -33,11 +49,30 @@ func TestCrcManPageGenerator_WhenInvoked_GeneratesManPagesForAllCrcSubCommands(t
 		"crc-config.1",
 		"crc-console.1",
 		"crc-delete.1",
+		"crc-diagnose.1",
+		"crc-dns-add.1",
+		"crc-dns-list.1",
+		"crc-dns-remove.1",
+		"crc-dns.1",
 		"crc-generate-kubeconfig.1",
 		"crc-ip.1",
 		"crc-oc-env.1",
 		"crc-podman-env.1",
+		"crc-port-forward-add.1",
+		"crc-port-forward-list.1",
+		"crc-port-forward-remove.1",
+		"crc-port-forward.1",
+		"crc-pull-secret-add-registry.1",
+		"crc-pull-secret-import.1",
+		"crc-pull-secret-remove-registry.1",
+		"crc-pull-secret-show.1",
+		"crc-pull-secret.1",
 		"crc-setup.1",
+		"crc-snapshot-create.1",
+		"crc-snapshot-delete.1",
+		"crc-snapshot-list.1",
+		"crc-snapshot-restore.1",
+		"crc-snapshot.1",
 		"crc-start.1",
 		"crc-status.1",
 		"crc-stop.1",
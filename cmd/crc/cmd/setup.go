@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"text/tabwriter"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
@@ -19,6 +21,7 @@ import (
 
 var (
 	checkOnly             bool
+	reportOnly            bool
 	forceShowProgressbars bool
 )
 
@@ -26,6 +29,7 @@ func init() {
 	setupCmd.Flags().Bool(crcConfig.ExperimentalFeatures, false, "Allow the use of experimental features")
 	setupCmd.Flags().StringP(crcConfig.Bundle, "b", constants.GetDefaultBundlePath(crcConfig.GetPreset(config)), crcConfig.BundleHelpMsg(config))
 	setupCmd.Flags().BoolVar(&checkOnly, "check-only", false, "Only run the preflight checks, don't try to fix any misconfiguration")
+	setupCmd.Flags().BoolVar(&reportOnly, "report", false, "Run all the preflight checks and report their status, don't try to fix any misconfiguration")
 	setupCmd.Flags().BoolVar(&forceShowProgressbars, "show-progressbars", false, "Always show the progress bars for download and extraction")
 	addOutputFormatFlag(setupCmd)
 	rootCmd.AddCommand(setupCmd)
@@ -44,6 +48,10 @@ var setupCmd = &cobra.Command{
 }
 
 func runSetup(_ []string) error {
+	if reportOnly {
+		return runSetupReport(os.Stdout, preflight.ReportHost(config), outputFormat)
+	}
+
 	if config.Get(crcConfig.ConsentTelemetry).AsString() == "" {
 		fmt.Println("CRC is constantly improving and we would like to know more about usage (more details at https://developers.redhat.com/article/tool-data-collection)")
 		fmt.Println("Your preference can be changed manually if desired using 'crc config set consent-telemetry <yes/no>'")
@@ -60,7 +68,7 @@ func runSetup(_ []string) error {
 		}
 	}
 
-	if err := validation.ValidateBundle(config.Get(crcConfig.Bundle).AsString(), crcConfig.GetPreset(config)); err != nil {
+	if err := validation.ValidateBundle(config.Get(crcConfig.Bundle).AsString(), crcConfig.GetPreset(config), bundleSignaturePolicy(config)); err != nil {
 		return err
 	}
 
@@ -74,7 +82,7 @@ func runSetup(_ []string) error {
 		}
 	}
 
-	return render(&setupResult{
+	return output.Render(&setupResult{
 		Success: err == nil,
 		Error:   crcErrors.ToSerializableError(err),
 	}, os.Stdout, outputFormat)
@@ -85,7 +93,7 @@ type setupResult struct {
 	Error   *crcErrors.SerializableError `json:"error,omitempty"`
 }
 
-func (s *setupResult) prettyPrintTo(writer io.Writer) error {
+func (s *setupResult) PrettyPrintTo(writer io.Writer) error {
 	if s.Error != nil {
 		return s.Error
 	}
@@ -93,3 +101,47 @@ func (s *setupResult) prettyPrintTo(writer io.Writer) error {
 		"Use 'crc start' to start the instance")
 	return err
 }
+
+func runSetupReport(writer io.Writer, report *preflight.Report, outputFormat string) error {
+	if err := output.Render(&setupReportResult{report}, writer, outputFormat); err != nil {
+		return err
+	}
+	if !report.Success {
+		return exec.CodeExitError{
+			Err:  fmt.Errorf("%d preflight checks failed", len(report.Failed())),
+			Code: preflightFailedExitCode,
+		}
+	}
+	return nil
+}
+
+type setupReportResult struct {
+	*preflight.Report
+}
+
+func (s *setupReportResult) PrettyPrintTo(writer io.Writer) error {
+	w := tabwriter.NewWriter(writer, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tCHECK\tDESCRIPTION")
+	for _, check := range s.Checks {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", check.Status, check.Name, check.Description)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, check := range s.Failed() {
+		if _, err := fmt.Fprintf(writer, "\n%s: %s\n", check.Name, check.Error); err != nil {
+			return err
+		}
+		fix := "no automatic fix"
+		switch {
+		case check.Fixable && check.FixRequiresPrivileges:
+			fix = "fixed by 'crc setup', requires administrator privileges"
+		case check.Fixable:
+			fix = "fixed by 'crc setup'"
+		}
+		if _, err := fmt.Fprintf(writer, "  %s (%s)\n", check.FixDescription, fix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,13 +5,16 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/preflight"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/exec"
 )
 
 func TestSetupRenderActionPlainSuccess(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, render(&setupResult{
+	assert.NoError(t, output.Render(&setupResult{
 		Success: true,
 	}, out, ""))
 	assert.Equal(t, "Your system is correctly setup for using CRC. Use 'crc start' to start the instance\n", out.String())
@@ -20,7 +23,7 @@ func TestSetupRenderActionPlainSuccess(t *testing.T) {
 func TestSetupRenderActionPlainFailure(t *testing.T) {
 	out := new(bytes.Buffer)
 	err := errors.New("broken")
-	assert.EqualError(t, render(&setupResult{
+	assert.EqualError(t, output.Render(&setupResult{
 		Success: false,
 		Error:   crcErrors.ToSerializableError(err),
 	}, out, ""), "broken")
@@ -29,17 +32,46 @@ func TestSetupRenderActionPlainFailure(t *testing.T) {
 
 func TestSetupRenderActionJSONSuccess(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, render(&setupResult{
+	assert.NoError(t, output.Render(&setupResult{
 		Success: true,
-	}, out, jsonFormat))
+	}, out, output.JSONFormat))
 	assert.JSONEq(t, `{"success": true}`, out.String())
 }
 
 func TestSetupRenderActionJSONFailure(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, render(&setupResult{
+	assert.NoError(t, output.Render(&setupResult{
 		Success: false,
 		Error:   crcErrors.ToSerializableError(errors.New("broken")),
-	}, out, jsonFormat))
+	}, out, output.JSONFormat))
 	assert.JSONEq(t, `{"success": false, "error": "broken"}`, out.String())
 }
+
+func TestSetupReport(t *testing.T) {
+	report := &preflight.Report{
+		Checks: []preflight.CheckResult{
+			{Name: "check-ram", Description: "Checking minimum RAM requirements", Status: preflight.CheckPassed},
+			{Name: "check-libvirt-running", Description: "Checking if libvirt daemon is running", Status: preflight.CheckFailed,
+				Error: "libvirt is not running", FixDescription: "Starting libvirt service", Fixable: true, FixRequiresPrivileges: true},
+		},
+	}
+
+	out := new(bytes.Buffer)
+	err := runSetupReport(out, report, "")
+	var exitErr exec.CodeExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, preflightFailedExitCode, exitErr.ExitStatus())
+	assert.Equal(t, `STATUS   CHECK                   DESCRIPTION
+passed   check-ram               Checking minimum RAM requirements
+failed   check-libvirt-running   Checking if libvirt daemon is running
+
+check-libvirt-running: libvirt is not running
+  Starting libvirt service (fixed by 'crc setup', requires administrator privileges)
+`, out.String())
+
+	out.Reset()
+	report.Checks = report.Checks[:1]
+	report.Success = true
+	assert.NoError(t, runSetupReport(out, report, output.JSONFormat))
+	assert.JSONEq(t, `{"success": true, "checks": [{"name": "check-ram", "description": "Checking minimum RAM requirements", "status": "passed", "fixable": false, "fixRequiresPrivileges": false}]}`, out.String())
+}
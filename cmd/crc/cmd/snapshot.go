@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/input"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{snapshotCreateCmd, snapshotListCmd, snapshotRestoreCmd, snapshotDeleteCmd} {
+		addOutputFormatFlag(cmd)
+		addInstanceNameFlag(cmd)
+		snapshotCmd.AddCommand(cmd)
+	}
+	addForceFlag(snapshotRestoreCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot SUBCOMMAND [flags]",
+	Short: "Manage snapshots of the instance",
+	Long:  "Create, list, restore and delete snapshots of a stopped instance",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Create a snapshot of the stopped instance",
+	Long:  "Save the disk image, kubeconfig and password files of the stopped instance",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		err := newMachine().CreateSnapshot(args[0])
+		return output.Render(&snapshotResult{
+			Success: err == nil,
+			Error:   crcErrors.ToSerializableError(err),
+			message: fmt.Sprintf("Created snapshot %s", args[0]),
+		}, os.Stdout, outputFormat)
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the snapshots of the instance",
+	Long:  "List the snapshots of the instance",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return runSnapshotList(os.Stdout, newMachine(), outputFormat)
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore NAME",
+	Short: "Restore a snapshot of the stopped instance",
+	Long:  "Roll back the stopped instance to the state saved in the snapshot",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return runSnapshotRestore(os.Stdout, newMachine(), args[0], outputFormat != output.JSONFormat, globalForce, outputFormat)
+	},
+}
+
+var snapshotDeleteCmd = &cobra.Command{
+	Use:   "delete NAME",
+	Short: "Delete a snapshot of the instance",
+	Long:  "Delete a snapshot of the instance",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		err := newMachine().DeleteSnapshot(args[0])
+		return output.Render(&snapshotResult{
+			Success: err == nil,
+			Error:   crcErrors.ToSerializableError(err),
+			message: fmt.Sprintf("Deleted snapshot %s", args[0]),
+		}, os.Stdout, outputFormat)
+	},
+}
+
+func restoreSnapshot(client machine.Client, name string, interactive, force bool) (bool, error) {
+	if err := checkIfMachineMissing(client); err != nil {
+		return false, err
+	}
+	if !interactive && !force {
+		return false, errors.New("non-interactive restore requires --force")
+	}
+	yes := input.PromptUserForYesOrNo(fmt.Sprintf("Do you want to discard the current state of the instance and restore snapshot %s", name), force)
+	if !yes {
+		return false, nil
+	}
+	return true, client.RestoreSnapshot(name)
+}
+
+func runSnapshotRestore(writer io.Writer, client machine.Client, name string, interactive, force bool, outputFormat string) error {
+	restored, err := restoreSnapshot(client, name, interactive, force)
+	result := &snapshotResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+	}
+	if restored {
+		result.message = fmt.Sprintf("Restored snapshot %s", name)
+	}
+	return output.Render(result, writer, outputFormat)
+}
+
+func runSnapshotList(writer io.Writer, client machine.Client, outputFormat string) error {
+	snapshots, err := client.ListSnapshots()
+	result := &snapshotListResult{
+		Success:   err == nil,
+		Error:     crcErrors.ToSerializableError(err),
+		Snapshots: []snapshotInfo{},
+	}
+	for _, snapshot := range snapshots {
+		result.Snapshots = append(result.Snapshots, snapshotInfo{
+			Name:         snapshot.Name,
+			CreationTime: snapshot.CreationTime,
+		})
+	}
+	return output.Render(result, writer, outputFormat)
+}
+
+type snapshotResult struct {
+	Success bool                         `json:"success"`
+	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	message string
+}
+
+func (s *snapshotResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if s.message == "" {
+		return nil
+	}
+	_, err := fmt.Fprintln(writer, s.message)
+	return err
+}
+
+type snapshotInfo struct {
+	Name         string    `json:"name"`
+	CreationTime time.Time `json:"creationTime"`
+}
+
+type snapshotListResult struct {
+	Success   bool                         `json:"success"`
+	Error     *crcErrors.SerializableError `json:"error,omitempty"`
+	Snapshots []snapshotInfo               `json:"snapshots"`
+}
+
+func (s *snapshotListResult) PrettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if len(s.Snapshots) == 0 {
+		_, err := fmt.Fprintln(writer, "No snapshots")
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCREATED")
+	for _, snapshot := range s.Snapshots {
+		fmt.Fprintf(w, "%s\t%s\n", snapshot.Name, snapshot.CreationTime.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
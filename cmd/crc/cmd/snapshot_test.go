@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotListPlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runSnapshotList(out, fakemachine.NewClient(), ""))
+	assert.Equal(t, "No snapshots\n", out.String())
+}
+
+func TestSnapshotListJSONSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runSnapshotList(out, fakemachine.NewClient(), output.JSONFormat))
+	assert.JSONEq(t, `{"success": true, "snapshots": []}`, out.String())
+}
+
+func TestSnapshotListJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runSnapshotList(out, fakemachine.NewFailingClient(), output.JSONFormat))
+	assert.JSONEq(t, `{"success": false, "snapshots": [], "error": "snapshot listing failed"}`, out.String())
+}
+
+func TestSnapshotRestorePlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runSnapshotRestore(out, fakemachine.NewClient(), "pristine", true, true, ""))
+	assert.Equal(t, "Restored snapshot pristine\n", out.String())
+}
+
+func TestSnapshotRestoreNonInteractiveWithoutForce(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runSnapshotRestore(out, fakemachine.NewClient(), "pristine", false, false, output.JSONFormat))
+	assert.JSONEq(t, `{"success": false, "error": "non-interactive restore requires --force"}`, out.String())
+}
+
+func TestSnapshotRestoreJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runSnapshotRestore(out, fakemachine.NewFailingClient(), "pristine", false, true, output.JSONFormat))
+	assert.JSONEq(t, `{"success": false, "error": "snapshot restore failed"}`, out.String())
+}
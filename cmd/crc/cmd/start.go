@@ -13,12 +13,14 @@ import (
 	"go.podman.io/common/pkg/strongunits"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	"github.com/crc-org/crc/v2/pkg/crc/cluster"
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/crc-org/crc/v2/pkg/crc/daemonclient"
 	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
 	"github.com/crc-org/crc/v2/pkg/crc/network"
@@ -34,6 +36,7 @@ import (
 
 func init() {
 	rootCmd.AddCommand(startCmd)
+	addInstanceNameFlag(startCmd)
 	addOutputFormatFlag(startCmd)
 
 	flagSet := pflag.NewFlagSet("start", pflag.ExitOnError)
@@ -46,8 +49,11 @@ func init() {
 	flagSet.Bool(crcConfig.DisableUpdateCheck, false, "Don't check for update")
 
 	startCmd.Flags().AddFlagSet(flagSet)
+	startCmd.Flags().StringVarP(&startProfileFile, "file", "f", "", "Path of a YAML or JSON profile with the settings to use for this start")
 }
 
+var startProfileFile string
+
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the instance",
@@ -56,10 +62,35 @@ var startCmd = &cobra.Command{
 		if err := viper.BindFlagSet(cmd.Flags()); err != nil {
 			return err
 		}
+		if startProfileFile != "" {
+			if err := applyStartProfile(cmd.Flags(), startProfileFile); err != nil {
+				return err
+			}
+		}
 		return renderStartResult(runStart(cmd.Context()))
 	},
 }
 
+// applyStartProfile makes the settings of the profile file take precedence over
+// the configuration file for this invocation. Flags given on the command line
+// still win over the profile.
+func applyStartProfile(flags *pflag.FlagSet, path string) error {
+	profile, err := crcConfig.LoadProfile(path)
+	if err != nil {
+		return err
+	}
+	var changedFlags []string
+	flags.Visit(func(flag *pflag.Flag) {
+		changedFlags = append(changedFlags, flag.Name)
+	})
+	profileConfig, err := crcConfig.ApplyProfile(config, profile.Without(changedFlags...), preflight.RegisterSettings)
+	if err != nil {
+		return err
+	}
+	config = profileConfig
+	return nil
+}
+
 func runStart(ctx context.Context) (*types.StartResult, error) {
 	if err := validateStartFlags(); err != nil {
 		return nil, err
@@ -69,26 +100,7 @@ func runStart(ctx context.Context) (*types.StartResult, error) {
 		logging.Debugf("Unable to find out if a new version is available: %v", err)
 	}
 
-	startConfig := types.StartConfig{
-		BundlePath:        config.Get(crcConfig.Bundle).AsString(),
-		Memory:            strongunits.MiB(config.Get(crcConfig.Memory).AsUInt()),
-		DiskSize:          strongunits.GiB(config.Get(crcConfig.DiskSize).AsUInt()),
-		CPUs:              config.Get(crcConfig.CPUs).AsUInt(),
-		NameServer:        config.Get(crcConfig.NameServer).AsString(),
-		PullSecret:        cluster.NewInteractivePullSecretLoader(config),
-		KubeAdminPassword: config.Get(crcConfig.KubeAdminPassword).AsString(),
-		DeveloperPassword: config.Get(crcConfig.DeveloperPassword).AsString(),
-		Preset:            crcConfig.GetPreset(config),
-		IngressHTTPPort:   config.Get(crcConfig.IngressHTTPPort).AsUInt(),
-		IngressHTTPSPort:  config.Get(crcConfig.IngressHTTPSPort).AsUInt(),
-		EnableSharedDirs:  config.Get(crcConfig.EnableSharedDirs).AsBool(),
-
-		EmergencyLogin: config.Get(crcConfig.EmergencyLogin).AsBool(),
-
-		PersistentVolumeSize: config.Get(crcConfig.PersistentVolumeSize).AsInt(),
-
-		EnableBundleQuayFallback: config.Get(crcConfig.EnableBundleQuayFallback).AsBool(),
-	}
+	startConfig := machine.NewStartConfig(config, cluster.NewInteractivePullSecretLoader(config))
 
 	client := newMachine()
 	isRunning, _ := client.IsRunning()
@@ -110,7 +122,7 @@ func runStart(ctx context.Context) (*types.StartResult, error) {
 }
 
 func renderStartResult(result *types.StartResult, err error) error {
-	return render(&startResult{
+	return output.Render(&startResult{
 		Success:       err == nil,
 		Error:         crcErrors.ToSerializableError(err),
 		ClusterConfig: toClusterConfig(result),
@@ -157,7 +169,7 @@ type startResult struct {
 	ClusterConfig *clusterConfig               `json:"clusterConfig,omitempty"`
 }
 
-func (s *startResult) prettyPrintTo(writer io.Writer) error {
+func (s *startResult) PrettyPrintTo(writer io.Writer) error {
 	if s.Error != nil {
 		var e *crcErrors.PreflightError
 		if errors.As(s.Error, &e) {
@@ -172,6 +184,13 @@ func (s *startResult) prettyPrintTo(writer io.Writer) error {
 	return writeTemplatedMessage(writer, s)
 }
 
+// bundleSignaturePolicy returns the policy checking the signature of the
+// custom bundles and the sha256sum of the default bundles, it is nil when
+// enforce-bundle-signature is not set
+func bundleSignaturePolicy(config crcConfig.Storage) *bundle.SignaturePolicy {
+	return bundle.NewSignaturePolicy(config.Get(crcConfig.EnforceBundleSignature).AsBool(), crcConfig.GetTrustedBundleKeys(config), crcConfig.GetBundleMirrors(config))
+}
+
 func validateStartFlags() error {
 	if err := validation.ValidateMemory(strongunits.MiB(config.Get(crcConfig.Memory).AsUInt()), crcConfig.GetPreset(config)); err != nil {
 		return err
@@ -182,7 +201,7 @@ func validateStartFlags() error {
 	if err := validation.ValidateDiskSize(strongunits.GiB(config.Get(crcConfig.DiskSize).AsUInt())); err != nil {
 		return err
 	}
-	if err := validation.ValidateBundle(config.Get(crcConfig.Bundle).AsString(), crcConfig.GetPreset(config)); err != nil {
+	if err := validation.ValidateBundle(config.Get(crcConfig.Bundle).AsString(), crcConfig.GetPreset(config), bundleSignaturePolicy(config)); err != nil {
 		return err
 	}
 	if config.Get(crcConfig.NameServer).AsString() != "" {
@@ -314,7 +333,7 @@ func writeMicroShiftTemplatedMessage(writer io.Writer) error {
 	return parsed.Execute(writer, &templateVariables{
 		EvalCommandLine:   shell.GenerateUsageHint(userShell, "crc oc-env"),
 		CommandLinePrefix: commandLinePrefix(userShell),
-		KubeConfigPath:    constants.KubeconfigFilePath,
+		KubeConfigPath:    constants.GetKubeconfigFilePath(instanceName),
 	})
 }
 
@@ -6,6 +6,7 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/preset"
 	"github.com/crc-org/crc/v2/pkg/os/shell"
@@ -19,7 +20,7 @@ const (
 
 func TestRenderActionPlainSuccess(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, render(&startResult{
+	assert.NoError(t, output.Render(&startResult{
 		Success: true,
 		ClusterConfig: &clusterConfig{
 			ClusterType:   preset.OpenShift,
@@ -53,7 +54,7 @@ func TestRenderActionPlainSuccess(t *testing.T) {
 func TestRenderActionPlainFailure(t *testing.T) {
 	out := new(bytes.Buffer)
 	err := errors.New("broken")
-	assert.EqualError(t, render(&startResult{
+	assert.EqualError(t, output.Render(&startResult{
 		Success: false,
 		Error:   crcErrors.ToSerializableError(err),
 	}, out, ""), "broken")
@@ -62,7 +63,7 @@ func TestRenderActionPlainFailure(t *testing.T) {
 
 func TestRenderActionJSONSuccess(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, render(&startResult{
+	assert.NoError(t, output.Render(&startResult{
 		Success: true,
 		ClusterConfig: &clusterConfig{
 			ClusterType:   preset.OpenShift,
@@ -77,7 +78,7 @@ func TestRenderActionJSONSuccess(t *testing.T) {
 				Password: "developer",
 			},
 		},
-	}, out, jsonFormat))
+	}, out, output.JSONFormat))
 	assert.Equal(t, `{
   "success": true,
   "clusterConfig": {
@@ -100,10 +101,10 @@ func TestRenderActionJSONSuccess(t *testing.T) {
 
 func TestRenderActionJSONFailure(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, render(&startResult{
+	assert.NoError(t, output.Render(&startResult{
 		Success: false,
 		Error:   crcErrors.ToSerializableError(errors.New("broken")),
-	}, out, jsonFormat))
+	}, out, output.JSONFormat))
 	assert.JSONEq(t, `{"success": false, "error": "broken"}`, out.String())
 }
 
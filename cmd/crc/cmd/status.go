@@ -14,6 +14,7 @@ import (
 	"go.podman.io/common/pkg/strongunits"
 
 	"github.com/cheggaaa/pb/v3"
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/crc-org/crc/v2/pkg/crc/daemonclient"
 	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
@@ -30,6 +31,7 @@ var (
 func init() {
 	statusCmd.Flags().BoolVarP(&watch, "watch", "w", false, "watch mode, continuously update status with CPU load graph")
 	addOutputFormatFlag(statusCmd)
+	addInstanceNameFlag(statusCmd)
 	rootCmd.AddCommand(statusCmd)
 }
 
@@ -38,7 +40,7 @@ var statusCmd = &cobra.Command{
 	Short: "Display status of the OpenShift cluster",
 	Long:  "Show details about the OpenShift cluster",
 	RunE: func(_ *cobra.Command, _ []string) error {
-		return runStatus(os.Stdout, daemonclient.New(), constants.MachineCacheDir, outputFormat, watch)
+		return runStatus(os.Stdout, daemonclient.NewForInstance(instanceName), constants.MachineCacheDir, outputFormat, watch)
 	},
 }
 
@@ -64,7 +66,7 @@ func runStatus(writer io.Writer, client *daemonclient.Client, cacheDir, outputFo
 		return runWatchStatus(writer, client, cacheDir)
 	}
 	status := getStatus(client, cacheDir)
-	return render(status, writer, outputFormat)
+	return output.Render(status, writer, outputFormat)
 }
 
 func runWatchStatus(writer io.Writer, client *daemonclient.Client, cacheDir string) error {
@@ -73,7 +75,7 @@ func runWatchStatus(writer io.Writer, client *daemonclient.Client, cacheDir stri
 	// do not render RAM size/use
 	status.RAMSize = 0
 	status.RAMUsage = 0
-	renderError := render(status, writer, outputFormat)
+	renderError := output.Render(status, writer, outputFormat)
 	if renderError != nil {
 		return renderError
 	}
@@ -185,7 +187,7 @@ func getStatus(client *daemonclient.Client, cacheDir string) *status {
 	}
 }
 
-func (s *status) prettyPrintTo(writer io.Writer) error {
+func (s *status) PrettyPrintTo(writer io.Writer) error {
 	if s.Error != nil {
 		return s.Error
 	}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	mocks "github.com/crc-org/crc/v2/test/mocks/api"
 
 	apiClient "github.com/crc-org/crc/v2/pkg/crc/api/client"
@@ -111,7 +112,7 @@ func TestJsonStatus(t *testing.T) {
 	out := new(bytes.Buffer)
 	assert.NoError(t, runStatus(out, &daemonclient.Client{
 		APIClient: client,
-	}, cacheDir, jsonFormat, false))
+	}, cacheDir, output.JSONFormat, false))
 
 	expected := `{
   "success": true,
@@ -154,7 +155,7 @@ func TestJsonStatusWithError(t *testing.T) {
 	out := new(bytes.Buffer)
 	assert.NoError(t, runStatus(out, &daemonclient.Client{
 		APIClient: client,
-	}, cacheDir, jsonFormat, false))
+	}, cacheDir, output.JSONFormat, false))
 
 	expected := `{
   "success": false,
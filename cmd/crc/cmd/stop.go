@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/input"
 	"github.com/crc-org/crc/v2/pkg/crc/machine"
@@ -16,6 +17,7 @@ func init() {
 	addOutputFormatFlag(stopCmd)
 	addForceFlag(stopCmd)
 	rootCmd.AddCommand(stopCmd)
+	addInstanceNameFlag(stopCmd)
 }
 
 var stopCmd = &cobra.Command{
@@ -23,7 +25,7 @@ var stopCmd = &cobra.Command{
 	Short: "Stop the instance",
 	Long:  "Stop the instance",
 	RunE: func(_ *cobra.Command, _ []string) error {
-		return runStop(os.Stdout, newMachine(), outputFormat != jsonFormat, globalForce, outputFormat)
+		return runStop(os.Stdout, newMachine(), outputFormat != output.JSONFormat, globalForce, outputFormat)
 	},
 }
 
@@ -51,7 +53,7 @@ func stopMachine(client machine.Client, interactive, force bool) (bool, error) {
 
 func runStop(writer io.Writer, client machine.Client, interactive, force bool, outputFormat string) error {
 	forced, err := stopMachine(client, interactive, force)
-	return render(&stopResult{
+	return output.Render(&stopResult{
 		Success: err == nil,
 		Forced:  forced,
 		Error:   crcErrors.ToSerializableError(err),
@@ -64,7 +66,7 @@ type stopResult struct {
 	Error   *crcErrors.SerializableError `json:"error,omitempty"`
 }
 
-func (s *stopResult) prettyPrintTo(writer io.Writer) error {
+func (s *stopResult) PrettyPrintTo(writer io.Writer) error {
 	if s.Error != nil {
 		return s.Error
 	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/fakemachine"
 	"github.com/stretchr/testify/assert"
 )
@@ -26,24 +27,24 @@ func TestStopWithForcePlainError(t *testing.T) {
 
 func TestStopJSONSuccess(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runStop(out, fakemachine.NewClient(), false, false, jsonFormat))
+	assert.NoError(t, runStop(out, fakemachine.NewClient(), false, false, output.JSONFormat))
 	assert.JSONEq(t, `{"success": true, "forced": false}`, out.String())
 }
 
 func TestStopJSONError(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runStop(out, fakemachine.NewFailingClient(), false, false, jsonFormat))
+	assert.NoError(t, runStop(out, fakemachine.NewFailingClient(), false, false, output.JSONFormat))
 	assert.JSONEq(t, `{"success": false, "forced": false, "error": "stop failed"}`, out.String())
 }
 
 func TestStopWithForceJSONError(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runStop(out, fakemachine.NewFailingClient(), false, true, jsonFormat))
+	assert.NoError(t, runStop(out, fakemachine.NewFailingClient(), false, true, output.JSONFormat))
 	assert.JSONEq(t, `{"success": false, "forced": true, "error": "poweroff failed"}`, out.String())
 }
 
 func TestStopWithForceWhenVMInErrorState(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runStop(out, fakemachine.NewStopFailsInErrorStateClient(), false, true, jsonFormat))
+	assert.NoError(t, runStop(out, fakemachine.NewStopFailsInErrorStateClient(), false, true, output.JSONFormat))
 	assert.JSONEq(t, `{"success": false, "forced": true, "error": "poweroff failed"}`, out.String())
 }
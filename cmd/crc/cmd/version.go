@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/crc-org/crc/v2/cmd/crc/cmd/output"
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	crcPreset "github.com/crc-org/crc/v2/pkg/crc/preset"
@@ -30,7 +31,7 @@ func runPrintVersion(writer io.Writer, version *version, outputFormat string) er
 	if err := checkIfNewVersionAvailable(config.Get(crcConfig.DisableUpdateCheck).AsBool()); err != nil {
 		logging.Debugf("Unable to find out if a new version is available: %v", err)
 	}
-	return render(version, writer, outputFormat)
+	return output.Render(version, writer, outputFormat)
 }
 
 type version struct {
@@ -49,7 +50,7 @@ func defaultVersion() *version {
 	}
 }
 
-func (v *version) prettyPrintTo(writer io.Writer) error {
+func (v *version) PrettyPrintTo(writer io.Writer) error {
 	for _, line := range v.lines() {
 		if _, err := fmt.Fprint(writer, line); err != nil {
 			return err
@@ -2,19 +2,110 @@ package compress
 
 import (
 	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/klauspost/compress/zstd"
+	"github.com/klauspost/pgzip"
+	"github.com/ulikunitz/xz"
 )
 
-func Compress(src, dest string) (err error) {
+// Format is the compression format of the tar archives
+type Format string
+
+const (
+	Zstd Format = "zstd"
+	Xz   Format = "xz"
+	Gzip Format = "gzip"
+)
+
+// Formats lists the supported compression formats, Zstd is the default one
+var Formats = []Format{Zstd, Xz, Gzip}
+
+// gzipBlockSize is the size of the blocks compressed in parallel by pgzip
+const gzipBlockSize = 1 << 20
+
+// xzDictCaps are the dictionary sizes of the xz presets 1 to 9, the xz
+// compression levels select them
+var xzDictCaps = []int{1 << 20, 2 << 20, 4 << 20, 4 << 20, 8 << 20, 8 << 20, 16 << 20, 32 << 20, 64 << 20}
+
+// MaxLevel returns the highest compression level of the format, or 0 when the
+// format is not supported
+func (format Format) MaxLevel() int {
+	switch format {
+	case Zstd:
+		return 22
+	case Xz:
+		return len(xzDictCaps)
+	case Gzip:
+		return pgzip.BestCompression
+	default:
+		return 0
+	}
+}
+
+// Options are the settings of the archives created by CompressWithOptions
+type Options struct {
+	// Format is the compression format, Zstd when it is empty
+	Format Format
+	// Level is between 1 and the MaxLevel of the format, 0 selects the
+	// default level
+	Level int
+	// Concurrency is the number of goroutines compressing the archive, 0
+	// uses all the CPUs. xz compression is single-threaded.
+	Concurrency int
+}
+
+// GetFormat returns the compression format, Zstd when it is not set
+func (options Options) GetFormat() Format {
+	if options.Format == "" {
+		return Zstd
+	}
+	return options.Format
+}
+
+// Validate checks that the format, level and concurrency of the options are
+// supported
+func (options Options) Validate() error {
+	format := options.GetFormat()
+	maxLevel := format.MaxLevel()
+	if maxLevel == 0 {
+		formats := make([]string, 0, len(Formats))
+		for _, f := range Formats {
+			formats = append(formats, string(f))
+		}
+		return fmt.Errorf("unknown compression format %s, supported formats are %s", format, strings.Join(formats, ", "))
+	}
+	if options.Level < 0 || options.Level > maxLevel {
+		return fmt.Errorf("invalid %s compression level %d, it must be between 1 and %d, or 0 for the default level", format, options.Level, maxLevel)
+	}
+	if options.Concurrency < 0 {
+		return fmt.Errorf("invalid compression concurrency %d, it must be positive, or 0 to use all the CPUs", options.Concurrency)
+	}
+	return nil
+}
+
+func Compress(src, dest string) error {
+	_, err := CompressWithOptions(src, dest, Options{})
+	return err
+}
+
+// CompressWithOptions creates the dest tar archive of the src directory and
+// returns its sha256sum, which is computed while the archive is written
+func CompressWithOptions(src, dest string, options Options) (sha256sum string, err error) {
+	if err := options.Validate(); err != nil {
+		return "", err
+	}
 	out, err := os.Create(dest)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer func() {
 		cerr := out.Close()
@@ -23,14 +114,58 @@ func Compress(src, dest string) (err error) {
 		}
 	}()
 
-	enc, err := zstd.NewWriter(out)
+	hash := sha256.New()
+	compressor, err := newCompressor(io.MultiWriter(out, hash), options)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if err := writeTar(compressor, src); err != nil {
+		_ = compressor.Close()
+		return "", err
+	}
+	// the end of the compressed stream is written on close
+	if err := compressor.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func newCompressor(writer io.Writer, options Options) (io.WriteCloser, error) {
+	switch options.GetFormat() {
+	case Xz:
+		config := xz.WriterConfig{}
+		if options.Level != 0 {
+			config.DictCap = xzDictCaps[options.Level-1]
+		}
+		return config.NewWriter(writer)
+	case Gzip:
+		level := options.Level
+		if level == 0 {
+			level = pgzip.DefaultCompression
+		}
+		gzipWriter, err := pgzip.NewWriterLevel(writer, level)
+		if err != nil {
+			return nil, err
+		}
+		concurrency := options.Concurrency
+		if concurrency == 0 {
+			concurrency = runtime.GOMAXPROCS(0)
+		}
+		if err := gzipWriter.SetConcurrency(gzipBlockSize, concurrency); err != nil {
+			return nil, err
+		}
+		return gzipWriter, nil
+	default:
+		zstdOptions := []zstd.EOption{zstd.WithEncoderConcurrency(options.Concurrency)}
+		if options.Level != 0 {
+			zstdOptions = append(zstdOptions, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(options.Level)))
+		}
+		return zstd.NewWriter(writer, zstdOptions...)
 	}
-	defer enc.Close()
+}
 
-	tarWriter := tar.NewWriter(enc)
-	defer tarWriter.Close()
+func writeTar(writer io.Writer, src string) error {
+	tarWriter := tar.NewWriter(writer)
 
 	basePath, _ := filepath.Split(src)
 	if basePath == "" {
@@ -47,7 +182,7 @@ func Compress(src, dest string) (err error) {
 	// $ zstdcat crc_libvirt_4.7.1_custom.zstd  | tar t
 	// crc_libvirt_4.7.1_custom
 	// crc_libvirt_4.7.1_custom/test
-	return filepath.Walk(src, func(file string, fi os.FileInfo, err1 error) error {
+	err = filepath.Walk(src, func(file string, fi os.FileInfo, err1 error) error {
 		if err1 != nil {
 			return err1
 		}
@@ -82,4 +217,8 @@ func Compress(src, dest string) (err error) {
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	return tarWriter.Close()
 }
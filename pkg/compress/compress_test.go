@@ -2,6 +2,7 @@ package compress
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -62,6 +63,53 @@ func TestCompressRelative(t *testing.T) {
 	testCompress(t, filepath.Join(currentDir, "testdata"))
 }
 
+func TestCompressWithOptions(t *testing.T) {
+	for _, format := range Formats {
+		for _, level := range []int{0, 1, format.MaxLevel()} {
+			archive := filepath.Join(t.TempDir(), testArchiveName)
+			sha256sum, err := CompressWithOptions("testdata", archive, Options{Format: format, Level: level, Concurrency: 2})
+			require.NoError(t, err)
+
+			content, err := os.ReadFile(archive)
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("%x", sha256.Sum256(content)), sha256sum)
+
+			destDir := t.TempDir()
+			_, err = extract.Uncompress(context.Background(), archive, destDir)
+			require.NoError(t, err, "format %s, level %d", format, level)
+			require.NoError(t, checkFiles(filepath.Join(destDir, "testdata"), files))
+		}
+	}
+}
+
+func TestCompressWithInvalidOptions(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), testArchiveName)
+	_, err := CompressWithOptions("testdata", archive, Options{Level: 23})
+	require.EqualError(t, err, "invalid zstd compression level 23, it must be between 1 and 22, or 0 for the default level")
+	require.NoFileExists(t, archive)
+
+	require.EqualError(t, Options{Format: Gzip, Level: 10}.Validate(), "invalid gzip compression level 10, it must be between 1 and 9, or 0 for the default level")
+	require.EqualError(t, Options{Format: "bzip2"}.Validate(), "unknown compression format bzip2, supported formats are zstd, xz, gzip")
+	require.EqualError(t, Options{Concurrency: -1}.Validate(), "invalid compression concurrency -1, it must be positive, or 0 to use all the CPUs")
+	require.Error(t, Options{Level: -1}.Validate())
+}
+
+func BenchmarkCompress(b *testing.B) {
+	for _, format := range Formats {
+		for _, concurrency := range []int{1, 0} {
+			b.Run(fmt.Sprintf("%s/concurrency=%d", format, concurrency), func(b *testing.B) {
+				archive := filepath.Join(b.TempDir(), testArchiveName)
+				options := Options{Format: format, Concurrency: concurrency}
+				for b.Loop() {
+					if _, err := CompressWithOptions("testdata", archive, options); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
 /* The code below is duplicated from pkg/extract/extract_test.go */
 type fileMap map[string]string
 
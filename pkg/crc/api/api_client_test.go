@@ -1,19 +1,30 @@
 package api
 
 import (
+	gocontext "context"
+	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	networktypes "github.com/containers/gvisor-tap-vsock/pkg/types"
 	"go.podman.io/common/pkg/strongunits"
 
 	apiClient "github.com/crc-org/crc/v2/pkg/crc/api/client"
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/diagnose"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/fakemachine"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 	"github.com/crc-org/crc/v2/pkg/crc/preset"
 	"github.com/crc-org/crc/v2/pkg/crc/version"
 	"github.com/stretchr/testify/assert"
@@ -33,7 +44,7 @@ func newTestClient() *testClient {
 	fakeMachine := fakemachine.NewClient()
 	config := setupNewInMemoryConfig()
 
-	ts := httptest.NewServer(NewMux(config, fakeMachine, &mockLogger{}, &mockTelemetry{}))
+	ts := httptest.NewServer(NewMux(config, machine.NewInstances(fakeMachine, nil), nil, &mockLogger{}, &mockTelemetry{}))
 
 	return &testClient{
 		apiClient.New(http.DefaultClient, ts.URL),
@@ -106,6 +117,36 @@ func TestStart(t *testing.T) {
 	)
 }
 
+func TestStartWithProfile(t *testing.T) {
+	client := newTestClient()
+	defer client.Close()
+
+	profile := "version: 1\npreset: microshift\ncpus: 2\nmemory: 4096\n"
+	resp, err := http.Post(client.httpServer.URL+"/start", "application/yaml", strings.NewReader(profile))
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the profile is not persisted in the configuration
+	assert.Equal(t, preset.OpenShift, crcConfig.GetPreset(client.config))
+
+	resp, err = http.Post(client.httpServer.URL+"/start", "application/yaml", strings.NewReader("version: 1\ncpus: 2\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestStartProfileConfig(t *testing.T) {
+	cfg, err := startProfileConfig(setupNewInMemoryConfig(), []byte(`{"version": 1, "preset": "microshift", "cpus": 3, "ingress-http-port": 8080}`))
+	assert.NoError(t, err)
+
+	startConfig := getStartConfig(cfg, apiClient.StartConfig{})
+	assert.Equal(t, preset.Microshift, startConfig.Preset)
+	assert.Equal(t, uint(3), startConfig.CPUs)
+	assert.Equal(t, uint(8080), startConfig.IngressHTTPPort)
+	assert.Equal(t, constants.GetDefaultMemory(preset.Microshift), startConfig.Memory)
+}
+
 func TestSetup(t *testing.T) {
 	client := newTestClient()
 	defer client.Close()
@@ -257,7 +298,7 @@ func TestTelemetry(t *testing.T) {
 	config := setupNewInMemoryConfig()
 
 	telemetry := &mockTelemetry{}
-	ts := httptest.NewServer(NewMux(config, fakeMachine, &mockLogger{}, telemetry))
+	ts := httptest.NewServer(NewMux(config, machine.NewInstances(fakeMachine, nil), nil, &mockLogger{}, telemetry))
 	defer ts.Close()
 
 	client := apiClient.New(http.DefaultClient, ts.URL)
@@ -274,7 +315,7 @@ func TestPullSecret(t *testing.T) {
 	fakeMachine := fakemachine.NewClient()
 	config := setupNewInMemoryConfig()
 
-	ts := httptest.NewServer(NewMux(config, fakeMachine, &mockLogger{}, &mockTelemetry{}))
+	ts := httptest.NewServer(NewMux(config, machine.NewInstances(fakeMachine, nil), nil, &mockLogger{}, &mockTelemetry{}))
 	defer ts.Close()
 
 	client := apiClient.New(http.DefaultClient, ts.URL)
@@ -294,3 +335,184 @@ func TestPullSecret(t *testing.T) {
 
 	assert.Error(t, client.SetPullSecret("{}")) // invalid
 }
+
+type fakeDNSServer struct {
+	zones []networktypes.Zone
+}
+
+func (s *fakeDNSServer) ListDNS() ([]networktypes.Zone, error) {
+	return s.zones, nil
+}
+
+func (s *fakeDNSServer) AddDNS(zone *networktypes.Zone) error {
+	s.zones = append(s.zones, *zone)
+	return nil
+}
+
+func TestDNSRecords(t *testing.T) {
+	config := setupNewInMemoryConfig()
+	dns := &fakeDNSServer{}
+	ts := httptest.NewServer(NewMux(config, machine.NewInstances(fakemachine.NewClient(), nil), network.NewDNS(dns, nil), &mockLogger{}, &mockTelemetry{}))
+	defer ts.Close()
+	client := apiClient.New(http.DefaultClient, ts.URL)
+
+	result, err := client.AddDNSRecord(apiClient.DNSRecordRequest{Hostname: "registry.corp.test", IP: "192.168.127.254"})
+	assert.NoError(t, err)
+	assert.False(t, result.RequiresDaemonRestart)
+	assert.Equal(t, []networktypes.Zone{{Name: "corp.test.", Records: []networktypes.Record{{Name: "registry", IP: net.ParseIP("192.168.127.254")}}}}, dns.zones)
+
+	records, err := client.DNSRecords()
+	assert.NoError(t, err)
+	assert.Equal(t, []network.DNSRecord{{Hostname: "registry.corp.test", IP: "192.168.127.254"}}, records.Records)
+	assert.Equal(t, "registry.corp.test=192.168.127.254", config.Get(crcConfig.DNSRecords).AsString())
+
+	_, err = client.AddDNSRecord(apiClient.DNSRecordRequest{Hostname: "*.corp.test", IP: "192.168.127.253"})
+	assert.NoError(t, err)
+	result, err = client.RemoveDNSRecord("registry.corp.test")
+	assert.NoError(t, err)
+	assert.False(t, result.RequiresDaemonRestart)
+	assert.Equal(t, networktypes.Zone{
+		Name:      "corp.test.",
+		Records:   []networktypes.Record{{Name: "registry", IP: net.ParseIP("192.168.127.253")}},
+		DefaultIP: net.ParseIP("192.168.127.253"),
+	}, dns.zones[len(dns.zones)-1])
+
+	// the server cannot forget registry.corp.test without a default IP
+	result, err = client.RemoveDNSRecord("*.corp.test")
+	assert.NoError(t, err)
+	assert.True(t, result.RequiresDaemonRestart)
+	assert.True(t, config.Get(crcConfig.DNSRecords).IsDefault)
+}
+
+func newTestV2Client(t *testing.T, fakeMachine *fakemachine.Client) *apiClient.V2Client {
+	ts := httptest.NewServer(NewMux(setupNewInMemoryConfig(), machine.NewInstances(fakeMachine, nil), nil, &mockLogger{}, &mockTelemetry{}))
+	t.Cleanup(ts.Close)
+	return apiClient.NewV2(http.DefaultClient, ts.URL+"/v2")
+}
+
+func TestV2Operations(t *testing.T) {
+	fakeMachine := fakemachine.NewClient()
+	client := newTestV2Client(t, fakeMachine)
+
+	op, err := client.Stop("crc")
+	assert.NoError(t, err)
+	assert.Equal(t, "stop", op.Kind)
+	op, err = client.WaitOperation(gocontext.Background(), op.ID, 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, apiClient.OperationSucceeded, op.Status)
+
+	fakeMachine.Failing = true
+	op, err = client.Delete("crc")
+	assert.NoError(t, err)
+	op, err = client.WaitOperation(gocontext.Background(), op.ID, 10*time.Millisecond)
+	assert.Equal(t, &apiClient.APIError{Code: crcErrors.CodeInternal, Message: "delete failed"}, err)
+	assert.Equal(t, apiClient.OperationFailed, op.Status)
+
+	ops, err := client.Operations()
+	assert.NoError(t, err)
+	assert.Len(t, ops.Operations, 2)
+}
+
+func TestV2Diagnose(t *testing.T) {
+	handler := NewHandler(setupNewInMemoryConfig(), machine.NewInstances(fakemachine.NewClient(), nil), nil, &mockLogger{}, &mockTelemetry{})
+	handler.CollectDiagnostics = func(_ gocontext.Context, _ crcConfig.Storage, machineClient machine.Client, _ cluster.PullSecretLoader, dir string) (*diagnose.Result, error) {
+		return &diagnose.Result{
+			Path:   filepath.Join(dir, "crc-diagnostics-"+machineClient.GetName()+".tar.zst"),
+			Errors: []string{"vm: diagnostics failed"},
+		}, nil
+	}
+	ts := httptest.NewServer(newServerWithRoutes(handler).Handler())
+	t.Cleanup(ts.Close)
+	client := apiClient.NewV2(http.DefaultClient, ts.URL+"/v2")
+
+	op, err := client.Diagnose("crc")
+	assert.NoError(t, err)
+	assert.Equal(t, "diagnose", op.Kind)
+	assert.True(t, op.Cancellable)
+	op, err = client.WaitOperation(gocontext.Background(), op.ID, 10*time.Millisecond)
+	assert.NoError(t, err)
+	var result apiClient.DiagnoseResult
+	assert.NoError(t, json.Unmarshal(op.Result, &result))
+	assert.Equal(t, apiClient.DiagnoseResult{
+		Path:   filepath.Join(constants.DiagnosticsDir, "crc-diagnostics-crc.tar.zst"),
+		Errors: []string{"vm: diagnostics failed"},
+	}, result)
+}
+
+func TestV2Errors(t *testing.T) {
+	client := newTestV2Client(t, fakemachine.NewClient())
+
+	_, err := client.Status("foo")
+	assert.Equal(t, &apiClient.APIError{Code: crcErrors.CodeNotFound, Message: "unknown instance: foo", StatusCode: http.StatusNotFound}, err)
+
+	_, err = client.SetConfig(crcConfig.CPUs, 1)
+	var apiErr *apiClient.APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, crcErrors.CodeInvalidArgument, apiErr.Code)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+}
+
+func TestV2Config(t *testing.T) {
+	client := newTestV2Client(t, fakemachine.NewClient())
+
+	value, err := client.SetConfig(crcConfig.CPUs, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, apiClient.ConfigValueResult{Key: crcConfig.CPUs, Value: float64(8), Message: "Changes to configuration property 'cpus' are only applied when the CRC instance is started.\nIf you already have a running CRC instance, then for this configuration change to take effect, stop the CRC instance with 'crc stop' and restart it with 'crc start'."}, value)
+
+	value, err = client.GetConfig(crcConfig.CPUs)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(8), value.Value)
+	assert.False(t, value.IsDefault)
+
+	value, err = client.UnsetConfig(crcConfig.CPUs)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(4), value.Value)
+	assert.True(t, value.IsDefault)
+}
+
+func TestV2OpenAPI(t *testing.T) {
+	client := newTestV2Client(t, fakemachine.NewClient())
+
+	data, err := client.OpenAPI()
+	assert.NoError(t, err)
+	var document struct {
+		OpenAPI    string
+		Paths      map[string]map[string]interface{}
+		Components struct {
+			Schemas map[string]interface{}
+		}
+	}
+	assert.NoError(t, json.Unmarshal(data, &document))
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+	assert.Contains(t, document.Paths["/v2/instances/{name}/start"], "post")
+	assert.Contains(t, document.Paths["/v2/instances/{name}"], "delete")
+	assert.Contains(t, document.Paths["/v2/config/{key}"], "put")
+	for _, schema := range []string{"APIError", "Operation", "StartConfig", "ClusterStatusResult", "ClusterConfig", "Report"} {
+		assert.Contains(t, document.Components.Schemas, schema)
+	}
+}
+
+func TestV2OpenAPIOperationIDsAreUnique(t *testing.T) {
+	client := newTestV2Client(t, fakemachine.NewClient())
+
+	data, err := client.OpenAPI()
+	assert.NoError(t, err)
+	var document struct {
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+		}
+	}
+	assert.NoError(t, json.Unmarshal(data, &document))
+	seen := map[string]string{}
+	for path, operations := range document.Paths {
+		for method, operation := range operations {
+			assert.NotEmpty(t, operation.OperationID, "%s %s", method, path)
+			if previous, ok := seen[operation.OperationID]; ok {
+				t.Errorf("operationId %s is used by %s and %s %s", operation.OperationID, previous, method, path)
+			}
+			seen[operation.OperationID] = method + " " + path
+		}
+	}
+	assert.Equal(t, "listInstances", document.Paths["/v2/instances"]["get"].OperationID)
+	assert.Equal(t, "getInstance", document.Paths["/v2/instances/{name}"]["get"].OperationID)
+}
@@ -6,10 +6,11 @@ import (
 	"github.com/crc-org/crc/v2/pkg/crc/cluster"
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
 	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 )
 
-func NewMux(config *crcConfig.Config, machine machine.Client, logger Logger, telemetry Telemetry) http.Handler {
-	handler := NewHandler(config, machine, logger, telemetry)
+func NewMux(config *crcConfig.Config, instances *machine.Instances, dns *network.DNS, logger Logger, telemetry Telemetry) http.Handler {
+	handler := NewHandler(config, instances, dns, logger, telemetry)
 
 	server := newServerWithRoutes(handler)
 
@@ -32,6 +33,19 @@ func newServerWithRoutes(handler *Handler) *server {
 	server.DELETE("/delete", handler.Delete)
 	server.GET("/delete", handler.Delete)
 
+	server.GET("/instances", handler.ListInstances)
+
+	// per-instance routes, mirroring the routes above which target the default instance
+	server.POST("/instances/{name}/start", handler.Start)
+	server.GET("/instances/{name}/start", handler.Start)
+	server.POST("/instances/{name}/stop", handler.Stop)
+	server.GET("/instances/{name}/stop", handler.Stop)
+	server.POST("/instances/{name}/poweroff", handler.PowerOff)
+	server.GET("/instances/{name}/status", handler.Status)
+	server.DELETE("/instances/{name}/delete", handler.Delete)
+	server.GET("/instances/{name}/delete", handler.Delete)
+	server.GET("/instances/{name}/webconsoleurl", handler.GetWebconsoleInfo)
+
 	server.GET("/version", handler.GetVersion)
 
 	server.GET("/webconsoleurl", handler.GetWebconsoleInfo)
@@ -45,9 +59,17 @@ func newServerWithRoutes(handler *Handler) *server {
 	server.GET("/telemetry", handler.UploadTelemetry)
 	server.POST("/telemetry", handler.UploadTelemetry)
 
+	server.GET("/preflight", handler.PreflightReport)
+
+	server.GET("/dns", handler.ListDNSRecords)
+	server.POST("/dns", handler.AddDNSRecord)
+	server.DELETE("/dns", handler.RemoveDNSRecord)
+
 	server.GET("/pull-secret", getPullSecret(handler.Config))
 	server.POST("/pull-secret", setPullSecret())
 
+	registerV2Routes(server, handler)
+
 	return server
 }
 
@@ -12,7 +12,10 @@ import (
 
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/fakemachine"
+	"github.com/crc-org/crc/v2/pkg/crc/metrics"
+	"github.com/crc-org/crc/v2/pkg/crc/preflight"
 	"github.com/crc-org/crc/v2/pkg/crc/preset"
 	"github.com/crc-org/crc/v2/pkg/crc/version"
 	"github.com/stretchr/testify/assert"
@@ -41,13 +44,29 @@ func removePullSecret(t *testing.T, server *mockServer) {
 	assert.NoError(t, err)
 }
 
+func fakePreflightReport(crcConfig.Storage) *preflight.Report {
+	return &preflight.Report{
+		Checks: []preflight.CheckResult{
+			{
+				Name:           "check-ram",
+				Description:    "Checking minimum RAM requirements",
+				Status:         preflight.CheckFailed,
+				Error:          "not enough memory",
+				FixDescription: "crc requires at least 10.5GB to run",
+				SkipSetting:    "skip-check-ram",
+			},
+		},
+	}
+}
+
 func newMockServer(pullSecretPath string) *mockServer {
 	fakeMachine := fakemachine.NewClient()
 
 	config := setupNewInMemoryConfig()
 	_, _ = config.Set(crcConfig.PullSecretFile, pullSecretPath)
 
-	handler := NewHandler(config, fakeMachine, &mockLogger{}, &mockTelemetry{})
+	handler := NewHandler(config, machine.NewInstances(fakeMachine, nil), nil, &mockLogger{}, &mockTelemetry{})
+	handler.ReportPreflight = fakePreflightReport
 
 	return &mockServer{
 		server: newServerWithRoutes(handler),
@@ -94,6 +113,9 @@ type response struct {
 	protoMinor int
 	// headers
 	body string
+	// bodyPrefix is checked instead of body when it is set, for the
+	// responses too large to be compared
+	bodyPrefix string
 }
 
 type testCase struct {
@@ -117,6 +139,13 @@ func post(resource string) request {
 	}
 }
 
+func put(resource string) request {
+	return request{
+		httpMethod: http.MethodPut,
+		resource:   resource,
+	}
+}
+
 func deleteRequest(resource string) request {
 	return request{
 		httpMethod: http.MethodDelete,
@@ -163,6 +192,11 @@ func (resp response) withBody(body string) response {
 	return resp
 }
 
+func (resp response) withBodyPrefix(prefix string) response {
+	resp.bodyPrefix = prefix
+	return resp
+}
+
 var testCases = []testCase{
 	// start
 	{
@@ -332,6 +366,38 @@ var testCases = []testCase{
 		response:    httpError(500).withBody("unexpected end of JSON input\n"),
 	},
 
+	// preflight
+	{
+		request:  get("preflight"),
+		response: jSon(`{"success":false,"checks":[{"name":"check-ram","description":"Checking minimum RAM requirements","status":"failed","error":"not enough memory","fixDescription":"crc requires at least 10.5GB to run","fixable":false,"fixRequiresPrivileges":false,"skipSetting":"skip-check-ram"}]}`),
+	},
+
+	// dns
+	{
+		request:  get("dns"),
+		response: jSon(`{"Records":[]}`),
+	},
+	{
+		request:  post("dns").withBody(`{"hostname":"Registry.corp.test","ip":"192.168.127.254"}`),
+		response: jSon(`{"Record":{"hostname":"registry.corp.test","ip":"192.168.127.254"},"RequiresDaemonRestart":true}`),
+	},
+	{
+		request:  post("dns").withBody(`{"hostname":"corp","ip":"192.168.127.254"}`),
+		response: httpError(500).withBody("invalid hostname 'corp', it must include a domain\n"),
+	},
+	{
+		request:  get("dns"),
+		response: jSon(`{"Records":[{"hostname":"registry.corp.test","ip":"192.168.127.254"}]}`),
+	},
+	{
+		request:  deleteRequest("dns").withBody(`{"hostname":"registry.corp.test"}`),
+		response: jSon(`{"Record":{"hostname":"registry.corp.test","ip":"192.168.127.254"},"RequiresDaemonRestart":true}`),
+	},
+	{
+		request:  deleteRequest("dns").withBody(`{"hostname":"registry.corp.test"}`),
+		response: httpError(500).withBody("no DNS record for registry.corp.test\n"),
+	},
+
 	// pull-secret
 	{
 		request: get("pull-secret"),
@@ -357,6 +423,64 @@ var testCases = []testCase{
 		response:    httpError(500).withBody("empty pull secret\n"),
 	},
 
+	// instances
+	{
+		request:  get("instances"),
+		response: jSon(`{"Instances":[{"Name":"crc","CrcStatus":"Running"}]}`),
+	},
+	{
+		request:  post("instances/crc/start"),
+		response: jSon(`{"Status":"","ClusterConfig":{"ClusterType":"openshift","ClusterCACert":"MIIDODCCAiCgAwIBAgIIRVfCKNUa1wIwDQYJ","KubeConfig":"/tmp/kubeconfig","KubeAdminPass":"foobar","DeveloperPass":"foobar","ClusterAPI":"https://foo.testing:6443","WebConsoleURL":"https://console.foo.testing:6443","ProxyConfig":null},"KubeletStarted":true}`),
+	},
+	{
+		request:  get("instances/crc/start"),
+		response: jSon(`{"Status":"","ClusterConfig":{"ClusterType":"openshift","ClusterCACert":"MIIDODCCAiCgAwIBAgIIRVfCKNUa1wIwDQYJ","KubeConfig":"/tmp/kubeconfig","KubeAdminPass":"foobar","DeveloperPass":"foobar","ClusterAPI":"https://foo.testing:6443","WebConsoleURL":"https://console.foo.testing:6443","ProxyConfig":null},"KubeletStarted":true}`),
+	},
+	{
+		request:  post("instances/crc/stop"),
+		response: empty(),
+	},
+	{
+		request:  get("instances/crc/stop"),
+		response: empty(),
+	},
+	{
+		request:  post("instances/crc/poweroff"),
+		response: empty(),
+	},
+	{
+		request:  get("instances/crc/status"),
+		response: jSon(`{"CrcStatus":"Running","OpenshiftStatus":"Running","OpenshiftVersion":"4.5.1","DiskUse":10000000000,"DiskSize":20000000000,"RAMUse":1000,"RAMSize":2000,"Preset":"openshift"}`),
+	},
+	{
+		request:  deleteRequest("instances/crc/delete"),
+		response: empty(),
+	},
+	{
+		request:  get("instances/crc/delete"),
+		response: empty(),
+	},
+	{
+		request:  get("instances/crc/webconsoleurl"),
+		response: jSon(`{"ClusterConfig":{"ClusterType":"openshift","ClusterCACert":"MIIDODCCAiCgAwIBAgIIRVfCKNUa1wIwDQYJ","KubeConfig":"/tmp/kubeconfig","KubeAdminPass":"foobar","DeveloperPass":"foobar","ClusterAPI":"https://foo.testing:6443","WebConsoleURL":"https://console.foo.testing:6443","ProxyConfig":null},"State":"Running"}`),
+	},
+
+	// instances with failure
+	{
+		request:     get("instances/crc/status"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("broken\n"),
+	},
+	{
+		request:  get("instances/foo/status"),
+		response: httpError(500).withBody("unknown instance: foo\n"),
+	},
+	{
+		request:  get("instances/Foo_/status"),
+		response: httpError(500).withBody("invalid instance name 'Foo_': must consist of lower case alphanumeric characters or '-', start and end with an alphanumeric character and be at most 63 characters\n"),
+	},
+
 	// not found
 	{
 		request:  get("notfound"),
@@ -368,6 +492,97 @@ var testCases = []testCase{
 		request:  get("config?cpus"),
 		response: jSon(`{"Configs":{"cpus":4}}`),
 	},
+
+	// v2
+	{
+		request:  get("v2/version"),
+		response: jSon(fmt.Sprintf(`{"CrcVersion":"%s","CommitSha":"%s","OpenshiftVersion":"%s","MicroshiftVersion":"%s"}`, version.GetCRCVersion(), version.GetCommitSha(), version.GetBundleVersion(preset.OpenShift), version.GetBundleVersion(preset.Microshift))),
+	},
+	{
+		request:  get("v2/instances"),
+		response: jSon(`{"Instances":[{"Name":"crc","CrcStatus":"Running"}]}`),
+	},
+	{
+		request:  get("v2/instances/crc"),
+		response: jSon(`{"CrcStatus":"Running","OpenshiftStatus":"Running","OpenshiftVersion":"4.5.1","DiskUse":10000000000,"DiskSize":20000000000,"RAMUse":1000,"RAMSize":2000,"Preset":"openshift"}`),
+	},
+	{
+		request:  get("v2/instances/foo"),
+		response: httpError(404).withBody(`{"code":"NotFound","message":"unknown instance: foo"}`),
+	},
+	{
+		request:  deleteRequest("v2/instances/Foo_"),
+		response: httpError(400).withBody(`{"code":"InvalidArgument","message":"invalid instance name 'Foo_': must consist of lower case alphanumeric characters or '-', start and end with an alphanumeric character and be at most 63 characters"}`),
+	},
+	{
+		request:  post("v2/instances/crc/start").withBody("xx"),
+		response: httpError(400).withBody(`{"code":"InvalidArgument","message":"invalid character 'x' looking for beginning of value"}`),
+	},
+	{
+		request:  post("v2/instances/foo/stop"),
+		response: httpError(404).withBody(`{"code":"NotFound","message":"unknown instance: foo"}`),
+	},
+	{
+		request:  post("v2/instances/crc/poweroff"),
+		response: httpError(204),
+	},
+	{
+		request:     post("v2/instances/crc/poweroff"),
+		failRequest: true,
+		response:    httpError(500).withBody(`{"code":"Internal","message":"poweroff failed"}`),
+	},
+	{
+		request:  get("v2/instances/crc/console"),
+		response: jSon(`{"ClusterConfig":{"ClusterType":"openshift","ClusterCACert":"MIIDODCCAiCgAwIBAgIIRVfCKNUa1wIwDQYJ","KubeConfig":"/tmp/kubeconfig","KubeAdminPass":"foobar","DeveloperPass":"foobar","ClusterAPI":"https://foo.testing:6443","WebConsoleURL":"https://console.foo.testing:6443","ProxyConfig":null},"State":"Running"}`),
+	},
+	{
+		request:  post("v2/instances/Foo_/diagnose"),
+		response: httpError(400).withBody(`{"code":"InvalidArgument","message":"invalid instance name 'Foo_': must consist of lower case alphanumeric characters or '-', start and end with an alphanumeric character and be at most 63 characters"}`),
+	},
+	{
+		request:  post("v2/instances/crc/diagnose").withBody(`{"dir":"/etc"}`),
+		response: httpError(400).withBody(`{"code":"InvalidArgument","message":"diagnose requests take no body, the archive is always written in the diagnostics directory"}`),
+	},
+	{
+		request:  get("v2/operations"),
+		response: jSon(`{"operations":[]}`),
+	},
+	{
+		request:  get("v2/operations/42"),
+		response: httpError(404).withBody(`{"code":"NotFound","message":"unknown operation: 42"}`),
+	},
+	{
+		request:  deleteRequest("v2/operations/42"),
+		response: httpError(404).withBody(`{"code":"NotFound","message":"unknown operation: 42"}`),
+	},
+	{
+		request:  get("v2/config"),
+		response: jSon("").withBodyPrefix(`{"Configs":{`),
+	},
+	{
+		request:  get("v2/config/cpus"),
+		response: jSon(`{"key":"cpus","value":4,"isDefault":true}`),
+	},
+	{
+		request:  get("v2/config/foo"),
+		response: httpError(404).withBody(`{"code":"NotFound","message":"unknown configuration property: foo"}`),
+	},
+	{
+		request:  put("v2/config/cpus").withBody(`{"value":1}`),
+		response: httpError(400).withBody(`{"code":"InvalidArgument","message":"Value '1' for configuration property 'cpus' is invalid, reason: requires CPUs \u003e= 4"}`),
+	},
+	{
+		request:  deleteRequest("v2/config/foo"),
+		response: httpError(400).withBody(`{"code":"InvalidArgument","message":"Configuration property 'foo' does not exist"}`),
+	},
+	{
+		request:  get("v2/preflight"),
+		response: jSon(`{"success":false,"checks":[{"name":"check-ram","description":"Checking minimum RAM requirements","status":"failed","error":"not enough memory","fixDescription":"crc requires at least 10.5GB to run","fixable":false,"fixRequiresPrivileges":false,"skipSetting":"skip-check-ram"}]}`),
+	},
+	{
+		request:  get("v2/openapi.json"),
+		response: jSon("").withBodyPrefix(`{"components":{"schemas":{`),
+	},
 }
 
 var invalidHTTPMethods = []testCase{
@@ -445,12 +660,32 @@ var invalidHTTPMethods = []testCase{
 		response: httpError(404).withBody("Not Found\n"),
 	},
 
+	// preflight
+	{
+		request:  post("preflight"),
+		response: httpError(404).withBody("Not Found\n"),
+	},
+
 	// pull-secret
 	{
 		request: deleteRequest("pull-secret"),
 		// other 404 return "not found", and others "404 not found"
 		response: httpError(404).withBody("Not Found\n"),
 	},
+
+	// v2 routes only accept the method matching their semantics
+	{
+		request:  get("v2/instances/crc/start"),
+		response: httpError(404).withBody("Not Found\n"),
+	},
+	{
+		request:  get("v2/instances/crc/stop"),
+		response: httpError(404).withBody("Not Found\n"),
+	},
+	{
+		request:  post("v2/config/cpus"),
+		response: httpError(404).withBody("Not Found\n"),
+	},
 }
 
 func testOne(t *testing.T, testCase *testCase, server *mockServer) {
@@ -465,7 +700,11 @@ func testOne(t *testing.T, testCase *testCase, server *mockServer) {
 	require.Equal(t, testCase.response.protoMinor, resp.ProtoMinor, testCase.request)
 	body, err := io.ReadAll(resp.Body)
 	require.NoError(t, err, testCase.request)
-	require.Equal(t, testCase.response.body, string(body), testCase.request)
+	if testCase.response.bodyPrefix != "" {
+		require.True(t, strings.HasPrefix(string(body), testCase.response.bodyPrefix), testCase.request)
+	} else {
+		require.Equal(t, testCase.response.body, string(body), testCase.request)
+	}
 	fmt.Println("-----")
 }
 
@@ -498,9 +737,31 @@ func TestRoutes(t *testing.T) {
 
 	server := newMockServer("")
 	for pattern, methodMap := range server.routes {
-		assert.Contains(t, routes, pattern)
+		methods := routes[pattern]
+		for path := range routes {
+			if _, ok := matchPattern(pattern, path); ok {
+				methods = append(methods, routes[path]...)
+			}
+		}
+		assert.NotEmpty(t, methods, "%s is missing from the API testcases", pattern)
 		for method := range methodMap {
-			assert.Contains(t, routes[pattern], method, "routes[%s][%s] is missing from the API testcases", pattern, method)
+			assert.Contains(t, methods, method, "routes[%s][%s] is missing from the API testcases", pattern, method)
 		}
 	}
 }
+
+func TestMetricsUseRoutePatterns(t *testing.T) {
+	server := newMockServer("")
+	collector := metrics.NewCollector(machine.NewInstances(fakemachine.NewClient(), nil), nil)
+	defer collector.Close()
+
+	handler := collector.Middleware(server.Handler())
+	for _, path := range []string{"/instances/crc/status", "/instances/crc/webconsoleurl", "/instances/crc/status", "/no/such/route"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `crc_api_requests_total{method="GET",path="/instances/{name}/status",code="200"} 2`)
+	assert.Contains(t, rec.Body.String(), `crc_api_requests_total{method="GET",path="unmatched",code="404"} 1`)
+}
@@ -0,0 +1,311 @@
+package api
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/crc-org/crc/v2/pkg/crc/api/client"
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/crc-org/crc/v2/pkg/crc/preflight"
+	"github.com/crc-org/crc/v2/pkg/crc/validation"
+)
+
+// v2Route describes a route of the v2 API, the OpenAPI document is generated
+// from the routes. request and response are values of the types of the
+// bodies, nil when there is none.
+type v2Route struct {
+	method      string
+	pattern     string
+	operationID string
+	summary     string
+	request     interface{}
+	response    interface{}
+	status      int
+	operation   bool
+	handler     func(c *context) error
+}
+
+type v2Handler struct {
+	*Handler
+	operations *operations
+}
+
+// registerV2Routes adds the /v2 routes to server. Unlike the v1 routes, they
+// only accept the method matching their semantics, errors are JSON
+// client.APIError objects and long running requests return an operation.
+func registerV2Routes(server *server, handler *Handler) {
+	h := &v2Handler{
+		Handler:    handler,
+		operations: newOperations(),
+	}
+	routes := []v2Route{
+		{method: http.MethodGet, pattern: "/v2/version", operationID: "getVersion", summary: "Get the version of the daemon",
+			response: client.VersionResult{}, status: http.StatusOK, handler: h.GetVersion},
+		{method: http.MethodGet, pattern: "/v2/instances", operationID: "listInstances", summary: "List the instances",
+			response: client.ListInstancesResult{}, status: http.StatusOK, handler: h.ListInstances},
+		{method: http.MethodGet, pattern: "/v2/instances/{name}", operationID: "getInstance", summary: "Get the status of an instance",
+			response: client.ClusterStatusResult{}, status: http.StatusOK, handler: h.status},
+		{method: http.MethodDelete, pattern: "/v2/instances/{name}", operationID: "deleteInstance", summary: "Delete an instance",
+			response: client.Operation{}, status: http.StatusAccepted, operation: true, handler: h.delete},
+		{method: http.MethodPost, pattern: "/v2/instances/{name}/start", operationID: "startInstance", summary: "Start an instance, the body is optional and is either a start configuration or a start profile",
+			request: client.StartConfig{}, response: client.Operation{}, status: http.StatusAccepted, operation: true, handler: h.start},
+		{method: http.MethodPost, pattern: "/v2/instances/{name}/stop", operationID: "stopInstance", summary: "Stop an instance",
+			response: client.Operation{}, status: http.StatusAccepted, operation: true, handler: h.stop},
+		{method: http.MethodPost, pattern: "/v2/instances/{name}/poweroff", operationID: "powerOffInstance", summary: "Power off an instance",
+			status: http.StatusNoContent, handler: h.powerOff},
+		{method: http.MethodGet, pattern: "/v2/instances/{name}/console", operationID: "getInstanceConsole", summary: "Get the web console URL and credentials of an instance",
+			response: client.ConsoleResult{}, status: http.StatusOK, handler: h.console},
+		{method: http.MethodPost, pattern: "/v2/instances/{name}/diagnose", operationID: "diagnoseInstance", summary: "Collect the diagnostics of an instance in an archive in the diagnostics directory of the crc directory",
+			response: client.Operation{}, status: http.StatusAccepted, operation: true, handler: h.diagnose},
+		{method: http.MethodGet, pattern: "/v2/operations", operationID: "listOperations", summary: "List the recent operations",
+			response: client.OperationsResult{}, status: http.StatusOK, handler: h.listOperations},
+		{method: http.MethodGet, pattern: "/v2/operations/{id}", operationID: "getOperation", summary: "Get an operation",
+			response: client.Operation{}, status: http.StatusOK, handler: h.getOperation},
+		{method: http.MethodDelete, pattern: "/v2/operations/{id}", operationID: "cancelOperation", summary: "Cancel a running operation",
+			response: client.Operation{}, status: http.StatusAccepted, handler: h.cancelOperation},
+		{method: http.MethodGet, pattern: "/v2/config", operationID: "getConfig", summary: "Get the configuration, secrets are omitted",
+			response: client.GetConfigResult{}, status: http.StatusOK, handler: h.GetConfig},
+		{method: http.MethodGet, pattern: "/v2/config/{key}", operationID: "getConfigValue", summary: "Get a configuration property",
+			response: client.ConfigValueResult{}, status: http.StatusOK, handler: h.getConfigValue},
+		{method: http.MethodPut, pattern: "/v2/config/{key}", operationID: "setConfigValue", summary: "Set a configuration property",
+			request: client.ConfigValueRequest{}, response: client.ConfigValueResult{}, status: http.StatusOK, handler: h.setConfigValue},
+		{method: http.MethodDelete, pattern: "/v2/config/{key}", operationID: "unsetConfigValue", summary: "Reset a configuration property to its default value",
+			response: client.ConfigValueResult{}, status: http.StatusOK, handler: h.unsetConfigValue},
+		{method: http.MethodGet, pattern: "/v2/preflight", operationID: "getPreflightReport", summary: "Run the preflight checks without fixing anything",
+			response: preflight.Report{}, status: http.StatusOK, handler: h.PreflightReport},
+	}
+	for _, route := range routes {
+		server.handle(route.method, route.pattern, v2(route.handler))
+	}
+	document := openAPIDocument(routes)
+	server.GET("/v2/openapi.json", func(c *context) error {
+		return c.JSON(http.StatusOK, document)
+	})
+}
+
+// v2 sends the errors of handler as JSON client.APIError objects
+func v2(handler func(c *context) error) func(c *context) error {
+	return func(c *context) error {
+		if err := handler(c); err != nil {
+			apiErr := toAPIError(err)
+			return c.JSON(httpStatus(apiErr.Code), apiErr)
+		}
+		return nil
+	}
+}
+
+func toAPIError(err error) *client.APIError {
+	return &client.APIError{
+		Code:    crcErrors.CodeOf(err),
+		Message: err.Error(),
+	}
+}
+
+func httpStatus(code crcErrors.Code) int {
+	switch code {
+	case crcErrors.CodeInvalidArgument:
+		return http.StatusBadRequest
+	case crcErrors.CodeNotFound, crcErrors.CodeVMNotExist:
+		return http.StatusNotFound
+	case crcErrors.CodeConflict, crcErrors.CodeCancelled:
+		return http.StatusConflict
+	case crcErrors.CodePreflightFailed:
+		return http.StatusPreconditionFailed
+	case crcErrors.CodeDaemonNotRunning:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// client returns the machine client of the {name} instance of the request
+func (h *v2Handler) client(c *context) (machine.Client, error) {
+	name := c.Param("name")
+	if err := validation.ValidateInstanceName(name); err != nil {
+		return nil, crcErrors.WithCode(crcErrors.CodeInvalidArgument, err)
+	}
+	machineClient, err := h.Instances.Get(name)
+	if err != nil {
+		return nil, crcErrors.WithCode(crcErrors.CodeNotFound, err)
+	}
+	return machineClient, nil
+}
+
+// existingClient is client, failing when the instance was not created
+func (h *v2Handler) existingClient(c *context) (machine.Client, error) {
+	machineClient, err := h.client(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := machine.CheckIfMachineMissing(machineClient); err != nil {
+		return nil, err
+	}
+	return machineClient, nil
+}
+
+func (h *v2Handler) status(c *context) error {
+	if _, err := h.existingClient(c); err != nil {
+		return err
+	}
+	return h.Status(c)
+}
+
+func (h *v2Handler) console(c *context) error {
+	if _, err := h.existingClient(c); err != nil {
+		return err
+	}
+	return h.GetWebconsoleInfo(c)
+}
+
+func (h *v2Handler) start(c *context) error {
+	machineClient, err := h.client(c)
+	if err != nil {
+		return err
+	}
+	cfg, args, err := h.startRequest(c)
+	if err != nil {
+		return crcErrors.WithCode(crcErrors.CodeInvalidArgument, err)
+	}
+	op := h.operations.start("start", c.Param("name"), true, func(ctx gocontext.Context) (interface{}, error) {
+		return start(ctx, machineClient, cfg, args)
+	})
+	return c.JSON(http.StatusAccepted, op)
+}
+
+func (h *v2Handler) stop(c *context) error {
+	machineClient, err := h.existingClient(c)
+	if err != nil {
+		return err
+	}
+	op := h.operations.start("stop", c.Param("name"), false, func(gocontext.Context) (interface{}, error) {
+		_, err := machineClient.Stop()
+		return nil, err
+	})
+	return c.JSON(http.StatusAccepted, op)
+}
+
+func (h *v2Handler) delete(c *context) error {
+	machineClient, err := h.existingClient(c)
+	if err != nil {
+		return err
+	}
+	op := h.operations.start("delete", c.Param("name"), false, func(gocontext.Context) (interface{}, error) {
+		return nil, machineClient.Delete()
+	})
+	return c.JSON(http.StatusAccepted, op)
+}
+
+func (h *v2Handler) powerOff(c *context) error {
+	machineClient, err := h.existingClient(c)
+	if err != nil {
+		return err
+	}
+	if err := machineClient.PowerOff(); err != nil {
+		return err
+	}
+	return c.Code(http.StatusNoContent)
+}
+
+func (h *v2Handler) diagnose(c *context) error {
+	machineClient, err := h.client(c)
+	if err != nil {
+		return err
+	}
+	// the clients cannot choose where the daemon writes the archive
+	if len(c.requestBody) > 0 {
+		return crcErrors.WithCode(crcErrors.CodeInvalidArgument, errors.New("diagnose requests take no body, the archive is always written in the diagnostics directory"))
+	}
+	op := h.operations.start("diagnose", c.Param("name"), true, func(ctx gocontext.Context) (interface{}, error) {
+		result, err := h.CollectDiagnostics(ctx, h.Config, machineClient, cluster.NewNonInteractivePullSecretLoader(h.Config, ""), constants.DiagnosticsDir)
+		if err != nil {
+			return nil, err
+		}
+		return client.DiagnoseResult{
+			Path:   result.Path,
+			Errors: append([]string{}, result.Errors...),
+		}, nil
+	})
+	return c.JSON(http.StatusAccepted, op)
+}
+
+func (h *v2Handler) listOperations(c *context) error {
+	return c.JSON(http.StatusOK, client.OperationsResult{
+		Operations: h.operations.list(),
+	})
+}
+
+func (h *v2Handler) getOperation(c *context) error {
+	op, err := h.operations.get(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, op)
+}
+
+func (h *v2Handler) cancelOperation(c *context) error {
+	op, err := h.operations.cancel(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusAccepted, op)
+}
+
+// configValue returns the value of the {key} setting, secrets are reported
+// as missing
+func (h *v2Handler) configValue(c *context) (client.ConfigValueResult, error) {
+	key := c.Param("key")
+	value := h.Config.Get(key)
+	if value.Invalid || value.IsSecret {
+		return client.ConfigValueResult{}, crcErrors.WithCode(crcErrors.CodeNotFound, fmt.Errorf("unknown configuration property: %s", key))
+	}
+	return client.ConfigValueResult{
+		Key:       key,
+		Value:     value.Value,
+		IsDefault: value.IsDefault,
+	}, nil
+}
+
+func (h *v2Handler) getConfigValue(c *context) error {
+	crcConfig.UpdateDefaults(h.Config)
+	result, err := h.configValue(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *v2Handler) setConfigValue(c *context) error {
+	var req client.ConfigValueRequest
+	if err := c.Bind(&req); err != nil {
+		return crcErrors.WithCode(crcErrors.CodeInvalidArgument, err)
+	}
+	message, err := h.Config.Set(c.Param("key"), req.Value)
+	if err != nil {
+		return crcErrors.WithCode(crcErrors.CodeInvalidArgument, err)
+	}
+	return h.configValueResult(c, message)
+}
+
+func (h *v2Handler) unsetConfigValue(c *context) error {
+	message, err := h.Config.Unset(c.Param("key"))
+	if err != nil {
+		return crcErrors.WithCode(crcErrors.CodeInvalidArgument, err)
+	}
+	return h.configValueResult(c, message)
+}
+
+func (h *v2Handler) configValueResult(c *context, message string) error {
+	result, err := h.configValue(c)
+	if err != nil {
+		// the value of secrets is not returned
+		result = client.ConfigValueResult{Key: c.Param("key")}
+	}
+	result.Message = message
+	return c.JSON(http.StatusOK, result)
+}
@@ -23,6 +23,9 @@ type Client interface {
 	Telemetry(action string) error
 	IsPullSecretDefined() (bool, error)
 	SetPullSecret(data string) error
+	DNSRecords() (DNSRecordsResult, error)
+	AddDNSRecord(record DNSRecordRequest) (DNSRecordResult, error)
+	RemoveDNSRecord(hostname string) (DNSRecordResult, error)
 }
 
 type HTTPError struct {
@@ -210,6 +213,44 @@ func (c *client) SetPullSecret(data string) error {
 	return nil
 }
 
+func (c *client) DNSRecords() (DNSRecordsResult, error) {
+	var dr = DNSRecordsResult{}
+	body, err := c.sendGetRequest("/dns")
+	if err != nil {
+		return dr, err
+	}
+	err = json.Unmarshal(body, &dr)
+	if err != nil {
+		return dr, err
+	}
+	return dr, nil
+}
+
+func (c *client) AddDNSRecord(record DNSRecordRequest) (DNSRecordResult, error) {
+	return c.sendDNSRecordRequest(http.MethodPost, record)
+}
+
+func (c *client) RemoveDNSRecord(hostname string) (DNSRecordResult, error) {
+	return c.sendDNSRecordRequest(http.MethodDelete, DNSRecordRequest{Hostname: hostname})
+}
+
+func (c *client) sendDNSRecordRequest(method string, record DNSRecordRequest) (DNSRecordResult, error) {
+	var dr = DNSRecordResult{}
+	var data = new(bytes.Buffer)
+	if err := json.NewEncoder(data).Encode(record); err != nil {
+		return dr, fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	body, err := c.sendRequest("/dns", method, data)
+	if err != nil {
+		return dr, err
+	}
+	err = json.Unmarshal(body, &dr)
+	if err != nil {
+		return dr, err
+	}
+	return dr, nil
+}
+
 func (c *client) sendGetRequest(url string) ([]byte, error) {
 	res, err := c.client.Get(fmt.Sprintf("%s%s", c.base, url))
 	if err != nil {
@@ -2,27 +2,37 @@ package client
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
 	"github.com/r3labs/sse/v2"
 )
 
 type SSEClient struct {
-	client *sse.Client
+	client       *sse.Client
+	statusStream string
 }
 
-func NewSSEClient(transport *http.Transport) *SSEClient {
-	client := sse.NewClient("http://unix/events")
+func NewSSEClient(transport http.RoundTripper, baseURL string) *SSEClient {
+	client := sse.NewClient(baseURL + "/events")
 	client.Connection.Transport = transport
 	return &SSEClient{
-		client: client,
+		client:       client,
+		statusStream: "status",
 	}
 }
 
+// ForInstance makes Status follow the load of the instance called name
+// instead of the one of the default instance
+func (c *SSEClient) ForInstance(name string) {
+	c.statusStream = fmt.Sprintf("status/%s", name)
+}
+
 func (c *SSEClient) Status(statusCallback func(*types.ClusterLoadResult)) error {
-	err := c.client.Subscribe("status", func(msg *sse.Event) {
+	err := c.client.Subscribe(c.statusStream, func(msg *sse.Event) {
 		wmState := &types.ClusterLoadResult{}
 		err := json.Unmarshal(msg.Data, wmState)
 		if err != nil {
@@ -34,3 +44,14 @@ func (c *SSEClient) Status(statusCallback func(*types.ClusterLoadResult)) error
 
 	return err
 }
+
+func (c *SSEClient) Lifecycle(lifecycleCallback func(*lifecycle.Event)) error {
+	return c.client.Subscribe("lifecycle", func(msg *sse.Event) {
+		event := &lifecycle.Event{}
+		if err := json.Unmarshal(msg.Data, event); err != nil {
+			logging.Errorf("Could not parse lifecycle event: %s", err)
+			return
+		}
+		lifecycleCallback(event)
+	})
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 	"github.com/crc-org/crc/v2/pkg/crc/preset"
 	"go.podman.io/common/pkg/strongunits"
 )
@@ -33,6 +34,15 @@ type ClusterStatusResult struct {
 	Preset               preset.Preset
 }
 
+type InstanceResult struct {
+	Name      string
+	CrcStatus string
+}
+
+type ListInstancesResult struct {
+	Instances []InstanceResult
+}
+
 type ConsoleResult struct {
 	ClusterConfig types.ClusterConfig
 	State         state.State
@@ -61,6 +71,25 @@ type GetOrUnsetConfigRequest struct {
 	Properties []string `json:"properties"`
 }
 
+type DNSRecordRequest struct {
+	Hostname string `json:"hostname"`
+	IP       string `json:"ip,omitempty"`
+}
+
+// DNSRecordsResult struct is used to return the DNS records added to the
+// virtual network with 'crc dns add'
+type DNSRecordsResult struct {
+	Records []network.DNSRecord
+}
+
+// DNSRecordResult struct is used to return the record added or removed by a
+// DNS request, RequiresDaemonRestart is set when the running DNS server could
+// not be updated
+type DNSRecordResult struct {
+	Record                network.DNSRecord
+	RequiresDaemonRestart bool
+}
+
 type TelemetryRequest struct {
 	Action string `json:"action"`
 	Source string `json:"source"`
@@ -0,0 +1,246 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+)
+
+// APIError is the body of the error responses of the v2 API
+type APIError struct {
+	Code       crcErrors.Code `json:"code"`
+	Message    string         `json:"message"`
+	StatusCode int            `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+type OperationStatus string
+
+const (
+	OperationRunning   OperationStatus = "running"
+	OperationSucceeded OperationStatus = "succeeded"
+	OperationFailed    OperationStatus = "failed"
+	OperationCancelled OperationStatus = "cancelled"
+)
+
+// Operation tracks a long running request of the v2 API, such as starting
+// an instance. Result is the JSON result of the request once it succeeded.
+type Operation struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	Instance    string          `json:"instance"`
+	Status      OperationStatus `json:"status"`
+	Cancellable bool            `json:"cancellable"`
+	Error       *APIError       `json:"error,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	FinishedAt  *time.Time      `json:"finishedAt,omitempty"`
+}
+
+// Done returns true when the operation is not running anymore
+func (o *Operation) Done() bool {
+	return o.Status != OperationRunning
+}
+
+type OperationsResult struct {
+	Operations []Operation `json:"operations"`
+}
+
+type ConfigValueRequest struct {
+	Value interface{} `json:"value"`
+}
+
+type ConfigValueResult struct {
+	Key       string      `json:"key"`
+	Value     interface{} `json:"value"`
+	IsDefault bool        `json:"isDefault"`
+	Message   string      `json:"message,omitempty"`
+}
+
+// DiagnoseResult is the result of the diagnose operations
+type DiagnoseResult struct {
+	Path   string   `json:"path"`
+	Errors []string `json:"errors"`
+}
+
+// V2Client is a client of the /api/v2 routes of the daemon
+type V2Client struct {
+	client *http.Client
+	base   string
+}
+
+func NewV2(httpClient *http.Client, baseURL string) *V2Client {
+	return &V2Client{
+		client: httpClient,
+		base:   baseURL,
+	}
+}
+
+func (c *V2Client) Version() (VersionResult, error) {
+	var vr VersionResult
+	return vr, c.do(http.MethodGet, "/version", nil, &vr)
+}
+
+func (c *V2Client) Instances() (ListInstancesResult, error) {
+	var ir ListInstancesResult
+	return ir, c.do(http.MethodGet, "/instances", nil, &ir)
+}
+
+func (c *V2Client) Status(instance string) (ClusterStatusResult, error) {
+	var sr ClusterStatusResult
+	return sr, c.do(http.MethodGet, instancePath(instance, ""), nil, &sr)
+}
+
+// Start starts instance in the background, the returned operation reports
+// the progress
+func (c *V2Client) Start(instance string, config StartConfig) (Operation, error) {
+	var op Operation
+	return op, c.do(http.MethodPost, instancePath(instance, "/start"), config, &op)
+}
+
+func (c *V2Client) Stop(instance string) (Operation, error) {
+	var op Operation
+	return op, c.do(http.MethodPost, instancePath(instance, "/stop"), nil, &op)
+}
+
+func (c *V2Client) PowerOff(instance string) error {
+	return c.do(http.MethodPost, instancePath(instance, "/poweroff"), nil, nil)
+}
+
+func (c *V2Client) Delete(instance string) (Operation, error) {
+	var op Operation
+	return op, c.do(http.MethodDelete, instancePath(instance, ""), nil, &op)
+}
+
+func (c *V2Client) WebconsoleURL(instance string) (ConsoleResult, error) {
+	var cr ConsoleResult
+	return cr, c.do(http.MethodGet, instancePath(instance, "/console"), nil, &cr)
+}
+
+// Diagnose collects the diagnostics of instance in an archive in the
+// diagnostics directory of the daemon host, in the background. The result
+// of the returned operation is a DiagnoseResult.
+func (c *V2Client) Diagnose(instance string) (Operation, error) {
+	var op Operation
+	return op, c.do(http.MethodPost, instancePath(instance, "/diagnose"), nil, &op)
+}
+
+func (c *V2Client) Operations() (OperationsResult, error) {
+	var or OperationsResult
+	return or, c.do(http.MethodGet, "/operations", nil, &or)
+}
+
+func (c *V2Client) Operation(id string) (Operation, error) {
+	var op Operation
+	return op, c.do(http.MethodGet, "/operations/"+url.PathEscape(id), nil, &op)
+}
+
+// CancelOperation requests the cancellation of the operation, it keeps
+// running until the cancellation is effective
+func (c *V2Client) CancelOperation(id string) (Operation, error) {
+	var op Operation
+	return op, c.do(http.MethodDelete, "/operations/"+url.PathEscape(id), nil, &op)
+}
+
+// WaitOperation polls the operation every interval until it is done, and
+// returns its error when it did not succeed
+func (c *V2Client) WaitOperation(ctx context.Context, id string, interval time.Duration) (Operation, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		op, err := c.Operation(id)
+		if err != nil {
+			return op, err
+		}
+		if op.Done() {
+			if op.Error != nil {
+				return op, op.Error
+			}
+			return op, nil
+		}
+		select {
+		case <-ctx.Done():
+			return op, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *V2Client) Config() (GetConfigResult, error) {
+	var gcr GetConfigResult
+	return gcr, c.do(http.MethodGet, "/config", nil, &gcr)
+}
+
+func (c *V2Client) GetConfig(key string) (ConfigValueResult, error) {
+	var cr ConfigValueResult
+	return cr, c.do(http.MethodGet, "/config/"+url.PathEscape(key), nil, &cr)
+}
+
+func (c *V2Client) SetConfig(key string, value interface{}) (ConfigValueResult, error) {
+	var cr ConfigValueResult
+	return cr, c.do(http.MethodPut, "/config/"+url.PathEscape(key), ConfigValueRequest{Value: value}, &cr)
+}
+
+func (c *V2Client) UnsetConfig(key string) (ConfigValueResult, error) {
+	var cr ConfigValueResult
+	return cr, c.do(http.MethodDelete, "/config/"+url.PathEscape(key), nil, &cr)
+}
+
+// OpenAPI returns the OpenAPI document describing the v2 API
+func (c *V2Client) OpenAPI() ([]byte, error) {
+	var doc json.RawMessage
+	return doc, c.do(http.MethodGet, "/openapi.json", nil, &doc)
+}
+
+func instancePath(instance, suffix string) string {
+	return "/instances/" + url.PathEscape(instance) + suffix
+}
+
+// do sends the request with the JSON encoding of in as body, and decodes the
+// response into out, error responses are returned as *APIError
+func (c *V2Client) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("Failed to encode data to JSON: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.base+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.client.Do(req) //nolint:gosec // G704: SSRF vulnerability check not needed as the base URL is the daemon
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("Unknown error reading response: %w", err)
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{StatusCode: res.StatusCode}
+		if err := json.Unmarshal(data, apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Code = crcErrors.CodeInternal
+			apiErr.Message = fmt.Sprintf("Error occurred sending %s request to : %s : %d", method, path, res.StatusCode)
+		}
+		return apiErr
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -2,6 +2,8 @@ package events
 
 import (
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
@@ -13,25 +15,25 @@ type EventServer struct {
 	sseServer *sse.Server
 	muStreams sync.RWMutex
 	streams   map[string]EventStream
-	machine   machine.Client
+	instances *machine.Instances
 }
 
-func NewEventServer(machine machine.Client) *EventServer {
+func NewEventServer(instances *machine.Instances) *EventServer {
 
 	var sseServer = sse.New()
 	sseServer.AutoReplay = false
 
 	eventServer := &EventServer{
 		sseServer: sseServer,
-		machine:   machine,
+		instances: instances,
 		streams:   map[string]EventStream{},
 	}
 
 	sseServer.OnSubscribe = func(streamId string, sub *sse.Subscriber) {
 		logging.Debugf("OnSubscribe on channel: %s", streamId)
-		stream, ok := eventServer.streams[streamId]
 		eventServer.muStreams.Lock()
 		defer eventServer.muStreams.Unlock()
+		stream, ok := eventServer.streams[streamId]
 		if !ok {
 			stream = createEventStream(eventServer, streamId)
 			if stream == nil {
@@ -46,7 +48,9 @@ func NewEventServer(machine machine.Client) *EventServer {
 
 	sseServer.OnUnsubscribe = func(streamId string, sub *sse.Subscriber) {
 		logging.Debugf("OnUnsubscribe on channel: %s", streamId)
+		eventServer.muStreams.RLock()
 		stream, ok := eventServer.streams[streamId]
+		eventServer.muStreams.RUnlock()
 		if !ok {
 			logging.Debugf("Could not find stream:%s", streamId)
 			return
@@ -56,19 +60,56 @@ func NewEventServer(machine machine.Client) *EventServer {
 
 	sseServer.CreateStream(LOGS)
 	sseServer.CreateStream(STATUS)
+	sseServer.CreateStream(LIFECYCLE)
 	return eventServer
 }
 
 func (es *EventServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// the status streams of the instances other than the default one are
+	// only created when a client subscribes to them
+	streamID := r.URL.Query().Get("stream")
+	if name, ok := instanceStatusStream(streamID); ok && !es.sseServer.StreamExists(streamID) {
+		if !es.instanceExists(name) {
+			http.Error(w, "Stream not found!", http.StatusNotFound)
+			return
+		}
+		es.sseServer.CreateStream(streamID)
+	}
 	es.sseServer.ServeHTTP(w, r)
 }
 
+func (es *EventServer) instanceExists(name string) bool {
+	names, err := es.instances.List()
+	if err != nil {
+		logging.Debugf("Cannot list instances: %v", err)
+		return false
+	}
+	return slices.Contains(names, name)
+}
+
+// instanceStatusStream returns the name of the instance of a status stream
+// of the 'status/<instance>' form
+func instanceStatusStream(streamID string) (string, bool) {
+	name, ok := strings.CutPrefix(streamID, STATUS+"/")
+	return name, ok && name != ""
+}
+
 func createEventStream(server *EventServer, streamID string) EventStream {
 	switch streamID {
 	case LOGS:
 		return newLogsStream(server)
 	case STATUS:
-		return newStatusStream(server)
+		return newStatusStream(server, server.instances.Default(), STATUS)
+	case LIFECYCLE:
+		return newLifecycleStream(server)
+	}
+	if name, ok := instanceStatusStream(streamID); ok {
+		client, err := server.instances.Get(name)
+		if err != nil {
+			logging.Errorf("Cannot get instance %s: %v", name, err)
+			return nil
+		}
+		return newStatusStream(server, client, streamID)
 	}
 	return nil
 }
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/crc-org/crc/v2/pkg/crc/api/client"
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startEventServer serves the event streams on /events like the daemon
+func startEventServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.StripPrefix("/events", NewEventServer(machine.NewInstances(fakemachine.NewClient(), nil))))
+	t.Cleanup(func() {
+		// the event streams only end when their clients disconnect
+		server.CloseClientConnections()
+		server.Close()
+	})
+	return server
+}
+
+func TestLifecycleEvents(t *testing.T) {
+	server := startEventServer(t)
+
+	received := make(chan *lifecycle.Event, 100)
+	go func() {
+		_ = client.NewSSEClient(http.DefaultTransport, server.URL).Lifecycle(func(event *lifecycle.Event) {
+			received <- event
+		})
+	}()
+	// the events published before the client subscribed are not sent to it
+	require.Eventually(t, func() bool {
+		lifecycle.Publish(lifecycle.Event{Phase: lifecycle.Stopped, Instance: "probe"})
+		select {
+		case <-received:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 10*time.Second, 10*time.Millisecond)
+
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.VMStarting, Instance: "crc"})
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.Failed, Instance: "crc", Message: "Cannot start machine"})
+
+	var phases []lifecycle.Phase
+	var failure *lifecycle.Event
+	for len(phases) < 2 {
+		select {
+		case event := <-received:
+			if event.Instance == "probe" {
+				continue
+			}
+			phases = append(phases, event.Phase)
+			if event.Phase == lifecycle.Failed {
+				failure = event
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("missing lifecycle events, received %v", phases)
+		}
+	}
+	assert.Equal(t, []lifecycle.Phase{lifecycle.VMStarting, lifecycle.Failed}, phases)
+	require.NotNil(t, failure)
+	assert.Equal(t, "crc", failure.Instance)
+	assert.Equal(t, "Cannot start machine", failure.Message)
+	assert.False(t, failure.Timestamp.IsZero())
+}
+
+func TestStatusStreamOfUnknownInstance(t *testing.T) {
+	server := startEventServer(t)
+
+	res, err := http.Get(server.URL + "/events?stream=status/missing")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
@@ -3,8 +3,9 @@ package events
 import "github.com/r3labs/sse/v2"
 
 const (
-	LOGS   = "logs"   // Logs event channel, contains daemon logs
-	STATUS = "status" // status event channel, contains VM load info
+	LOGS      = "logs"      // Logs event channel, contains daemon logs
+	STATUS    = "status"    // status event channel, contains VM load info
+	LIFECYCLE = "lifecycle" // lifecycle event channel, contains start/stop/delete progress
 )
 
 type EventPublisher interface {
@@ -0,0 +1,43 @@
+package events
+
+import (
+	"encoding/json"
+
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/r3labs/sse/v2"
+)
+
+// LifecycleListener forwards the events published by the lifecycle package
+// to the clients connected to the lifecycle stream
+type LifecycleListener struct {
+	unsubscribe func()
+}
+
+func newLifecycleStream(server *EventServer) EventStream {
+	return newStream(NewLifecycleListener(), newEventPublisher(LIFECYCLE, server.sseServer))
+}
+
+func NewLifecycleListener() EventProducer {
+	return &LifecycleListener{}
+}
+
+func (l *LifecycleListener) Start(publisher EventPublisher) {
+	logging.Debug("Start sending lifecycle events")
+	l.unsubscribe = lifecycle.Subscribe(func(event lifecycle.Event) {
+		bytes, err := json.Marshal(event)
+		if err != nil {
+			logging.Errorf("unexpected error during lifecycle event to JSON conversion: %v", err)
+			return
+		}
+		publisher.Publish(&sse.Event{Event: []byte(LIFECYCLE), Data: bytes})
+	})
+}
+
+func (l *LifecycleListener) Stop() {
+	logging.Debug("Stop sending lifecycle events")
+	if l.unsubscribe != nil {
+		l.unsubscribe()
+		l.unsubscribe = nil
+	}
+}
@@ -19,8 +19,8 @@ type TickListener struct {
 	tickPeriod time.Duration
 }
 
-func newStatusStream(server *EventServer) EventStream {
-	return newStream(NewStatusListener(server.machine), newEventPublisher(STATUS, server.sseServer))
+func newStatusStream(server *EventServer, machine crcMachine.Client, streamID string) EventStream {
+	return newStream(NewStatusListener(machine), newEventPublisher(streamID, server.sseServer))
 }
 
 func NewStatusListener(machine crcMachine.Client) EventProducer {
@@ -2,6 +2,7 @@ package api
 
 import (
 	gocontext "context"
+	"fmt"
 	"net/http"
 
 	"go.podman.io/common/pkg/strongunits"
@@ -9,19 +10,31 @@ import (
 	"github.com/crc-org/crc/v2/pkg/crc/api/client"
 	"github.com/crc-org/crc/v2/pkg/crc/cluster"
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/diagnose"
 	"github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 	"github.com/crc-org/crc/v2/pkg/crc/preflight"
 	"github.com/crc-org/crc/v2/pkg/crc/preset"
+	"github.com/crc-org/crc/v2/pkg/crc/validation"
 	"github.com/crc-org/crc/v2/pkg/crc/version"
 )
 
 type Handler struct {
 	Logger    Logger
 	Client    machine.Client
+	Instances *machine.Instances
 	Config    *crcConfig.Config
+	DNS       *network.DNS
 	Telemetry Telemetry
+
+	// ReportPreflight runs the preflight checks of the preflight report
+	ReportPreflight func(crcConfig.Storage) *preflight.Report
+	// CollectDiagnostics creates the archive of the diagnose requests
+	CollectDiagnostics func(ctx gocontext.Context, config crcConfig.Storage, machineClient machine.Client, pullSecret cluster.PullSecretLoader, outputDir string) (*diagnose.Result, error)
 }
 
 type Logger interface {
@@ -42,17 +55,64 @@ func (h *Handler) Logs(c *context) error {
 	})
 }
 
-func NewHandler(config *crcConfig.Config, machine machine.Client, logger Logger, telemetry Telemetry) *Handler {
+func NewHandler(config *crcConfig.Config, instances *machine.Instances, dns *network.DNS, logger Logger, telemetry Telemetry) *Handler {
 	return &Handler{
-		Client:    machine,
+		Client:    instances.Default(),
+		Instances: instances,
 		Config:    config,
+		DNS:       dns,
 		Logger:    logger,
 		Telemetry: telemetry,
+
+		ReportPreflight:    preflight.ReportHost,
+		CollectDiagnostics: diagnose.Collect,
+	}
+}
+
+// client returns the machine client targeted by the request, requests
+// outside of the /instances/{name} routes use the default instance
+func (h *Handler) client(c *context) (machine.Client, error) {
+	name := c.Param("name")
+	if name == "" {
+		return h.Client, nil
+	}
+	if err := validation.ValidateInstanceName(name); err != nil {
+		return nil, err
+	}
+	return h.Instances.Get(name)
+}
+
+func (h *Handler) ListInstances(c *context) error {
+	names, err := h.Instances.List()
+	if err != nil {
+		return err
+	}
+	instances := []client.InstanceResult{}
+	for _, name := range names {
+		machineClient, err := h.Instances.Get(name)
+		if err != nil {
+			return err
+		}
+		crcStatus := state.Stopped
+		if running, _ := machineClient.IsRunning(); running {
+			crcStatus = state.Running
+		}
+		instances = append(instances, client.InstanceResult{
+			Name:      name,
+			CrcStatus: string(crcStatus),
+		})
 	}
+	return c.JSON(http.StatusOK, client.ListInstancesResult{
+		Instances: instances,
+	})
 }
 
 func (h *Handler) Status(c *context) error {
-	exists, err := h.Client.Exists()
+	machineClient, err := h.client(c)
+	if err != nil {
+		return err
+	}
+	exists, err := machineClient.Exists()
 	if err != nil {
 		return err
 	}
@@ -60,7 +120,7 @@ func (h *Handler) Status(c *context) error {
 		return c.String(http.StatusInternalServerError, string(errors.VMNotExist))
 	}
 
-	res, err := h.Client.Status()
+	res, err := machineClient.Status()
 	if err != nil {
 		return err
 	}
@@ -79,10 +139,13 @@ func (h *Handler) Status(c *context) error {
 }
 
 func (h *Handler) Stop(c *context) error {
-	_, err := h.Client.Stop()
+	machineClient, err := h.client(c)
 	if err != nil {
 		return err
 	}
+	if _, err := machineClient.Stop(); err != nil {
+		return err
+	}
 	return c.Code(http.StatusOK)
 }
 
@@ -90,34 +153,73 @@ func (h *Handler) PowerOff(c *context) error {
 	if c.method != http.MethodPost {
 		return c.String(http.StatusMethodNotAllowed, "Only POST is allowed")
 	}
-	if err := h.Client.PowerOff(); err != nil {
+	machineClient, err := h.client(c)
+	if err != nil {
+		return err
+	}
+	if err := machineClient.PowerOff(); err != nil {
 		return err
 	}
 	return c.Code(http.StatusOK)
 }
 
 func (h *Handler) Start(c *context) error {
+	machineClient, err := h.client(c)
+	if err != nil {
+		return err
+	}
+	cfg, args, err := h.startRequest(c)
+	if err != nil {
+		return err
+	}
+	res, err := start(gocontext.Background(), machineClient, cfg, args)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// startRequest returns the configuration and the arguments of a start
+// request, its body is either empty, a client.StartConfig or a start profile
+func (h *Handler) startRequest(c *context) (*crcConfig.Config, client.StartConfig, error) {
 	crcConfig.UpdateDefaults(h.Config)
 	var parsedArgs client.StartConfig
-	if len(c.requestBody) > 0 {
-		if err := c.Bind(&parsedArgs); err != nil {
-			return err
-		}
+	if len(c.requestBody) == 0 {
+		return h.Config, parsedArgs, nil
 	}
-	if err := preflight.StartPreflightChecks(h.Config); err != nil {
-		return err
+	if crcConfig.IsProfile(c.requestBody) {
+		cfg, err := startProfileConfig(h.Config, c.requestBody)
+		return cfg, parsedArgs, err
 	}
+	if err := c.Bind(&parsedArgs); err != nil {
+		return nil, parsedArgs, err
+	}
+	return h.Config, parsedArgs, nil
+}
 
-	startConfig := getStartConfig(h.Config, parsedArgs)
-	res, err := h.Client.Start(gocontext.Background(), startConfig)
+func start(ctx gocontext.Context, machineClient machine.Client, cfg *crcConfig.Config, args client.StartConfig) (client.StartResult, error) {
+	if err := preflight.StartPreflightChecks(cfg); err != nil {
+		return client.StartResult{}, err
+	}
+	res, err := machineClient.Start(ctx, getStartConfig(cfg, args))
 	if err != nil {
-		return err
+		return client.StartResult{}, err
 	}
-	return c.JSON(http.StatusOK, client.StartResult{
+	return client.StartResult{
 		Status:         string(res.Status),
 		ClusterConfig:  res.ClusterConfig,
 		KubeletStarted: res.KubeletStarted,
-	})
+	}, nil
+}
+
+// startProfileConfig returns the configuration to use when the body of the
+// start request is a start profile
+func startProfileConfig(cfg *crcConfig.Config, body []byte) (*crcConfig.Config, error) {
+	profile, err := crcConfig.ParseProfile(body, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile: %w", err)
+	}
+	return crcConfig.ApplyProfile(cfg, profile, preflight.RegisterSettings)
 }
 
 func getStartConfig(cfg crcConfig.Storage, args client.StartConfig) types.StartConfig {
@@ -134,8 +236,16 @@ func getStartConfig(cfg crcConfig.Storage, args client.StartConfig) types.StartC
 		IngressHTTPSPort:         cfg.Get(crcConfig.IngressHTTPSPort).AsUInt(),
 		Preset:                   crcConfig.GetPreset(cfg),
 		EnableSharedDirs:         cfg.Get(crcConfig.EnableSharedDirs).AsBool(),
+		SharedDirs:               crcConfig.GetSharedDirs(cfg),
 		EmergencyLogin:           cfg.Get(crcConfig.EmergencyLogin).AsBool(),
 		EnableBundleQuayFallback: cfg.Get(crcConfig.EnableBundleQuayFallback).AsBool(),
+		BundleMirrors:            crcConfig.GetBundleMirrors(cfg),
+		EnforceBundleSignature:   cfg.Get(crcConfig.EnforceBundleSignature).AsBool(),
+		TrustedBundleKeys:        crcConfig.GetTrustedBundleKeys(cfg),
+		ProvisioningDir:          cfg.Get(crcConfig.ProvisioningDir).AsString(),
+		PortForwards:             crcConfig.GetPortForwards(cfg),
+		Registries:               crcConfig.GetRegistriesConfig(cfg),
+		AdditionalTrustedCAFiles: crcConfig.GetAdditionalTrustedCAFiles(cfg),
 	}
 }
 
@@ -149,21 +259,28 @@ func (h *Handler) GetVersion(c *context) error {
 }
 
 func (h *Handler) Delete(c *context) error {
-	err := h.Client.Delete()
+	machineClient, err := h.client(c)
 	if err != nil {
 		return err
 	}
+	if err := machineClient.Delete(); err != nil {
+		return err
+	}
 	return c.Code(http.StatusOK)
 }
 
 func (h *Handler) GetWebconsoleInfo(c *context) error {
-	if err := machine.CheckIfMachineMissing(h.Client); err != nil {
+	machineClient, err := h.client(c)
+	if err != nil {
+		return err
+	}
+	if err := machine.CheckIfMachineMissing(machineClient); err != nil {
 		// In case of machine doesn't exist then consoleResult error
 		// should be updated so that when rendering the result it have
 		// error details also.
 		return err
 	}
-	res, err := h.Client.GetConsoleURL()
+	res, err := machineClient.GetConsoleURL()
 	if err != nil {
 		return err
 	}
@@ -272,6 +389,62 @@ func (h *Handler) GetConfig(c *context) error {
 	})
 }
 
+// PreflightReport runs all the preflight checks applicable to the host and
+// reports their status, nothing is fixed
+func (h *Handler) PreflightReport(c *context) error {
+	return c.JSON(http.StatusOK, h.ReportPreflight(h.Config))
+}
+
+func (h *Handler) ListDNSRecords(c *context) error {
+	return c.JSON(http.StatusOK, client.DNSRecordsResult{
+		Records: append([]network.DNSRecord{}, crcConfig.GetDNSRecords(h.Config)...),
+	})
+}
+
+func (h *Handler) AddDNSRecord(c *context) error {
+	var req client.DNSRecordRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	record, err := network.NewDNSRecord(req.Hostname, req.IP)
+	if err != nil {
+		return err
+	}
+	if err := crcConfig.SetDNSRecord(h.Config, record); err != nil {
+		return err
+	}
+	result := client.DNSRecordResult{Record: record}
+	if h.DNS == nil {
+		result.RequiresDaemonRestart = true
+	} else if err := h.DNS.AddRecord(record); err != nil {
+		logging.Warnf("Failed to add %s to the DNS server: %v", record, err)
+		result.RequiresDaemonRestart = true
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// RemoveDNSRecord removes the record from the configuration and from the
+// DNS server of the virtual network. The server keeps resolving a name it
+// cannot forget until the daemon restarts.
+func (h *Handler) RemoveDNSRecord(c *context) error {
+	var req client.DNSRecordRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	record, err := crcConfig.RemoveDNSRecord(h.Config, req.Hostname)
+	if err != nil {
+		return err
+	}
+	result := client.DNSRecordResult{Record: record}
+	if h.DNS == nil {
+		result.RequiresDaemonRestart = true
+	} else if err := h.DNS.RemoveRecord(record, crcConfig.GetDNSRecords(h.Config)); err != nil {
+		logging.Warnf("Failed to remove %s from the DNS server: %v", record, err)
+		result.RequiresDaemonRestart = true
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
 func (h *Handler) UploadTelemetry(c *context) error {
 	var req client.TelemetryRequest
 	if err := c.Bind(&req); err != nil {
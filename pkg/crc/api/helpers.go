@@ -5,21 +5,29 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/metrics"
 )
 
 type context struct {
 	method      string
 	requestBody []byte
 	url         *url.URL
+	params      map[string]string
 
 	code         int
 	headers      map[string]string
 	responseBody []byte
 }
 
+// Param returns the value of the {name} segment of the matched route pattern
+func (c *context) Param(name string) string {
+	return c.params[name]
+}
+
 func (c *context) Bind(r interface{}) error {
 	return json.Unmarshal(c.requestBody, r)
 }
@@ -56,30 +64,67 @@ func newServer() *server {
 }
 
 func (s *server) GET(pattern string, handler func(c *context) error) {
-	s.routesLock.Lock()
-	defer s.routesLock.Unlock()
-	if _, ok := s.routes[pattern]; !ok {
-		s.routes[pattern] = make(map[string]func(*context) error)
-	}
-	s.routes[pattern][http.MethodGet] = handler
+	s.handle(http.MethodGet, pattern, handler)
 }
 
 func (s *server) POST(pattern string, handler func(c *context) error) {
-	s.routesLock.Lock()
-	defer s.routesLock.Unlock()
-	if _, ok := s.routes[pattern]; !ok {
-		s.routes[pattern] = make(map[string]func(*context) error)
-	}
-	s.routes[pattern][http.MethodPost] = handler
+	s.handle(http.MethodPost, pattern, handler)
+}
+
+func (s *server) PUT(pattern string, handler func(c *context) error) {
+	s.handle(http.MethodPut, pattern, handler)
 }
 
 func (s *server) DELETE(pattern string, handler func(c *context) error) {
+	s.handle(http.MethodDelete, pattern, handler)
+}
+
+func (s *server) handle(method, pattern string, handler func(c *context) error) {
 	s.routesLock.Lock()
 	defer s.routesLock.Unlock()
 	if _, ok := s.routes[pattern]; !ok {
 		s.routes[pattern] = make(map[string]func(*context) error)
 	}
-	s.routes[pattern][http.MethodDelete] = handler
+	s.routes[pattern][method] = handler
+}
+
+// lookup returns the routes registered for path. Patterns can contain
+// segments such as {name} which match any non-empty path segment.
+func (s *server) lookup(path string) (string, map[string]func(*context) error, map[string]string, bool) {
+	if route, ok := s.routes[path]; ok {
+		return path, route, nil, true
+	}
+	for pattern, route := range s.routes {
+		if params, ok := matchPattern(pattern, path); ok {
+			return pattern, route, params, true
+		}
+	}
+	return "", nil, nil, false
+}
+
+func matchPattern(pattern, path string) (map[string]string, bool) {
+	if !strings.Contains(pattern, "{") {
+		return nil, false
+	}
+	patternSegments := strings.Split(pattern, "/")
+	pathSegments := strings.Split(path, "/")
+	if len(patternSegments) != len(pathSegments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if pathSegments[i] == "" {
+				return nil, false
+			}
+			params[strings.Trim(segment, "{}")] = pathSegments[i]
+			continue
+		}
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	return params, true
 }
 
 func (s *server) Handler() http.Handler {
@@ -99,13 +144,14 @@ func (s *server) Handler() http.Handler {
 		}()
 
 		s.routesLock.RLock()
-		route, ok := s.routes[r.URL.Path]
+		pattern, route, params, ok := s.lookup(r.URL.Path)
 		if !ok {
 			s.routesLock.RUnlock()
 			status = http.StatusNotFound
 			http.Error(w, "Not Found", status)
 			return
 		}
+		metrics.SetRoute(r, pattern)
 		handler, ok := route[r.Method]
 		if !ok {
 			s.routesLock.RUnlock()
@@ -126,6 +172,7 @@ func (s *server) Handler() http.Handler {
 			requestBody: requestBody,
 			headers:     make(map[string]string),
 			url:         r.URL,
+			params:      params,
 		}
 		if err := handler(c); err != nil {
 			status = http.StatusInternalServerError
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crc-org/crc/v2/pkg/crc/api/client"
+	"github.com/crc-org/crc/v2/pkg/crc/version"
+)
+
+type jsonObject = map[string]interface{}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	marshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// openAPIDocument generates the OpenAPI 3 description of routes, the schemas
+// of the bodies are derived from the Go types of the route
+func openAPIDocument(routes []v2Route) jsonObject {
+	schemas := newSchemaGenerator()
+	errorResponse := jsonObject{
+		"description": "Error",
+		"content":     jsonContent(schemas.schema(reflect.TypeOf(client.APIError{}))),
+	}
+	paths := jsonObject{}
+	for _, route := range routes {
+		path, ok := paths[route.pattern].(jsonObject)
+		if !ok {
+			path = jsonObject{}
+			paths[route.pattern] = path
+		}
+		response := jsonObject{"description": http.StatusText(route.status)}
+		if route.response != nil {
+			response["content"] = jsonContent(schemas.schema(reflect.TypeOf(route.response)))
+		}
+		description := route.summary
+		if route.operation {
+			description += ". The request runs in the background, poll /v2/operations/{id} to get its result."
+		}
+		op := jsonObject{
+			"summary":     route.summary,
+			"description": description,
+			"operationId": route.operationID,
+			"responses": jsonObject{
+				strconv.Itoa(route.status): response,
+				"default":                  errorResponse,
+			},
+		}
+		if params := pathParameters(route.pattern); len(params) > 0 {
+			op["parameters"] = params
+		}
+		if route.request != nil {
+			op["requestBody"] = jsonObject{
+				"required": route.method == http.MethodPut,
+				"content":  jsonContent(schemas.schema(reflect.TypeOf(route.request))),
+			}
+		}
+		path[strings.ToLower(route.method)] = op
+	}
+	paths["/v2/openapi.json"] = jsonObject{
+		"get": jsonObject{
+			"summary":     "Get the OpenAPI description of the API",
+			"operationId": "getOpenAPI",
+			"responses": jsonObject{
+				"200": jsonObject{"description": "OK", "content": jsonContent(jsonObject{"type": "object"})},
+			},
+		},
+	}
+	return jsonObject{
+		"openapi": "3.0.3",
+		"info": jsonObject{
+			"title":   "CRC daemon API",
+			"version": version.GetCRCVersion(),
+		},
+		"servers": []jsonObject{{"url": "/api"}},
+		"paths":   paths,
+		"components": jsonObject{
+			"schemas": schemas.schemas,
+		},
+	}
+}
+
+func jsonContent(schema jsonObject) jsonObject {
+	return jsonObject{
+		"application/json": jsonObject{"schema": schema},
+	}
+}
+
+func pathParameters(pattern string) []jsonObject {
+	var params []jsonObject
+	for _, segment := range strings.Split(pattern, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, jsonObject{
+				"name":     strings.Trim(segment, "{}"),
+				"in":       "path",
+				"required": true,
+				"schema":   jsonObject{"type": "string"},
+			})
+		}
+	}
+	return params
+}
+
+type schemaGenerator struct {
+	schemas jsonObject
+	names   map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: jsonObject{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// schema returns the JSON schema of the encoding/json encoding of t, named
+// structs are added to the components and referenced
+func (g *schemaGenerator) schema(t reflect.Type) jsonObject {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return jsonObject{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return jsonObject{}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// the encoding is custom, it cannot be described from the type
+		return jsonObject{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return jsonObject{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return jsonObject{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonObject{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return jsonObject{"type": "number"}
+	case reflect.String:
+		return jsonObject{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return jsonObject{"type": "string", "format": "byte"}
+		}
+		return jsonObject{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return jsonObject{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = g.uniqueName(t)
+			g.names[t] = name
+			g.schemas[name] = g.structSchema(t)
+		}
+		return jsonObject{"$ref": "#/components/schemas/" + name}
+	default:
+		return jsonObject{}
+	}
+}
+
+// uniqueName prefixes the name of t with its package name when another type
+// already has this name
+func (g *schemaGenerator) uniqueName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := g.schemas[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + name
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) jsonObject {
+	properties := jsonObject{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schema(field.Type)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+	schema := jsonObject{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
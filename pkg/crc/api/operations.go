@@ -0,0 +1,155 @@
+package api
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/crc-org/crc/v2/pkg/crc/api/client"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+)
+
+// maxFinishedOperations is the number of finished operations kept for
+// clients polling them, older ones are forgotten
+const maxFinishedOperations = 100
+
+type operation struct {
+	client.Operation
+	cancel          gocontext.CancelFunc
+	cancelRequested bool
+}
+
+// operations runs the long running requests of the v2 API in the
+// background, clients poll them by ID
+type operations struct {
+	lock   sync.Mutex
+	nextID uint64
+	byID   map[string]*operation
+	order  []string
+}
+
+func newOperations() *operations {
+	return &operations{
+		byID: make(map[string]*operation),
+	}
+}
+
+// start runs fn in the background. When cancellable is true, fn must return
+// once its context is cancelled.
+func (o *operations) start(kind, instance string, cancellable bool, fn func(ctx gocontext.Context) (interface{}, error)) client.Operation {
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+
+	o.lock.Lock()
+	o.nextID++
+	op := &operation{
+		Operation: client.Operation{
+			ID:          strconv.FormatUint(o.nextID, 10),
+			Kind:        kind,
+			Instance:    instance,
+			Status:      client.OperationRunning,
+			Cancellable: cancellable,
+			CreatedAt:   time.Now(),
+		},
+		cancel: cancel,
+	}
+	o.byID[op.ID] = op
+	o.order = append(o.order, op.ID)
+	o.prune()
+	started := op.Operation
+	o.lock.Unlock()
+
+	go func() {
+		defer cancel()
+		result, err := fn(ctx)
+		o.finish(op, result, err)
+	}()
+	return started
+}
+
+func (o *operations) finish(op *operation, result interface{}, err error) {
+	var data []byte
+	if err == nil && result != nil {
+		data, err = json.Marshal(result)
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	now := time.Now()
+	op.FinishedAt = &now
+	switch {
+	case err != nil && op.cancelRequested:
+		op.Status = client.OperationCancelled
+		op.Error = toAPIError(crcErrors.WithCode(crcErrors.CodeCancelled, err))
+	case err != nil:
+		logging.Debugf("%s operation %s failed: %v", op.Kind, op.ID, err)
+		op.Status = client.OperationFailed
+		op.Error = toAPIError(err)
+	default:
+		op.Status = client.OperationSucceeded
+		op.Result = data
+	}
+	o.prune()
+}
+
+// prune forgets the oldest finished operations, o.lock must be held
+func (o *operations) prune() {
+	finished := 0
+	for _, id := range o.order {
+		if o.byID[id].Done() {
+			finished++
+		}
+	}
+	kept := o.order[:0]
+	for _, id := range o.order {
+		if finished > maxFinishedOperations && o.byID[id].Done() {
+			delete(o.byID, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	o.order = kept
+}
+
+func (o *operations) get(id string) (client.Operation, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	op, ok := o.byID[id]
+	if !ok {
+		return client.Operation{}, crcErrors.WithCode(crcErrors.CodeNotFound, fmt.Errorf("unknown operation: %s", id))
+	}
+	return op.Operation, nil
+}
+
+func (o *operations) list() []client.Operation {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	ops := make([]client.Operation, 0, len(o.order))
+	for _, id := range o.order {
+		ops = append(ops, o.byID[id].Operation)
+	}
+	return ops
+}
+
+// cancel requests the cancellation of a running operation, it is reported
+// as cancelled once it returns
+func (o *operations) cancel(id string) (client.Operation, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	op, ok := o.byID[id]
+	switch {
+	case !ok:
+		return client.Operation{}, crcErrors.WithCode(crcErrors.CodeNotFound, fmt.Errorf("unknown operation: %s", id))
+	case op.Done():
+		return op.Operation, crcErrors.WithCode(crcErrors.CodeConflict, fmt.Errorf("operation %s already finished", id))
+	case !op.Cancellable:
+		return op.Operation, crcErrors.WithCode(crcErrors.CodeConflict, fmt.Errorf("%s operation %s cannot be cancelled", op.Kind, id))
+	}
+	op.cancelRequested = true
+	op.cancel()
+	return op.Operation, nil
+}
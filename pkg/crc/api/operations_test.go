@@ -0,0 +1,98 @@
+package api
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/crc-org/crc/v2/pkg/crc/api/client"
+	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitOperation(t *testing.T, ops *operations, id string) client.Operation {
+	var op client.Operation
+	require.Eventually(t, func() bool {
+		var err error
+		op, err = ops.get(id)
+		require.NoError(t, err)
+		return op.Done()
+	}, 5*time.Second, 10*time.Millisecond)
+	return op
+}
+
+func TestOperationSucceeds(t *testing.T) {
+	ops := newOperations()
+	started := ops.start("start", "crc", true, func(gocontext.Context) (interface{}, error) {
+		return client.StartResult{Status: "Running"}, nil
+	})
+	assert.Equal(t, "1", started.ID)
+	assert.Equal(t, client.OperationRunning, started.Status)
+
+	op := waitOperation(t, ops, started.ID)
+	assert.Equal(t, client.OperationSucceeded, op.Status)
+	assert.Nil(t, op.Error)
+	assert.NotNil(t, op.FinishedAt)
+	assert.Contains(t, string(op.Result), `"Status":"Running"`)
+	assert.Len(t, ops.list(), 1)
+}
+
+func TestOperationFails(t *testing.T) {
+	ops := newOperations()
+	started := ops.start("stop", "crc", false, func(gocontext.Context) (interface{}, error) {
+		return nil, crcErrors.VMNotExist
+	})
+
+	op := waitOperation(t, ops, started.ID)
+	assert.Equal(t, client.OperationFailed, op.Status)
+	assert.Equal(t, &client.APIError{Code: crcErrors.CodeVMNotExist, Message: string(crcErrors.VMNotExist)}, op.Error)
+
+	_, err := ops.cancel(started.ID)
+	assert.EqualError(t, err, "operation 1 already finished")
+	assert.Equal(t, crcErrors.CodeConflict, crcErrors.CodeOf(err))
+}
+
+func TestOperationCancel(t *testing.T) {
+	ops := newOperations()
+	release := make(chan struct{})
+	uncancellable := ops.start("stop", "crc", false, func(gocontext.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	defer close(release)
+	_, err := ops.cancel(uncancellable.ID)
+	assert.EqualError(t, err, "stop operation 1 cannot be cancelled")
+
+	started := ops.start("start", "crc", true, func(ctx gocontext.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, fmt.Errorf("start interrupted: %w", ctx.Err())
+	})
+	_, err = ops.cancel(started.ID)
+	require.NoError(t, err)
+
+	op := waitOperation(t, ops, started.ID)
+	assert.Equal(t, client.OperationCancelled, op.Status)
+	assert.Equal(t, crcErrors.CodeCancelled, op.Error.Code)
+
+	_, err = ops.cancel("42")
+	assert.Equal(t, crcErrors.CodeNotFound, crcErrors.CodeOf(err))
+}
+
+func TestOperationsArePruned(t *testing.T) {
+	ops := newOperations()
+	var last client.Operation
+	for i := 0; i < maxFinishedOperations+10; i++ {
+		last = ops.start("stop", "crc", false, func(gocontext.Context) (interface{}, error) {
+			return nil, errors.New("stop failed")
+		})
+		waitOperation(t, ops, last.ID)
+	}
+	assert.Len(t, ops.list(), maxFinishedOperations)
+	_, err := ops.get("1")
+	assert.Error(t, err)
+	_, err = ops.get(last.ID)
+	assert.NoError(t, err)
+}
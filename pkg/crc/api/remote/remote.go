@@ -0,0 +1,394 @@
+package remote
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	crctls "github.com/crc-org/crc/v2/pkg/crc/tls"
+	crcos "github.com/crc-org/crc/v2/pkg/os"
+	"github.com/pkg/errors"
+)
+
+// Files of a credentials directory, the server directory holds the CA key
+// and certificate and the list of the clients, a client directory holds the
+// CA certificate, a client key and certificate and the token of the client
+const (
+	CACertFile     = "ca.crt"
+	ClientCertFile = "client.crt"
+	ClientKeyFile  = "client.key"
+	TokenFile      = "token"
+	caKeyFile      = "ca.key"
+	clientsFile    = "clients.json"
+)
+
+// Server holds the credentials of the remote API listener of the daemon
+type Server struct {
+	caKey       *rsa.PrivateKey
+	caCert      *x509.Certificate
+	clientsPath string
+
+	// clients is the content of clientsPath when it was last read, the file
+	// is replaced by the commands issuing and revoking client credentials
+	// while the daemon is running
+	clientsLock sync.Mutex
+	clients     *clientList
+	clientsInfo os.FileInfo
+}
+
+// clientList is the content of the clients file, each client has its own
+// token bound to its certificate
+type clientList struct {
+	Clients map[string]client `json:"clients"`
+	// Revoked holds the serial numbers of the revoked client certificates
+	Revoked []string `json:"revoked,omitempty"`
+}
+
+type client struct {
+	// TokenSha256 is the sha256sum of the bearer token of the client, the
+	// token itself is only written to the credentials of the client
+	TokenSha256 string `json:"tokenSha256"`
+	CertSerial  string `json:"certSerial"`
+}
+
+// LoadOrCreateServer reads the CA from dir, it is generated the first time
+// the remote API is enabled
+func LoadOrCreateServer(dir string) (*Server, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	caKey, caCert, err := loadOrCreateCA(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		caKey:       caKey,
+		caCert:      caCert,
+		clientsPath: filepath.Join(dir, clientsFile),
+	}, nil
+}
+
+func loadOrCreateCA(dir string) (*rsa.PrivateKey, *x509.Certificate, error) {
+	keyPath, certPath := filepath.Join(dir, caKeyFile), filepath.Join(dir, CACertFile)
+	if crcos.FileExists(keyPath) && crcos.FileExists(certPath) {
+		return readKeyPair(keyPath, certPath)
+	}
+	key, cert, err := crctls.GenerateSelfSignedCertificate(&crctls.CertCfg{
+		Subject:   pkix.Name{CommonName: "crc-remote-api-signer", OrganizationalUnit: []string{"crc"}},
+		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Validity:  crctls.ValidityTenYears,
+		IsCA:      true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := writeKeyPair(key, cert, keyPath, certPath); err != nil {
+		return nil, nil, err
+	}
+	return key, cert, nil
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "failed to generate the remote API token")
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func tokenSha256(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func certSerial(cert *x509.Certificate) string {
+	return cert.SerialNumber.Text(16)
+}
+
+// loadClients returns the client list, it is read again when the file
+// changed since the last call
+func (s *Server) loadClients() (*clientList, error) {
+	s.clientsLock.Lock()
+	defer s.clientsLock.Unlock()
+
+	info, err := os.Stat(s.clientsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &clientList{Clients: map[string]client{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.clients != nil && os.SameFile(info, s.clientsInfo) && info.ModTime().Equal(s.clientsInfo.ModTime()) {
+		return s.clients, nil
+	}
+	content, err := os.ReadFile(s.clientsPath)
+	if err != nil {
+		return nil, err
+	}
+	clients := &clientList{}
+	if err := json.Unmarshal(content, clients); err != nil {
+		return nil, errors.Wrapf(err, "invalid remote API client list %s", s.clientsPath)
+	}
+	if clients.Clients == nil {
+		clients.Clients = map[string]client{}
+	}
+	s.clients, s.clientsInfo = clients, info
+	return clients, nil
+}
+
+// updateClients applies update to the client list and writes it atomically
+func (s *Server) updateClients(update func(*clientList) error) error {
+	clients, err := s.loadClients()
+	if err != nil {
+		return err
+	}
+	updated := &clientList{
+		Clients: make(map[string]client, len(clients.Clients)),
+		Revoked: slices.Clone(clients.Revoked),
+	}
+	for name, c := range clients.Clients {
+		updated.Clients[name] = c
+	}
+	if err := update(updated); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(updated, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := s.clientsPath + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.clientsPath)
+}
+
+func readKeyPair(keyPath, certPath string) (*rsa.PrivateKey, *x509.Certificate, error) {
+	keyPem, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := crctls.PemToPrivateKey(keyPem)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid key %s", keyPath)
+	}
+	certPem, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := crctls.PemToCert(certPem)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid certificate %s", certPath)
+	}
+	return key, cert, nil
+}
+
+func writeKeyPair(key *rsa.PrivateKey, cert *x509.Certificate, keyPath, certPath string) error {
+	if err := os.WriteFile(keyPath, crctls.PrivateKeyToPem(key), 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(certPath, crctls.CertToPem(cert), 0600)
+}
+
+// Listen listens on address with a server certificate signed by the CA,
+// only the clients presenting a certificate signed by the CA are accepted
+func (s *Server) Listen(address string) (net.Listener, error) {
+	config, err := s.TLSConfig(address)
+	if err != nil {
+		return nil, err
+	}
+	return tls.Listen("tcp", address, config)
+}
+
+// TLSConfig returns the server configuration for a listener on address
+func (s *Server) TLSConfig(address string) (*tls.Config, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	dnsNames, ips := serverNames(host)
+	key, cert, err := crctls.GenerateSignedCertificate(s.caKey, s.caCert, &crctls.CertCfg{
+		Subject:      pkix.Name{CommonName: "crc-remote-api", OrganizationalUnit: []string{"crc"}},
+		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		Validity:     crctls.ValidityOneYear,
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	})
+	if err != nil {
+		return nil, err
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(s.caCert)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{cert.Raw},
+			PrivateKey:  key,
+			Leaf:        cert,
+		}},
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// serverNames returns the names of the server certificate, when listening
+// on all the interfaces they are the hostname and all the host addresses
+func serverNames(host string) ([]string, []net.IP) {
+	dnsNames := []string{"localhost"}
+	ips := []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	ip := net.ParseIP(host)
+	switch {
+	case host != "" && ip == nil:
+		dnsNames = append(dnsNames, host)
+	case ip != nil && !ip.IsUnspecified():
+		ips = append(ips, ip)
+	default:
+		if hostname, err := os.Hostname(); err == nil {
+			dnsNames = append(dnsNames, hostname)
+		}
+		if addrs, err := net.InterfaceAddrs(); err == nil {
+			for _, addr := range addrs {
+				if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+					ips = append(ips, ipNet.IP)
+				}
+			}
+		}
+	}
+	return dnsNames, ips
+}
+
+// Authenticate rejects the requests which do not have in their
+// Authorization header the bearer token issued with the client certificate
+// of the connection, and the requests of revoked clients
+func (s *Server) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.authenticate(r); err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) authenticate(r *http.Request) error {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return errors.New("missing client certificate")
+	}
+	cert := r.TLS.PeerCertificates[0]
+	clients, err := s.loadClients()
+	if err != nil {
+		return errors.New("cannot read the client list")
+	}
+	serial := certSerial(cert)
+	if slices.Contains(clients.Revoked, serial) {
+		return errors.New("revoked client certificate")
+	}
+	c, ok := clients.Clients[cert.Subject.CommonName]
+	if !ok || c.CertSerial != serial {
+		return errors.New("unknown client certificate")
+	}
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(tokenSha256(token)), []byte(c.TokenSha256)) != 1 {
+		return errors.New("invalid or missing bearer token")
+	}
+	return nil
+}
+
+// IssueClientCredentials writes to dir a client certificate called name
+// signed by the CA, together with the CA certificate and a bearer token
+// which is only accepted with this certificate. The credentials previously
+// issued to name are revoked.
+func (s *Server) IssueClientCredentials(name, dir string) error {
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+	key, cert, err := crctls.GenerateSignedCertificate(s.caKey, s.caCert, &crctls.CertCfg{
+		Subject:      pkix.Name{CommonName: name, OrganizationalUnit: []string{"crc-remote-api-clients"}},
+		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		Validity:     crctls.ValidityOneYear,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := writeKeyPair(key, cert, filepath.Join(dir, ClientKeyFile), filepath.Join(dir, ClientCertFile)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, CACertFile), crctls.CertToPem(s.caCert), 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, TokenFile), []byte(token), 0600); err != nil {
+		return err
+	}
+	return s.updateClients(func(clients *clientList) error {
+		if previous, ok := clients.Clients[name]; ok {
+			clients.Revoked = append(clients.Revoked, previous.CertSerial)
+		}
+		clients.Clients[name] = client{
+			TokenSha256: tokenSha256(token),
+			CertSerial:  certSerial(cert),
+		}
+		return nil
+	})
+}
+
+// RevokeClientCredentials revokes the certificate and the token issued to
+// name, the running daemon rejects its requests from then on
+func (s *Server) RevokeClientCredentials(name string) error {
+	return s.updateClients(func(clients *clientList) error {
+		previous, ok := clients.Clients[name]
+		if !ok {
+			return fmt.Errorf("no credentials were issued to client '%s'", name)
+		}
+		clients.Revoked = append(clients.Revoked, previous.CertSerial)
+		delete(clients.Clients, name)
+		return nil
+	})
+}
+
+// ClientTLSConfig reads the client credentials written by
+// IssueClientCredentials and returns the TLS configuration and the bearer
+// token to connect to the remote API
+func ClientTLSConfig(dir string) (*tls.Config, string, error) {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, ClientCertFile), filepath.Join(dir, ClientKeyFile))
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to load the remote API client certificate")
+	}
+	caPem, err := os.ReadFile(filepath.Join(dir, CACertFile))
+	if err != nil {
+		return nil, "", err
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caPem) {
+		return nil, "", fmt.Errorf("failed to parse %s", filepath.Join(dir, CACertFile))
+	}
+	token, err := os.ReadFile(filepath.Join(dir, TokenFile))
+	if err != nil {
+		return nil, "", err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      rootCAs,
+		MinVersion:   tls.VersionTLS12,
+	}, strings.TrimSpace(string(token)), nil
+}
@@ -0,0 +1,188 @@
+package remote
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, server *Server) string {
+	listener, err := server.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	s := &http.Server{
+		Handler: server.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})),
+	}
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return "https://" + listener.Addr().String()
+}
+
+func get(client *http.Client, url, token string) (int, string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	return res.StatusCode, string(body), err
+}
+
+func TestLoadOrCreateServerReusesCredentials(t *testing.T) {
+	dir := t.TempDir()
+	server, err := LoadOrCreateServer(dir)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, CACertFile))
+
+	reloaded, err := LoadOrCreateServer(dir)
+	require.NoError(t, err)
+	assert.Equal(t, server.caCert.Raw, reloaded.caCert.Raw)
+}
+
+func newClient(t *testing.T, server *Server, name string) (*http.Client, string) {
+	dir := t.TempDir()
+	require.NoError(t, server.IssueClientCredentials(name, dir))
+	tlsConfig, token, err := ClientTLSConfig(dir)
+	require.NoError(t, err)
+	assert.Len(t, token, 64)
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}, token
+}
+
+func TestRemoteAccess(t *testing.T) {
+	serverDir, clientDir := t.TempDir(), t.TempDir()
+	server, err := LoadOrCreateServer(serverDir)
+	require.NoError(t, err)
+	url := serve(t, server)
+	require.NoError(t, server.IssueClientCredentials("ci", clientDir))
+
+	tlsConfig, token, err := ClientTLSConfig(clientDir)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+
+	status, body, err := get(client, url, token)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", body)
+
+	status, _, err = get(client, url, "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, _, err = get(client, url, token+"0")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestRemoteAccessRequiresClientCertificate(t *testing.T) {
+	serverDir, clientDir := t.TempDir(), t.TempDir()
+	server, err := LoadOrCreateServer(serverDir)
+	require.NoError(t, err)
+	url := serve(t, server)
+	require.NoError(t, server.IssueClientCredentials("ci", clientDir))
+
+	tlsConfig, token, err := ClientTLSConfig(clientDir)
+	require.NoError(t, err)
+	tlsConfig.Certificates = nil
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	_, _, err = get(client, url, token)
+	assert.Error(t, err)
+}
+
+func TestRemoteAccessRejectsOtherCA(t *testing.T) {
+	server, err := LoadOrCreateServer(t.TempDir())
+	require.NoError(t, err)
+	url := serve(t, server)
+
+	_, token := newClient(t, server, "ci")
+	other, err := LoadOrCreateServer(t.TempDir())
+	require.NoError(t, err)
+	clientDir := t.TempDir()
+	require.NoError(t, other.IssueClientCredentials("ci", clientDir))
+	tlsConfig, _, err := ClientTLSConfig(clientDir)
+	require.NoError(t, err)
+	tlsConfig.RootCAs.AddCert(server.caCert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	_, _, err = get(client, url, token)
+	assert.Error(t, err)
+}
+
+func TestRemoteAccessTokenIsBoundToClient(t *testing.T) {
+	server, err := LoadOrCreateServer(t.TempDir())
+	require.NoError(t, err)
+	url := serve(t, server)
+	ci, ciToken := newClient(t, server, "ci")
+	laptop, laptopToken := newClient(t, server, "laptop")
+	assert.NotEqual(t, ciToken, laptopToken)
+
+	for _, test := range []struct {
+		client *http.Client
+		token  string
+		status int
+	}{
+		{ci, ciToken, http.StatusOK},
+		{laptop, laptopToken, http.StatusOK},
+		{ci, laptopToken, http.StatusUnauthorized},
+		{laptop, ciToken, http.StatusUnauthorized},
+	} {
+		status, _, err := get(test.client, url, test.token)
+		require.NoError(t, err)
+		assert.Equal(t, test.status, status)
+	}
+}
+
+func TestRevokeClientCredentials(t *testing.T) {
+	serverDir := t.TempDir()
+	server, err := LoadOrCreateServer(serverDir)
+	require.NoError(t, err)
+	url := serve(t, server)
+	ci, ciToken := newClient(t, server, "ci")
+	laptop, laptopToken := newClient(t, server, "laptop")
+
+	// credentials are managed by other processes while the daemon is running
+	cli, err := LoadOrCreateServer(serverDir)
+	require.NoError(t, err)
+	require.NoError(t, cli.RevokeClientCredentials("ci"))
+	assert.EqualError(t, cli.RevokeClientCredentials("ci"), "no credentials were issued to client 'ci'")
+
+	status, body, err := get(ci, url, ciToken)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "revoked client certificate\n", body)
+	status, _, err = get(laptop, url, laptopToken)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	// issuing new credentials to a client revokes its previous ones
+	_, newCiToken := newClient(t, cli, "ci")
+	status, _, err = get(ci, url, newCiToken)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	_, newLaptopToken := newClient(t, cli, "laptop")
+	status, _, err = get(laptop, url, newLaptopToken)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _, err = get(laptop, url, laptopToken)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestClientTLSConfigMissingCredentials(t *testing.T) {
+	_, _, err := ClientTLSConfig(t.TempDir())
+	assert.ErrorContains(t, err, "failed to load the remote API client certificate")
+}
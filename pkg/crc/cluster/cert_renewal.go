@@ -2,13 +2,18 @@ package cluster
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	crcerrors "github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/oc"
 	"github.com/crc-org/crc/v2/pkg/crc/ssh"
+	"github.com/crc-org/crc/v2/pkg/crc/systemd"
+	crcos "github.com/crc-org/crc/v2/pkg/os"
 
 	k8scerts "k8s.io/api/certificates/v1beta1"
 )
@@ -43,12 +48,14 @@ func approvePendingCSRs(ctx context.Context, ocConfig oc.Config, expectedSignerN
 	}, time.Second*5)
 }
 
-func ApproveCSRAndWaitForCertsRenewal(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, client, server, aggregratorClient bool) error {
-	const (
-		kubeletClientSignerName  = "kubernetes.io/kube-apiserver-client-kubelet"
-		kubeletServingSignerName = "kubernetes.io/kubelet-serving"
-	)
+const (
+	kubeletCertBackupSuffix = ".crc-backup"
+
+	kubeletClientSignerName  = "kubernetes.io/kube-apiserver-client-kubelet"
+	kubeletServingSignerName = "kubernetes.io/kubelet-serving"
+)
 
+func ApproveCSRAndWaitForCertsRenewal(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, client, server, aggregratorClient bool) error {
 	// First, kubelet starts and tries to connect to API server. If its certificate is expired, it asks for a new one
 	// Admin needs to approve it. The Kubernetes controller manager will then issue the cert, kubelet will fetch it and use it.
 	// Kubelet stores the cert in /var/lib/kubelet/pki/kubelet-client-current.pem
@@ -96,3 +103,152 @@ func waitForCertRenewal(sshRunner *ssh.Runner, cert string) func() error {
 		return &crcerrors.RetriableError{Err: fmt.Errorf("certificate %s still expired", cert)}
 	}
 }
+
+// RenewKubeletCertificates makes kubelet request new client and serving
+// certificates even when the current ones are still valid, kubelet then
+// bootstraps again using its long-lived bootstrap kubeconfig. The current
+// certificates are moved aside and restored when the renewal fails.
+func RenewKubeletCertificates(ctx context.Context, runner crcos.CommandRunner, ocConfig oc.Config) error {
+	serials := make(map[string]string)
+	var backups []string
+	for _, cert := range []string{KubeletClientCert, KubeletServerCert} {
+		current, err := ReadCertificate(runner, cert)
+		if errors.Is(err, ErrCertificateNotFound) {
+			// any certificate issued by kubelet is then a new one
+			serials[cert] = ""
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		serials[cert] = current.SerialNumber.String()
+		backups = append(backups, cert)
+	}
+
+	sd := systemd.NewInstanceSystemdCommander(runner)
+	if err := sd.Stop("kubelet"); err != nil {
+		return fmt.Errorf("failed to stop kubelet: %w", err)
+	}
+	for i, cert := range backups {
+		if _, stderr, err := runner.RunPrivileged("Moving aside kubelet certificates", "mv", "-f", cert, cert+kubeletCertBackupSuffix); err != nil {
+			err = fmt.Errorf("failed to move aside %s: %s: %w", cert, stderr, err)
+			return restoreKubeletCertificates(sd, runner, backups[:i], err)
+		}
+	}
+	if err := sd.Start("kubelet"); err != nil {
+		return restoreKubeletCertificates(sd, runner, backups, fmt.Errorf("failed to start kubelet: %w", err))
+	}
+
+	logging.Info("Renewing kubelet certificates... [will take up to 10 minutes]")
+	if err := renewKubeletCertificates(ctx, runner, ocConfig, serials); err != nil {
+		return restoreKubeletCertificates(sd, runner, backups, err)
+	}
+	for _, cert := range backups {
+		if _, stderr, err := runner.RunPrivileged("Removing previous kubelet certificates", "rm", "-f", cert+kubeletCertBackupSuffix); err != nil {
+			logging.Warnf("Failed to remove %s: %s: %v", cert+kubeletCertBackupSuffix, stderr, err)
+		}
+	}
+	return nil
+}
+
+func renewKubeletCertificates(ctx context.Context, runner crcos.CommandRunner, ocConfig oc.Config, serials map[string]string) error {
+	if err := approvePendingCSRs(ctx, ocConfig, kubeletClientSignerName); err != nil {
+		return err
+	}
+	if err := approvePendingCSRs(ctx, ocConfig, kubeletServingSignerName); err != nil {
+		return err
+	}
+	for _, cert := range []string{KubeletClientCert, KubeletServerCert} {
+		if err := crcerrors.Retry(ctx, 5*time.Minute, waitForNewCert(runner, cert, serials[cert]), time.Second*5); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreKubeletCertificates puts back the certificates moved aside by
+// RenewKubeletCertificates and restarts kubelet with them, renewalErr is
+// returned with the restoration failures
+func restoreKubeletCertificates(sd *systemd.Commander, runner crcos.CommandRunner, backups []string, renewalErr error) error {
+	logging.Warnf("Failed to renew kubelet certificates, restoring the previous ones: %v", renewalErr)
+	errs := []error{renewalErr}
+	if err := sd.Stop("kubelet"); err != nil {
+		errs = append(errs, fmt.Errorf("failed to stop kubelet: %w", err))
+	}
+	for _, cert := range backups {
+		if _, stderr, err := runner.RunPrivileged("Restoring kubelet certificates", "mv", "-f", cert+kubeletCertBackupSuffix, cert); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %s: %w", cert, stderr, err))
+		}
+	}
+	if err := sd.Start("kubelet"); err != nil {
+		errs = append(errs, fmt.Errorf("failed to start kubelet: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func waitForNewCert(runner crcos.CommandRunner, cert, oldSerial string) func() error {
+	return func() error {
+		current, err := ReadCertificate(runner, cert)
+		if err != nil {
+			return &crcerrors.RetriableError{Err: err}
+		}
+		if current.SerialNumber.String() == oldSerial {
+			return &crcerrors.RetriableError{Err: fmt.Errorf("certificate %s not renewed yet", cert)}
+		}
+		return nil
+	}
+}
+
+// KubeAPIServerRevision returns the latest revision of the kube-apiserver
+// static pods, a new revision is rolled out when their configuration changes
+func KubeAPIServerRevision(ocConfig oc.Config) (int, error) {
+	latest, _, err := kubeAPIServerRevisions(ocConfig)
+	return latest, err
+}
+
+// WaitForKubeAPIServerRollout waits until a revision newer than
+// previousRevision is running on all the nodes
+func WaitForKubeAPIServerRollout(ctx context.Context, ocConfig oc.Config, previousRevision int) error {
+	logging.Info("Waiting for the kube-apiserver rollout... [will take up to 15 minutes]")
+	return crcerrors.Retry(ctx, 15*time.Minute, func() error {
+		latest, current, err := kubeAPIServerRevisions(ocConfig)
+		if err != nil {
+			return &crcerrors.RetriableError{Err: err}
+		}
+		if latest <= previousRevision {
+			return &crcerrors.RetriableError{Err: fmt.Errorf("no kube-apiserver revision newer than %d yet", previousRevision)}
+		}
+		if len(current) == 0 {
+			return &crcerrors.RetriableError{Err: fmt.Errorf("kube-apiserver revision %d not rolled out yet", latest)}
+		}
+		for _, revision := range current {
+			if revision != latest {
+				return &crcerrors.RetriableError{Err: fmt.Errorf("kube-apiserver revision %d not rolled out yet", latest)}
+			}
+		}
+		return nil
+	}, 10*time.Second)
+}
+
+// kubeAPIServerRevisions returns the latest kube-apiserver revision and the
+// revision running on each node
+func kubeAPIServerRevisions(ocConfig oc.Config) (int, []int, error) {
+	stdout, stderr, err := ocConfig.RunOcCommand("get", "kubeapiserver", "cluster", "-o",
+		`jsonpath='{.status.latestAvailableRevision}{" "}{.status.nodeStatuses[*].currentRevision}'`)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get the kube-apiserver revisions: %s: %w", stderr, err)
+	}
+	fields := strings.Fields(stdout)
+	if len(fields) == 0 {
+		return 0, nil, fmt.Errorf("no kube-apiserver revision available")
+	}
+	revisions := make([]int, 0, len(fields))
+	for _, field := range fields {
+		revision, err := strconv.Atoi(field)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid kube-apiserver revision %q: %w", field, err)
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions[0], revisions[1:], nil
+}
@@ -0,0 +1,189 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/crc/oc"
+	crctls "github.com/crc-org/crc/v2/pkg/crc/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pendingCSRs = `{"items": [
+  {"metadata": {"name": "csr-client"}, "spec": {"signerName": "kubernetes.io/kube-apiserver-client-kubelet"}},
+  {"metadata": {"name": "csr-serving"}, "spec": {"signerName": "kubernetes.io/kubelet-serving"}}
+]}`
+
+// kubeletVM fakes the files of the VM and a kubelet which issues new
+// certificates when it starts without them
+type kubeletVM struct {
+	t        *testing.T
+	files    map[string]string
+	commands []string
+	revision string
+}
+
+func newKubeletVM(t *testing.T) *kubeletVM {
+	return &kubeletVM{
+		t: t,
+		files: map[string]string{
+			KubeletClientCert: newCertificatePem(t),
+			KubeletServerCert: newCertificatePem(t),
+		},
+	}
+}
+
+func newCertificatePem(t *testing.T) string {
+	_, cert, err := crctls.GetSelfSignedCA()
+	require.NoError(t, err)
+	return string(crctls.CertToPem(cert))
+}
+
+func (vm *kubeletVM) Run(command string, args ...string) (string, string, error) {
+	cmd := strings.Join(append([]string{command}, args...), " ")
+	switch {
+	case strings.Contains(cmd, "oc get csr -ojson"):
+		return pendingCSRs, "", nil
+	case strings.Contains(cmd, "oc get kubeapiserver"):
+		return vm.revision, "", nil
+	case strings.Contains(cmd, "oc get"), strings.Contains(cmd, "oc adm certificate approve"):
+		return "", "", nil
+	}
+	vm.t.Fatalf("unexpected command %s", cmd)
+	return "", "", nil
+}
+
+func (vm *kubeletVM) RunPrivate(command string, args ...string) (string, string, error) {
+	if command == "sudo" && len(args) == 2 && args[0] == "cat" {
+		content, ok := vm.files[args[1]]
+		if !ok {
+			return "", "cat: " + args[1] + ": No such file or directory", errors.New("exit status 1")
+		}
+		return content, "", nil
+	}
+	return vm.Run(command, args...)
+}
+
+func (vm *kubeletVM) RunPrivileged(_ string, cmdAndArgs ...string) (string, string, error) {
+	cmd := strings.Join(cmdAndArgs, " ")
+	if cmd == "systemctl daemon-reload" {
+		return "", "", nil
+	}
+	vm.commands = append(vm.commands, cmd)
+	switch {
+	case cmd == "systemctl start kubelet":
+		for _, cert := range []string{KubeletClientCert, KubeletServerCert} {
+			if _, ok := vm.files[cert]; !ok {
+				vm.files[cert] = newCertificatePem(vm.t)
+			}
+		}
+	case cmdAndArgs[0] == "mv":
+		content, ok := vm.files[cmdAndArgs[2]]
+		if !ok {
+			return "", "No such file or directory", errors.New("exit status 1")
+		}
+		delete(vm.files, cmdAndArgs[2])
+		vm.files[cmdAndArgs[3]] = content
+	case cmdAndArgs[0] == "rm":
+		delete(vm.files, cmdAndArgs[2])
+	}
+	return "", "", nil
+}
+
+func testOCConfig(vm *kubeletVM) oc.Config {
+	return oc.Config{
+		Runner:           vm,
+		OcExecutablePath: "oc",
+		Timeout:          "30s",
+	}
+}
+
+func TestRenewKubeletCertificates(t *testing.T) {
+	vm := newKubeletVM(t)
+	previous := map[string]string{
+		KubeletClientCert: vm.files[KubeletClientCert],
+		KubeletServerCert: vm.files[KubeletServerCert],
+	}
+
+	require.NoError(t, RenewKubeletCertificates(context.Background(), vm, testOCConfig(vm)))
+	assert.Equal(t, []string{
+		"systemctl stop kubelet",
+		"mv -f " + KubeletClientCert + " " + KubeletClientCert + kubeletCertBackupSuffix,
+		"mv -f " + KubeletServerCert + " " + KubeletServerCert + kubeletCertBackupSuffix,
+		"systemctl start kubelet",
+		"rm -f " + KubeletClientCert + kubeletCertBackupSuffix,
+		"rm -f " + KubeletServerCert + kubeletCertBackupSuffix,
+	}, vm.commands)
+	assert.Len(t, vm.files, 2)
+	for cert, content := range previous {
+		assert.NotEqual(t, content, vm.files[cert])
+	}
+}
+
+func TestRenewKubeletCertificatesRestoresCertificatesOnFailure(t *testing.T) {
+	vm := newKubeletVM(t)
+	previous := map[string]string{
+		KubeletClientCert: vm.files[KubeletClientCert],
+		KubeletServerCert: vm.files[KubeletServerCert],
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := RenewKubeletCertificates(ctx, vm, testOCConfig(vm))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{
+		"systemctl stop kubelet",
+		"mv -f " + KubeletClientCert + " " + KubeletClientCert + kubeletCertBackupSuffix,
+		"mv -f " + KubeletServerCert + " " + KubeletServerCert + kubeletCertBackupSuffix,
+		"systemctl start kubelet",
+		"systemctl stop kubelet",
+		"mv -f " + KubeletClientCert + kubeletCertBackupSuffix + " " + KubeletClientCert,
+		"mv -f " + KubeletServerCert + kubeletCertBackupSuffix + " " + KubeletServerCert,
+		"systemctl start kubelet",
+	}, vm.commands)
+	assert.Equal(t, previous, vm.files)
+}
+
+func TestRenewKubeletCertificatesWithMissingCertificate(t *testing.T) {
+	vm := newKubeletVM(t)
+	delete(vm.files, KubeletServerCert)
+
+	require.NoError(t, RenewKubeletCertificates(context.Background(), vm, testOCConfig(vm)))
+	assert.Equal(t, []string{
+		"systemctl stop kubelet",
+		"mv -f " + KubeletClientCert + " " + KubeletClientCert + kubeletCertBackupSuffix,
+		"systemctl start kubelet",
+		"rm -f " + KubeletClientCert + kubeletCertBackupSuffix,
+	}, vm.commands)
+	assert.Contains(t, vm.files, KubeletServerCert)
+}
+
+func TestWaitForKubeAPIServerRollout(t *testing.T) {
+	vm := newKubeletVM(t)
+	vm.revision = "8 8"
+	assert.NoError(t, WaitForKubeAPIServerRollout(context.Background(), testOCConfig(vm), 7))
+
+	revision, err := KubeAPIServerRevision(testOCConfig(vm))
+	require.NoError(t, err)
+	assert.Equal(t, 8, revision)
+}
+
+func TestKubeAPIServerRevisions(t *testing.T) {
+	vm := newKubeletVM(t)
+	vm.revision = "9 8"
+	latest, current, err := kubeAPIServerRevisions(testOCConfig(vm))
+	require.NoError(t, err)
+	assert.Equal(t, 9, latest)
+	assert.Equal(t, []int{8}, current)
+
+	vm.revision = ""
+	_, _, err = kubeAPIServerRevisions(testOCConfig(vm))
+	assert.EqualError(t, err, "no kube-apiserver revision available")
+
+	vm.revision = "9 unknown"
+	_, _, err = kubeAPIServerRevisions(testOCConfig(vm))
+	assert.ErrorContains(t, err, `invalid kube-apiserver revision "unknown"`)
+}
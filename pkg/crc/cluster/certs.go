@@ -0,0 +1,67 @@
+package cluster
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	crcos "github.com/crc-org/crc/v2/pkg/os"
+)
+
+const (
+	KubeAPIServerLocalhostServingCert = "/etc/kubernetes/static-pod-resources/kube-apiserver-certs/secrets/localhost-serving-cert-certkey/tls.crt"
+	KubeAPIServerExternalServingCert  = "/etc/kubernetes/static-pod-resources/kube-apiserver-certs/secrets/external-loadbalancer-serving-certkey/tls.crt"
+)
+
+// Certificate is a certificate of the cluster stored in the VM
+type Certificate struct {
+	Name string
+	Path string
+}
+
+// Certificates lists the cluster certificates which can expire while the
+// instance is stopped
+var Certificates = []Certificate{
+	{Name: "kubelet-client", Path: KubeletClientCert},
+	{Name: "kubelet-server", Path: KubeletServerCert},
+	{Name: "aggregator-client", Path: AggregatorClientCert},
+	{Name: "kube-apiserver-localhost-serving", Path: KubeAPIServerLocalhostServingCert},
+	{Name: "kube-apiserver-external-serving", Path: KubeAPIServerExternalServingCert},
+}
+
+// ErrCertificateNotFound is returned when a certificate file does not exist in the VM
+var ErrCertificateNotFound = errors.New("certificate not found")
+
+// ReadCertificate returns the certificate stored at path in the VM
+func ReadCertificate(runner crcos.CommandRunner, path string) (*x509.Certificate, error) {
+	// the kubelet files also contain the private key, the output must not be logged
+	output, stderr, err := runner.RunPrivate("sudo", "cat", path)
+	if err != nil && strings.Contains(stderr, "No such file or directory") {
+		return nil, fmt.Errorf("failed to read %s: %w", path, ErrCertificateNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %s: %w", path, stderr, err)
+	}
+	cert, err := firstCertificate([]byte(output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cert, nil
+}
+
+// firstCertificate parses the first certificate of the PEM data, other
+// blocks such as private keys are skipped
+func firstCertificate(data []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no certificate found")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
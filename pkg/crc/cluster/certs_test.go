@@ -0,0 +1,39 @@
+package cluster
+
+import (
+	"testing"
+
+	crctls "github.com/crc-org/crc/v2/pkg/crc/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstCertificateSkipsPrivateKey(t *testing.T) {
+	key, cert, err := crctls.GetSelfSignedCA()
+	require.NoError(t, err)
+
+	// kubelet stores the private key before the certificate
+	data := append(crctls.PrivateKeyToPem(key), crctls.CertToPem(cert)...)
+	parsed, err := firstCertificate(data)
+	require.NoError(t, err)
+	assert.Equal(t, cert.SerialNumber, parsed.SerialNumber)
+	assert.Equal(t, "admin-kubeconfig-signer-custom", parsed.Subject.CommonName)
+}
+
+func TestFirstCertificateWithoutCertificate(t *testing.T) {
+	key, _, err := crctls.GetSelfSignedCA()
+	require.NoError(t, err)
+
+	_, err = firstCertificate(crctls.PrivateKeyToPem(key))
+	assert.EqualError(t, err, "no certificate found")
+}
+
+func TestReadMissingCertificate(t *testing.T) {
+	vm := newKubeletVM(t)
+	_, err := ReadCertificate(vm, KubeAPIServerLocalhostServingCert)
+	assert.ErrorIs(t, err, ErrCertificateNotFound)
+
+	cert, err := ReadCertificate(vm, KubeletClientCert)
+	require.NoError(t, err)
+	assert.Equal(t, "admin-kubeconfig-signer-custom", cert.Subject.CommonName)
+}
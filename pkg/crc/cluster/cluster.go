@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"math"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -17,7 +16,6 @@ import (
 
 	"go.podman.io/common/pkg/strongunits"
 
-	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/network/httpproxy"
@@ -184,7 +182,10 @@ func EnsureSSHKeyPresentInTheCluster(ctx context.Context, ocConfig oc.Config, ss
 	return nil
 }
 
-func EnsurePullSecretPresentInTheCluster(ctx context.Context, ocConfig oc.Config, pullSec PullSecretLoader) error {
+// EnsurePullSecretPresentInTheCluster adds the user's pull secret to the
+// cluster when it has no valid one. With replace, a valid pull secret of the
+// cluster is also replaced when its credentials differ from the user's ones.
+func EnsurePullSecretPresentInTheCluster(ctx context.Context, ocConfig oc.Config, pullSec PullSecretLoader, replace bool) error {
 	if err := WaitForOpenshiftResource(ctx, ocConfig, "secret"); err != nil {
 		return err
 	}
@@ -197,15 +198,23 @@ func EnsurePullSecretPresentInTheCluster(ctx context.Context, ocConfig oc.Config
 	if err != nil {
 		return err
 	}
-	if err := validation.ImagePullSecret(string(decoded)); err == nil {
+	clusterPullSecretValid := validation.ImagePullSecret(string(decoded)) == nil
+	if clusterPullSecretValid && !replace {
 		return nil
 	}
 
-	logging.Info("Adding user's pull secret to the cluster...")
 	content, err := pullSec.Value()
 	if err != nil {
 		return err
 	}
+	if clusterPullSecretValid {
+		if SamePullSecrets(string(decoded), content) {
+			return nil
+		}
+		logging.Info("Updating the pull secret of the cluster...")
+	} else {
+		logging.Info("Adding user's pull secret to the cluster...")
+	}
 	base64OfPullSec := base64.StdEncoding.EncodeToString([]byte(content))
 	cmdArgs := []string{"patch", "secret", "pull-secret", "-p",
 		fmt.Sprintf(`'{"data":{".dockerconfigjson":"%s"}}'`, base64OfPullSec),
@@ -218,7 +227,7 @@ func EnsurePullSecretPresentInTheCluster(ctx context.Context, ocConfig oc.Config
 	return nil
 }
 
-func EnsureGeneratedClientCAPresentInTheCluster(ctx context.Context, ocConfig oc.Config, sshRunner *ssh.Runner, selfSignedCACert *x509.Certificate, adminCert string) error {
+func EnsureGeneratedClientCAPresentInTheCluster(ctx context.Context, ocConfig oc.Config, sshRunner *ssh.Runner, kubeconfigFilePath string, selfSignedCACert *x509.Certificate, adminCert string) error {
 	selfSignedCAPem := crctls.CertToPem(selfSignedCACert)
 	if err := WaitForOpenshiftResource(ctx, ocConfig, "configmaps"); err != nil {
 		return err
@@ -244,7 +253,7 @@ func EnsureGeneratedClientCAPresentInTheCluster(ctx context.Context, ocConfig oc
 	if err != nil {
 		return fmt.Errorf("failed to patch admin-kubeconfig-client-ca config map with new CA: %s: %w", stderr, err)
 	}
-	if err := sshRunner.CopyFile(constants.KubeconfigFilePath, ocConfig.KubeconfigPath, 0644); err != nil {
+	if err := sshRunner.CopyFile(kubeconfigFilePath, ocConfig.KubeconfigPath, 0644); err != nil {
 		return fmt.Errorf("failed to copy generated kubeconfig file to VM: %w", err)
 	}
 
@@ -350,16 +359,13 @@ func EnsureClusterIDIsNotEmpty(ctx context.Context, ocConfig oc.Config) error {
 	return nil
 }
 
-func AddProxyConfigToCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, proxy *httpproxy.ProxyConfig) error {
-	type trustedCA struct {
-		Name string `json:"name"`
-	}
-
+// AddProxyConfigToCluster sets the proxies of the cluster, the proxy CA is
+// part of the trusted CA bundle set by EnsureTrustedCABundleInCluster
+func AddProxyConfigToCluster(ctx context.Context, ocConfig oc.Config, proxy *httpproxy.ProxyConfig) error {
 	type proxySpecConfig struct {
-		HTTPProxy  string    `json:"httpProxy"`
-		HTTPSProxy string    `json:"httpsProxy"`
-		NoProxy    string    `json:"noProxy"`
-		TrustedCA  trustedCA `json:"trustedCA"`
+		HTTPProxy  string `json:"httpProxy"`
+		HTTPSProxy string `json:"httpsProxy"`
+		NoProxy    string `json:"noProxy"`
 	}
 
 	type patchSpec struct {
@@ -378,15 +384,6 @@ func AddProxyConfigToCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfi
 		return err
 	}
 
-	if proxy.ProxyCACert != "" {
-		trustedCAName := "user-ca-bundle"
-		logging.Debug("Adding proxy CA cert to cluster")
-		if err := addProxyCACertToCluster(sshRunner, ocConfig, proxy, trustedCAName); err != nil {
-			return err
-		}
-		patch.Spec.TrustedCA = trustedCA{Name: trustedCAName}
-	}
-
 	patchEncode, err := json.Marshal(patch)
 	if err != nil {
 		return fmt.Errorf("failed to encode to json: %w", err)
@@ -400,34 +397,6 @@ func AddProxyConfigToCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfi
 	return nil
 }
 
-func addProxyCACertToCluster(sshRunner *ssh.Runner, ocConfig oc.Config, proxy *httpproxy.ProxyConfig, trustedCAName string) error {
-	proxyConfigMapFileName := fmt.Sprintf("/tmp/%s.json", trustedCAName)
-	proxyCABundleTemplate := `{
-  "apiVersion": "v1",
-  "data": {
-    "ca-bundle.crt": "%s"
-  },
-  "kind": "ConfigMap",
-  "metadata": {
-    "name": "%s",
-    "namespace": "openshift-config"
-  }
-}
-`
-	// Replace the carriage return ("\n" or "\r\n") with literal `\n` string
-	re := regexp.MustCompile(`\r?\n`)
-	p := fmt.Sprintf(proxyCABundleTemplate, re.ReplaceAllString(proxy.ProxyCACert, `\n`), trustedCAName)
-	err := sshRunner.CopyDataPrivileged([]byte(p), proxyConfigMapFileName, 0644)
-	if err != nil {
-		return err
-	}
-	cmdArgs := []string{"apply", "-f", proxyConfigMapFileName}
-	if _, stderr, err := ocConfig.RunOcCommandPrivate(cmdArgs...); err != nil {
-		return fmt.Errorf("failed to add proxy cert details: %s: %w", stderr, err)
-	}
-	return nil
-}
-
 type PullSecretMemoizer struct {
 	value  string
 	Getter PullSecretLoader
@@ -446,6 +415,21 @@ func (p *PullSecretMemoizer) Value() (string, error) {
 
 func WaitForPullSecretPresentOnInstanceDisk(ctx context.Context, sshRunner *ssh.Runner) error {
 	logging.Info("Waiting until the user's pull secret is written to the instance disk...")
+	return waitForPullSecretOnInstanceDisk(ctx, sshRunner, func(pullSecret string) bool {
+		return validation.ImagePullSecret(pullSecret) == nil
+	})
+}
+
+// WaitForPullSecretOnInstanceDisk waits until the machine config operator
+// writes the credentials of pullSecret to the instance disk
+func WaitForPullSecretOnInstanceDisk(ctx context.Context, sshRunner *ssh.Runner, pullSecret string) error {
+	logging.Info("Waiting until the new pull secret is written to the instance disk...")
+	return waitForPullSecretOnInstanceDisk(ctx, sshRunner, func(onDisk string) bool {
+		return SamePullSecrets(onDisk, pullSecret)
+	})
+}
+
+func waitForPullSecretOnInstanceDisk(ctx context.Context, sshRunner *ssh.Runner, updated func(string) bool) error {
 	pullSecretPresentFunc := func() error {
 		if err := sshRunner.WaitForConnectivity(ctx, 30*time.Second); err != nil {
 			return err
@@ -454,7 +438,7 @@ func WaitForPullSecretPresentOnInstanceDisk(ctx context.Context, sshRunner *ssh.
 		if err != nil {
 			return fmt.Errorf("failed to read %s file: %s: %w", vmPullSecretPath, stderr, err)
 		}
-		if err := validation.ImagePullSecret(stdout); err != nil {
+		if !updated(stdout) {
 			return &errors.RetriableError{Err: fmt.Errorf("pull secret not updated to disk")}
 		}
 		return nil
@@ -33,6 +33,7 @@ type Status struct {
 	progressing []string
 	degraded    []string
 	unavailable []string
+	total       int
 }
 
 const maxNames = 5
@@ -71,12 +72,17 @@ func joinWithLimit(names []string, maxNames int) string {
 	return ret
 }
 
+// OperatorsAvailable returns the number of available operators and the total number of operators
+func (status *Status) OperatorsAvailable() (int, int) {
+	return status.total - len(status.unavailable), status.total
+}
+
 func (status *Status) IsReady() bool {
 	return status.Available && !status.Progressing && !status.Degraded && !status.Disabled
 }
 
-func GetClusterOperatorsStatus(ctx context.Context, ip string, kubeconfigFilePath string) (*Status, error) {
-	lister, err := openshiftClient(ip, kubeconfigFilePath)
+func GetClusterOperatorsStatus(ctx context.Context, apiAddress string, kubeconfigFilePath string) (*Status, error) {
+	lister, err := openshiftClient(apiAddress, kubeconfigFilePath)
 	if err != nil {
 		return nil, err
 	}
@@ -99,6 +105,7 @@ func getStatus(ctx context.Context, lister operatorLister, selector []string) (*
 			continue
 		}
 		found = true
+		cs.total++
 		for _, con := range c.Status.Conditions {
 			switch con.Type {
 			case openshiftapi.OperatorAvailable:
@@ -143,11 +150,11 @@ func getStatus(ctx context.Context, lister operatorLister, selector []string) (*
 	return cs, nil
 }
 
-func GetClusterNodeStatus(ctx context.Context, ip string, kubeconfigFilePath string) (*Status, error) {
+func GetClusterNodeStatus(ctx context.Context, apiAddress string, kubeconfigFilePath string) (*Status, error) {
 	status := &Status{
 		Available: true,
 	}
-	clientSet, err := kubernetesClient(ip, kubeconfigFilePath)
+	clientSet, err := kubernetesClient(apiAddress, kubeconfigFilePath)
 	if err != nil {
 		return nil, err
 	}
@@ -178,31 +185,31 @@ type operatorLister interface {
 	List(ctx context.Context, opts metav1.ListOptions) (*openshiftapi.ClusterOperatorList, error)
 }
 
-func openshiftClient(ip string, kubeconfigFilePath string) (*clientset.Clientset, error) {
-	config, err := kubernetesClientConfiguration(ip, kubeconfigFilePath)
+func openshiftClient(apiAddress string, kubeconfigFilePath string) (*clientset.Clientset, error) {
+	config, err := kubernetesClientConfiguration(apiAddress, kubeconfigFilePath)
 	if err != nil {
 		return nil, err
 	}
 	return clientset.NewForConfig(config)
 }
 
-func kubernetesClient(ip string, kubeconfigFilePath string) (*k8sclient.Clientset, error) {
-	config, err := kubernetesClientConfiguration(ip, kubeconfigFilePath)
+func kubernetesClient(apiAddress string, kubeconfigFilePath string) (*k8sclient.Clientset, error) {
+	config, err := kubernetesClientConfiguration(apiAddress, kubeconfigFilePath)
 	if err != nil {
 		return nil, err
 	}
 	return k8sclient.NewForConfig(config)
 }
 
-func kubernetesClientConfiguration(ip string, kubeconfigFilePath string) (*restclient.Config, error) {
+func kubernetesClientConfiguration(apiAddress string, kubeconfigFilePath string) (*restclient.Config, error) {
 	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigFilePath)
 	if err != nil {
 		return nil, err
 	}
-	// override dial to directly use the IP of the VM
+	// override dial to directly use the address of the API server of the VM
 	config.Dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
 		var d net.Dialer
-		return d.DialContext(ctx, "tcp", fmt.Sprintf("%s:6443", ip))
+		return d.DialContext(ctx, "tcp", apiAddress)
 	}
 	// discard any proxy configuration of the host
 	config.Proxy = func(_ *http.Request) (*url.URL, error) {
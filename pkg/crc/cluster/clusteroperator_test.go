@@ -15,11 +15,13 @@ import (
 var (
 	available = &Status{
 		Available: true,
+		total:     3,
 	}
 	progressing = &Status{
 		Available:   true,
 		Progressing: true,
 		progressing: []string{"authentication"},
+		total:       3,
 	}
 )
 
@@ -27,6 +29,9 @@ func TestGetClusterOperatorsStatus(t *testing.T) {
 	status, err := getStatus(context.Background(), lister("co.json"), []string{})
 	assert.NoError(t, err)
 	assert.Equal(t, available, status)
+	availableCount, total := status.OperatorsAvailable()
+	assert.Equal(t, 3, availableCount)
+	assert.Equal(t, 3, total)
 }
 
 func TestGetClusterOperatorsStatusProgressing(t *testing.T) {
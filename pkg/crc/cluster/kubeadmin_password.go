@@ -28,8 +28,8 @@ func GenerateUserPassword(passwordFile string, user string) error {
 }
 
 // UpdateUserPasswords updates the htpasswd secret
-func UpdateUserPasswords(ctx context.Context, ocConfig oc.Config, newKubeAdminPassword string, newDeveloperPassword string) error {
-	credentials, err := resolveUserPasswords(newKubeAdminPassword, newDeveloperPassword, constants.GetKubeAdminPasswordPath(), constants.GetDeveloperPasswordPath())
+func UpdateUserPasswords(ctx context.Context, ocConfig oc.Config, machineName string, newKubeAdminPassword string, newDeveloperPassword string) error {
+	credentials, err := resolveUserPasswords(newKubeAdminPassword, newDeveloperPassword, constants.GetKubeAdminPasswordPath(machineName), constants.GetDeveloperPasswordPath(machineName))
 	if err != nil {
 		return err
 	}
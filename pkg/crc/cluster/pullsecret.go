@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
 
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
@@ -96,7 +99,7 @@ func (loader *nonInteractivePullSecretLoader) Value() (string, error) {
 	}
 	logging.Debugf("Cannot load secret from configuration: %v", err)
 
-	fromKeyring, err := loadFromKeyring()
+	fromKeyring, err := LoadFromKeyring()
 	if err == nil {
 		logging.Debugf("Using secret from keyring")
 		return fromKeyring, nil
@@ -106,7 +109,8 @@ func (loader *nonInteractivePullSecretLoader) Value() (string, error) {
 	return "", fmt.Errorf("unable to load pull secret from path %q or from configuration", loader.path)
 }
 
-func loadFromKeyring() (string, error) {
+// LoadFromKeyring returns the pull secret stored with StoreInKeyring
+func LoadFromKeyring() (string, error) {
 	pullsecret, err := keyring.Get(keyringService, keyringUser)
 	if err != nil {
 		return "", err
@@ -188,3 +192,158 @@ func promptUserForSecret() (string, error) {
 	}
 	return secret, nil
 }
+
+// PullSecretRegistry is a registry with credentials in a pull secret
+type PullSecretRegistry struct {
+	Name string
+	// Username is empty when the credentials are in a credentials store
+	Username string
+}
+
+// pullSecretAuths returns the other fields of the pull secret and its
+// credentials by registry, the entries are kept as is
+func pullSecretAuths(pullSecret string) (map[string]json.RawMessage, map[string]json.RawMessage, error) {
+	if err := validation.ImagePullSecret(pullSecret); err != nil {
+		return nil, nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(pullSecret), &fields); err != nil {
+		return nil, nil, err
+	}
+	var auths map[string]json.RawMessage
+	if err := json.Unmarshal(fields["auths"], &auths); err != nil {
+		return nil, nil, err
+	}
+	return fields, auths, nil
+}
+
+func marshalPullSecret(fields, auths map[string]json.RawMessage) (string, error) {
+	data, err := json.Marshal(auths)
+	if err != nil {
+		return "", err
+	}
+	fields["auths"] = data
+	pullSecret, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return string(pullSecret), validation.ImagePullSecret(string(pullSecret))
+}
+
+// PullSecretRegistries lists the registries of the pull secret sorted by
+// name, the tokens are not returned
+func PullSecretRegistries(pullSecret string) ([]PullSecretRegistry, error) {
+	_, auths, err := pullSecretAuths(pullSecret)
+	if err != nil {
+		return nil, err
+	}
+	var registries []PullSecretRegistry
+	for name, entry := range auths {
+		registry := PullSecretRegistry{Name: name}
+		var credentials struct {
+			Auth string `json:"auth"`
+		}
+		if err := json.Unmarshal(entry, &credentials); err == nil {
+			if decoded, err := base64.StdEncoding.DecodeString(credentials.Auth); err == nil {
+				registry.Username, _, _ = strings.Cut(string(decoded), ":")
+			}
+		}
+		registries = append(registries, registry)
+	}
+	sort.Slice(registries, func(i, j int) bool {
+		return registries[i].Name < registries[j].Name
+	})
+	return registries, nil
+}
+
+func validateRegistryName(registry string) error {
+	if registry == "" || strings.ContainsAny(registry, " \t\n") || strings.Contains(registry, "://") {
+		return fmt.Errorf("invalid registry '%s', must be a host name with an optional port and path such as quay.io/myorg", registry)
+	}
+	return nil
+}
+
+// AddPullSecretRegistry adds the credentials of registry to the pull
+// secret, they replace the existing ones of the registry
+func AddPullSecretRegistry(pullSecret, registry, username, password string) (string, error) {
+	if err := validateRegistryName(registry); err != nil {
+		return "", err
+	}
+	if username == "" || password == "" {
+		return "", fmt.Errorf("username and password of registry %s cannot be empty", registry)
+	}
+	fields, auths, err := pullSecretAuths(pullSecret)
+	if err != nil {
+		return "", err
+	}
+	entry, err := json.Marshal(map[string]string{
+		"auth": base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+	})
+	if err != nil {
+		return "", err
+	}
+	auths[registry] = entry
+	return marshalPullSecret(fields, auths)
+}
+
+// RemovePullSecretRegistry removes the credentials of registry from the pull
+// secret, the pull secret must keep the credentials of another registry
+func RemovePullSecretRegistry(pullSecret, registry string) (string, error) {
+	fields, auths, err := pullSecretAuths(pullSecret)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := auths[registry]; !ok {
+		return "", fmt.Errorf("registry %s is not in the pull secret", registry)
+	}
+	if len(auths) == 1 {
+		return "", fmt.Errorf("cannot remove %s, the only registry of the pull secret", registry)
+	}
+	delete(auths, registry)
+	return marshalPullSecret(fields, auths)
+}
+
+// MergePullSecrets adds the credentials of the imported pull secret to the
+// pull secret, they replace the existing ones of the same registries
+func MergePullSecrets(pullSecret, imported string) (string, error) {
+	_, importedAuths, err := pullSecretAuths(imported)
+	if err != nil {
+		return "", fmt.Errorf("cannot import pull secret: %w", err)
+	}
+	fields, auths, err := pullSecretAuths(pullSecret)
+	if err != nil {
+		return "", err
+	}
+	for registry, entry := range importedAuths {
+		auths[registry] = entry
+	}
+	return marshalPullSecret(fields, auths)
+}
+
+// SamePullSecrets reports whether the pull secrets have the same
+// credentials, regardless of their formatting
+func SamePullSecrets(pullSecret, other string) bool {
+	_, auths, err := pullSecretAuths(pullSecret)
+	if err != nil {
+		return false
+	}
+	_, otherAuths, err := pullSecretAuths(other)
+	if err != nil || len(auths) != len(otherAuths) {
+		return false
+	}
+	for registry, entry := range auths {
+		otherEntry, ok := otherAuths[registry]
+		if !ok || !sameJSON(entry, otherEntry) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameJSON(a, b json.RawMessage) bool {
+	var valueA, valueB interface{}
+	if json.Unmarshal(a, &valueA) != nil || json.Unmarshal(b, &valueB) != nil {
+		return false
+	}
+	return reflect.DeepEqual(valueA, valueB)
+}
@@ -55,3 +55,61 @@ func TestLoadPullSecret(t *testing.T) {
 
 	assert.Error(t, StoreInKeyring(secret4))
 }
+
+const redHatSecret = `{"auths":{"cloud.openshift.com":{"auth":"Y2xvdWQ6dG9rZW4=","email":"user@example.com"},"quay.io":{"credsStore":"secretservice"}},"other":true}` // #nosec G101
+
+func TestPullSecretRegistries(t *testing.T) {
+	registries, err := PullSecretRegistries(redHatSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, []PullSecretRegistry{
+		{Name: "cloud.openshift.com", Username: "cloud"},
+		{Name: "quay.io"},
+	}, registries)
+
+	_, err = PullSecretRegistries(secret4)
+	assert.Error(t, err)
+}
+
+func TestAddPullSecretRegistry(t *testing.T) {
+	secret, err := AddPullSecretRegistry(redHatSecret, "registry.example.com:5000", "user", "password")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"auths":{"cloud.openshift.com":{"auth":"Y2xvdWQ6dG9rZW4=","email":"user@example.com"},"quay.io":{"credsStore":"secretservice"},"registry.example.com:5000":{"auth":"dXNlcjpwYXNzd29yZA=="}},"other":true}`, secret)
+
+	secret, err = AddPullSecretRegistry(secret, "quay.io", "quay", "token")
+	assert.NoError(t, err)
+	registries, err := PullSecretRegistries(secret)
+	assert.NoError(t, err)
+	assert.Contains(t, registries, PullSecretRegistry{Name: "quay.io", Username: "quay"})
+
+	_, err = AddPullSecretRegistry(redHatSecret, "https://quay.io", "user", "password")
+	assert.EqualError(t, err, "invalid registry 'https://quay.io', must be a host name with an optional port and path such as quay.io/myorg")
+	_, err = AddPullSecretRegistry(redHatSecret, "quay.io", "user", "")
+	assert.EqualError(t, err, "username and password of registry quay.io cannot be empty")
+}
+
+func TestRemovePullSecretRegistry(t *testing.T) {
+	secret, err := RemovePullSecretRegistry(redHatSecret, "quay.io")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"auths":{"cloud.openshift.com":{"auth":"Y2xvdWQ6dG9rZW4=","email":"user@example.com"}},"other":true}`, secret)
+
+	_, err = RemovePullSecretRegistry(secret, "quay.io")
+	assert.EqualError(t, err, "registry quay.io is not in the pull secret")
+	_, err = RemovePullSecretRegistry(secret, "cloud.openshift.com")
+	assert.EqualError(t, err, "cannot remove cloud.openshift.com, the only registry of the pull secret")
+}
+
+func TestMergePullSecrets(t *testing.T) {
+	secret, err := MergePullSecrets(redHatSecret, secret1)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"auths":{"cloud.openshift.com":{"auth":"Y2xvdWQ6dG9rZW4=","email":"user@example.com"},"quay.io":{"auth":"secret1"}},"other":true}`, secret)
+
+	_, err = MergePullSecrets(redHatSecret, secret4)
+	assert.ErrorContains(t, err, "cannot import pull secret: invalid pull secret")
+}
+
+func TestSamePullSecrets(t *testing.T) {
+	assert.True(t, SamePullSecrets(secret1, `{ "auths": { "quay.io": { "auth": "secret1" } } }`))
+	assert.False(t, SamePullSecrets(secret1, secret2))
+	assert.False(t, SamePullSecrets(secret1, redHatSecret))
+	assert.False(t, SamePullSecrets(secret1, secret4))
+}
@@ -0,0 +1,168 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/oc"
+	"github.com/crc-org/crc/v2/pkg/crc/registries"
+	"github.com/crc-org/crc/v2/pkg/crc/ssh"
+)
+
+const (
+	registriesConfPath           = "/etc/containers/registries.conf.d/99-crc-registries.conf"
+	registryMirrorSetName        = "crc-registry-mirrors"
+	registryMirrorSetsFileName   = "/tmp/crc-registry-mirrors.json"
+	registrySourcesAnnotation    = "crc.dev/registry-sources"
+	registrySourcesJSONPathQuery = `jsonpath="{.metadata.annotations.crc\.dev/registry-sources}"`
+)
+
+// EnsureRegistriesConfInVM writes the registries.conf drop-in file of cfg in
+// the VM, or removes it when cfg is empty. It returns true when the file was
+// changed, cri-o must then be restarted.
+func EnsureRegistriesConfInVM(sshRunner *ssh.Runner, cfg registries.Config) (bool, error) {
+	current, _, err := sshRunner.Run(fmt.Sprintf("sudo cat %s 2>/dev/null || true", registriesConfPath))
+	if err != nil {
+		return false, err
+	}
+	desired := ""
+	if !cfg.IsEmpty() {
+		desired = cfg.RegistriesConf()
+	}
+	if strings.TrimSpace(current) == strings.TrimSpace(desired) {
+		return false, nil
+	}
+	if desired == "" {
+		logging.Info("Removing container registries configuration...")
+		if _, stderr, err := sshRunner.RunPrivileged("Removing registries configuration", "rm", "-f", registriesConfPath); err != nil {
+			return false, fmt.Errorf("failed to remove %s: %s: %w", registriesConfPath, stderr, err)
+		}
+		return true, nil
+	}
+	logging.Info("Updating container registries configuration...")
+	if err := sshRunner.CopyDataPrivileged([]byte(desired), registriesConfPath, 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", registriesConfPath, err)
+	}
+	return true, nil
+}
+
+// EnsureRegistriesConfigInCluster reconciles the ImageDigestMirrorSet and
+// ImageTagMirrorSet resources and the registry sources of the cluster image
+// configuration with cfg. The registry sources are only reset when they were
+// set by crc, so that manual changes are kept when cfg is empty.
+func EnsureRegistriesConfigInCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, cfg registries.Config) error {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "imagedigestmirrorsets"); err != nil {
+		return err
+	}
+	if err := ensureRegistryMirrorSets(sshRunner, ocConfig, cfg); err != nil {
+		return err
+	}
+	return ensureRegistrySources(ocConfig, cfg)
+}
+
+func ensureRegistryMirrorSets(sshRunner *ssh.Runner, ocConfig oc.Config, cfg registries.Config) error {
+	if len(cfg.Mirrors) == 0 {
+		if _, stderr, err := ocConfig.RunOcCommand("delete", "imagedigestmirrorset,imagetagmirrorset", registryMirrorSetName, "--ignore-not-found"); err != nil {
+			return fmt.Errorf("failed to remove registry mirrors: %s: %w", stderr, err)
+		}
+		return nil
+	}
+	manifest, err := registryMirrorSets(cfg)
+	if err != nil {
+		return err
+	}
+	if err := sshRunner.CopyDataPrivileged(manifest, registryMirrorSetsFileName, 0644); err != nil {
+		return err
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("apply", "-f", registryMirrorSetsFileName); err != nil {
+		return fmt.Errorf("failed to apply registry mirrors: %s: %w", stderr, err)
+	}
+	return nil
+}
+
+// registryMirrorSets returns the list of the ImageDigestMirrorSet and
+// ImageTagMirrorSet resources with the mirrors of cfg
+func registryMirrorSets(cfg registries.Config) ([]byte, error) {
+	type imageMirrors struct {
+		Source  string   `json:"source"`
+		Mirrors []string `json:"mirrors"`
+	}
+	sources, bySource := cfg.MirrorsBySource()
+	mirrors := make([]imageMirrors, 0, len(sources))
+	for _, source := range sources {
+		mirrors = append(mirrors, imageMirrors{Source: source, Mirrors: bySource[source]})
+	}
+	mirrorSet := func(kind, mirrorsField string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "config.openshift.io/v1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": registryMirrorSetName},
+			"spec":       map[string]interface{}{mirrorsField: mirrors},
+		}
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items": []interface{}{
+			mirrorSet("ImageDigestMirrorSet", "imageDigestMirrors"),
+			mirrorSet("ImageTagMirrorSet", "imageTagMirrors"),
+		},
+	}, "", "  ")
+}
+
+func ensureRegistrySources(ocConfig oc.Config, cfg registries.Config) error {
+	applied, stderr, err := ocConfig.RunOcCommand("get", "image.config.openshift.io", "cluster", "-o", registrySourcesJSONPathQuery)
+	if err != nil {
+		return fmt.Errorf("failed to get the cluster image configuration: %s: %w", stderr, err)
+	}
+	patch, err := registrySourcesPatch(cfg, strings.TrimSpace(applied))
+	if err != nil || patch == nil {
+		return err
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("patch", "image.config.openshift.io", "cluster", "--type", "merge", "--patch", fmt.Sprintf("'%s'", patch)); err != nil {
+		return fmt.Errorf("failed to update the registry sources of the cluster: %s: %w", stderr, err)
+	}
+	return nil
+}
+
+// registrySourcesPatch returns the merge patch of the cluster image
+// configuration applying cfg, applied is the value of the annotation
+// recording the registry sources set by crc. It returns nil when there is
+// nothing to change.
+func registrySourcesPatch(cfg registries.Config, applied string) ([]byte, error) {
+	sources := map[string]interface{}{
+		"insecureRegistries": nilIfEmpty(cfg.Insecure),
+		"blockedRegistries":  nilIfEmpty(cfg.Blocked),
+	}
+	desired, err := json.Marshal(sources)
+	if err != nil {
+		return nil, err
+	}
+	var annotation interface{} = string(desired)
+	if len(cfg.Insecure) == 0 && len(cfg.Blocked) == 0 {
+		if applied == "" {
+			return nil, nil
+		}
+		annotation = nil
+	} else if applied == string(desired) {
+		return nil, nil
+	}
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{registrySourcesAnnotation: annotation},
+		},
+		"spec": map[string]interface{}{
+			"registrySources": sources,
+		},
+	})
+}
+
+func nilIfEmpty(values []string) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
@@ -0,0 +1,71 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/crc/registries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryMirrorSets(t *testing.T) {
+	manifest, err := registryMirrorSets(registries.Config{
+		Mirrors: []registries.Mirror{
+			{Source: "quay.io", Mirror: "mirror.corp.test/quay"},
+			{Source: "docker.io", Mirror: "mirror.corp.test/docker"},
+			{Source: "docker.io", Mirror: "backup.corp.test/docker"},
+		},
+	})
+	require.NoError(t, err)
+	mirrors := `[
+		{"source": "docker.io", "mirrors": ["mirror.corp.test/docker", "backup.corp.test/docker"]},
+		{"source": "quay.io", "mirrors": ["mirror.corp.test/quay"]}
+	]`
+	assert.JSONEq(t, `{
+		"apiVersion": "v1",
+		"kind": "List",
+		"items": [
+			{
+				"apiVersion": "config.openshift.io/v1",
+				"kind": "ImageDigestMirrorSet",
+				"metadata": {"name": "crc-registry-mirrors"},
+				"spec": {"imageDigestMirrors": `+mirrors+`}
+			},
+			{
+				"apiVersion": "config.openshift.io/v1",
+				"kind": "ImageTagMirrorSet",
+				"metadata": {"name": "crc-registry-mirrors"},
+				"spec": {"imageTagMirrors": `+mirrors+`}
+			}
+		]
+	}`, string(manifest))
+}
+
+func TestRegistrySourcesPatch(t *testing.T) {
+	cfg := registries.Config{Insecure: []string{"mirror.corp.test"}}
+	patch, err := registrySourcesPatch(cfg, "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"metadata": {"annotations": {"crc.dev/registry-sources": "{\"blockedRegistries\":null,\"insecureRegistries\":[\"mirror.corp.test\"]}"}},
+		"spec": {"registrySources": {"insecureRegistries": ["mirror.corp.test"], "blockedRegistries": null}}
+	}`, string(patch))
+
+	// already applied
+	patch, err = registrySourcesPatch(cfg, `{"blockedRegistries":null,"insecureRegistries":["mirror.corp.test"]}`)
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+}
+
+func TestRegistrySourcesPatchWhenUnset(t *testing.T) {
+	// registry sources not managed by crc are kept
+	patch, err := registrySourcesPatch(registries.Config{}, "")
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+
+	patch, err = registrySourcesPatch(registries.Config{}, `{"blockedRegistries":null,"insecureRegistries":["mirror.corp.test"]}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"metadata": {"annotations": {"crc.dev/registry-sources": null}},
+		"spec": {"registrySources": {"insecureRegistries": null, "blockedRegistries": null}}
+	}`, string(patch))
+}
@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/network/httpproxy"
 )
 
 // WaitForClusterStable checks that the cluster is running a number of consecutive times
-func WaitForClusterStable(ctx context.Context, ip string, kubeconfigFilePath string, proxy *httpproxy.ProxyConfig) error {
+func WaitForClusterStable(ctx context.Context, apiAddress string, kubeconfigFilePath string, proxy *httpproxy.ProxyConfig) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
@@ -30,8 +31,15 @@ func WaitForClusterStable(ctx context.Context, ip string, kubeconfigFilePath str
 	var count int // holds num of consecutive matches
 
 	for i := 0; i < retryCount; i++ {
-		status, err := GetClusterOperatorsStatus(ctx, ip, kubeconfigFilePath)
+		status, err := GetClusterOperatorsStatus(ctx, apiAddress, kubeconfigFilePath)
 		if err == nil {
+			available, total := status.OperatorsAvailable()
+			lifecycle.Publish(lifecycle.Event{
+				Phase:   lifecycle.OperatorsProgress,
+				Message: status.String(),
+				Current: int64(available),
+				Total:   int64(total),
+			})
 			// update counter for consecutive matches
 			if status.IsReady() {
 				count++
@@ -47,6 +55,7 @@ func WaitForClusterStable(ctx context.Context, ip string, kubeconfigFilePath str
 			// break if done
 			if count == numConsecutive {
 				logging.Debugf("Cluster took %s to stabilize", time.Since(startTime))
+				lifecycle.Publish(lifecycle.Event{Phase: lifecycle.ClusterStable})
 				return nil
 			}
 		} else {
@@ -0,0 +1,115 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/oc"
+	"github.com/crc-org/crc/v2/pkg/crc/ssh"
+)
+
+const (
+	userCABundleName        = "user-ca-bundle"
+	additionalTrustedCAPath = "/etc/pki/ca-trust/source/anchors/crc-additional-trusted-ca.pem"
+	trustedCAFileName       = "/tmp/crc-user-ca-bundle.json"
+	trustedCAAnnotation     = "crc.dev/managed-trusted-ca"
+	trustedCAJSONPath       = `jsonpath="{.metadata.annotations.crc\.dev/managed-trusted-ca}"`
+)
+
+// EnsureAdditionalTrustedCAInVM adds the PEM bundle to the trust store of the
+// VM, or removes it when bundle is empty. It returns true when the trust
+// store was changed, the services using it must then be restarted.
+func EnsureAdditionalTrustedCAInVM(sshRunner *ssh.Runner, bundle string) (bool, error) {
+	current, _, err := sshRunner.Run(fmt.Sprintf("sudo cat %s 2>/dev/null || true", additionalTrustedCAPath))
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(current) == strings.TrimSpace(bundle) {
+		return false, nil
+	}
+	if bundle == "" {
+		logging.Info("Removing additional trusted CA certificates from the VM...")
+		if _, stderr, err := sshRunner.RunPrivileged("Removing additional trusted CA certificates", "rm", "-f", additionalTrustedCAPath); err != nil {
+			return false, fmt.Errorf("failed to remove %s: %s: %w", additionalTrustedCAPath, stderr, err)
+		}
+	} else {
+		logging.Info("Adding additional trusted CA certificates to the VM...")
+		if err := sshRunner.CopyDataPrivileged([]byte(bundle), additionalTrustedCAPath, 0644); err != nil {
+			return false, fmt.Errorf("failed to write %s: %w", additionalTrustedCAPath, err)
+		}
+	}
+	if _, stderr, err := sshRunner.RunPrivileged("Updating the trust store", "update-ca-trust", "extract"); err != nil {
+		return false, fmt.Errorf("failed to update the trust store: %s: %w", stderr, err)
+	}
+	return true, nil
+}
+
+// EnsureTrustedCABundleInCluster makes the trusted CA bundle of the cluster
+// the proxy CA followed by the additional trusted CAs. It is the only writer
+// of the user-ca-bundle config map, which is only removed when it was added by
+// crc, so that manual changes are kept when there is no CA to trust.
+func EnsureTrustedCABundleInCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, proxyCA, additionalCAs string) error {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "proxy"); err != nil {
+		return err
+	}
+	bundle := trustedCABundle(proxyCA, additionalCAs)
+	if bundle == "" {
+		managed, _, err := ocConfig.RunOcCommand("get", "configmap", userCABundleName, "-n", "openshift-config", "--ignore-not-found", "-o", trustedCAJSONPath)
+		if err != nil || strings.TrimSpace(managed) == "" {
+			return err
+		}
+		logging.Info("Removing trusted CA certificates from the cluster...")
+		if _, stderr, err := ocConfig.RunOcCommand("patch", "proxy", "cluster", "--type", "merge", "-p", `'{"spec":{"trustedCA":{"name":""}}}'`); err != nil {
+			return fmt.Errorf("failed to reset the trusted CA of the cluster: %s: %w", stderr, err)
+		}
+		if _, stderr, err := ocConfig.RunOcCommand("delete", "configmap", userCABundleName, "-n", "openshift-config", "--ignore-not-found"); err != nil {
+			return fmt.Errorf("failed to remove the %s config map: %s: %w", userCABundleName, stderr, err)
+		}
+		return nil
+	}
+
+	logging.Info("Adding trusted CA certificates to the cluster...")
+	manifest, err := userCABundle(bundle)
+	if err != nil {
+		return err
+	}
+	if err := sshRunner.CopyDataPrivileged(manifest, trustedCAFileName, 0644); err != nil {
+		return err
+	}
+	if _, stderr, err := ocConfig.RunOcCommandPrivate("apply", "-f", trustedCAFileName); err != nil {
+		return fmt.Errorf("failed to update the %s config map: %s: %w", userCABundleName, stderr, err)
+	}
+	patch := fmt.Sprintf(`'{"spec":{"trustedCA":{"name":%q}}}'`, userCABundleName)
+	if _, stderr, err := ocConfig.RunOcCommand("patch", "proxy", "cluster", "--type", "merge", "-p", patch); err != nil {
+		return fmt.Errorf("failed to set the trusted CA of the cluster: %s: %w", stderr, err)
+	}
+	return nil
+}
+
+// trustedCABundle concatenates the PEM bundles of the proxy CA and of the
+// additional trusted CAs
+func trustedCABundle(proxyCA, additionalCAs string) string {
+	var bundles []string
+	for _, bundle := range []string{proxyCA, additionalCAs} {
+		if bundle = strings.TrimSpace(bundle); bundle != "" {
+			bundles = append(bundles, bundle+"\n")
+		}
+	}
+	return strings.Join(bundles, "")
+}
+
+func userCABundle(bundle string) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":        userCABundleName,
+			"namespace":   "openshift-config",
+			"annotations": map[string]string{trustedCAAnnotation: "true"},
+		},
+		"data": map[string]string{"ca-bundle.crt": bundle},
+	})
+}
@@ -0,0 +1,33 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserCABundle(t *testing.T) {
+	manifest, err := userCABundle("-----BEGIN CERTIFICATE-----\n")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"apiVersion": "v1",
+		"kind": "ConfigMap",
+		"metadata": {
+			"name": "user-ca-bundle",
+			"namespace": "openshift-config",
+			"annotations": {"crc.dev/managed-trusted-ca": "true"}
+		},
+		"data": {"ca-bundle.crt": "-----BEGIN CERTIFICATE-----\n"}
+	}`, string(manifest))
+}
+
+func TestTrustedCABundle(t *testing.T) {
+	proxyCA := "-----BEGIN CERTIFICATE-----\nproxy\n-----END CERTIFICATE-----"
+	additionalCAs := "-----BEGIN CERTIFICATE-----\nadditional\n-----END CERTIFICATE-----\n"
+
+	assert.Empty(t, trustedCABundle("", ""))
+	assert.Equal(t, proxyCA+"\n", trustedCABundle(proxyCA, ""))
+	assert.Equal(t, additionalCAs, trustedCABundle("", additionalCAs))
+	assert.Equal(t, proxyCA+"\n"+additionalCAs, trustedCABundle(proxyCA, additionalCAs))
+}
@@ -15,6 +15,17 @@ func RequiresRestartMsg(key string, _ interface{}) string {
 		"stop the CRC instance with 'crc stop' and restart it with 'crc start'.", key)
 }
 
+func RequiresDaemonRestartMsg(key string, _ interface{}) string {
+	return fmt.Sprintf("Changes to configuration property '%s' are only applied when the CRC daemon is started.\n"+
+		"If the daemon is already running, restart it for this configuration change to take effect.", key)
+}
+
+func RequiresDaemonAndInstanceRestartMsg(key string, _ interface{}) string {
+	return fmt.Sprintf("Changes to configuration property '%s' are only applied when the CRC daemon and the CRC instance are started.\n"+
+		"If they are already running, then for this configuration change to take effect, "+
+		"stop the CRC instance with 'crc stop', restart the daemon and restart the instance with 'crc start'.", key)
+}
+
 func RequiresDeleteMsg(key string, _ interface{}) string {
 	return fmt.Sprintf("Changes to configuration property '%s' are only applied when the CRC instance is created.\n"+
 		"If you already have a running CRC instance, then for this configuration change to take effect, "+
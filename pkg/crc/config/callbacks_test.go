@@ -10,3 +10,10 @@ func TestSuccessfullyApplied(t *testing.T) {
 	assert.Equal(t, "Successfully configured http-proxy to http://proxy", SuccessfullyApplied("http-proxy", "http://proxy"))
 	assert.Equal(t, "Successfully configured enable-experimental-features to true", SuccessfullyApplied("enable-experimental-features", true))
 }
+
+func TestRequiresDaemonAndInstanceRestartMsg(t *testing.T) {
+	assert.Equal(t, "Changes to configuration property 'shared-dirs' are only applied when the CRC daemon and the CRC instance are started.\n"+
+		"If they are already running, then for this configuration change to take effect, "+
+		"stop the CRC instance with 'crc stop', restart the daemon and restart the instance with 'crc start'.",
+		RequiresDaemonAndInstanceRestartMsg("shared-dirs", "/src:/mnt/src"))
+}
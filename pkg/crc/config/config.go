@@ -13,6 +13,8 @@ const (
 	configPropDoesntExistMsg = "Configuration property '%s' does not exist"
 	invalidProp              = "Value '%v' for configuration property '%s' is invalid, reason: %s"
 	invalidType              = "Type %T for configuration property '%s' is invalid"
+	lockedProp               = "Configuration property '%s' is locked by the system configuration and cannot be changed"
+	shadowedProp             = "Warning: configuration property '%s' is overridden by its %s value, this change is not applied"
 )
 
 type ValueChangedFunc func(config *Config, key string, value interface{})
@@ -82,6 +84,9 @@ func (c *Config) Set(key string, value interface{}) (string, error) {
 	if !ok {
 		return "", fmt.Errorf(configPropDoesntExistMsg, key)
 	}
+	if err := c.checkNotLocked(setting); err != nil {
+		return "", err
+	}
 
 	if err := c.validate(key, value); err != nil {
 		return "", err
@@ -129,7 +134,7 @@ func (c *Config) Set(key string, value interface{}) (string, error) {
 		if _, err := c.Unset(key); err != nil {
 			return "", err
 		}
-		return c.settingsByName[key].callbackFn(key, castValue), nil
+		return c.withShadowedWarning(key, c.settingsByName[key].callbackFn(key, castValue)), nil
 	}
 
 	if setting.isSecret {
@@ -144,7 +149,7 @@ func (c *Config) Set(key string, value interface{}) (string, error) {
 
 	c.valueChangeNotify(key, value)
 
-	return c.settingsByName[key].callbackFn(key, castValue), nil
+	return c.withShadowedWarning(key, c.settingsByName[key].callbackFn(key, castValue)), nil
 }
 
 // Unset unsets a given config key
@@ -153,6 +158,9 @@ func (c *Config) Unset(key string) (string, error) {
 	if !ok {
 		return "", fmt.Errorf(configPropDoesntExistMsg, key)
 	}
+	if err := c.checkNotLocked(setting); err != nil {
+		return "", err
+	}
 	if setting.isSecret {
 		if err := c.secretStorage.Unset(key); err != nil {
 			return "", err
@@ -165,7 +173,66 @@ func (c *Config) Unset(key string) (string, error) {
 
 	c.valueChangeNotify(key, nil)
 
-	return fmt.Sprintf("Successfully unset configuration property '%s'", key), nil
+	return c.withShadowedWarning(key, fmt.Sprintf("Successfully unset configuration property '%s'", key)), nil
+}
+
+// checkNotLocked fails when the setting is locked, or when the layers cannot
+// be read to know if it is locked
+func (c *Config) checkNotLocked(setting Setting) error {
+	layered, ok := c.storage.(LayeredStorage)
+	if !ok || setting.isSecret {
+		return nil
+	}
+	locked, err := layered.IsLocked(setting.Name)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return fmt.Errorf(lockedProp, setting.Name)
+	}
+	return nil
+}
+
+// withShadowedWarning adds a warning to the message of Set and Unset when the
+// value of the user configuration file is overridden by a higher layer
+func (c *Config) withShadowedWarning(key string, message string) string {
+	source, err := c.Source(key)
+	if err != nil {
+		return message
+	}
+	switch source {
+	case SourceProject, SourceEnv, SourceFlag:
+		warning := fmt.Sprintf(shadowedProp, key, source)
+		if message == "" {
+			return warning
+		}
+		return message + "\n" + warning
+	default:
+		return message
+	}
+}
+
+// Source returns the configuration layer the value of key comes from, the
+// values of a storage without layers come from the user
+func (c *Config) Source(key string) (Source, error) {
+	setting, ok := c.settingsByName[key]
+	if !ok {
+		return SourceDefault, fmt.Errorf(configPropDoesntExistMsg, key)
+	}
+	if setting.isSecret {
+		if c.secretStorage.Get(key) == nil {
+			return SourceDefault, nil
+		}
+		return SourceUser, nil
+	}
+	if layered, ok := c.storage.(LayeredStorage); ok {
+		_, source, err := layered.Lookup(key)
+		return source, err
+	}
+	if c.storage.Get(key) == nil {
+		return SourceDefault, nil
+	}
+	return SourceUser, nil
 }
 
 func (c *Config) RegisterNotifier(key string, changeNotifier ValueChangedFunc) error {
@@ -197,6 +264,15 @@ func (c *Config) Get(key string) SettingValue {
 	var value interface{}
 	if setting.isSecret {
 		value = c.secretStorage.Get(key)
+	} else if layered, ok := c.storage.(LayeredStorage); ok {
+		var err error
+		// an unreadable layer may hide locked values, do not fall back to
+		// the default value
+		if value, _, err = layered.Lookup(key); err != nil {
+			return SettingValue{
+				Invalid: true,
+			}
+		}
 	} else {
 		value = c.storage.Get(key)
 	}
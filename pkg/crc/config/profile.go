@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ProfileVersion is the only version of the start profile format
+const ProfileVersion = 1
+
+const profileVersionKey = "version"
+
+// profilePasswordFiles maps the profile keys which read a password from a
+// file to the setting they fill
+var profilePasswordFiles = map[string]string{
+	"kubeadmin-password-file":  KubeAdminPassword,
+	"developer-password-file":  DeveloperPassword,
+	"shared-dir-password-file": SharedDirPassword,
+}
+
+// profilePathSettings are the settings holding a local path, relative paths
+// in a profile file are resolved against the directory of the file
+var profilePathSettings = []string{Bundle, PullSecretFile, ProxyCAFile, ProvisioningDir}
+
+// Profile is a declarative set of settings used for a single `crc start`
+// instead of the values stored in the configuration file
+type Profile struct {
+	Settings map[string]interface{}
+}
+
+// LoadProfile reads a YAML or JSON start profile from path
+func LoadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := ParseProfile(data, filepath.Dir(absPath))
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile %s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// IsProfile returns true when data is a YAML or JSON document with a
+// top-level version key, as opposed to a legacy start request
+func IsProfile(data []byte) bool {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return false
+	}
+	_, ok := raw[profileVersionKey]
+	return ok
+}
+
+// ParseProfile parses a YAML or JSON start profile. Relative paths are
+// resolved against baseDir when it is not empty.
+func ParseProfile(data []byte, baseDir string) (*Profile, error) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	version, ok := raw[profileVersionKey]
+	if !ok {
+		return nil, fmt.Errorf("missing '%s' key", profileVersionKey)
+	}
+	if fmt.Sprint(version) != fmt.Sprint(ProfileVersion) {
+		return nil, fmt.Errorf("unsupported profile version %v, only version %d is supported", version, ProfileVersion)
+	}
+	delete(raw, profileVersionKey)
+
+	profile := &Profile{Settings: map[string]interface{}{}}
+	for key, value := range raw {
+		if setting, ok := profilePasswordFiles[key]; ok {
+			password, err := os.ReadFile(resolveProfilePath(baseDir, fmt.Sprint(value)))
+			if err != nil {
+				return nil, fmt.Errorf("cannot read %s: %w", key, err)
+			}
+			profile.Settings[setting] = strings.TrimRight(string(password), "\r\n")
+			continue
+		}
+		if slices.Contains(profilePathSettings, key) {
+			value = resolveProfilePath(baseDir, fmt.Sprint(value))
+		}
+		profile.Settings[key] = value
+	}
+	return profile, nil
+}
+
+func resolveProfilePath(baseDir, path string) string {
+	if baseDir == "" || path == "" || strings.Contains(path, "://") || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// Without removes the given settings from the profile, this is used to give
+// precedence to command line flags
+func (p *Profile) Without(keys ...string) *Profile {
+	settings := maps.Clone(p.Settings)
+	for _, key := range keys {
+		delete(settings, key)
+	}
+	return &Profile{Settings: settings}
+}
+
+// ApplyProfile returns a configuration which reads the profile settings
+// before falling back to the values of cfg. cfg itself is not modified and
+// the profile settings are never persisted. The settings which are not
+// registered by RegisterSettings, such as the preflight ones, are added with
+// registerSettings.
+func ApplyProfile(cfg *Config, profile *Profile, registerSettings ...func(Schema)) (*Config, error) {
+	overlay := New(
+		&overlayStorage{values: profile.Settings, parent: cfg.storage},
+		&overlayStorage{values: profile.Settings, parent: cfg.secretStorage},
+	)
+	// preset-dependent defaults and validations must use the preset of the profile
+	RegisterSettings(overlay)
+	for _, register := range registerSettings {
+		register(overlay)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(profile.Settings)) {
+		if _, ok := overlay.settingsByName[key]; !ok {
+			return nil, fmt.Errorf(configPropDoesntExistMsg, key)
+		}
+		if err := overlay.validate(key, profile.Settings[key]); err != nil {
+			return nil, err
+		}
+	}
+	return overlay, nil
+}
+
+// overlayStorage returns values from a fixed set of settings, and defers to
+// its parent for the other settings and for all modifications
+type overlayStorage struct {
+	values map[string]interface{}
+	parent RawStorage
+}
+
+func (s *overlayStorage) Get(key string) interface{} {
+	if value, ok := s.values[key]; ok {
+		return value
+	}
+	return s.parent.Get(key)
+}
+
+func (s *overlayStorage) Set(key string, value interface{}) error {
+	return s.parent.Set(key, value)
+}
+
+func (s *overlayStorage) Unset(key string) error {
+	return s.parent.Unset(key)
+}
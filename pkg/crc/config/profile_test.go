@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/crc/preset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kubeadmin-password"), []byte("secret\n"), 0600))
+	proxyCAFile := filepath.Join(t.TempDir(), "proxy.pem")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crc.yaml"), []byte(`version: 1
+preset: microshift
+cpus: 2
+pull-secret-file: pull-secret.json
+proxy-ca-file: `+proxyCAFile+`
+bundle: docker://quay.io/crcont/microshift-bundle:latest
+kubeadmin-password-file: kubeadmin-password
+`), 0600))
+
+	profile, err := LoadProfile(filepath.Join(dir, "crc.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		Preset:            "microshift",
+		CPUs:              float64(2),
+		PullSecretFile:    filepath.Join(dir, "pull-secret.json"),
+		ProxyCAFile:       proxyCAFile,
+		Bundle:            "docker://quay.io/crcont/microshift-bundle:latest",
+		KubeAdminPassword: "secret",
+	}, profile.Settings)
+}
+
+func TestParseProfileVersion(t *testing.T) {
+	_, err := ParseProfile([]byte(`{"cpus": 4}`), "")
+	assert.EqualError(t, err, "missing 'version' key")
+
+	_, err = ParseProfile([]byte(`{"version": 2, "cpus": 4}`), "")
+	assert.EqualError(t, err, "unsupported profile version 2, only version 1 is supported")
+
+	profile, err := ParseProfile([]byte(`{"version": 1, "cpus": 4}`), "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{CPUs: float64(4)}, profile.Settings)
+}
+
+func TestIsProfile(t *testing.T) {
+	assert.True(t, IsProfile([]byte("version: 1\n")))
+	assert.True(t, IsProfile([]byte(`{"version": 1}`)))
+	assert.False(t, IsProfile([]byte(`{"pullSecretFile": "/tmp/pull-secret"}`)))
+	assert.False(t, IsProfile([]byte("not a profile")))
+}
+
+func TestApplyProfile(t *testing.T) {
+	cfg, err := newInMemoryConfig()
+	require.NoError(t, err)
+	_, err = cfg.Set(IngressHTTPPort, 8080)
+	require.NoError(t, err)
+
+	// 2 CPUs are only valid with the microshift preset, which comes from the profile
+	profileConfig, err := ApplyProfile(cfg, &Profile{Settings: map[string]interface{}{
+		Preset: "microshift",
+		CPUs:   float64(2),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, preset.Microshift, GetPreset(profileConfig))
+	assert.Equal(t, uint(2), profileConfig.Get(CPUs).AsUInt())
+	assert.Equal(t, uint(8080), profileConfig.Get(IngressHTTPPort).AsUInt())
+	assert.Equal(t, defaultMemory(profileConfig), profileConfig.Get(Memory).AsUInt())
+
+	// the base configuration is untouched
+	assert.Equal(t, preset.OpenShift, GetPreset(cfg))
+	assert.True(t, cfg.Get(CPUs).IsDefault)
+
+	_, err = ApplyProfile(cfg, &Profile{Settings: map[string]interface{}{CPUs: float64(2)}})
+	assert.EqualError(t, err, "Value '2' for configuration property 'cpus' is invalid, reason: requires CPUs >= 4")
+
+	_, err = ApplyProfile(cfg, &Profile{Settings: map[string]interface{}{"foo": "bar"}})
+	assert.EqualError(t, err, "Configuration property 'foo' does not exist")
+
+	registerFoo := func(schema Schema) {
+		schema.AddSetting("foo", false, ValidateBool, SuccessfullyApplied, "")
+	}
+	profileConfig, err = ApplyProfile(cfg, &Profile{Settings: map[string]interface{}{"foo": true}}, registerFoo)
+	require.NoError(t, err)
+	assert.True(t, profileConfig.Get("foo").AsBool())
+}
+
+func TestProfileWithout(t *testing.T) {
+	profile := &Profile{Settings: map[string]interface{}{CPUs: 4, Memory: 10752}}
+	assert.Equal(t, map[string]interface{}{Memory: 10752}, profile.Without(CPUs).Settings)
+	assert.Len(t, profile.Settings, 2)
+}
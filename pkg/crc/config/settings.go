@@ -2,12 +2,18 @@ package config
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
 
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/network"
 	"github.com/crc-org/crc/v2/pkg/crc/preset"
+	"github.com/crc-org/crc/v2/pkg/crc/registries"
+	"github.com/crc-org/crc/v2/pkg/crc/shareddirs"
 	"github.com/crc-org/crc/v2/pkg/crc/version"
+	"github.com/spf13/cast"
 )
 
 const (
@@ -33,14 +39,36 @@ const (
 	Preset                   = "preset"
 	EnableSharedDirs         = "enable-shared-dirs"
 	SharedDirPassword        = "shared-dir-password" // #nosec G101
+	SharedDirs               = "shared-dirs"
 	IngressHTTPPort          = "ingress-http-port"
 	IngressHTTPSPort         = "ingress-https-port"
 	EmergencyLogin           = "enable-emergency-login"
 	PersistentVolumeSize     = "persistent-volume-size"
 	EnableBundleQuayFallback = "enable-bundle-quay-fallback"
+	BundleMirrors            = "bundle-mirrors"
+	ProvisioningDir          = "provisioning-dir"
+	PortForwards             = "port-forwards"
+	DNSRecords               = "dns-records"
+	RemoteAPIAddress         = "remote-api-address"
+	RegistryMirrors          = "registry-mirrors"
+	InsecureRegistries       = "insecure-registries"
+	BlockedRegistries        = "blocked-registries"
+	AdditionalTrustedCAFiles = "additional-trusted-ca-files"
+	TrustedBundleKeys        = "trusted-bundle-keys"
+	EnforceBundleSignature   = "enforce-bundle-signature"
 )
 
 func RegisterSettings(cfg *Config) {
+	addSettings(cfg)
+
+	if err := cfg.RegisterNotifier(Preset, presetChanged); err != nil {
+		logging.Debugf("Failed to register notifier for Preset: %v", err)
+	}
+}
+
+// addSettings adds the settings with the defaults of the current preset, it
+// is called again when the preset changes
+func addSettings(cfg *Config) {
 	validateHostNetworkAccess := func(value interface{}) (bool, string) {
 		mode := GetNetworkMode(cfg)
 		if mode != network.UserNetworkingMode {
@@ -50,6 +78,44 @@ func RegisterSettings(cfg *Config) {
 		return ValidateBool(value)
 	}
 
+	validatePortForwards := func(value interface{}) (bool, string) {
+		if cast.ToString(value) == "" {
+			return true, ""
+		}
+		if mode := GetNetworkMode(cfg); mode != network.UserNetworkingMode {
+			return false, fmt.Sprintf("%s can only be used with %s set to '%s'",
+				PortForwards, NetworkMode, network.UserNetworkingMode)
+		}
+		return validatePortForwardsValue(value, reservedHostPorts(cfg))
+	}
+
+	validateDNSRecords := func(value interface{}) (bool, string) {
+		if cast.ToString(value) == "" {
+			return true, ""
+		}
+		if mode := GetNetworkMode(cfg); mode != network.UserNetworkingMode {
+			return false, fmt.Sprintf("%s can only be used with %s set to '%s'",
+				DNSRecords, NetworkMode, network.UserNetworkingMode)
+		}
+		if _, err := network.ParseDNSRecords(cast.ToString(value)); err != nil {
+			return false, err.Error()
+		}
+		return true, ""
+	}
+
+	validateIngressPort := func(value interface{}) (bool, string) {
+		if ok, msg := validatePort(value); !ok {
+			return false, msg
+		}
+		port := cast.ToUint(value)
+		for _, forward := range GetPortForwards(cfg) {
+			if forward.Protocol == types.TCP && uint(forward.HostPort) == port {
+				return false, fmt.Sprintf("port %d is already used by the port forward %s", port, forward)
+			}
+		}
+		return true, ""
+	}
+
 	validCPUs := func(value interface{}) (bool, string) {
 		return validateCPUs(value, GetPreset(cfg))
 	}
@@ -87,6 +153,10 @@ func RegisterSettings(cfg *Config) {
 		fmt.Sprintf("Total size in GiB of the persistent volume used by the CSI driver for %s preset (must be greater than or equal to '%d')", preset.Microshift, constants.DefaultPersistentVolumeSize))
 	cfg.AddSetting(EnableSharedDirs, true, ValidateBool, SuccessfullyApplied,
 		"Mounts the host's home directory into the CRC VM (true/false, default: true)")
+	cfg.AddSetting(SharedDirs, "", validateSharedDirs, RequiresDaemonAndInstanceRestartMsg,
+		"Host directories mounted into the CRC VM instead of the home directory when enable-shared-dirs is true (string, comma-separated list of HOST:GUEST[:ro] entries such as '/home/user/src:/mnt/src:ro')")
+	cfg.AddSetting(ProvisioningDir, Path(""), validatePath, SuccessfullyApplied,
+		"Directory with the manifests/, hooks/vm/ and hooks/host/ subdirectories applied to the cluster after it starts")
 
 	if !version.IsInstaller() {
 		cfg.AddSetting(NetworkMode, string(defaultNetworkMode()), network.ValidateMode, network.SuccessfullyAppliedMode,
@@ -95,6 +165,19 @@ func RegisterSettings(cfg *Config) {
 
 	cfg.AddSetting(HostNetworkAccess, false, validateHostNetworkAccess, RequiresCleanupAndSetupMsg,
 		"Allow TCP/IP connections from the CRC VM to services running on the host (true/false, default: false)")
+	cfg.AddSetting(PortForwards, "", validatePortForwards, SuccessfullyApplied,
+		"Additional host ports forwarded to the CRC VM with user network mode (string, comma-separated list such as '8080:80,5353:192.168.127.2:53/udp')")
+	cfg.AddSetting(DNSRecords, "", validateDNSRecords, SuccessfullyApplied,
+		"Additional DNS records of the user network mode (string, comma-separated list such as 'registry.corp.test=192.168.127.254,*.apps.corp.test=192.168.127.2')")
+	cfg.AddSetting(RemoteAPIAddress, "", validateRemoteAPIAddress, RequiresDaemonRestartMsg,
+		"Address the daemon listens on for authenticated remote API access over TLS (string, like '0.0.0.0:8443', disabled when empty)")
+	// Container registries configuration
+	cfg.AddSetting(RegistryMirrors, "", validateRegistryMirrors, RequiresRestartMsg,
+		"Mirrors of the container registries (string, comma-separated list such as 'docker.io=mirror.corp.test/docker,quay.io=mirror.corp.test/quay')")
+	cfg.AddSetting(InsecureRegistries, "", validateRegistries, RequiresRestartMsg,
+		"Container registries accessed without TLS verification (string, comma-separated list such as 'registry.corp.test:5000,*.corp.test')")
+	cfg.AddSetting(BlockedRegistries, "", validateRegistries, RequiresRestartMsg,
+		"Container registries images cannot be pulled from (string, comma-separated list such as 'docker.io')")
 	// Proxy Configuration
 	cfg.AddSetting(HTTPProxy, "", validateHTTPProxy, SuccessfullyApplied,
 		"HTTP proxy URL (string, like 'http://my-proxy.com:8443')")
@@ -104,6 +187,8 @@ func RegisterSettings(cfg *Config) {
 		"Hosts, ipv4 addresses or CIDR which do not use a proxy (string, comma-separated list such as '127.0.0.1,192.168.100.1/24')")
 	cfg.AddSetting(ProxyCAFile, Path(""), validatePath, SuccessfullyApplied,
 		"Path to an HTTPS proxy certificate authority (CA)")
+	cfg.AddSetting(AdditionalTrustedCAFiles, "", validateTrustedCAFiles, RequiresRestartMsg,
+		"Certificate authorities trusted by the VM and the cluster (string, comma-separated list of PEM files or of directories of .pem/.crt files)")
 
 	cfg.AddSetting(EnableClusterMonitoring, false, ValidateBool, SuccessfullyApplied,
 		"Enable cluster monitoring Operator (true/false, default: false)")
@@ -119,17 +204,19 @@ func RegisterSettings(cfg *Config) {
 		"User defined kubeadmin password")
 	cfg.AddSetting(DeveloperPassword, constants.DefaultDeveloperPassword, validateString, SuccessfullyApplied,
 		"User defined developer password")
-	cfg.AddSetting(IngressHTTPPort, constants.OpenShiftIngressHTTPPort, validatePort, RequiresHTTPPortChangeWarning,
+	cfg.AddSetting(IngressHTTPPort, constants.OpenShiftIngressHTTPPort, validateIngressPort, RequiresHTTPPortChangeWarning,
 		fmt.Sprintf("HTTP port to use for OpenShift ingress/routes on the host (1024-65535, default: %d)", constants.OpenShiftIngressHTTPPort))
-	cfg.AddSetting(IngressHTTPSPort, constants.OpenShiftIngressHTTPSPort, validatePort, RequiresHTTPSPortChangeWarning,
+	cfg.AddSetting(IngressHTTPSPort, constants.OpenShiftIngressHTTPSPort, validateIngressPort, RequiresHTTPSPortChangeWarning,
 		fmt.Sprintf("HTTPS port to use for OpenShift ingress/routes on the host (1024-65535, default: %d)", constants.OpenShiftIngressHTTPSPort))
 
 	cfg.AddSetting(EnableBundleQuayFallback, false, ValidateBool, SuccessfullyApplied,
 		"If bundle download from the default location fails, fallback to quay.io (true/false, default: false)")
-
-	if err := cfg.RegisterNotifier(Preset, presetChanged); err != nil {
-		logging.Debugf("Failed to register notifier for Preset: %v", err)
-	}
+	cfg.AddSetting(BundleMirrors, "", validateBundleMirrors, SuccessfullyApplied,
+		"Mirrors to download the default bundle from before falling back to mirror.openshift.com (string, comma-separated list of http(s) URLs)")
+	cfg.AddSetting(TrustedBundleKeys, "", validateTrustedBundleKeys, SuccessfullyApplied,
+		"Public keys trusted to sign the custom bundles (string, comma-separated list of armored OpenPGP public key files)")
+	cfg.AddSetting(EnforceBundleSignature, false, ValidateBool, SuccessfullyApplied,
+		"Refuse the custom bundles which are not signed with one of the trusted-bundle-keys, and the default bundles which do not match the sha256sums of the release (true/false, default: false)")
 }
 
 func presetChanged(cfg *Config, _ string, _ interface{}) {
@@ -152,6 +239,138 @@ func GetPreset(config Storage) preset.Preset {
 	return preset.ParsePreset(config.Get(Preset).AsString())
 }
 
+// GetBundleMirrors returns the list of mirrors set in the bundle-mirrors setting
+func GetBundleMirrors(config Storage) []string {
+	var mirrors []string
+	for _, mirror := range strings.Split(config.Get(BundleMirrors).AsString(), ",") {
+		if mirror = strings.TrimSpace(mirror); mirror != "" {
+			mirrors = append(mirrors, mirror)
+		}
+	}
+	return mirrors
+}
+
+// GetAdditionalTrustedCAFiles returns the paths set in the
+// additional-trusted-ca-files setting
+func GetAdditionalTrustedCAFiles(config Storage) []string {
+	var paths []string
+	for _, path := range strings.Split(config.Get(AdditionalTrustedCAFiles).AsString(), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// GetTrustedBundleKeys returns the paths set in the trusted-bundle-keys
+// setting
+func GetTrustedBundleKeys(config Storage) []string {
+	var paths []string
+	for _, path := range strings.Split(config.Get(TrustedBundleKeys).AsString(), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// GetRegistriesConfig returns the configuration set in the registry-mirrors,
+// insecure-registries and blocked-registries settings
+func GetRegistriesConfig(config Storage) registries.Config {
+	var cfg registries.Config
+	var err error
+	if cfg.Mirrors, err = registries.ParseMirrors(config.Get(RegistryMirrors).AsString()); err != nil {
+		logging.Debugf("Ignoring invalid %s value: %v", RegistryMirrors, err)
+	}
+	if cfg.Insecure, err = registries.ParseRegistries(config.Get(InsecureRegistries).AsString()); err != nil {
+		logging.Debugf("Ignoring invalid %s value: %v", InsecureRegistries, err)
+	}
+	if cfg.Blocked, err = registries.ParseRegistries(config.Get(BlockedRegistries).AsString()); err != nil {
+		logging.Debugf("Ignoring invalid %s value: %v", BlockedRegistries, err)
+	}
+	return cfg
+}
+
+// GetSharedDirs returns the directories set in the shared-dirs setting
+func GetSharedDirs(config Storage) []shareddirs.SharedDir {
+	dirs, err := shareddirs.ParseList(config.Get(SharedDirs).AsString())
+	if err != nil {
+		logging.Debugf("Ignoring invalid %s value: %v", SharedDirs, err)
+		return nil
+	}
+	return dirs
+}
+
+// GetPortForwards returns the port forwards set in the port-forwards setting
+func GetPortForwards(config Storage) []network.PortForward {
+	forwards, err := network.ParsePortForwards(config.Get(PortForwards).AsString())
+	if err != nil {
+		logging.Debugf("Ignoring invalid %s value: %v", PortForwards, err)
+		return nil
+	}
+	return forwards
+}
+
+// GetDNSRecords returns the DNS records set in the dns-records setting
+func GetDNSRecords(config Storage) []network.DNSRecord {
+	records, err := network.ParseDNSRecords(config.Get(DNSRecords).AsString())
+	if err != nil {
+		logging.Debugf("Ignoring invalid %s value: %v", DNSRecords, err)
+		return nil
+	}
+	return records
+}
+
+// SetDNSRecord adds record to the dns-records setting, replacing the record
+// of the same hostname if there is one
+func SetDNSRecord(cfg *Config, record network.DNSRecord) error {
+	records := []network.DNSRecord{}
+	for _, existing := range GetDNSRecords(cfg) {
+		if existing.Hostname != record.Hostname {
+			records = append(records, existing)
+		}
+	}
+	_, err := cfg.Set(DNSRecords, network.FormatDNSRecords(append(records, record)))
+	return err
+}
+
+// RemoveDNSRecord removes the record of hostname from the dns-records setting
+func RemoveDNSRecord(cfg *Config, hostname string) (network.DNSRecord, error) {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	var (
+		removed *network.DNSRecord
+		kept    []network.DNSRecord
+	)
+	for _, record := range GetDNSRecords(cfg) {
+		if record.Hostname == hostname {
+			removed = &record
+			continue
+		}
+		kept = append(kept, record)
+	}
+	if removed == nil {
+		return network.DNSRecord{}, fmt.Errorf("no DNS record for %s", hostname)
+	}
+	var err error
+	if len(kept) == 0 {
+		_, err = cfg.Unset(DNSRecords)
+	} else {
+		_, err = cfg.Set(DNSRecords, network.FormatDNSRecords(kept))
+	}
+	return *removed, err
+}
+
+// reservedHostPorts are the TCP ports of the host already forwarded to the VM
+// by crc, additional port forwards cannot use them
+func reservedHostPorts(config Storage) map[uint]string {
+	return map[uint]string{
+		config.Get(IngressHTTPPort).AsUInt():  IngressHTTPPort,
+		config.Get(IngressHTTPSPort).AsUInt(): IngressHTTPSPort,
+		constants.OpenShiftAPIPort:            "OpenShift API",
+		constants.VsockSSHPort:                "SSH",
+	}
+}
+
 func defaultNetworkMode() network.Mode {
 	return network.UserNetworkingMode
 }
@@ -176,7 +395,7 @@ func revalidateSettingsValue(cfg *Config, key string) error {
 }
 
 func UpdateDefaults(cfg *Config) {
-	RegisterSettings(cfg)
+	addSettings(cfg)
 
 	// The `memory` and `cpus` values are preset-dependent.
 	// When they are lower than the preset requirements, this code
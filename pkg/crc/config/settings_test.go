@@ -2,13 +2,18 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"go.podman.io/common/pkg/strongunits"
 
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 	crcpreset "github.com/crc-org/crc/v2/pkg/crc/preset"
+	"github.com/crc-org/crc/v2/pkg/crc/registries"
+	"github.com/crc-org/crc/v2/pkg/crc/shareddirs"
+	crctls "github.com/crc-org/crc/v2/pkg/crc/tls"
 	"github.com/crc-org/crc/v2/pkg/crc/version"
 
 	"github.com/spf13/cast"
@@ -266,6 +271,42 @@ var configDefaultValuesTestArguments = []struct {
 	{
 		EnableBundleQuayFallback, false,
 	},
+	{
+		BundleMirrors, "",
+	},
+	{
+		ProvisioningDir, Path(""),
+	},
+	{
+		PortForwards, "",
+	},
+	{
+		DNSRecords, "",
+	},
+	{
+		RemoteAPIAddress, "",
+	},
+	{
+		RegistryMirrors, "",
+	},
+	{
+		InsecureRegistries, "",
+	},
+	{
+		BlockedRegistries, "",
+	},
+	{
+		AdditionalTrustedCAFiles, "",
+	},
+	{
+		TrustedBundleKeys, "",
+	},
+	{
+		EnforceBundleSignature, false,
+	},
+	{
+		SharedDirs, "",
+	},
 	{
 		Preset, "openshift",
 	},
@@ -349,6 +390,27 @@ var configProvidedValuesTestArguments = []struct {
 	{
 		EnableBundleQuayFallback, true,
 	},
+	{
+		BundleMirrors, "https://mirror.example.com/crc/bundles,http://10.0.0.1/bundles",
+	},
+	{
+		PortForwards, "8080:192.168.127.2:80/tcp,5353:192.168.127.2:53/udp",
+	},
+	{
+		DNSRecords, "registry.corp.test=192.168.127.254,*.apps.corp.test=192.168.127.2",
+	},
+	{
+		RemoteAPIAddress, "0.0.0.0:8443",
+	},
+	{
+		RegistryMirrors, "docker.io=mirror.corp.test/docker",
+	},
+	{
+		InsecureRegistries, "mirror.corp.test,*.corp.test",
+	},
+	{
+		BlockedRegistries, "registry.untrusted.test",
+	},
 	{
 		Preset, "microshift",
 	},
@@ -377,3 +439,171 @@ func TestSetProvidedValuesOverrideDefaultValuesInConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestBundleMirrors(t *testing.T) {
+	cfg, err := newInMemoryConfig()
+	require.NoError(t, err)
+	assert.Empty(t, GetBundleMirrors(cfg))
+
+	_, err = cfg.Set(BundleMirrors, "https://mirror.example.com/crc/bundles, http://10.0.0.1/bundles,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://mirror.example.com/crc/bundles", "http://10.0.0.1/bundles"}, GetBundleMirrors(cfg))
+
+	_, err = cfg.Set(BundleMirrors, "https://mirror.example.com,ftp://mirror.example.com")
+	assert.EqualError(t, err, "Value 'https://mirror.example.com,ftp://mirror.example.com' for configuration property 'bundle-mirrors' is invalid, reason: ftp://mirror.example.com is not a valid http or https URL")
+}
+
+func TestPortForwards(t *testing.T) {
+	cfg, err := newInMemoryConfig()
+	require.NoError(t, err)
+	assert.Empty(t, GetPortForwards(cfg))
+
+	_, err = cfg.Set(PortForwards, "8080:80,5353:192.168.127.3:53/udp")
+	require.NoError(t, err)
+	assert.Equal(t, []network.PortForward{
+		{Protocol: "tcp", HostPort: 8080, GuestIP: "192.168.127.2", GuestPort: 80},
+		{Protocol: "udp", HostPort: 5353, GuestIP: "192.168.127.3", GuestPort: 53},
+	}, GetPortForwards(cfg))
+
+	_, err = cfg.Set(PortForwards, "8080:80,8080:8080")
+	assert.EqualError(t, err, "Value '8080:80,8080:8080' for configuration property 'port-forwards' is invalid, reason: port forwards 8080:192.168.127.2:80/tcp and 8080:192.168.127.2:8080/tcp use the same host port")
+	_, err = cfg.Set(PortForwards, "443:443")
+	assert.EqualError(t, err, "Value '443:443' for configuration property 'port-forwards' is invalid, reason: port forward 443:192.168.127.2:443/tcp conflicts with the port used by ingress-https-port")
+	_, err = cfg.Set(PortForwards, "6443:6443")
+	assert.EqualError(t, err, "Value '6443:6443' for configuration property 'port-forwards' is invalid, reason: port forward 6443:192.168.127.2:6443/tcp conflicts with the port used by OpenShift API")
+	// the ingress ports are only reserved for tcp
+	_, err = cfg.Set(PortForwards, "443:443/udp")
+	assert.NoError(t, err)
+
+	_, err = cfg.Set(PortForwards, "8080:80")
+	require.NoError(t, err)
+	_, err = cfg.Set(IngressHTTPPort, 8080)
+	assert.EqualError(t, err, "Value '8080' for configuration property 'ingress-http-port' is invalid, reason: port 8080 is already used by the port forward 8080:192.168.127.2:80/tcp")
+}
+
+func TestPortForwardsRequireUserNetworking(t *testing.T) {
+	cfg, err := newInMemoryConfig()
+	require.NoError(t, err)
+	if _, ok := cfg.settingsByName[NetworkMode]; !ok {
+		t.Skip("network mode cannot be changed")
+	}
+	_, err = cfg.Set(NetworkMode, string(network.SystemNetworkingMode))
+	require.NoError(t, err)
+	_, err = cfg.Set(PortForwards, "8080:80")
+	assert.EqualError(t, err, "Value '8080:80' for configuration property 'port-forwards' is invalid, reason: port-forwards can only be used with network-mode set to 'user'")
+}
+
+func TestDNSRecords(t *testing.T) {
+	cfg, err := newInMemoryConfig()
+	require.NoError(t, err)
+	assert.Empty(t, GetDNSRecords(cfg))
+
+	require.NoError(t, SetDNSRecord(cfg, network.DNSRecord{Hostname: "registry.corp.test", IP: "192.168.127.254"}))
+	require.NoError(t, SetDNSRecord(cfg, network.DNSRecord{Hostname: "git.corp.test", IP: "192.168.127.3"}))
+	require.NoError(t, SetDNSRecord(cfg, network.DNSRecord{Hostname: "registry.corp.test", IP: "192.168.127.4"}))
+	assert.Equal(t, "git.corp.test=192.168.127.3,registry.corp.test=192.168.127.4", cfg.Get(DNSRecords).AsString())
+
+	record, err := RemoveDNSRecord(cfg, "Registry.corp.test.")
+	require.NoError(t, err)
+	assert.Equal(t, network.DNSRecord{Hostname: "registry.corp.test", IP: "192.168.127.4"}, record)
+	_, err = RemoveDNSRecord(cfg, "registry.corp.test")
+	assert.EqualError(t, err, "no DNS record for registry.corp.test")
+	_, err = RemoveDNSRecord(cfg, "git.corp.test")
+	require.NoError(t, err)
+	assert.True(t, cfg.Get(DNSRecords).IsDefault)
+
+	_, err = cfg.Set(DNSRecords, "registry=192.168.127.254")
+	assert.EqualError(t, err, "Value 'registry=192.168.127.254' for configuration property 'dns-records' is invalid, reason: invalid hostname 'registry', it must include a domain")
+}
+
+func TestRemoteAPIAddress(t *testing.T) {
+	cfg, err := newInMemoryConfig()
+	require.NoError(t, err)
+
+	_, err = cfg.Set(RemoteAPIAddress, "127.0.0.1:8443")
+	assert.NoError(t, err)
+	_, err = cfg.Set(RemoteAPIAddress, "crc.example.com:8443")
+	assert.NoError(t, err)
+	_, err = cfg.Set(RemoteAPIAddress, "8443")
+	assert.EqualError(t, err, "Value '8443' for configuration property 'remote-api-address' is invalid, reason: invalid address '8443', must be HOST:PORT")
+	_, err = cfg.Set(RemoteAPIAddress, "0.0.0.0:https")
+	assert.EqualError(t, err, "Value '0.0.0.0:https' for configuration property 'remote-api-address' is invalid, reason: invalid port 'https' in address '0.0.0.0:https'")
+}
+
+func TestRegistriesConfig(t *testing.T) {
+	cfg, err := newInMemoryConfig()
+	require.NoError(t, err)
+	assert.True(t, GetRegistriesConfig(cfg).IsEmpty())
+
+	_, err = cfg.Set(RegistryMirrors, "docker.io=mirror.corp.test/docker,quay.io=mirror.corp.test/quay")
+	require.NoError(t, err)
+	_, err = cfg.Set(InsecureRegistries, "mirror.corp.test")
+	require.NoError(t, err)
+	_, err = cfg.Set(BlockedRegistries, "*.untrusted.test")
+	require.NoError(t, err)
+	assert.Equal(t, registries.Config{
+		Mirrors: []registries.Mirror{
+			{Source: "docker.io", Mirror: "mirror.corp.test/docker"},
+			{Source: "quay.io", Mirror: "mirror.corp.test/quay"},
+		},
+		Insecure: []string{"mirror.corp.test"},
+		Blocked:  []string{"*.untrusted.test"},
+	}, GetRegistriesConfig(cfg))
+
+	_, err = cfg.Set(RegistryMirrors, "docker.io")
+	assert.EqualError(t, err, "Value 'docker.io' for configuration property 'registry-mirrors' is invalid, reason: invalid registry mirror 'docker.io', must be SOURCE=MIRROR")
+	_, err = cfg.Set(InsecureRegistries, "https://mirror.corp.test")
+	assert.EqualError(t, err, "Value 'https://mirror.corp.test' for configuration property 'insecure-registries' is invalid, reason: invalid registry 'https://mirror.corp.test'")
+}
+
+func TestAdditionalTrustedCAFiles(t *testing.T) {
+	cfg, err := newInMemoryConfig()
+	require.NoError(t, err)
+	assert.Empty(t, GetAdditionalTrustedCAFiles(cfg))
+
+	dir := t.TempDir()
+	_, cert, err := crctls.GetSelfSignedCA()
+	require.NoError(t, err)
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, crctls.CertToPem(cert), 0600))
+
+	_, err = cfg.Set(AdditionalTrustedCAFiles, caFile+", "+dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{caFile, dir}, GetAdditionalTrustedCAFiles(cfg))
+
+	invalidFile := filepath.Join(dir, "invalid.crt")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("invalid"), 0600))
+	_, err = cfg.Set(AdditionalTrustedCAFiles, invalidFile)
+	assert.EqualError(t, err, fmt.Sprintf("Value '%s' for configuration property 'additional-trusted-ca-files' is invalid, reason: invalid CA file %s: failed to decode certificate PEM", invalidFile, invalidFile))
+}
+
+func TestTrustedBundleKeys(t *testing.T) {
+	cfg, err := newInMemoryConfig()
+	require.NoError(t, err)
+	assert.Empty(t, GetTrustedBundleKeys(cfg))
+
+	dir := t.TempDir()
+	invalidKey := filepath.Join(dir, "invalid.asc")
+	require.NoError(t, os.WriteFile(invalidKey, []byte("invalid"), 0600))
+	_, err = cfg.Set(TrustedBundleKeys, invalidKey)
+	assert.ErrorContains(t, err, fmt.Sprintf("Value '%s' for configuration property 'trusted-bundle-keys' is invalid, reason: failed to parse public key %s", invalidKey, invalidKey))
+
+	_, err = cfg.Set(TrustedBundleKeys, filepath.Join(dir, "missing.asc"))
+	assert.Error(t, err)
+	assert.Empty(t, GetTrustedBundleKeys(cfg))
+}
+
+func TestSharedDirs(t *testing.T) {
+	cfg, err := newInMemoryConfig()
+	require.NoError(t, err)
+	assert.Empty(t, GetSharedDirs(cfg))
+
+	dir := t.TempDir()
+	_, err = cfg.Set(SharedDirs, dir+":/mnt/src:ro")
+	require.NoError(t, err)
+	assert.Equal(t, []shareddirs.SharedDir{{Source: dir, Target: "/mnt/src", ReadOnly: true}}, GetSharedDirs(cfg))
+
+	missing := filepath.Join(dir, "missing")
+	_, err = cfg.Set(SharedDirs, missing+":/mnt/src")
+	assert.ErrorContains(t, err, "Value '"+missing+":/mnt/src' for configuration property 'shared-dirs' is invalid, reason: invalid shared directory "+missing+":/mnt/src")
+}
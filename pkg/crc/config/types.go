@@ -7,6 +7,7 @@ type Storage interface {
 	Set(key string, value interface{}) (string, error)
 	Unset(key string) (string, error)
 	AllConfigs() map[string]SettingValue
+	Source(key string) (Source, error)
 }
 
 type Schema interface {
@@ -56,6 +57,30 @@ type RawStorage interface {
 	Unset(key string) error
 }
 
+// Source is the configuration layer a value comes from
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceSystem  Source = "system"
+	// SourceLocked is the source of the keys locked by the system
+	// configuration file
+	SourceLocked  Source = "system, locked"
+	SourceUser    Source = "user"
+	SourceProject Source = "project"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// LayeredStorage is a RawStorage merging several configuration layers, some
+// of its keys may be locked to prevent changing them. Its methods fail when
+// a layer cannot be read, so that locked keys are never ignored.
+type LayeredStorage interface {
+	RawStorage
+	Lookup(key string) (interface{}, Source, error)
+	IsLocked(key string) (bool, error)
+}
+
 // type Path is used for a setting which is a file path
 type Path string
 
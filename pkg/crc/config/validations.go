@@ -2,14 +2,23 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"runtime"
+	"strconv"
 	"strings"
 
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
 	"go.podman.io/common/pkg/strongunits"
 
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/gpg"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 	"github.com/crc-org/crc/v2/pkg/crc/network/httpproxy"
 	crcpreset "github.com/crc-org/crc/v2/pkg/crc/preset"
+	"github.com/crc-org/crc/v2/pkg/crc/registries"
+	"github.com/crc-org/crc/v2/pkg/crc/shareddirs"
+	crctls "github.com/crc-org/crc/v2/pkg/crc/tls"
 	"github.com/crc-org/crc/v2/pkg/crc/validation"
 	"github.com/spf13/cast"
 )
@@ -132,6 +141,21 @@ func validateNoProxy(value interface{}) (bool, string) {
 	return true, ""
 }
 
+// validateBundleMirrors checks that every entry of the comma-separated list is a http(s) URL
+func validateBundleMirrors(value interface{}) (bool, string) {
+	for _, mirror := range strings.Split(cast.ToString(value), ",") {
+		mirror = strings.TrimSpace(mirror)
+		if mirror == "" {
+			continue
+		}
+		u, err := url.Parse(mirror)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return false, fmt.Sprintf("%s is not a valid http or https URL", mirror)
+		}
+	}
+	return true, ""
+}
+
 func validateYesNo(value interface{}) (bool, string) {
 	if cast.ToString(value) == "yes" || cast.ToString(value) == "no" {
 		return true, ""
@@ -164,3 +188,100 @@ func validatePort(value interface{}) (bool, string) {
 	}
 	return true, ""
 }
+
+// validateRemoteAPIAddress checks the address is empty or in the HOST:PORT format
+func validateRemoteAPIAddress(value interface{}) (bool, string) {
+	address := cast.ToString(value)
+	if address == "" {
+		return true, ""
+	}
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return false, fmt.Sprintf("invalid address '%s', must be HOST:PORT", address)
+	}
+	if portNumber, err := strconv.ParseUint(port, 10, 16); err != nil || portNumber == 0 {
+		return false, fmt.Sprintf("invalid port '%s' in address '%s'", port, address)
+	}
+	return true, ""
+}
+
+// validateTrustedCAFiles checks that every entry of the comma-separated list
+// is a PEM file of certificates, or a directory of such files
+func validateTrustedCAFiles(value interface{}) (bool, string) {
+	var paths []string
+	for _, path := range strings.Split(cast.ToString(value), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if _, err := crctls.LoadCACertificates(paths); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// validateTrustedBundleKeys checks that every entry of the comma-separated
+// list is a file of armored OpenPGP public keys
+func validateTrustedBundleKeys(value interface{}) (bool, string) {
+	var paths []string
+	for _, path := range strings.Split(cast.ToString(value), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if _, err := gpg.ReadKeyring(paths); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// validateSharedDirs checks the syntax of the shared directories and that
+// their host paths are existing directories
+func validateSharedDirs(value interface{}) (bool, string) {
+	dirs, err := shareddirs.ParseList(cast.ToString(value))
+	if err != nil {
+		return false, err.Error()
+	}
+	for _, dir := range dirs {
+		if err := dir.Validate(); err != nil {
+			return false, err.Error()
+		}
+	}
+	return true, ""
+}
+
+// validateRegistryMirrors checks the syntax of the SOURCE=MIRROR list
+func validateRegistryMirrors(value interface{}) (bool, string) {
+	if _, err := registries.ParseMirrors(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// validateRegistries checks the syntax of the comma-separated registry list
+func validateRegistries(value interface{}) (bool, string) {
+	if _, err := registries.ParseRegistries(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// validatePortForwardsValue checks the syntax of the port forwards and that
+// they do not listen on the same host port, or on one of the reserved ports
+func validatePortForwardsValue(value interface{}, reserved map[uint]string) (bool, string) {
+	forwards, err := network.ParsePortForwards(cast.ToString(value))
+	if err != nil {
+		return false, err.Error()
+	}
+	for i, forward := range forwards {
+		if name, ok := reserved[uint(forward.HostPort)]; ok && forward.Protocol == types.TCP {
+			return false, fmt.Sprintf("port forward %s conflicts with the port used by %s", forward, name)
+		}
+		for _, other := range forwards[:i] {
+			if forward.SameHostPort(other) {
+				return false, fmt.Sprintf("port forwards %s and %s use the same host port", other, forward)
+			}
+		}
+	}
+	return true, ""
+}
@@ -1,49 +1,148 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/spf13/cast"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// lockedKeysKey is the key of the system configuration file listing the
+// keys which cannot be overridden by the other layers
+const lockedKeysKey = "locked-keys"
+
+// projectKeys are the only keys read from the project configuration file.
+// It comes from the working directory, which may be any cloned repository, so
+// the keys giving access to the host or changing what is trusted, such as
+// provisioning-dir, bundle, proxies or remote-api-address, are ignored.
+var projectKeys = []string{Preset, CPUs, Memory, DiskSize, PersistentVolumeSize, EnableClusterMonitoring}
+
 type ViperStorage struct {
 	storeLock *sync.Mutex
 
 	// store flag values
 	flagSet *pflag.FlagSet
 
-	configFile string
-	envPrefix  string
+	// systemConfigFile and projectConfigFile are optional and never written,
+	// configFile is the user configuration file changed by Set and Unset
+	systemConfigFile  string
+	configFile        string
+	projectConfigFile string
+	envPrefix         string
+
+	projectLogOnce *sync.Once
 }
 
 func NewViperStorage(configFile, envPrefix string) (*ViperStorage, error) {
+	return NewLayeredViperStorage("", configFile, "", envPrefix)
+}
+
+// NewLayeredViperStorage returns a storage merging the system, user and
+// project configuration files, the environment variables and the flags, each
+// layer overrides the previous ones. The keys listed in the 'locked-keys'
+// array of the system configuration file always have their system value. The
+// project configuration file is optional, only its projectKeys are used.
+func NewLayeredViperStorage(systemConfigFile, configFile, projectConfigFile, envPrefix string) (*ViperStorage, error) {
 	return &ViperStorage{
-		storeLock:  &sync.Mutex{},
-		configFile: configFile,
-		envPrefix:  envPrefix,
+		storeLock:         &sync.Mutex{},
+		systemConfigFile:  systemConfigFile,
+		configFile:        configFile,
+		projectConfigFile: projectConfigFile,
+		envPrefix:         envPrefix,
+		projectLogOnce:    &sync.Once{},
 	}, nil
 }
 
-func (c *ViperStorage) viperInstance() (*viper.Viper, error) {
+// configLayers are the values of the configuration files
+type configLayers struct {
+	system  map[string]interface{}
+	user    map[string]interface{}
+	project map[string]interface{}
+	locked  []string
+}
+
+func (c *ViperStorage) readLayers() (*configLayers, error) {
 	if err := ensureConfigFileExists(c.configFile); err != nil {
 		return nil, err
 	}
+	user, err := readConfigFile(c.configFile)
+	if err != nil {
+		return nil, err
+	}
+	system, err := readOptionalConfigFile(c.systemConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	project, err := c.readProjectConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	layers := &configLayers{
+		system:  system,
+		user:    user,
+		project: project,
+	}
+	if locked, ok := system[lockedKeysKey]; ok {
+		layers.locked, err = cast.ToStringSliceE(locked)
+		if err != nil {
+			return nil, fmt.Errorf("invalid '%s' in configuration file '%s': %w", lockedKeysKey, c.systemConfigFile, err)
+		}
+		delete(system, lockedKeysKey)
+	}
+	return layers, nil
+}
+
+// readProjectConfigFile returns the projectKeys of the project configuration
+// file, its use is logged once
+func (c *ViperStorage) readProjectConfigFile() (map[string]interface{}, error) {
+	project, err := readOptionalConfigFile(c.projectConfigFile)
+	if err != nil || len(project) == 0 {
+		return project, err
+	}
+	var ignored []string
+	for key := range project {
+		if !slices.Contains(projectKeys, key) {
+			ignored = append(ignored, key)
+			delete(project, key)
+		}
+	}
+	c.projectLogOnce.Do(func() {
+		logging.Infof("Using the project configuration file %s", c.projectConfigFile)
+		if len(ignored) > 0 {
+			slices.Sort(ignored)
+			logging.Warnf("Ignoring %s from %s, only %s can be set in a project configuration file",
+				strings.Join(ignored, ", "), c.projectConfigFile, strings.Join(projectKeys, ", "))
+		}
+	})
+	return project, nil
+}
+
+func (layers *configLayers) isLocked(key string) bool {
+	return slices.Contains(layers.locked, key)
+}
+
+func (c *ViperStorage) viperInstance(layers *configLayers) (*viper.Viper, error) {
 	v := viper.New()
-	v.SetConfigFile(c.configFile)
-	v.SetConfigType("json")
 	v.SetEnvPrefix(c.envPrefix)
 	// Replaces '-' in flags with '_' in env variables
 	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	v.AutomaticEnv()
 	v.SetTypeByDefaultValue(true)
-	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("error reading configuration file '%s': %w", c.configFile, err)
+	for _, values := range []map[string]interface{}{layers.system, layers.user, layers.project} {
+		if err := v.MergeConfigMap(values); err != nil {
+			return nil, err
+		}
 	}
 	if c.flagSet == nil {
 		return v, nil
@@ -52,13 +151,70 @@ func (c *ViperStorage) viperInstance() (*viper.Viper, error) {
 }
 
 func (c *ViperStorage) Get(key string) interface{} {
+	value, _, err := c.Lookup(key)
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// Lookup returns the value of key and the layer it comes from, it fails when
+// a configuration file cannot be read
+func (c *ViperStorage) Lookup(key string) (interface{}, Source, error) {
 	c.storeLock.Lock()
 	defer c.storeLock.Unlock()
-	viperInstance, err := c.viperInstance()
+	layers, err := c.readLayers()
 	if err != nil {
-		return nil
+		return nil, SourceDefault, err
 	}
-	return viperInstance.Get(key)
+	if layers.isLocked(key) {
+		return layers.system[key], SourceLocked, nil
+	}
+	viperInstance, err := c.viperInstance(layers)
+	if err != nil {
+		return nil, SourceDefault, err
+	}
+	return viperInstance.Get(key), c.source(layers, key), nil
+}
+
+// IsLocked returns true when key is locked by the system configuration file
+func (c *ViperStorage) IsLocked(key string) (bool, error) {
+	c.storeLock.Lock()
+	defer c.storeLock.Unlock()
+	layers, err := c.readLayers()
+	if err != nil {
+		return false, err
+	}
+	return layers.isLocked(key), nil
+}
+
+// Validate checks that all the configuration files can be read
+func (c *ViperStorage) Validate() error {
+	c.storeLock.Lock()
+	defer c.storeLock.Unlock()
+	_, err := c.readLayers()
+	return err
+}
+
+func (c *ViperStorage) source(layers *configLayers, key string) Source {
+	if c.flagSet != nil {
+		if flag := c.flagSet.Lookup(key); flag != nil && flag.Changed {
+			return SourceFlag
+		}
+	}
+	if os.Getenv(strings.ToUpper(c.envPrefix+"_"+strings.ReplaceAll(key, "-", "_"))) != "" {
+		return SourceEnv
+	}
+	if _, ok := layers.project[key]; ok {
+		return SourceProject
+	}
+	if _, ok := layers.user[key]; ok {
+		return SourceUser
+	}
+	if _, ok := layers.system[key]; ok {
+		return SourceSystem
+	}
+	return SourceDefault
 }
 
 func (c *ViperStorage) Set(key string, value interface{}) error {
@@ -113,6 +269,32 @@ func (c *ViperStorage) BindFlagSet(flagSet *pflag.FlagSet) error {
 	return nil
 }
 
+func readConfigFile(file string) (map[string]interface{}, error) {
+	in, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading configuration file '%s': %w", file, err)
+	}
+	cfg := map[string]interface{}{}
+	if len(bytes.TrimSpace(in)) == 0 {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(in, &cfg); err != nil {
+		return nil, fmt.Errorf("error reading configuration file '%s': %w", file, err)
+	}
+	return cfg, nil
+}
+
+// readOptionalConfigFile returns no values when file is empty or does not exist
+func readOptionalConfigFile(file string) (map[string]interface{}, error) {
+	if file == "" {
+		return map[string]interface{}{}, nil
+	}
+	if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+		return map[string]interface{}{}, nil
+	}
+	return readConfigFile(file)
+}
+
 // ensureConfigFileExists creates the viper config file if it does not exists
 func ensureConfigFileExists(file string) error {
 	_, err := os.Stat(file)
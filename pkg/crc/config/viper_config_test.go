@@ -351,3 +351,111 @@ func TestCallbacks(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, callback)
 }
+
+func newLayeredTestConfig(t *testing.T, system, user, project string) (*Config, string) {
+	dir := t.TempDir()
+	systemConfigFile := filepath.Join(dir, "config.json")
+	configFile := filepath.Join(dir, "crc.json")
+	projectConfigFile := filepath.Join(dir, ".crc.json")
+	for file, content := range map[string]string{systemConfigFile: system, configFile: user, projectConfigFile: project} {
+		if content != "" {
+			require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+		}
+	}
+
+	storage, err := NewLayeredViperStorage(systemConfigFile, configFile, projectConfigFile, "CRC")
+	require.NoError(t, err)
+	config := New(storage, NewEmptyInMemorySecretStorage())
+	config.AddSetting(cpus, 4, func(value interface{}) (bool, string) {
+		return validateCPUs(value, preset.OpenShift)
+	}, RequiresRestartMsg, "")
+	config.AddSetting(nameServer, "", validateIPAddress, SuccessfullyApplied, "")
+	return config, configFile
+}
+
+func TestViperConfigLayers(t *testing.T) {
+	config, _ := newLayeredTestConfig(t, `{"cpus": 6, "nameservers": "1.1.1.1"}`, `{"cpus": 8}`, "")
+	assert.Equal(t, SettingValue{Value: 8}, config.Get(cpus))
+	assertSource(t, config, cpus, SourceUser)
+	assert.Equal(t, SettingValue{Value: "1.1.1.1"}, config.Get(nameServer))
+	assertSource(t, config, nameServer, SourceSystem)
+
+	config, _ = newLayeredTestConfig(t, `{"cpus": 6}`, `{"cpus": 8}`, `{"cpus": 10}`)
+	assert.Equal(t, SettingValue{Value: 10}, config.Get(cpus))
+	assertSource(t, config, cpus, SourceProject)
+	assertSource(t, config, nameServer, SourceDefault)
+
+	t.Setenv("CRC_CPUS", "12")
+	assert.Equal(t, SettingValue{Value: 12}, config.Get(cpus))
+	assertSource(t, config, cpus, SourceEnv)
+}
+
+func TestViperConfigLockedKeys(t *testing.T) {
+	config, configFile := newLayeredTestConfig(t, `{"cpus": 6, "locked-keys": ["cpus", "nameservers"]}`, `{"cpus": 8}`, `{"cpus": 10}`)
+	t.Setenv("CRC_CPUS", "12")
+
+	assert.Equal(t, SettingValue{Value: 6}, config.Get(cpus))
+	assertSource(t, config, cpus, SourceLocked)
+	// a locked key without system value is locked to its default value
+	assert.Equal(t, SettingValue{Value: "", IsDefault: true}, config.Get(nameServer))
+
+	_, err := config.Set(cpus, 5)
+	assert.EqualError(t, err, "Configuration property 'cpus' is locked by the system configuration and cannot be changed")
+	_, err = config.Unset(cpus)
+	assert.EqualError(t, err, "Configuration property 'cpus' is locked by the system configuration and cannot be changed")
+
+	bin, err := os.ReadFile(configFile)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"cpus": 8}`, string(bin))
+}
+
+func assertSource(t *testing.T, config *Config, key string, expected Source) {
+	source, err := config.Source(key)
+	require.NoError(t, err)
+	assert.Equal(t, expected, source)
+}
+
+func TestViperConfigProjectKeys(t *testing.T) {
+	config, _ := newLayeredTestConfig(t, "", "", `{"cpus": 10, "nameservers": "1.1.1.1"}`)
+	assert.Equal(t, SettingValue{Value: 10}, config.Get(cpus))
+	// nameservers cannot be set by a project configuration file
+	assert.Equal(t, SettingValue{Value: "", IsDefault: true}, config.Get(nameServer))
+	assertSource(t, config, nameServer, SourceDefault)
+}
+
+func TestViperConfigShadowedSet(t *testing.T) {
+	config, configFile := newLayeredTestConfig(t, "", "", `{"cpus": 10}`)
+	message, err := config.Set(cpus, 8)
+	require.NoError(t, err)
+	assert.Contains(t, message, "Warning: configuration property 'cpus' is overridden by its project value, this change is not applied")
+
+	bin, err := os.ReadFile(configFile)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"cpus": 8}`, string(bin))
+
+	t.Setenv("CRC_NAMESERVERS", "1.1.1.1")
+	message, err = config.Unset(nameServer)
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully unset configuration property 'nameservers'\nWarning: configuration property 'nameservers' is overridden by its env value, this change is not applied", message)
+}
+
+func TestViperConfigInvalidSystemConfiguration(t *testing.T) {
+	config, configFile := newLayeredTestConfig(t, `{"cpus": 6, "locked-keys": ["cpus"]`, `{"cpus": 8}`, "")
+	// the locked value cannot be read, the default value is not used instead
+	assert.True(t, config.Get(cpus).Invalid)
+	_, err := config.Source(cpus)
+	assert.ErrorContains(t, err, "error reading configuration file")
+
+	_, err = config.Set(cpus, 5)
+	assert.ErrorContains(t, err, "error reading configuration file")
+	_, err = config.Unset(cpus)
+	assert.ErrorContains(t, err, "error reading configuration file")
+
+	bin, err := os.ReadFile(configFile)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"cpus": 8}`, string(bin))
+
+	storage, err := NewLayeredViperStorage(filepath.Join(filepath.Dir(configFile), "config.json"), configFile, "", "CRC")
+	require.NoError(t, err)
+	assert.Error(t, storage.Validate())
+}
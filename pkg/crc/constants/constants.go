@@ -24,14 +24,19 @@ const (
 
 	CrcEnvPrefix = "CRC"
 
+	// ProjectConfigEnvVar enables the ProjectConfigFile of the current
+	// directory when it is set to true
+	ProjectConfigEnvVar = "CRC_ENABLE_PROJECT_CONFIG"
+
 	ConfigFile                = "crc.json"
+	ProjectConfigFile         = ".crc.json"
 	LogFile                   = "crc.log"
 	DaemonLogFile             = "crcd.log"
 	AdminHelperLogFile        = "admin-helper.log"
 	CrcLandingPageURL         = "https://console.redhat.com/openshift/create/local" // #nosec G101
 	DefaultAdminHelperURLBase = "https://github.com/crc-org/admin-helper/releases/download/v%s/%s"
 	BackgroundLauncherURL     = "https://github.com/crc-org/win32-background-launcher/releases/download/v%s/win32-background-launcher.exe"
-	DefaultBundleURLBase      = "https://mirror.openshift.com/pub/openshift-v4/clients/crc/bundles/%s/%s/%s"
+	DefaultBundleMirror       = "https://mirror.openshift.com/pub/openshift-v4/clients/crc/bundles"
 	DefaultContext            = "admin"
 	DefaultDeveloperPassword  = "developer"
 	DaemonHTTPEndpoint        = "http://unix/api"
@@ -54,6 +59,7 @@ const (
 
 	OpenShiftIngressHTTPPort  = 80
 	OpenShiftIngressHTTPSPort = 443
+	OpenShiftAPIPort          = 6443
 
 	BackgroundLauncherExecutable = "crc-background-launcher.exe"
 
@@ -149,30 +155,44 @@ var (
 	MachineInstanceDir     = filepath.Join(MachineBaseDir, "machines")
 	SocketBaseDir          = filepath.Join(CrcBaseDir, "sockets")
 	DaemonSocketPath       = filepath.Join(SocketBaseDir, "crc.sock")
-	KubeconfigFilePath     = filepath.Join(MachineInstanceDir, DefaultName, "kubeconfig")
-	PasswdFilePath         = filepath.Join(MachineInstanceDir, DefaultName, "passwd")
+	RemoteAPIDir           = filepath.Join(CrcBaseDir, "remote-api")
+	DiagnosticsDir         = filepath.Join(CrcBaseDir, "diagnostics")
 )
 
 func GetDefaultBundlePath(preset crcpreset.Preset) string {
 	return filepath.Join(MachineCacheDir, GetDefaultBundle(preset))
 }
 
-func GetDefaultBundleDownloadURL(preset crcpreset.Preset) string {
-	return fmt.Sprintf(DefaultBundleURLBase,
+// GetBundleDownloadURL returns the URL of the default bundle for preset on
+// the mirror with the given base URL
+func GetBundleDownloadURL(mirror string, preset crcpreset.Preset) string {
+	return fmt.Sprintf("%s/%s/%s/%s",
+		strings.TrimSuffix(mirror, "/"),
 		preset.String(),
 		version.GetBundleVersion(preset),
 		GetDefaultBundle(preset),
 	)
 }
 
-func GetDefaultBundleSignedHashURL(preset crcpreset.Preset) string {
-	return fmt.Sprintf(DefaultBundleURLBase,
+// GetBundleSignedHashURL returns the URL of the signed sha256sum file for
+// the default bundles of preset on the mirror with the given base URL
+func GetBundleSignedHashURL(mirror string, preset crcpreset.Preset) string {
+	return fmt.Sprintf("%s/%s/%s/%s",
+		strings.TrimSuffix(mirror, "/"),
 		preset.String(),
 		version.GetBundleVersion(preset),
 		"sha256sum.txt.sig",
 	)
 }
 
+func GetDefaultBundleDownloadURL(preset crcpreset.Preset) string {
+	return GetBundleDownloadURL(DefaultBundleMirror, preset)
+}
+
+func GetDefaultBundleSignedHashURL(preset crcpreset.Preset) string {
+	return GetBundleSignedHashURL(DefaultBundleMirror, preset)
+}
+
 func ResolveHelperPath(executableName string) string {
 	if version.IsInstaller() {
 		return filepath.Join(version.InstallPath(), executableName)
@@ -235,29 +255,46 @@ func EnsureAdminHelperLogFileExists() error {
 	return nil
 }
 
-func GetPublicKeyPath() string {
-	return filepath.Join(MachineInstanceDir, DefaultName, "id_ed25519.pub")
+// GetInstanceDir returns the directory holding the files of the machineName instance
+func GetInstanceDir(machineName string) string {
+	return filepath.Join(MachineInstanceDir, machineName)
+}
+
+func GetInstanceSnapshotsDir(machineName string) string {
+	return filepath.Join(GetInstanceDir(machineName), "snapshots")
+}
+
+func GetKubeconfigFilePath(machineName string) string {
+	return filepath.Join(GetInstanceDir(machineName), "kubeconfig")
+}
+
+func GetPasswdFilePath(machineName string) string {
+	return filepath.Join(GetInstanceDir(machineName), "passwd")
+}
+
+func GetPublicKeyPath(machineName string) string {
+	return filepath.Join(GetInstanceDir(machineName), "id_ed25519.pub")
 }
 
-func GetPrivateKeyPath() string {
-	return filepath.Join(MachineInstanceDir, DefaultName, "id_ed25519")
+func GetPrivateKeyPath(machineName string) string {
+	return filepath.Join(GetInstanceDir(machineName), "id_ed25519")
 }
 
-func GetHostDockerSocketPath() string {
-	return filepath.Join(MachineInstanceDir, DefaultName, "docker.sock")
+func GetHostDockerSocketPath(machineName string) string {
+	return filepath.Join(GetInstanceDir(machineName), "docker.sock")
 }
 
 // For backward compatibility to v 2.40.0
-func GetECDSAPrivateKeyPath() string {
-	return filepath.Join(MachineInstanceDir, DefaultName, "id_ecdsa")
+func GetECDSAPrivateKeyPath(machineName string) string {
+	return filepath.Join(GetInstanceDir(machineName), "id_ecdsa")
 }
 
-func GetKubeAdminPasswordPath() string {
-	return filepath.Join(MachineInstanceDir, DefaultName, "kubeadmin-password")
+func GetKubeAdminPasswordPath(machineName string) string {
+	return filepath.Join(GetInstanceDir(machineName), "kubeadmin-password")
 }
 
-func GetDeveloperPasswordPath() string {
-	return filepath.Join(MachineInstanceDir, DefaultName, "developer-password")
+func GetDeveloperPasswordPath(machineName string) string {
+	return filepath.Join(GetInstanceDir(machineName), "developer-password")
 }
 
 func GetWin32BackgroundLauncherDownloadURL() string {
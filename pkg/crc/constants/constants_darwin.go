@@ -1,6 +1,7 @@
 package constants
 
 import (
+	"fmt"
 	"path/filepath"
 )
 
@@ -14,4 +15,14 @@ var (
 	TapSocketPath        = filepath.Join(SocketBaseDir, "tap.sock")
 	DaemonHTTPSocketPath = filepath.Join(SocketBaseDir, "crc-http.sock")
 	UnixgramSocketPath   = filepath.Join(SocketBaseDir, "crc-unixgram.sock")
+	SystemConfigPath     = filepath.Join("/etc", "crc", "config.json")
 )
+
+// GetUnixgramSocketPath returns the path of the unixgram socket connecting
+// the VM of the instance with the given address index to the daemon
+func GetUnixgramSocketPath(index int) string {
+	if index == 0 {
+		return UnixgramSocketPath
+	}
+	return filepath.Join(SocketBaseDir, fmt.Sprintf("crc-unixgram-%d.sock", index))
+}
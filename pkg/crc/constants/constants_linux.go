@@ -9,4 +9,7 @@ const (
 	TapSocketPath    = ""
 )
 
-var DaemonHTTPSocketPath = filepath.Join(SocketBaseDir, "crc-http.sock")
+var (
+	DaemonHTTPSocketPath = filepath.Join(SocketBaseDir, "crc-http.sock")
+	SystemConfigPath     = filepath.Join("/etc", "crc", "config.json")
+)
@@ -1,5 +1,10 @@
 package constants
 
+import (
+	"os"
+	"path/filepath"
+)
+
 const (
 	OcExecutableName       = "oc.exe"
 	TapSocketPath          = ""
@@ -7,3 +12,5 @@ const (
 	DaemonTaskName         = "crcDaemon"
 	AdminHelperServiceName = "crcAdminHelper"
 )
+
+var SystemConfigPath = filepath.Join(os.Getenv("ProgramData"), "crc", "config.json")
@@ -3,37 +3,151 @@ package daemonclient
 import (
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	networkclient "github.com/containers/gvisor-tap-vsock/pkg/client"
 	"github.com/crc-org/crc/v2/pkg/crc/api/client"
+	"github.com/crc-org/crc/v2/pkg/crc/api/remote"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	crcversion "github.com/crc-org/crc/v2/pkg/crc/version"
 	pkgerrors "github.com/pkg/errors"
 )
 
 const genericDaemonNotRunningMessage = "Is 'crc daemon' running? Cannot reach daemon API"
 
+// Environment variables to drive the remote API of a daemon running on
+// another host instead of the local daemon, CRC_DAEMON_CREDENTIALS is the
+// directory created by 'crc daemon client-credentials'
+const (
+	DaemonURLEnv         = "CRC_DAEMON_URL"
+	DaemonCredentialsEnv = "CRC_DAEMON_CREDENTIALS"
+)
+
 type Client struct {
 	NetworkClient *networkclient.Client
 	APIClient     client.Client
+	V2Client      *client.V2Client
 	SSEClient     *client.SSEClient
 }
 
+// endpoint is the base URL of the daemon and the transport to reach it
+type endpoint struct {
+	transport http.RoundTripper
+	baseURL   string
+}
+
+func localEndpoint() endpoint {
+	return endpoint{
+		transport: transport(),
+		baseURL:   "http://unix",
+	}
+}
+
+func remoteEndpoint(url, credentialsDir string) (endpoint, error) {
+	if credentialsDir == "" {
+		return endpoint{}, fmt.Errorf("%s must be set together with %s", DaemonCredentialsEnv, DaemonURLEnv)
+	}
+	tlsConfig, token, err := remote.ClientTLSConfig(credentialsDir)
+	if err != nil {
+		return endpoint{}, err
+	}
+	return endpoint{
+		transport: &bearerTokenTransport{
+			token: token,
+			transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+		baseURL: strings.TrimSuffix(url, "/"),
+	}, nil
+}
+
+// defaultEndpoint is the remote daemon when CRC_DAEMON_URL is set, and the
+// local daemon otherwise
+func defaultEndpoint() endpoint {
+	url := os.Getenv(DaemonURLEnv)
+	if url == "" {
+		return localEndpoint()
+	}
+	e, err := remoteEndpoint(url, os.Getenv(DaemonCredentialsEnv))
+	if err != nil {
+		// report the error on each request instead of silently using the local daemon
+		return endpoint{
+			transport: errorTransport{err: pkgerrors.Wrapf(err, "cannot connect to the daemon at %s", url)},
+			baseURL:   url,
+		}
+	}
+	return e
+}
+
+type bearerTokenTransport struct {
+	token     string
+	transport http.RoundTripper
+}
+
+func (t *bearerTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.transport.RoundTrip(req)
+}
+
+type errorTransport struct {
+	err error
+}
+
+func (t errorTransport) RoundTrip(_ *http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// New returns a client of the daemon selected by the CRC_DAEMON_URL
+// environment variable, the local daemon by default
 func New() *Client {
+	return newClient(defaultEndpoint())
+}
+
+// NewLocal returns a client of the daemon running on this host
+func NewLocal() *Client {
+	return newClient(localEndpoint())
+}
+
+func newClient(e endpoint) *Client {
 	return &Client{
 		NetworkClient: networkclient.New(&http.Client{
-			Transport: transport(),
-		}, "http://unix/network"),
+			Transport: e.transport,
+		}, e.baseURL+"/network"),
 		APIClient: client.New(&http.Client{
 			Timeout:   30 * time.Second,
-			Transport: transport(),
-		}, "http://unix/api"),
-		SSEClient: client.NewSSEClient(transport()),
+			Transport: e.transport,
+		}, e.baseURL+"/api"),
+		V2Client: client.NewV2(&http.Client{
+			Timeout:   30 * time.Second,
+			Transport: e.transport,
+		}, e.baseURL+"/api/v2"),
+		SSEClient: client.NewSSEClient(e.transport, e.baseURL),
+	}
+}
+
+// NewForInstance returns a client whose lifecycle API calls and status
+// events target the instance called name instead of the default instance.
+func NewForInstance(name string) *Client {
+	e := defaultEndpoint()
+	c := newClient(e)
+	if name != constants.DefaultName {
+		c.APIClient = client.New(&http.Client{
+			Timeout:   30 * time.Second,
+			Transport: e.transport,
+		}, fmt.Sprintf("%s/api/instances/%s", e.baseURL, name))
+		c.SSEClient.ForInstance(name)
 	}
+	return c
 }
 
 func GetVersionFromDaemonAPI() (*client.VersionResult, error) {
-	apiClient := client.New(&http.Client{Transport: transport()}, "http://unix/api")
+	e := defaultEndpoint()
+	apiClient := client.New(&http.Client{Transport: e.transport}, e.baseURL+"/api")
 	version, err := apiClient.Version()
 	if err != nil {
 		return nil, pkgerrors.Wrap(err, genericDaemonNotRunningMessage)
@@ -0,0 +1,43 @@
+package daemonclient
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/crc/api/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVersionFromRemoteDaemon(t *testing.T) {
+	server, err := remote.LoadOrCreateServer(t.TempDir())
+	require.NoError(t, err)
+	listener, err := server.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/version", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"CrcVersion":"2.0.0"}`))
+	})
+	s := &http.Server{Handler: server.Authenticate(mux)}
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	defer s.Close()
+
+	credentialsDir := t.TempDir()
+	require.NoError(t, server.IssueClientCredentials("ci", credentialsDir))
+	t.Setenv(DaemonURLEnv, "https://"+listener.Addr().String()+"/")
+	t.Setenv(DaemonCredentialsEnv, credentialsDir)
+
+	version, err := GetVersionFromDaemonAPI()
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", version.CrcVersion)
+}
+
+func TestRemoteDaemonRequiresCredentials(t *testing.T) {
+	t.Setenv(DaemonURLEnv, "https://127.0.0.1:8443")
+	t.Setenv(DaemonCredentialsEnv, "")
+
+	_, err := New().APIClient.Version()
+	assert.ErrorContains(t, err, "CRC_DAEMON_CREDENTIALS must be set together with CRC_DAEMON_URL")
+}
@@ -0,0 +1,190 @@
+package diagnose
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crc-org/crc/v2/pkg/compress"
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
+	"github.com/crc-org/crc/v2/pkg/crc/preflight"
+	"github.com/crc-org/crc/v2/pkg/crc/version"
+)
+
+// Result describes the diagnostics archive
+type Result struct {
+	// Path is the path of the archive
+	Path string
+	// Errors lists the diagnostics which could not be collected
+	Errors []string
+}
+
+type collector struct {
+	dir      string
+	redactor *redactor
+	errors   []string
+
+	reportPreflight func(crcConfig.Storage) *preflight.Report
+	logDir          string
+}
+
+// Collect gathers the host information, the configuration, the logs and the
+// diagnostics of the instance in a zstd compressed tarball in outputDir. The
+// secret settings and the pull secret are redacted from all the files. A
+// diagnostic which cannot be collected is reported in Result.Errors and in
+// the errors.txt file of the archive.
+func Collect(ctx context.Context, config crcConfig.Storage, machineClient machine.Client, pullSecret cluster.PullSecretLoader, outputDir string) (*Result, error) {
+	return collect(ctx, config, machineClient, pullSecret, outputDir, preflight.ReportHost, constants.CrcBaseDir)
+}
+
+// collect is Collect with the function reporting the preflight checks of
+// the host and the directory of the log files
+func collect(ctx context.Context, config crcConfig.Storage, machineClient machine.Client, pullSecret cluster.PullSecretLoader, outputDir string,
+	reportPreflight func(crcConfig.Storage) *preflight.Report, logDir string) (*Result, error) {
+	outputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outputDir, 0700); err != nil {
+		return nil, err
+	}
+	tmpDir, err := os.MkdirTemp("", "crc-diagnostics")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	name := fmt.Sprintf("crc-diagnostics-%s-%s", machineClient.GetName(), time.Now().Format("20060102-150405"))
+	c := &collector{
+		dir:             filepath.Join(tmpDir, name),
+		reportPreflight: reportPreflight,
+		logDir:          logDir,
+	}
+	secret, err := pullSecret.Value()
+	if err != nil {
+		logging.Debugf("Cannot load the pull secret to redact it: %v", err)
+	}
+	c.redactor = newRedactor(config, secret)
+
+	c.collectHostInfo(config)
+	c.collectConfig(config)
+	c.collectLogs()
+	c.collectMachine(ctx, machineClient)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(c.errors) > 0 {
+		c.write("errors.txt", []byte(strings.Join(c.errors, "\n")+"\n"))
+	}
+
+	path := filepath.Join(outputDir, name+".tar.zst")
+	if err := compress.Compress(c.dir, path); err != nil {
+		return nil, fmt.Errorf("Cannot create the diagnostics archive: %w", err)
+	}
+	return &Result{
+		Path:   path,
+		Errors: c.errors,
+	}, nil
+}
+
+func (c *collector) fail(name string, err error) {
+	c.errors = append(c.errors, fmt.Sprintf("%s: %v", name, err))
+}
+
+// write stores the redacted content in the file with the slash-separated
+// name
+func (c *collector) write(name string, content []byte) {
+	path := filepath.Join(c.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		c.fail(name, err)
+		return
+	}
+	if err := os.WriteFile(path, c.redactor.redact(content), 0600); err != nil {
+		c.fail(name, err)
+	}
+}
+
+func (c *collector) collectHostInfo(config crcConfig.Storage) {
+	info := new(bytes.Buffer)
+	fmt.Fprintf(info, "CRC version: %s+%s\n", version.GetCRCVersion(), version.GetCommitSha())
+	fmt.Fprintf(info, "OS: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(info, "CPUs: %d\n", runtime.NumCPU())
+	if release, err := osRelease(); err != nil {
+		c.fail("host/info.txt", err)
+	} else {
+		fmt.Fprintf(info, "Release: %s\n", release)
+	}
+	c.write("host/info.txt", info.Bytes())
+
+	report, err := json.MarshalIndent(c.reportPreflight(config), "", "  ")
+	if err != nil {
+		c.fail("host/preflight.json", err)
+		return
+	}
+	c.write("host/preflight.json", report)
+}
+
+// collectConfig writes the settings which are not set to their default
+// value, like 'crc config view' does, the sensitive values are replaced
+func (c *collector) collectConfig(config crcConfig.Storage) {
+	var lines []string
+	for key, value := range config.AllConfigs() {
+		if value.IsDefault {
+			continue
+		}
+		if isSensitive(key, value) {
+			lines = append(lines, fmt.Sprintf("- %-38s: %s", key, redacted))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("- %-38s: %s", key, value.AsString()))
+	}
+	sort.Strings(lines)
+	c.write("config.txt", []byte(strings.Join(lines, "\n")+"\n"))
+}
+
+// collectLogs copies the log files of crc, of the daemon and of the admin
+// helper, the rotated ones included
+func (c *collector) collectLogs() {
+	logs, err := filepath.Glob(filepath.Join(c.logDir, "*.log"))
+	if err != nil {
+		c.fail("logs", err)
+		return
+	}
+	for _, log := range logs {
+		name := "logs/" + filepath.Base(log)
+		content, err := os.ReadFile(log)
+		if err != nil {
+			c.fail(name, err)
+			continue
+		}
+		c.write(name, content)
+	}
+}
+
+func (c *collector) collectMachine(ctx context.Context, machineClient machine.Client) {
+	// the files are written as they are collected, the output of the
+	// commands is not kept in memory
+	err := machineClient.Diagnostics(ctx, func(file types.DiagnosticFile) {
+		if file.Error != "" {
+			c.errors = append(c.errors, fmt.Sprintf("%s: %s", file.Name, c.redactor.redact([]byte(file.Error))))
+		}
+		if len(file.Content) > 0 || file.Error == "" {
+			c.write(file.Name, file.Content)
+		}
+	})
+	if err != nil {
+		c.fail("vm", err)
+	}
+}
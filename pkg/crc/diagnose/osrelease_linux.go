@@ -0,0 +1,15 @@
+package diagnose
+
+import (
+	"fmt"
+
+	"github.com/crc-org/crc/v2/pkg/os/linux"
+)
+
+func osRelease() (string, error) {
+	release, err := linux.GetOsRelease()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (id: %s, version: %s)", release.PrettyName, release.ID, release.VersionID), nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package diagnose
+
+import "runtime"
+
+func osRelease() (string, error) {
+	return runtime.GOOS, nil
+}
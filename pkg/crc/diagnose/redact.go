@@ -0,0 +1,123 @@
+package diagnose
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+)
+
+const redacted = "<redacted>"
+
+// authPattern matches the credentials of the registries in the pull secrets
+// and in the docker config files which may end up in the logs
+var authPattern = regexp.MustCompile(`("auth"\s*:\s*")[^"]*(")`)
+
+// userinfoPattern matches the credentials of the URLs, such as the proxy
+// URLs in the settings and in the logs
+var userinfoPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/?#@\s"']+@`)
+
+// passwordSettings are redacted like the secrets when they are set, their
+// default values are documented
+var passwordSettings = []string{
+	crcConfig.KubeAdminPassword,
+	crcConfig.DeveloperPassword,
+}
+
+// isSensitive reports whether the value of the setting must not appear in
+// the diagnostics
+func isSensitive(key string, value crcConfig.SettingValue) bool {
+	if value.IsSecret {
+		return true
+	}
+	return !value.IsDefault && slices.Contains(passwordSettings, key)
+}
+
+// redactor replaces the secrets in the content of the diagnostics files
+type redactor struct {
+	secrets []string
+}
+
+func newRedactor(config crcConfig.Storage, pullSecret string) *redactor {
+	var secrets []string
+	for key, value := range config.AllConfigs() {
+		if isSensitive(key, value) {
+			secrets = append(secrets, value.AsString())
+		}
+		secrets = append(secrets, urlCredentials(value.AsString())...)
+	}
+	secrets = append(secrets, pullSecretCredentials(pullSecret)...)
+
+	r := &redactor{}
+	seen := map[string]bool{}
+	for _, secret := range secrets {
+		if secret == "" || seen[secret] {
+			continue
+		}
+		seen[secret] = true
+		r.secrets = append(r.secrets, secret)
+	}
+	// a secret containing another one must be replaced first
+	sort.Slice(r.secrets, func(i, j int) bool {
+		return len(r.secrets[i]) > len(r.secrets[j])
+	})
+	return r
+}
+
+// pullSecretCredentials returns the pull secret and the tokens it contains,
+// both base64 encoded and decoded
+func pullSecretCredentials(pullSecret string) []string {
+	pullSecret = strings.TrimSpace(pullSecret)
+	if pullSecret == "" {
+		return nil
+	}
+	credentials := []string{pullSecret}
+	var auths struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal([]byte(pullSecret), &auths); err != nil {
+		return credentials
+	}
+	for _, auth := range auths.Auths {
+		credentials = append(credentials, auth.Auth)
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			continue
+		}
+		credentials = append(credentials, string(decoded))
+		if _, password, ok := strings.Cut(string(decoded), ":"); ok && password != "" {
+			credentials = append(credentials, password)
+		}
+	}
+	return credentials
+}
+
+// urlCredentials returns the userinfo of value and its decoded password
+// when value is a URL with credentials
+func urlCredentials(value string) []string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return nil
+	}
+	credentials := []string{u.User.String()}
+	if password, ok := u.User.Password(); ok && password != "" {
+		credentials = append(credentials, password)
+	}
+	return credentials
+}
+
+func (r *redactor) redact(content []byte) []byte {
+	text := string(content)
+	for _, secret := range r.secrets {
+		text = strings.ReplaceAll(text, secret, redacted)
+	}
+	text = userinfoPattern.ReplaceAllString(text, "${1}"+redacted+"@")
+	return []byte(authPattern.ReplaceAllString(text, "${1}"+redacted+"${2}"))
+}
@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"context"
+	"errors"
+)
+
+// Code identifies the kind of an error for API clients, which cannot rely
+// on the error messages
+type Code string
+
+const (
+	CodeInternal         Code = "Internal"
+	CodeInvalidArgument  Code = "InvalidArgument"
+	CodeNotFound         Code = "NotFound"
+	CodeConflict         Code = "Conflict"
+	CodeVMNotExist       Code = "VMNotExist"
+	CodeDaemonNotRunning Code = "DaemonNotRunning"
+	CodePreflightFailed  Code = "PreflightFailed"
+	CodeCancelled        Code = "Cancelled"
+)
+
+// CodedError attaches a Code to an error
+type CodedError struct {
+	Code Code
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// WithCode returns err with the given code, or nil when err is nil
+func WithCode(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+// CodeOf returns the code of err, the outermost CodedError wins over the
+// well-known errors of this package
+func CodeOf(err error) Code {
+	var coded *CodedError
+	var preflightErr *PreflightError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &coded):
+		return coded.Code
+	case errors.Is(err, VMNotExist):
+		return CodeVMNotExist
+	case errors.Is(err, DaemonNotRunning):
+		return CodeDaemonNotRunning
+	case errors.As(err, &preflightErr):
+		return CodePreflightFailed
+	case errors.Is(err, context.Canceled):
+		return CodeCancelled
+	default:
+		return CodeInternal
+	}
+}
@@ -0,0 +1,20 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOf(t *testing.T) {
+	assert.Equal(t, Code(""), CodeOf(nil))
+	assert.Equal(t, CodeInternal, CodeOf(errors.New("failed")))
+	assert.Equal(t, CodeVMNotExist, CodeOf(fmt.Errorf("cannot stop: %w", VMNotExist)))
+	assert.Equal(t, CodePreflightFailed, CodeOf(&PreflightError{Err: errors.New("not enough memory")}))
+	assert.Equal(t, CodeCancelled, CodeOf(fmt.Errorf("start interrupted: %w", context.Canceled)))
+	assert.Equal(t, CodeNotFound, CodeOf(fmt.Errorf("wrapped: %w", WithCode(CodeNotFound, VMNotExist))))
+	assert.NoError(t, WithCode(CodeNotFound, nil))
+}
@@ -2,6 +2,7 @@ package gpg
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -15,27 +16,122 @@ import (
 )
 
 func Verify(filePath, signatureFilePath string) error {
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewBufferString(constants.CrcOrgPublicKey))
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return VerifyWithKeyring(keyring, filePath, signatureFilePath)
+}
+
+// VerifyWithKeyring checks the armored detached signature of filePath against
+// the keys of keyring
+func VerifyWithKeyring(keyring openpgp.EntityList, filePath, signatureFilePath string) error {
 	data, err := os.Open(filePath)
 	if err != nil {
 		return err
 	}
 	defer data.Close()
 
+	_, err = CheckSignature(keyring, data, signatureFilePath)
+	return err
+}
+
+// CheckSignature checks the armored detached signature of data against the
+// keys of keyring and returns the fingerprint of the signing key
+func CheckSignature(keyring openpgp.EntityList, data io.Reader, signatureFilePath string) (string, error) {
 	signature, err := os.Open(signatureFilePath)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer signature.Close()
 
-	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewBufferString(constants.CrcOrgPublicKey))
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, data, signature, nil)
 	if err != nil {
-		return fmt.Errorf("failed to parse public key: %w", err)
+		return "", fmt.Errorf("failed to check signature: %w", err)
+	}
+	logging.Debugf("Got valid signature from key id: %s", signer.PrimaryKey.KeyIdString())
+	return Fingerprint(signer), nil
+}
+
+// Fingerprint returns the hexadecimal fingerprint of the primary key of entity
+func Fingerprint(entity *openpgp.Entity) string {
+	return fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)
+}
+
+// ReadKeyring reads the armored public keys of the files
+func ReadKeyring(paths []string) (openpgp.EntityList, error) {
+	var keyring openpgp.EntityList
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+		}
+		keyring = append(keyring, keys...)
+	}
+	return keyring, nil
+}
+
+// SigningKey is a private key ready to sign files
+type SigningKey struct {
+	entity *openpgp.Entity
+}
+
+// ErrPassphraseRequired is returned by ReadSigningKey when the key is
+// encrypted and no passphrase is given
+var ErrPassphraseRequired = errors.New("the private key is encrypted, a passphrase is required")
+
+// ReadSigningKey reads the first private key of the armored key file, the
+// passphrase function is only called when the key is encrypted and can be nil
+func ReadSigningKey(path string, passphrase func() ([]byte, error)) (*SigningKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	entity := keys[0]
+	if entity.PrivateKey == nil {
+		return nil, fmt.Errorf("%s does not contain a private key", path)
+	}
+	if entity.PrivateKey.Encrypted {
+		if passphrase == nil {
+			return nil, ErrPassphraseRequired
+		}
+		secret, err := passphrase()
+		if err != nil {
+			return nil, err
+		}
+		if err := entity.DecryptPrivateKeys(secret); err != nil {
+			return nil, fmt.Errorf("failed to decrypt private key %s: %w", path, err)
+		}
 	}
+	return &SigningKey{entity: entity}, nil
+}
 
-	if _, err = openpgp.CheckArmoredDetachedSignature(keyring, data, signature, nil); err != nil {
-		return fmt.Errorf("failed to check signature: %w", err)
+// Sign writes the armored detached signature of filePath to signatureFilePath
+func (key *SigningKey) Sign(filePath, signatureFilePath string) (err error) {
+	data, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer data.Close()
+
+	signature, err := os.Create(signatureFilePath)
+	if err != nil {
+		return err
 	}
-	return nil
+	defer func() {
+		if cerr := signature.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return openpgp.ArmoredDetachSign(signature, key.entity, data, nil)
 }
 
 func GetVerifiedClearsignedMsgV3(pubkey, clearSignedMsg string) (string, error) {
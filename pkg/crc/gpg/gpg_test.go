@@ -1,10 +1,17 @@
 package gpg
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -43,3 +50,69 @@ func TestVerify(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedMsg, msg)
 }
+
+// writeTestKeys writes the armored private and public keys of a new key to
+// dir, the private key is encrypted when passphrase is not empty
+func writeTestKeys(t *testing.T, dir, passphrase string) (string, string) {
+	entity, err := openpgp.NewEntity("CRC test", "", "crc@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	require.NoError(t, err)
+
+	public := new(bytes.Buffer)
+	w, err := armor.Encode(public, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	if passphrase != "" {
+		require.NoError(t, entity.EncryptPrivateKeys([]byte(passphrase), nil))
+	}
+	private := new(bytes.Buffer)
+	w, err = armor.Encode(private, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivateWithoutSigning(w, nil))
+	require.NoError(t, w.Close())
+
+	privateKeyPath := filepath.Join(dir, "private.asc")
+	publicKeyPath := filepath.Join(dir, "public.asc")
+	require.NoError(t, os.WriteFile(privateKeyPath, private.Bytes(), 0600))
+	require.NoError(t, os.WriteFile(publicKeyPath, public.Bytes(), 0600))
+	return privateKeyPath, publicKeyPath
+}
+
+func TestSignAndVerifyWithKeyring(t *testing.T) {
+	dir := t.TempDir()
+	privateKeyPath, publicKeyPath := writeTestKeys(t, dir, "")
+	_, otherPublicKeyPath := writeTestKeys(t, t.TempDir(), "")
+
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, []byte("content"), 0600))
+	key, err := ReadSigningKey(privateKeyPath, nil)
+	require.NoError(t, err)
+	require.NoError(t, key.Sign(file, file+".sig"))
+
+	keyring, err := ReadKeyring([]string{otherPublicKeyPath, publicKeyPath})
+	require.NoError(t, err)
+	assert.NoError(t, VerifyWithKeyring(keyring, file, file+".sig"))
+
+	otherKeyring, err := ReadKeyring([]string{otherPublicKeyPath})
+	require.NoError(t, err)
+	assert.ErrorContains(t, VerifyWithKeyring(otherKeyring, file, file+".sig"), "failed to check signature")
+
+	require.NoError(t, os.WriteFile(file, []byte("tampered"), 0600))
+	assert.ErrorContains(t, VerifyWithKeyring(keyring, file, file+".sig"), "failed to check signature")
+}
+
+func TestReadEncryptedSigningKey(t *testing.T) {
+	privateKeyPath, publicKeyPath := writeTestKeys(t, t.TempDir(), "secret")
+
+	_, err := ReadSigningKey(privateKeyPath, nil)
+	assert.ErrorIs(t, err, ErrPassphraseRequired)
+	_, err = ReadSigningKey(privateKeyPath, func() ([]byte, error) { return []byte("wrong"), nil })
+	assert.ErrorContains(t, err, "failed to decrypt private key")
+	key, err := ReadSigningKey(privateKeyPath, func() ([]byte, error) { return []byte("secret"), nil })
+	assert.NoError(t, err)
+	assert.NotNil(t, key)
+
+	_, err = ReadSigningKey(publicKeyPath, nil)
+	assert.EqualError(t, err, publicKeyPath+" does not contain a private key")
+}
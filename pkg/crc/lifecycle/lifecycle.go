@@ -0,0 +1,85 @@
+package lifecycle
+
+import (
+	"sync"
+	"time"
+)
+
+type Phase string
+
+const (
+	BundleDownload    Phase = "BundleDownload"
+	BundleExtraction  Phase = "BundleExtraction"
+	VMStarting        Phase = "VMStarting"
+	SSHReachable      Phase = "SSHReachable"
+	KubeletStarted    Phase = "KubeletStarted"
+	OperatorsProgress Phase = "OperatorsProgress"
+	ClusterStable     Phase = "ClusterStable"
+	Provisioned       Phase = "Provisioned"
+	Started           Phase = "Started"
+	Stopping          Phase = "Stopping"
+	Stopped           Phase = "Stopped"
+	Deleting          Phase = "Deleting"
+	Deleted           Phase = "Deleted"
+	// Failed ends a start, stop or delete operation which returned an
+	// error, the error is in the message of the event
+	Failed Phase = "Failed"
+)
+
+// Event describes a step of a lifecycle operation. Current and Total are
+// only set for the phases reporting a progress, bytes for BundleDownload and
+// available operators for OperatorsProgress.
+type Event struct {
+	Phase     Phase     `json:"phase"`
+	Instance  string    `json:"instance,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Current   int64     `json:"current,omitempty"`
+	Total     int64     `json:"total,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type Listener func(Event)
+
+var (
+	listenersLock sync.RWMutex
+	listeners     = map[int]Listener{}
+	nextID        int
+)
+
+// Subscribe registers listener to receive all the events published from now
+// on. The returned function unregisters it.
+func Subscribe(listener Listener) func() {
+	listenersLock.Lock()
+	defer listenersLock.Unlock()
+
+	id := nextID
+	nextID++
+	listeners[id] = listener
+	return func() {
+		listenersLock.Lock()
+		defer listenersLock.Unlock()
+		delete(listeners, id)
+	}
+}
+
+// Publish sends event to all the registered listeners
+func Publish(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	listenersLock.RLock()
+	defer listenersLock.RUnlock()
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// PublishProgress is a shortcut to publish an event reporting a progress
+func PublishProgress(phase Phase, current, total int64) {
+	Publish(Event{
+		Phase:   phase,
+		Current: current,
+		Total:   total,
+	})
+}
@@ -0,0 +1,27 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublish(t *testing.T) {
+	var received []Event
+	unsubscribe := Subscribe(func(event Event) {
+		received = append(received, event)
+	})
+
+	Publish(Event{Phase: VMStarting, Instance: "crc"})
+	PublishProgress(OperatorsProgress, 3, 30)
+	unsubscribe()
+	Publish(Event{Phase: Started})
+
+	assert.Len(t, received, 2)
+	assert.Equal(t, VMStarting, received[0].Phase)
+	assert.Equal(t, "crc", received[0].Instance)
+	assert.False(t, received[0].Timestamp.IsZero())
+	assert.Equal(t, OperatorsProgress, received[1].Phase)
+	assert.Equal(t, int64(3), received[1].Current)
+	assert.Equal(t, int64(30), received[1].Total)
+}
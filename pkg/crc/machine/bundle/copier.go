@@ -39,6 +39,8 @@ func NewCopier(srcBundle *CrcBundleInfo, basePath string, customBundleName strin
 
 	copier.copiedBundle.Name = customBundleName
 	copier.copiedBundle.Type = getType(srcBundle.Type)
+	// the copied disk image contains the whole disk unless SetBaseBundle is used
+	copier.copiedBundle.BaseBundle = nil
 	copier.srcBundle = srcBundle
 	copier.copiedBundle.cachedPath = bundlePath
 
@@ -102,9 +104,27 @@ func (copier *Copier) SetDiskImage(path string, format string) error {
 	return nil
 }
 
-func (copier *Copier) GenerateBundle(bundleName string) error {
+// SetBaseBundle makes the copied bundle a delta bundle of the source bundle.
+// It returns the path of the disk image of the source bundle relative to the
+// directory of the copied disk image, which is the backing file of the qcow2
+// overlay, both bundles are extracted in the same cache directory.
+func (copier *Copier) SetBaseBundle() (string, error) {
+	if format := copier.srcBundle.GetDiskImageFormat(); format != "qcow2" {
+		return "", fmt.Errorf("cannot generate a delta bundle from a %s disk image", format)
+	}
+	baseName := filepath.Base(copier.srcBundle.cachedPath)
+	copier.copiedBundle.BaseBundle = &BaseBundle{
+		Name:              baseName,
+		DiskImageChecksum: copier.srcBundle.Storage.DiskImages[0].Checksum,
+	}
+	return filepath.Join("..", baseName, copier.srcBundle.Storage.DiskImages[0].Name), nil
+}
+
+// GenerateBundle writes the bundle archive to bundlePath, compressed with
+// the compression options, and returns its sha256sum
+func (copier *Copier) GenerateBundle(bundlePath string, compression compress.Options) (string, error) {
 	if err := copier.copiedBundle.verify(); err != nil {
-		return err
+		return "", err
 	}
 
 	// update bundle info
@@ -113,15 +133,15 @@ func (copier *Copier) GenerateBundle(bundleName string) error {
 	// Create the metadata json for custom bundle
 	bundleContent, err := json.MarshalIndent(copier.copiedBundle, "", " ")
 	if err != nil {
-		return err
+		return "", err
 	}
 	err = os.WriteFile(copier.resolvePath("crc-bundle-info.json"), bundleContent, 0600)
 	if err != nil {
-		return fmt.Errorf("error copying bundle metadata  %w", err)
+		return "", fmt.Errorf("error copying bundle metadata  %w", err)
 	}
 
 	logging.Infof("Compressing %s...", GetBundleNameWithoutExtension(copier.copiedBundle.Name))
-	return compress.Compress(copier.copiedBundle.cachedPath, fmt.Sprintf("%s%s", bundleName, bundleExtension))
+	return compress.CompressWithOptions(copier.copiedBundle.cachedPath, bundlePath, compression)
 }
 
 func sha256sum(path string) (string, error) {
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/crc-org/crc/v2/pkg/compress"
 	crcos "github.com/crc-org/crc/v2/pkg/os"
 	"github.com/stretchr/testify/assert"
 )
@@ -35,8 +36,33 @@ func TestGenerateBundle(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, copier.SetDiskImage(copier.copiedBundle.GetDiskImagePath(), "qcow2"))
 
-	assert.NoError(t, copier.GenerateBundle(customBundleName))
-	defer os.Remove(fmt.Sprintf("%s%s", customBundleName, bundleExtension))
+	bundlePath := filepath.Join(t.TempDir(), fmt.Sprintf("%s%s", customBundleName, bundleExtension))
+	sha256sum, err := copier.GenerateBundle(bundlePath, compress.Options{Level: 1})
+	assert.NoError(t, err)
+	assert.FileExists(t, bundlePath)
+	expectedSha256sum, err := CalculateBundleSha256Sum(bundlePath)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSha256sum, sha256sum)
+}
+
+func TestSetBaseBundle(t *testing.T) {
+	var b CrcBundleInfo
+	assert.NoError(t, json.Unmarshal([]byte(jsonForBundle("crc_libvirt_4.7.1")), &b))
+	b.cachedPath = filepath.Join(t.TempDir(), "crc_libvirt_4.7.1")
+	b.BaseBundle = &BaseBundle{Name: "crc_libvirt_4.7.0", DiskImageChecksum: "0000"}
+
+	copier, err := NewCopier(&b, t.TempDir(), "custom_bundle")
+	assert.NoError(t, err)
+	assert.Nil(t, copier.copiedBundle.BaseBundle)
+
+	backingFile, err := copier.SetBaseBundle()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("..", "crc_libvirt_4.7.1", "crc.qcow2"), backingFile)
+	assert.Equal(t, &BaseBundle{
+		Name:              "crc_libvirt_4.7.1",
+		DiskImageChecksum: "245a0e5acd4f09000a9a5f37d731082ed1cf3fdcad1b5320cbe9b153c9fd82a4",
+	}, copier.copiedBundle.BaseBundle)
+	assert.True(t, copier.copiedBundle.IsDelta())
 }
 
 func TestGetType(t *testing.T) {
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +19,7 @@ import (
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/crc-org/crc/v2/pkg/crc/gpg"
 	"github.com/crc-org/crc/v2/pkg/crc/image"
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	crcPreset "github.com/crc-org/crc/v2/pkg/crc/preset"
 	"github.com/crc-org/crc/v2/pkg/download"
@@ -33,10 +36,20 @@ type CrcBundleInfo struct {
 	Nodes       []Node      `json:"nodes"`
 	Storage     Storage     `json:"storage"`
 	DriverInfo  DriverInfo  `json:"driverInfo"`
+	// BaseBundle is set for the delta bundles, their disk image is a qcow2
+	// overlay of the disk image of the base bundle
+	BaseBundle *BaseBundle `json:"baseBundle,omitempty"`
 
 	cachedPath string
 }
 
+type BaseBundle struct {
+	// Name of the base bundle in the cache directory
+	Name string `json:"name"`
+	// DiskImageChecksum is the sha256sum of the disk image of the base bundle
+	DiskImageChecksum string `json:"diskImageSha256sum"`
+}
+
 type BuildInfo struct {
 	BuildTime                 string `json:"buildTime"`
 	OpenshiftInstallerVersion string `json:"openshiftInstallerVersion"`
@@ -183,6 +196,25 @@ func (bundle *CrcBundleInfo) GetBundleBuildTime() (time.Time, error) {
 	return time.Parse(time.RFC3339, strings.TrimSpace(bundle.BuildInfo.BuildTime))
 }
 
+// GetSize returns the disk space used by the extracted bundle
+func (bundle *CrcBundleInfo) GetSize() (int64, error) {
+	var size int64
+	err := filepath.WalkDir(bundle.cachedPath, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
 func (bundle *CrcBundleInfo) GetVersion() string {
 	return bundle.ClusterInfo.OpenShiftVersion.String()
 }
@@ -203,6 +235,10 @@ func (bundle *CrcBundleInfo) GetBundleType() crcPreset.Preset {
 	return crcPreset.ParsePreset(bundleType)
 }
 
+func (bundle *CrcBundleInfo) IsDelta() bool {
+	return bundle.BaseBundle != nil
+}
+
 func (bundle *CrcBundleInfo) IsOpenShift() bool {
 	preset := bundle.GetBundleType()
 	return preset == crcPreset.OpenShift || preset == crcPreset.OKD
@@ -261,10 +297,22 @@ func GetBundleNameWithExtension(bundleName string) string {
 	return fmt.Sprintf("%s%s", bundleName, bundleExtension)
 }
 
+var customBundleSuffixRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.-]*$`)
+
 func GetCustomBundleName(bundleFilename string) string {
+	name, _ := GetCustomBundleNameWithSuffix(bundleFilename, strconv.FormatInt(time.Now().Unix(), 10))
+	return name
+}
+
+// GetCustomBundleNameWithSuffix returns the filename of a bundle generated
+// from the bundleFilename bundle, with suffix instead of its timestamp
+func GetCustomBundleNameWithSuffix(bundleFilename string, suffix string) (string, error) {
+	if !customBundleSuffixRegex.MatchString(suffix) {
+		return "", fmt.Errorf("invalid bundle name '%s', it can only contain letters, digits, '.' and '-'", suffix)
+	}
 	re := regexp.MustCompile(`(?:_[0-9]+)*.crcbundle$`)
 	baseName := re.ReplaceAllLiteralString(bundleFilename, "")
-	return fmt.Sprintf("%s_%d%s", baseName, time.Now().Unix(), bundleExtension)
+	return fmt.Sprintf("%s_%s%s", baseName, suffix, bundleExtension), nil
 }
 
 func GetBundleNameFromURI(bundleURI string) (string, error) {
@@ -326,20 +374,33 @@ func GetBundleInfoFromName(bundleName string) (*FilenameInfo, error) {
 	return &filenameInfo, nil
 }
 
-func getBundleDownloadInfo(preset crcPreset.Preset) (*download.RemoteFile, error) {
-	sha256sum, err := getDefaultBundleVerifiedHash(preset)
+func getBundleDownloadInfo(preset crcPreset.Preset, mirror string) (*download.RemoteFile, error) {
+	sha256sum, err := getDefaultBundleVerifiedHash(preset, mirror)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get verified hash for default bundle: %w", err)
 	}
-	downloadInfo := download.NewRemoteFile(constants.GetDefaultBundleDownloadURL(preset), sha256sum)
+	downloadInfo := download.NewRemoteFile(constants.GetBundleDownloadURL(mirror, preset), sha256sum)
 	return downloadInfo, nil
 }
 
-// getDefaultBundleVerifiedHash downloads the sha256sum.txt.sig file from mirror.openshift.com
+// getDefaultBundleVerifiedHash downloads the sha256sum.txt.sig file from the mirror
 // then verifies it is signed by redhat release key, if signature is valid it returns the hash
 // for the default bundle of preset from the file
-func getDefaultBundleVerifiedHash(preset crcPreset.Preset) (string, error) {
-	return getVerifiedHash(constants.GetDefaultBundleSignedHashURL(preset), constants.GetDefaultBundle(preset))
+func getDefaultBundleVerifiedHash(preset crcPreset.Preset, mirror string) (string, error) {
+	return getVerifiedHash(constants.GetBundleSignedHashURL(mirror, preset), constants.GetDefaultBundle(preset))
+}
+
+// bundleMirrors returns the user configured mirrors followed by
+// mirror.openshift.com, without duplicates
+func bundleMirrors(mirrors []string) []string {
+	var all []string
+	for _, mirror := range slices.Concat(mirrors, []string{constants.DefaultBundleMirror}) {
+		mirror = strings.TrimSuffix(strings.TrimSpace(mirror), "/")
+		if mirror != "" && !slices.Contains(all, mirror) {
+			all = append(all, mirror)
+		}
+	}
+	return all
 }
 
 func getVerifiedHash(url string, file string) (string, error) {
@@ -370,15 +431,34 @@ func getVerifiedHash(url string, file string) (string, error) {
 	return "", fmt.Errorf("%s hash is missing or shasums are malformed", file)
 }
 
-func downloadDefault(ctx context.Context, preset crcPreset.Preset) (string, error) {
-	downloadInfo, err := getBundleDownloadInfo(preset)
-	if err != nil {
-		return "", err
+func downloadDefault(ctx context.Context, preset crcPreset.Preset, mirrors []string) (string, error) {
+	var err error
+	for _, mirror := range bundleMirrors(mirrors) {
+		var downloadInfo *download.RemoteFile
+		downloadInfo, err = getBundleDownloadInfo(preset, mirror)
+		if err == nil {
+			var bundlePath string
+			bundlePath, err = downloadInfo.Download(ctx, constants.GetDefaultBundlePath(preset), 0664)
+			if err == nil {
+				return bundlePath, nil
+			}
+		}
+		if ctx.Err() != nil {
+			return "", err
+		}
+		logging.Warnf("Unable to download bundle from %s: %v", mirror, err)
 	}
-	return downloadInfo.Download(ctx, constants.GetDefaultBundlePath(preset), 0664)
+	return "", err
 }
 
-func Download(ctx context.Context, preset crcPreset.Preset, bundleURI string, enableBundleQuayFallback bool) (string, error) {
+// Download fetches the bundle designated by bundleURI and returns its local
+// path. The default bundle is looked up on mirrors first, then on
+// mirror.openshift.com. The signature of the other bundles is checked with
+// signaturePolicy.
+func Download(ctx context.Context, preset crcPreset.Preset, bundleURI string, enableBundleQuayFallback bool, mirrors []string, signaturePolicy *SignaturePolicy) (string, error) {
+	ctx = download.WithProgressCallback(ctx, func(current, total int64) {
+		lifecycle.PublishProgress(lifecycle.BundleDownload, current, total)
+	})
 	// If we are asked to download
 	// ~/.crc/cache/crc_podman_libvirt_4.1.1.crcbundle, this means we want
 	// are downloading the default bundle for this release. This uses a
@@ -387,7 +467,7 @@ func Download(ctx context.Context, preset crcPreset.Preset, bundleURI string, en
 	if bundleURI == constants.GetDefaultBundlePath(preset) {
 		switch preset {
 		case crcPreset.OpenShift, crcPreset.Microshift:
-			downloadedBundlePath, err := downloadDefault(ctx, preset)
+			downloadedBundlePath, err := downloadDefault(ctx, preset, mirrors)
 			if err != nil && enableBundleQuayFallback {
 				logging.Info("Unable to download bundle from mirror, falling back to quay")
 				return image.PullBundle(ctx, constants.GetDefaultBundleImageRegistry(preset))
@@ -401,14 +481,47 @@ func Download(ctx context.Context, preset crcPreset.Preset, bundleURI string, en
 	}
 	switch {
 	case strings.HasPrefix(bundleURI, "http://"), strings.HasPrefix(bundleURI, "https://"):
-		return download.Download(ctx, bundleURI, constants.MachineCacheDir, 0644, nil)
+		bundlePath, err := download.Download(ctx, bundleURI, constants.MachineCacheDir, 0644, nil)
+		if err != nil || signaturePolicy == nil {
+			return bundlePath, err
+		}
+		if err := downloadSignature(bundleURI, bundlePath, signaturePolicy); err != nil {
+			_ = os.Remove(bundlePath)
+			_ = os.Remove(bundlePath + SignatureExtension)
+			return "", err
+		}
+		return bundlePath, nil
 	case strings.HasPrefix(bundleURI, "docker://"):
+		if signaturePolicy != nil {
+			return "", fmt.Errorf("cannot verify the signature of bundle %s, only bundle files can be signed", bundleURI)
+		}
 		return image.PullBundle(ctx, bundleURI)
 	}
 	// the `bundleURI` parameter turned out to be a local path
+	if err := signaturePolicy.Verify(bundleURI); err != nil {
+		return "", err
+	}
 	return bundleURI, nil
 }
 
+// downloadSignature fetches the signature published next to the bundle and
+// checks the downloaded bundle with it
+func downloadSignature(bundleURI, bundlePath string, signaturePolicy *SignaturePolicy) error {
+	res, err := download.InMemory(bundleURI + SignatureExtension)
+	if err != nil {
+		return fmt.Errorf("cannot download the signature of bundle %s: %w", filepath.Base(bundlePath), err)
+	}
+	defer res.Close()
+	signature, err := io.ReadAll(res)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(bundlePath+SignatureExtension, signature, 0644); err != nil { // #nosec G306
+		return err
+	}
+	return signaturePolicy.Verify(bundlePath)
+}
+
 type Version struct {
 	CrcVersion       *semver.Version `json:"crcVersion"`
 	GitSha           string          `json:"gitSha"`
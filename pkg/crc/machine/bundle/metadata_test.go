@@ -167,6 +167,17 @@ func TestCustomBundleName(t *testing.T) {
 	checkBundleName(t, customBundleName)
 }
 
+func TestCustomBundleNameWithSuffix(t *testing.T) {
+	name, err := GetCustomBundleNameWithSuffix("crc_libvirt_4.7.1_amd64_1612345678.crcbundle", "my-app.v2")
+	require.NoError(t, err)
+	require.Equal(t, "crc_libvirt_4.7.1_amd64_my-app.v2.crcbundle", name)
+
+	for _, suffix := range []string{"", "-app", "my app", "my_app", "../app"} {
+		_, err := GetCustomBundleNameWithSuffix("crc_libvirt_4.7.1_amd64.crcbundle", suffix)
+		require.Error(t, err, suffix)
+	}
+}
+
 func TestGetBundleType(t *testing.T) {
 	var bundle CrcBundleInfo
 	bundle.Type = "okd"
@@ -207,6 +218,12 @@ func TestVerifiedHash(t *testing.T) {
 	require.ErrorContains(t, err, "signature made by unknown entity")
 }
 
+func TestBundleMirrors(t *testing.T) {
+	assert.Equal(t, []string{constants.DefaultBundleMirror}, bundleMirrors(nil))
+	assert.Equal(t, []string{"https://mirror.example.com/bundles", constants.DefaultBundleMirror},
+		bundleMirrors([]string{"https://mirror.example.com/bundles/", " https://mirror.example.com/bundles", constants.DefaultBundleMirror + "/"}))
+}
+
 func testDataURI(t *testing.T, sha256sum string) string {
 	absPath, err := filepath.Abs(filepath.Join("testdata", sha256sum))
 	require.NoError(t, err)
@@ -10,12 +10,15 @@ import (
 	"runtime"
 	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	crcerrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	crcPreset "github.com/crc-org/crc/v2/pkg/crc/preset"
 	"github.com/crc-org/crc/v2/pkg/extract"
 	crcos "github.com/crc-org/crc/v2/pkg/os"
 	"github.com/pkg/errors"
@@ -87,11 +90,21 @@ func checkVersion(bundleInfo CrcBundleInfo) error {
 	return nil
 }
 
-func (repo *Repository) Use(bundleName string) (*CrcBundleInfo, error) {
+// Use returns the extracted bundle, the signature policy is enforced on
+// it and its base bundles
+func (repo *Repository) Use(bundleName string, signaturePolicy *SignaturePolicy) (*CrcBundleInfo, error) {
 	bundleInfo, err := repo.Get(bundleName)
 	if err != nil {
 		return nil, err
 	}
+	if err := signaturePolicy.verifyExtracted(bundleInfo); err != nil {
+		return nil, err
+	}
+	// the disk images of the base bundles were checked when the delta bundle
+	// was extracted, their metadata is enough here
+	if err := repo.verifyBaseBundles(bundleInfo, false, signaturePolicy); err != nil {
+		return nil, err
+	}
 	if err := bundleInfo.createSymlinkOrCopyOpenShiftClient(repo.OcBinDir); err != nil {
 		return nil, err
 	}
@@ -121,9 +134,17 @@ func (bundle *CrcBundleInfo) createSymlinkOrCopyOpenShiftClient(ocBinDir string)
 	return bundle.copyExecutableFromBundle(ocBinDir, OcExecutable, constants.OcExecutableName)
 }
 
-func (repo *Repository) Extract(ctx context.Context, path string) error {
+// Extract uncompresses the bundle archive in the cache directory. The
+// signature policy is enforced on the archive and on the base bundles, the
+// verification is recorded in the bundle directory for Use.
+func (repo *Repository) Extract(ctx context.Context, path string, signaturePolicy *SignaturePolicy) error {
 	bundleName := filepath.Base(path)
 
+	record, err := signaturePolicy.verifyArchive(path)
+	if err != nil {
+		return err
+	}
+
 	tmpDir := filepath.Join(repo.CacheDir, "tmp-extract")
 	_ = os.RemoveAll(tmpDir) // clean up before using it
 	defer func() {
@@ -137,7 +158,7 @@ func (repo *Repository) Extract(ctx context.Context, path string) error {
 	bundleBaseDir := GetBundleNameWithoutExtension(bundleName)
 	bundleDir := filepath.Join(repo.CacheDir, bundleBaseDir)
 	_ = os.RemoveAll(bundleDir)
-	err := crcerrors.Retry(context.Background(), time.Minute, func() error {
+	err = crcerrors.Retry(context.Background(), time.Minute, func() error {
 		if err := os.Rename(filepath.Join(tmpDir, bundleBaseDir), bundleDir); err != nil {
 			return &crcerrors.RetriableError{Err: err}
 		}
@@ -147,7 +168,70 @@ func (repo *Repository) Extract(ctx context.Context, path string) error {
 		return err
 	}
 
-	return os.Chmod(bundleDir, 0755)
+	if err := os.Chmod(bundleDir, 0755); err != nil {
+		return err
+	}
+	// only the records written after a verification are trusted, not the
+	// ones shipped in the archive
+	if err := os.Remove(filepath.Join(bundleDir, signatureRecordFilename)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if record != nil {
+		if err := writeSignatureRecord(bundleDir, record); err != nil {
+			return err
+		}
+	}
+
+	bundleInfo, err := repo.Get(bundleBaseDir)
+	if err != nil {
+		return err
+	}
+	if err := repo.verifyBaseBundles(bundleInfo, true, signaturePolicy); err != nil {
+		// a delta bundle cannot be used without its base bundles
+		_ = os.RemoveAll(bundleDir)
+		return err
+	}
+	return nil
+}
+
+// verifyBaseBundles checks that the base bundles of a delta bundle are in the
+// cache directory and that their disk images are the ones the delta bundles
+// were generated from. The sha256sum of the disk images is computed when
+// computeChecksum is set, otherwise the one from their metadata is used. The
+// signature policy is enforced on each base bundle.
+func (repo *Repository) verifyBaseBundles(bundleInfo *CrcBundleInfo, computeChecksum bool, signaturePolicy *SignaturePolicy) error {
+	if bundleInfo.IsDelta() && runtime.GOOS != "linux" {
+		return fmt.Errorf("delta bundle %s can only be used on linux", bundleInfo.GetBundleName())
+	}
+	seen := map[string]bool{}
+	for bundleInfo.IsDelta() {
+		base := bundleInfo.BaseBundle
+		if seen[base.Name] {
+			return fmt.Errorf("bundle %s is its own base bundle", base.Name)
+		}
+		seen[base.Name] = true
+
+		baseInfo, err := repo.Get(base.Name)
+		if err != nil {
+			return errors.Wrapf(err, "cannot use the base bundle %s of %s, download or extract it first", base.Name, bundleInfo.GetBundleName())
+		}
+		if err := signaturePolicy.verifyExtracted(baseInfo); err != nil {
+			return errors.Wrapf(err, "cannot use the base bundle %s of %s", base.Name, bundleInfo.GetBundleName())
+		}
+		checksum := baseInfo.Storage.DiskImages[0].Checksum
+		if computeChecksum {
+			checksum, err = sha256sum(baseInfo.GetDiskImagePath())
+			if err != nil {
+				return err
+			}
+		}
+		if checksum != base.DiskImageChecksum {
+			return fmt.Errorf("base bundle %s does not match %s, the sha256sum of its disk image is %s instead of %s",
+				base.Name, bundleInfo.GetBundleName(), checksum, base.DiskImageChecksum)
+		}
+		bundleInfo = baseInfo
+	}
+	return nil
 }
 
 func (repo *Repository) List() ([]CrcBundleInfo, error) {
@@ -173,6 +257,90 @@ func (repo *Repository) List() ([]CrcBundleInfo, error) {
 	return ret, nil
 }
 
+// Verify checks the sha256sum of all the disk images and files of the
+// bundle against the values recorded in its metadata
+func (repo *Repository) Verify(bundleName string) error {
+	bundleInfo, err := repo.Get(bundleName)
+	if err != nil {
+		return err
+	}
+	files := []File{}
+	for _, diskImage := range bundleInfo.Storage.DiskImages {
+		files = append(files, diskImage.File)
+	}
+	for _, file := range bundleInfo.Storage.Files {
+		files = append(files, file.File)
+	}
+
+	var failures []string
+	for _, file := range files {
+		sum, err := sha256sum(bundleInfo.resolvePath(file.Name))
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		if sum != file.Checksum {
+			failures = append(failures, fmt.Sprintf("%s: sha256sum mismatch, expected %s, got %s", file.Name, file.Checksum, sum))
+		}
+	}
+	if len(failures) != 0 {
+		return fmt.Errorf("bundle %s is corrupted:\n%s", bundleInfo.GetBundleName(), strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// Prune removes the extracted bundles and their archives from the cache
+// directory, except for the 'keep' most recent bundles of each preset, the
+// bundles listed in 'exclude' and the base bundles of the delta bundles
+// which are kept. It returns the names of the removed bundles.
+func (repo *Repository) Prune(keep int, exclude []string) ([]string, error) {
+	bundles, err := repo.List()
+	if err != nil {
+		return nil, err
+	}
+	kept := map[string]bool{}
+	for _, bundleName := range exclude {
+		kept[GetBundleNameWithoutExtension(bundleName)] = true
+	}
+	keptPerPreset := map[crcPreset.Preset]int{}
+	for _, bundleInfo := range bundles {
+		bundleName := filepath.Base(bundleInfo.cachedPath)
+		if kept[bundleName] || keptPerPreset[bundleInfo.GetBundleType()] >= keep {
+			continue
+		}
+		keptPerPreset[bundleInfo.GetBundleType()]++
+		kept[bundleName] = true
+	}
+	// the delta bundles which are kept cannot be used without their base
+	// bundles, which can be deltas too
+	for protected := true; protected; {
+		protected = false
+		for _, bundleInfo := range bundles {
+			if bundleInfo.IsDelta() && kept[filepath.Base(bundleInfo.cachedPath)] && !kept[bundleInfo.BaseBundle.Name] {
+				kept[bundleInfo.BaseBundle.Name] = true
+				protected = true
+			}
+		}
+	}
+	var removed []string
+	for _, bundleInfo := range bundles {
+		bundleName := filepath.Base(bundleInfo.cachedPath)
+		if kept[bundleName] {
+			continue
+		}
+		logging.Debugf("Removing bundle %s", bundleName)
+		if err := os.RemoveAll(bundleInfo.cachedPath); err != nil {
+			return removed, err
+		}
+		archive := filepath.Join(repo.CacheDir, GetBundleNameWithExtension(bundleName))
+		if err := os.Remove(archive); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed = append(removed, bundleName)
+	}
+	return removed, nil
+}
+
 func (repo *Repository) CalculateBundleSha256Sum(bundlePath string) (string, error) {
 	return sha256sum(bundlePath)
 }
@@ -190,12 +358,16 @@ func CalculateBundleSha256Sum(bundlePath string) (string, error) {
 	return defaultRepo.CalculateBundleSha256Sum(bundlePath)
 }
 
-func Use(bundleName string) (*CrcBundleInfo, error) {
-	return defaultRepo.Use(bundleName)
+func Use(bundleName string, signaturePolicy *SignaturePolicy) (*CrcBundleInfo, error) {
+	return defaultRepo.Use(bundleName, signaturePolicy)
 }
 
-func Extract(ctx context.Context, path string) (*CrcBundleInfo, error) {
-	if err := defaultRepo.Extract(ctx, path); err != nil {
+func Extract(ctx context.Context, path string, signaturePolicy *SignaturePolicy) (*CrcBundleInfo, error) {
+	lifecycle.Publish(lifecycle.Event{
+		Phase:   lifecycle.BundleExtraction,
+		Message: fmt.Sprintf("Extracting bundle %s", filepath.Base(path)),
+	})
+	if err := defaultRepo.Extract(ctx, path, signaturePolicy); err != nil {
 		return nil, err
 	}
 	return defaultRepo.Get(filepath.Base(path))
@@ -204,3 +376,11 @@ func Extract(ctx context.Context, path string) (*CrcBundleInfo, error) {
 func List() ([]CrcBundleInfo, error) {
 	return defaultRepo.List()
 }
+
+func Verify(bundleName string) error {
+	return defaultRepo.Verify(bundleName)
+}
+
+func Prune(keep int, exclude []string) ([]string, error) {
+	return defaultRepo.Prune(keep, exclude)
+}
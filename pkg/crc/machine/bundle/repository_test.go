@@ -2,11 +2,15 @@ package bundle
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/crc-org/crc/v2/pkg/compress"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,7 +26,7 @@ func TestUse(t *testing.T) {
 		OcBinDir: ocBinDir,
 	}
 
-	bundle, err := repo.Use("crc_libvirt_4.6.1.crcbundle")
+	bundle, err := repo.Use("crc_libvirt_4.6.1.crcbundle", nil)
 	assert.NoError(t, err)
 	assert.Equal(t, "4.6.1", bundle.ClusterInfo.OpenShiftVersion.String())
 
@@ -40,7 +44,7 @@ func TestExtract(t *testing.T) {
 		OcBinDir: ocBinDir,
 	}
 
-	assert.NoError(t, repo.Extract(context.Background(), filepath.Join("testdata", testBundle(t))))
+	assert.NoError(t, repo.Extract(context.Background(), filepath.Join("testdata", testBundle(t)), nil))
 
 	bundle, err := repo.Get(testBundle(t))
 	assert.NoError(t, err)
@@ -130,3 +134,165 @@ func createDummyBundleContent(t *testing.T, dir, name, version string) {
 	assert.NoError(t, os.WriteFile(filepath.Join(bundleDir, "id_ecdsa_crc"), []byte("id_ecdsa_crc"), 0600))
 	assert.NoError(t, os.WriteFile(filepath.Join(bundleDir, "crc.qcow2"), []byte("crc.qcow2"), 0600))
 }
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.1", "1.0")
+
+	repo := &Repository{
+		CacheDir: dir,
+	}
+
+	err := repo.Verify("crc_libvirt_4.6.1.crcbundle")
+	assert.ErrorContains(t, err, "bundle crc_libvirt_4.6.1 is corrupted")
+	assert.ErrorContains(t, err, fmt.Sprintf("%s: sha256sum mismatch", constants.OcExecutableName))
+	assert.ErrorContains(t, err, "crc.qcow2: sha256sum mismatch")
+
+	metadataPath := filepath.Join(dir, "crc_libvirt_4.6.1", metadataFilename)
+	metadata, err := os.ReadFile(metadataPath)
+	assert.NoError(t, err)
+	for oldSum, file := range map[string]string{
+		"245a0e5acd4f09000a9a5f37d731082ed1cf3fdcad1b5320cbe9b153c9fd82a4": "crc.qcow2",
+		"983f0883a6dffd601afa663d10161bfd8033fd6d45cf587a9cb22e9a681d6047": constants.OcExecutableName,
+	} {
+		newSum, err := sha256sum(filepath.Join(dir, "crc_libvirt_4.6.1", file))
+		assert.NoError(t, err)
+		metadata = []byte(strings.ReplaceAll(string(metadata), oldSum, newSum))
+	}
+	assert.NoError(t, os.WriteFile(metadataPath, metadata, 0600))
+	assert.NoError(t, repo.Verify("crc_libvirt_4.6.1.crcbundle"))
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.15", "1.0")
+	createDummyBundleContent(t, dir, "crc_libvirt_4.7.0", "1.0")
+	createDummyBundleContent(t, dir, "crc_libvirt_4.10.0", "1.0")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "crc_libvirt_4.7.0.crcbundle"), []byte("archive"), 0600))
+
+	repo := &Repository{
+		CacheDir: dir,
+	}
+
+	removed, err := repo.Prune(1, []string{"crc_libvirt_4.6.15.crcbundle"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"crc_libvirt_4.7.0"}, removed)
+	assert.NoFileExists(t, filepath.Join(dir, "crc_libvirt_4.7.0.crcbundle"))
+	assert.NoDirExists(t, filepath.Join(dir, "crc_libvirt_4.7.0"))
+	assert.DirExists(t, filepath.Join(dir, "crc_libvirt_4.6.15"))
+	assert.DirExists(t, filepath.Join(dir, "crc_libvirt_4.10.0"))
+}
+
+func createCustomBundleContent(t *testing.T, dir, name string, baseBundle *BaseBundle) {
+	createDummyBundleContent(t, dir, name, "1.0")
+	var bundleInfo CrcBundleInfo
+	assert.NoError(t, json.Unmarshal([]byte(jsonForBundle("crc_libvirt_4.6.1")), &bundleInfo))
+	bundleInfo.Name = name
+	bundleInfo.BaseBundle = baseBundle
+	metadata, err := json.Marshal(bundleInfo)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name, metadataFilename), metadata, 0600))
+}
+
+func createDeltaBundleContent(t *testing.T, dir, name, baseName, baseChecksum string) {
+	createCustomBundleContent(t, dir, name, &BaseBundle{
+		Name:              baseName,
+		DiskImageChecksum: baseChecksum,
+	})
+}
+
+func TestUseDeltaBundle(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("delta bundles are only supported on linux")
+	}
+	dir := t.TempDir()
+	repo := &Repository{
+		CacheDir: dir,
+		OcBinDir: t.TempDir(),
+	}
+
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.1", "1.0")
+	createDeltaBundleContent(t, dir, "crc_libvirt_4.6.1_team", "crc_libvirt_4.6.1", "245a0e5acd4f09000a9a5f37d731082ed1cf3fdcad1b5320cbe9b153c9fd82a4")
+	createDeltaBundleContent(t, dir, "crc_libvirt_4.6.1_app", "crc_libvirt_4.6.1_team", "245a0e5acd4f09000a9a5f37d731082ed1cf3fdcad1b5320cbe9b153c9fd82a4")
+	bundle, err := repo.Use("crc_libvirt_4.6.1_app.crcbundle", nil)
+	assert.NoError(t, err)
+	assert.True(t, bundle.IsDelta())
+
+	createDeltaBundleContent(t, dir, "crc_libvirt_4.6.1_other", "crc_libvirt_4.6.1", "0000")
+	_, err = repo.Use("crc_libvirt_4.6.1_other.crcbundle", nil)
+	assert.EqualError(t, err, "base bundle crc_libvirt_4.6.1 does not match crc_libvirt_4.6.1_other, the sha256sum of its disk image is 245a0e5acd4f09000a9a5f37d731082ed1cf3fdcad1b5320cbe9b153c9fd82a4 instead of 0000")
+
+	createDeltaBundleContent(t, dir, "crc_libvirt_4.6.1_orphan", "crc_libvirt_4.6.0", "0000")
+	_, err = repo.Use("crc_libvirt_4.6.1_orphan.crcbundle", nil)
+	assert.ErrorContains(t, err, "cannot use the base bundle crc_libvirt_4.6.0 of crc_libvirt_4.6.1_orphan, download or extract it first")
+
+	createDeltaBundleContent(t, dir, "crc_libvirt_4.6.1_loop", "crc_libvirt_4.6.1_loop", "245a0e5acd4f09000a9a5f37d731082ed1cf3fdcad1b5320cbe9b153c9fd82a4")
+	_, err = repo.Use("crc_libvirt_4.6.1_loop.crcbundle", nil)
+	assert.EqualError(t, err, "bundle crc_libvirt_4.6.1_loop is its own base bundle")
+}
+
+func TestExtractDeltaBundle(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("delta bundles are only supported on linux")
+	}
+	dir := t.TempDir()
+	repo := &Repository{
+		CacheDir: dir,
+	}
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.1", "1.0")
+	baseChecksum, err := sha256sum(filepath.Join(dir, "crc_libvirt_4.6.1", "crc.qcow2"))
+	assert.NoError(t, err)
+
+	srcDir := t.TempDir()
+	createDeltaBundleContent(t, srcDir, "crc_libvirt_4.6.1_team", "crc_libvirt_4.6.1", baseChecksum)
+	archive := filepath.Join(srcDir, "crc_libvirt_4.6.1_team.crcbundle")
+	assert.NoError(t, compress.Compress(filepath.Join(srcDir, "crc_libvirt_4.6.1_team"), archive))
+	assert.NoError(t, repo.Extract(context.Background(), archive, nil))
+	assert.DirExists(t, filepath.Join(dir, "crc_libvirt_4.6.1_team"))
+
+	// the metadata of the base bundle is right but its disk image changed
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "crc_libvirt_4.6.1", "crc.qcow2"), []byte("crc.qcow3"), 0600))
+	assert.ErrorContains(t, repo.Extract(context.Background(), archive, nil), "base bundle crc_libvirt_4.6.1 does not match crc_libvirt_4.6.1_team")
+	assert.NoDirExists(t, filepath.Join(dir, "crc_libvirt_4.6.1_team"))
+}
+
+func TestPruneKeepsBaseBundles(t *testing.T) {
+	dir := t.TempDir()
+
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.10", "1.0")
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.15", "1.0")
+	createDummyBundleContent(t, dir, "crc_libvirt_4.7.0", "1.0")
+	createDeltaBundleContent(t, dir, "crc_libvirt_4.6.1_team", "crc_libvirt_4.6.15", "0000")
+	createDeltaBundleContent(t, dir, "crc_libvirt_4.6.1_app", "crc_libvirt_4.6.1_team", "0000")
+	createDeltaBundleContent(t, dir, "crc_libvirt_4.6.1_other", "crc_libvirt_4.6.10", "0000")
+
+	repo := &Repository{
+		CacheDir: dir,
+	}
+
+	removed, err := repo.Prune(1, []string{"crc_libvirt_4.6.1_app.crcbundle"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"crc_libvirt_4.6.10", "crc_libvirt_4.6.1_other"}, removed)
+	for _, bundleName := range []string{"crc_libvirt_4.7.0", "crc_libvirt_4.6.15", "crc_libvirt_4.6.1_team", "crc_libvirt_4.6.1_app"} {
+		assert.DirExists(t, filepath.Join(dir, bundleName))
+	}
+}
+
+func TestPruneRemovesBaseBundlesOfRemovedDeltas(t *testing.T) {
+	dir := t.TempDir()
+
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.15", "1.0")
+	createDummyBundleContent(t, dir, "crc_libvirt_4.7.0", "1.0")
+	createDeltaBundleContent(t, dir, "crc_libvirt_4.6.1_team", "crc_libvirt_4.6.15", "0000")
+
+	repo := &Repository{
+		CacheDir: dir,
+	}
+
+	removed, err := repo.Prune(1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"crc_libvirt_4.6.15", "crc_libvirt_4.6.1_team"}, removed)
+	assert.DirExists(t, filepath.Join(dir, "crc_libvirt_4.7.0"))
+}
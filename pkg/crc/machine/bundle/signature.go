@@ -0,0 +1,228 @@
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/gpg"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	crcPreset "github.com/crc-org/crc/v2/pkg/crc/preset"
+)
+
+// SignatureExtension is the extension of the detached signature of a bundle,
+// which is stored next to it
+const SignatureExtension = ".sig"
+
+// SignaturePolicy lists the keys trusted to sign the custom bundles, the
+// default bundles must match the sha256sums signed with the release key
+// instead
+type SignaturePolicy struct {
+	// TrustedKeys are the paths to the armored public keys
+	TrustedKeys []string
+	// Mirrors are tried before mirror.openshift.com to fetch the signed
+	// sha256sums of the default bundles
+	Mirrors []string
+
+	// releaseSha256sum returns the sha256sum of the default bundle of a
+	// preset, it is only replaced in tests
+	releaseSha256sum func(preset crcPreset.Preset) (string, error)
+}
+
+// NewSignaturePolicy returns the policy used when the signature of the
+// bundles is enforced, and nil otherwise
+func NewSignaturePolicy(enforce bool, trustedKeys []string, mirrors []string) *SignaturePolicy {
+	if !enforce {
+		return nil
+	}
+	policy := &SignaturePolicy{
+		TrustedKeys: trustedKeys,
+		Mirrors:     mirrors,
+	}
+	policy.releaseSha256sum = policy.fetchReleaseSha256sum
+	return policy
+}
+
+// signatureRecordFilename is written in the directory of an extracted bundle
+// when the signature of its archive was verified
+const signatureRecordFilename = "crc-bundle-signature.json"
+
+// signatureRecord keeps the result of the verification of the archive of an
+// extracted bundle, so that the policy can be enforced without the archive
+type signatureRecord struct {
+	// ArchiveSha256sum is the sha256sum of the verified archive
+	ArchiveSha256sum string `json:"archiveSha256sum"`
+	// KeyFingerprint is the fingerprint of the key which signed it
+	KeyFingerprint string `json:"keyFingerprint"`
+	// Release is set when the archive is a default bundle matching the
+	// sha256sum signed with the release key
+	Release bool `json:"release,omitempty"`
+}
+
+// Verify checks the detached signature of the bundle archive with the
+// trusted keys, or its sha256sum with the release sha256sums for the default
+// bundles. A nil policy accepts any bundle.
+func (policy *SignaturePolicy) Verify(bundlePath string) error {
+	_, err := policy.verifyArchive(bundlePath)
+	return err
+}
+
+// verifyArchive checks the detached signature of the bundle archive, or the
+// release sha256sum for the default bundles, and returns the record of the
+// verification, nil with a nil policy
+func (policy *SignaturePolicy) verifyArchive(bundlePath string) (*signatureRecord, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	bundleName := filepath.Base(bundlePath)
+	if preset, ok := defaultBundlePreset(bundleName); ok {
+		return policy.verifyDefaultArchive(bundlePath, preset)
+	}
+	keyring, err := policy.keyring(bundleName)
+	if err != nil {
+		return nil, err
+	}
+	signaturePath := bundlePath + SignatureExtension
+	if _, err := os.Stat(signaturePath); errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("bundle %s is not signed, %s is missing", bundleName, filepath.Base(signaturePath))
+	}
+	archive, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	// the sha256sum is computed while the signature is checked to read the
+	// archive only once
+	hash := sha256.New()
+	fingerprint, err := gpg.CheckSignature(keyring, io.TeeReader(archive, hash), signaturePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature for bundle %s: %w", bundleName, err)
+	}
+	return &signatureRecord{
+		ArchiveSha256sum: hex.EncodeToString(hash.Sum(nil)),
+		KeyFingerprint:   fingerprint,
+	}, nil
+}
+
+// verifyDefaultArchive checks that the archive of a default bundle is the one
+// published for this release, its name alone does not make it trustworthy
+func (policy *SignaturePolicy) verifyDefaultArchive(bundlePath string, preset crcPreset.Preset) (*signatureRecord, error) {
+	bundleName := filepath.Base(bundlePath)
+	expected, err := policy.releaseSha256sum(preset)
+	if err != nil {
+		return nil, fmt.Errorf("cannot verify bundle %s: %w", bundleName, err)
+	}
+	actual, err := sha256sum(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(actual, expected) {
+		return nil, fmt.Errorf("bundle %s is not the one released with crc, its sha256sum is %s instead of %s", bundleName, actual, expected)
+	}
+	return &signatureRecord{
+		ArchiveSha256sum: actual,
+		Release:          true,
+	}, nil
+}
+
+// fetchReleaseSha256sum returns the sha256sum of the default bundle of preset
+// from the sha256sums signed with the release key, which are looked up on the
+// mirrors first, then on mirror.openshift.com
+func (policy *SignaturePolicy) fetchReleaseSha256sum(preset crcPreset.Preset) (string, error) {
+	var err error
+	for _, mirror := range bundleMirrors(policy.Mirrors) {
+		var sha256sum string
+		sha256sum, err = getDefaultBundleVerifiedHash(preset, mirror)
+		if err == nil {
+			return sha256sum, nil
+		}
+		logging.Warnf("Unable to get the sha256sums of the bundles from %s: %v", mirror, err)
+	}
+	return "", err
+}
+
+// verifyExtracted checks that an extracted bundle comes from an archive whose
+// signature was verified with a key which is still trusted, or which matched
+// the release sha256sum for the default bundles
+func (policy *SignaturePolicy) verifyExtracted(bundleInfo *CrcBundleInfo) error {
+	if policy == nil {
+		return nil
+	}
+	bundleName := bundleInfo.GetBundleName()
+	content, err := os.ReadFile(bundleInfo.resolvePath(signatureRecordFilename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("the signature of bundle %s was not verified when it was extracted, extract it again from its signed archive", bundleName)
+	}
+	if err != nil {
+		return err
+	}
+	var record signatureRecord
+	if err := json.Unmarshal(content, &record); err != nil {
+		return fmt.Errorf("cannot read the signature record of bundle %s: %w", bundleName, err)
+	}
+	if _, ok := defaultBundlePreset(bundleName); ok {
+		if !record.Release {
+			return fmt.Errorf("bundle %s was not verified with the release sha256sums when it was extracted, extract it again from its released archive", bundleName)
+		}
+		return nil
+	}
+	keyring, err := policy.keyring(bundleName)
+	if err != nil {
+		return err
+	}
+	for _, entity := range keyring {
+		if gpg.Fingerprint(entity) == record.KeyFingerprint {
+			return nil
+		}
+	}
+	return fmt.Errorf("bundle %s is signed with key %s which is not trusted to sign bundles", bundleName, record.KeyFingerprint)
+}
+
+func (policy *SignaturePolicy) keyring(bundleName string) (openpgp.EntityList, error) {
+	if len(policy.TrustedKeys) == 0 {
+		return nil, fmt.Errorf("cannot verify the signature of bundle %s, no key is trusted to sign bundles", bundleName)
+	}
+	return gpg.ReadKeyring(policy.TrustedKeys)
+}
+
+// writeSignatureRecord records in the directory of the extracted bundle that
+// its archive was verified
+func writeSignatureRecord(bundleDir string, record *signatureRecord) error {
+	content, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(bundleDir, signatureRecordFilename), content, 0600)
+}
+
+// defaultBundlePreset returns the preset of the default bundle named
+// bundleName, if any
+func defaultBundlePreset(bundleName string) (crcPreset.Preset, bool) {
+	for _, preset := range crcPreset.AllPresets() {
+		if GetBundleNameWithoutExtension(bundleName) == GetBundleNameWithoutExtension(constants.GetDefaultBundle(preset)) {
+			return preset, true
+		}
+	}
+	return "", false
+}
+
+// Sign writes the detached signature of the bundle archive next to it and
+// returns its path
+func Sign(bundlePath string, key *gpg.SigningKey) (string, error) {
+	signaturePath := bundlePath + SignatureExtension
+	if err := key.Sign(bundlePath, signaturePath); err != nil {
+		_ = os.Remove(signaturePath)
+		return "", err
+	}
+	return signaturePath, nil
+}
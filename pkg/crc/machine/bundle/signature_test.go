@@ -0,0 +1,221 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/crc-org/crc/v2/pkg/compress"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/gpg"
+	crcPreset "github.com/crc-org/crc/v2/pkg/crc/preset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSigningKey returns a new signing key and the path of its armored public
+// key
+func newSigningKey(t *testing.T) (*gpg.SigningKey, string) {
+	dir := t.TempDir()
+	entity, err := openpgp.NewEntity("CRC test", "", "crc@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	require.NoError(t, err)
+
+	write := func(path, blockType string, serialize func(w *bytes.Buffer) error) {
+		content := new(bytes.Buffer)
+		w, err := armor.Encode(content, blockType, nil)
+		require.NoError(t, err)
+		var raw bytes.Buffer
+		require.NoError(t, serialize(&raw))
+		_, err = w.Write(raw.Bytes())
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.NoError(t, os.WriteFile(path, content.Bytes(), 0600))
+	}
+	publicKeyPath := filepath.Join(dir, "public.asc")
+	privateKeyPath := filepath.Join(dir, "private.asc")
+	write(publicKeyPath, openpgp.PublicKeyType, func(w *bytes.Buffer) error { return entity.Serialize(w) })
+	write(privateKeyPath, openpgp.PrivateKeyType, func(w *bytes.Buffer) error { return entity.SerializePrivateWithoutSigning(w, nil) })
+
+	key, err := gpg.ReadSigningKey(privateKeyPath, nil)
+	require.NoError(t, err)
+	return key, publicKeyPath
+}
+
+func TestSignatureVerification(t *testing.T) {
+	key, publicKeyPath := newSigningKey(t)
+	_, otherPublicKeyPath := newSigningKey(t)
+
+	bundlePath := filepath.Join(t.TempDir(), "crc_libvirt_4.6.1_team.crcbundle")
+	require.NoError(t, os.WriteFile(bundlePath, []byte("bundle"), 0600))
+
+	policy := NewSignaturePolicy(true, []string{publicKeyPath}, nil)
+	assert.EqualError(t, policy.Verify(bundlePath), "bundle crc_libvirt_4.6.1_team.crcbundle is not signed, crc_libvirt_4.6.1_team.crcbundle.sig is missing")
+
+	signaturePath, err := Sign(bundlePath, key)
+	require.NoError(t, err)
+	assert.Equal(t, bundlePath+SignatureExtension, signaturePath)
+	assert.NoError(t, policy.Verify(bundlePath))
+	assert.NoError(t, NewSignaturePolicy(true, []string{otherPublicKeyPath, publicKeyPath}, nil).Verify(bundlePath))
+
+	assert.ErrorContains(t, NewSignaturePolicy(true, []string{otherPublicKeyPath}, nil).Verify(bundlePath), "invalid signature for bundle crc_libvirt_4.6.1_team.crcbundle")
+	assert.EqualError(t, NewSignaturePolicy(true, nil, nil).Verify(bundlePath), "cannot verify the signature of bundle crc_libvirt_4.6.1_team.crcbundle, no key is trusted to sign bundles")
+
+	require.NoError(t, os.WriteFile(bundlePath, []byte("tampered bundle"), 0600))
+	assert.ErrorContains(t, policy.Verify(bundlePath), "invalid signature for bundle crc_libvirt_4.6.1_team.crcbundle")
+
+	// the signature is not checked when it is not enforced
+	assert.Nil(t, NewSignaturePolicy(false, []string{publicKeyPath}, nil))
+	assert.NoError(t, NewSignaturePolicy(false, []string{publicKeyPath}, nil).Verify(bundlePath))
+}
+
+func TestDownloadLocalBundleSignature(t *testing.T) {
+	key, publicKeyPath := newSigningKey(t)
+	bundlePath := filepath.Join(t.TempDir(), "crc_libvirt_4.6.1_team.crcbundle")
+	require.NoError(t, os.WriteFile(bundlePath, []byte("bundle"), 0600))
+	policy := NewSignaturePolicy(true, []string{publicKeyPath}, nil)
+
+	_, err := Download(t.Context(), "openshift", bundlePath, false, nil, policy)
+	assert.ErrorContains(t, err, "is not signed")
+
+	_, err = Sign(bundlePath, key)
+	require.NoError(t, err)
+	path, err := Download(t.Context(), "openshift", bundlePath, false, nil, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, bundlePath, path)
+
+	_, err = Download(t.Context(), "openshift", "docker://quay.io/crc/bundle:4.6.1", false, nil, policy)
+	assert.EqualError(t, err, "cannot verify the signature of bundle docker://quay.io/crc/bundle:4.6.1, only bundle files can be signed")
+}
+
+func TestUseEnforcesSignature(t *testing.T) {
+	key, publicKeyPath := newSigningKey(t)
+	_, otherPublicKeyPath := newSigningKey(t)
+	policy := NewSignaturePolicy(true, []string{publicKeyPath}, nil)
+
+	srcDir := t.TempDir()
+	createCustomBundleContent(t, srcDir, "crc_libvirt_4.6.1_team", nil)
+	archive := filepath.Join(srcDir, "crc_libvirt_4.6.1_team.crcbundle")
+	require.NoError(t, compress.Compress(filepath.Join(srcDir, "crc_libvirt_4.6.1_team"), archive))
+
+	// extracted before the signature was enforced
+	repo := &Repository{
+		CacheDir: t.TempDir(),
+		OcBinDir: t.TempDir(),
+	}
+	require.NoError(t, repo.Extract(context.Background(), archive, nil))
+	_, err := repo.Use("crc_libvirt_4.6.1_team.crcbundle", nil)
+	assert.NoError(t, err)
+	_, err = repo.Use("crc_libvirt_4.6.1_team.crcbundle", policy)
+	assert.EqualError(t, err, "the signature of bundle crc_libvirt_4.6.1_team was not verified when it was extracted, extract it again from its signed archive")
+
+	assert.ErrorContains(t, repo.Extract(context.Background(), archive, policy), "is not signed")
+
+	_, err = Sign(archive, key)
+	require.NoError(t, err)
+	repo = &Repository{
+		CacheDir: t.TempDir(),
+		OcBinDir: t.TempDir(),
+	}
+	require.NoError(t, repo.Extract(context.Background(), archive, policy))
+	_, err = repo.Use("crc_libvirt_4.6.1_team.crcbundle", policy)
+	assert.NoError(t, err)
+	_, err = repo.Use("crc_libvirt_4.6.1_team.crcbundle", NewSignaturePolicy(true, []string{otherPublicKeyPath}, nil))
+	assert.ErrorContains(t, err, "bundle crc_libvirt_4.6.1_team is signed with key")
+}
+
+func TestUseDeltaBundleEnforcesBaseSignature(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("delta bundles are only supported on linux")
+	}
+	key, publicKeyPath := newSigningKey(t)
+	policy := NewSignaturePolicy(true, []string{publicKeyPath}, nil)
+
+	srcDir := t.TempDir()
+	createArchive := func(name string) string {
+		archive := filepath.Join(srcDir, name+".crcbundle")
+		require.NoError(t, compress.Compress(filepath.Join(srcDir, name), archive))
+		_, err := Sign(archive, key)
+		require.NoError(t, err)
+		return archive
+	}
+	createCustomBundleContent(t, srcDir, "crc_libvirt_4.6.1_base", nil)
+	// Use relies on the disk image sha256sum from the metadata of the base bundle
+	baseChecksum, err := sha256sum(filepath.Join(srcDir, "crc_libvirt_4.6.1_base", "crc.qcow2"))
+	require.NoError(t, err)
+	metadataPath := filepath.Join(srcDir, "crc_libvirt_4.6.1_base", metadataFilename)
+	metadata, err := os.ReadFile(metadataPath)
+	require.NoError(t, err)
+	metadata = bytes.ReplaceAll(metadata, []byte("245a0e5acd4f09000a9a5f37d731082ed1cf3fdcad1b5320cbe9b153c9fd82a4"), []byte(baseChecksum))
+	require.NoError(t, os.WriteFile(metadataPath, metadata, 0600))
+	baseArchive := createArchive("crc_libvirt_4.6.1_base")
+	createDeltaBundleContent(t, srcDir, "crc_libvirt_4.6.1_team", "crc_libvirt_4.6.1_base", baseChecksum)
+	deltaArchive := createArchive("crc_libvirt_4.6.1_team")
+
+	dir := t.TempDir()
+	repo := &Repository{
+		CacheDir: dir,
+		OcBinDir: t.TempDir(),
+	}
+	// the base bundle was extracted before the signature was enforced
+	require.NoError(t, repo.Extract(context.Background(), baseArchive, nil))
+	assert.ErrorContains(t, repo.Extract(context.Background(), deltaArchive, policy), "cannot use the base bundle crc_libvirt_4.6.1_base of crc_libvirt_4.6.1_team: the signature of bundle crc_libvirt_4.6.1_base was not verified")
+
+	require.NoError(t, repo.Extract(context.Background(), baseArchive, policy))
+	require.NoError(t, repo.Extract(context.Background(), deltaArchive, policy))
+	_, err = repo.Use("crc_libvirt_4.6.1_team.crcbundle", policy)
+	assert.NoError(t, err)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "crc_libvirt_4.6.1_base", signatureRecordFilename)))
+	_, err = repo.Use("crc_libvirt_4.6.1_team.crcbundle", policy)
+	assert.ErrorContains(t, err, "cannot use the base bundle crc_libvirt_4.6.1_base of crc_libvirt_4.6.1_team: the signature of bundle crc_libvirt_4.6.1_base was not verified")
+}
+
+func TestDefaultBundleEnforcesReleaseSha256sum(t *testing.T) {
+	bundleName := GetBundleNameWithoutExtension(constants.GetDefaultBundle(crcPreset.OpenShift))
+	srcDir := t.TempDir()
+	createCustomBundleContent(t, srcDir, bundleName, nil)
+	archive := filepath.Join(srcDir, bundleName+".crcbundle")
+	require.NoError(t, compress.Compress(filepath.Join(srcDir, bundleName), archive))
+	releaseSha256sum, err := sha256sum(archive)
+	require.NoError(t, err)
+
+	// the default bundles do not need any trusted key
+	policy := NewSignaturePolicy(true, nil, nil)
+	policy.releaseSha256sum = func(preset crcPreset.Preset) (string, error) {
+		assert.Equal(t, crcPreset.OpenShift, preset)
+		return releaseSha256sum, nil
+	}
+	assert.NoError(t, policy.Verify(archive))
+	repo := &Repository{
+		CacheDir: t.TempDir(),
+		OcBinDir: t.TempDir(),
+	}
+	require.NoError(t, repo.Extract(context.Background(), archive, policy))
+	_, err = repo.Use(bundleName+".crcbundle", policy)
+	assert.NoError(t, err)
+
+	// a tampered archive with the name of the default bundle, which claims
+	// to have been verified
+	record := []byte(`{"archiveSha256sum":"` + releaseSha256sum + `","release":true}`)
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, bundleName, signatureRecordFilename), record, 0600))
+	require.NoError(t, compress.Compress(filepath.Join(srcDir, bundleName), archive))
+	assert.ErrorContains(t, policy.Verify(archive), "bundle "+bundleName+".crcbundle is not the one released with crc")
+
+	repo = &Repository{
+		CacheDir: t.TempDir(),
+		OcBinDir: t.TempDir(),
+	}
+	assert.ErrorContains(t, repo.Extract(context.Background(), archive, policy), "is not the one released with crc")
+	assert.NoDirExists(t, filepath.Join(repo.CacheDir, bundleName))
+
+	// extracted before the signature was enforced
+	require.NoError(t, repo.Extract(context.Background(), archive, nil))
+	_, err = repo.Use(bundleName+".crcbundle", policy)
+	assert.EqualError(t, err, "the signature of bundle "+bundleName+" was not verified when it was extracted, extract it again from its signed archive")
+}
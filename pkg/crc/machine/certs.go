@@ -0,0 +1,198 @@
+package machine
+
+import (
+	"context"
+	"crypto/x509"
+	"os"
+
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	crcErr "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
+	"github.com/crc-org/crc/v2/pkg/crc/oc"
+	crcssh "github.com/crc-org/crc/v2/pkg/crc/ssh"
+	crctls "github.com/crc-org/crc/v2/pkg/crc/tls"
+	"github.com/pkg/errors"
+)
+
+const kubeconfigClientCertName = "kubeconfig-admin-client"
+
+// runningOpenShiftVM loads the VM of the instance, failing when it is not
+// a running OpenShift instance
+func (client *client) runningOpenShiftVM() (*virtualMachine, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		if errors.Is(err, errMissingHost(client.name)) {
+			return nil, crcErr.VMNotExist
+		}
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	vmState, err := vm.State()
+	if err != nil {
+		vm.Close()
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		vm.Close()
+		return nil, errors.New("Instance is not running, run 'crc start' first")
+	}
+	if vm.bundle.IsMicroshift() {
+		vm.Close()
+		return nil, errors.New("Certificates are only managed for the OpenShift preset")
+	}
+	return vm, nil
+}
+
+func (client *client) CertificatesStatus() ([]types.CertificateStatus, error) {
+	vm, err := client.runningOpenShiftVM()
+	if err != nil {
+		return nil, err
+	}
+	defer vm.Close()
+
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	var statuses []types.CertificateStatus
+	for _, cert := range cluster.Certificates {
+		x509Cert, err := cluster.ReadCertificate(sshRunner, cert.Path)
+		if errors.Is(err, cluster.ErrCertificateNotFound) {
+			statuses = append(statuses, types.CertificateStatus{Name: cert.Name, Path: cert.Path, Missing: true})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, certificateStatus(cert.Name, cert.Path, x509Cert))
+	}
+
+	kubeconfigPath := constants.GetKubeconfigFilePath(client.name)
+	clientCert, err := adminClientCertificate(kubeconfigPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read the kubeconfig client certificate")
+	}
+	x509Cert, err := crctls.PemToCert([]byte(clientCert))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot parse the client certificate of %s", kubeconfigPath)
+	}
+	return append(statuses, certificateStatus(kubeconfigClientCertName, kubeconfigPath, x509Cert)), nil
+}
+
+func certificateStatus(name, path string, cert *x509.Certificate) types.CertificateStatus {
+	return types.CertificateStatus{
+		Name:      name,
+		Path:      path,
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+	}
+}
+
+// instanceCertificates are the cluster operations used to rotate the
+// certificates of an instance
+type instanceCertificates interface {
+	RenewKubeletCertificates(ctx context.Context) error
+	WaitForAPIServer(ctx context.Context) error
+	KubeAPIServerRevision() (int, error)
+	TrustClientCA(ctx context.Context, kubeconfigPath string, ca *x509.Certificate, clientCert string) error
+	WaitForKubeAPIServerRollout(ctx context.Context, previousRevision int) error
+}
+
+type clusterCertificates struct {
+	sshRunner *crcssh.Runner
+	ocConfig  oc.Config
+}
+
+func (certs *clusterCertificates) RenewKubeletCertificates(ctx context.Context) error {
+	return cluster.RenewKubeletCertificates(ctx, certs.sshRunner, certs.ocConfig)
+}
+
+func (certs *clusterCertificates) WaitForAPIServer(ctx context.Context) error {
+	return cluster.WaitForAPIServer(ctx, certs.ocConfig)
+}
+
+func (certs *clusterCertificates) KubeAPIServerRevision() (int, error) {
+	return cluster.KubeAPIServerRevision(certs.ocConfig)
+}
+
+func (certs *clusterCertificates) TrustClientCA(ctx context.Context, kubeconfigPath string, ca *x509.Certificate, clientCert string) error {
+	return cluster.EnsureGeneratedClientCAPresentInTheCluster(ctx, certs.ocConfig, certs.sshRunner, kubeconfigPath, ca, clientCert)
+}
+
+func (certs *clusterCertificates) WaitForKubeAPIServerRollout(ctx context.Context, previousRevision int) error {
+	return cluster.WaitForKubeAPIServerRollout(ctx, certs.ocConfig, previousRevision)
+}
+
+// RotateCertificates issues a new kubeconfig client certificate signed by a
+// new CA, and makes kubelet renew its client and serving certificates. The
+// kube-apiserver certificates are rotated by the cluster operators.
+func (client *client) RotateCertificates(ctx context.Context) error {
+	vm, err := client.runningOpenShiftVM()
+	if err != nil {
+		return err
+	}
+	defer vm.Close()
+
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	certs := &clusterCertificates{
+		sshRunner: sshRunner,
+		ocConfig:  oc.UseOCWithSSH(sshRunner),
+	}
+	return rotateCertificates(ctx, constants.GetKubeconfigFilePath(client.name), certs)
+}
+
+func rotateCertificates(ctx context.Context, kubeconfigPath string, certs instanceCertificates) error {
+	if err := certs.RenewKubeletCertificates(ctx); err != nil {
+		return errors.Wrap(err, "Failed to renew kubelet certificates")
+	}
+	if err := certs.WaitForAPIServer(ctx); err != nil {
+		return errors.Wrap(err, "Error waiting for apiserver")
+	}
+
+	logging.Info("Issuing a new kubeconfig client certificate...")
+	return rotateKubeconfigClientCertificate(ctx, kubeconfigPath, certs)
+}
+
+// rotateKubeconfigClientCertificate replaces the admin client certificate of
+// the instance kubeconfig once the kube-apiserver trusts its CA, so that the
+// kubeconfig keeps working when the update fails
+func rotateKubeconfigClientCertificate(ctx context.Context, kubeconfigPath string, certs instanceCertificates) error {
+	selfSignedCAKey, selfSignedCACert, err := crctls.GetSelfSignedCA()
+	if err != nil {
+		return errors.Wrap(err, "Not able to generate root CA key and Cert")
+	}
+	clientKey, clientCert, err := crctls.GenerateClientCertificate(selfSignedCAKey, selfSignedCACert)
+	if err != nil {
+		return err
+	}
+	newKubeconfigPath := kubeconfigPath + ".new"
+	if err := updateClientCrtAndKeyToKubeconfig(clientKey, clientCert, kubeconfigPath, newKubeconfigPath); err != nil {
+		return errors.Wrapf(err, "Failed to update kubeconfig file: %s", kubeconfigPath)
+	}
+	defer os.Remove(newKubeconfigPath)
+
+	// the kube-apiserver only accepts the new client certificate once it
+	// runs a revision with the updated admin-kubeconfig-client-ca configmap
+	revision, err := certs.KubeAPIServerRevision()
+	if err != nil {
+		return err
+	}
+	if err := certs.TrustClientCA(ctx, newKubeconfigPath, selfSignedCACert, string(clientCert)); err != nil {
+		return errors.Wrap(err, "Failed to update user CA to cluster")
+	}
+	if err := certs.WaitForKubeAPIServerRollout(ctx, revision); err != nil {
+		return errors.Wrap(err, "Failed to roll out the new user CA")
+	}
+	return os.Rename(newKubeconfigPath, kubeconfigPath)
+}
@@ -0,0 +1,107 @@
+package machine
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCertificates struct {
+	calls       []string
+	failing     string
+	trustedCert string
+	// trustedKubeconfig is the kubeconfig copied to the VM
+	trustedKubeconfig []byte
+}
+
+func (certs *fakeCertificates) call(name string) error {
+	certs.calls = append(certs.calls, name)
+	if certs.failing == name {
+		return errors.New(name + " failed")
+	}
+	return nil
+}
+
+func (certs *fakeCertificates) RenewKubeletCertificates(_ context.Context) error {
+	return certs.call("renew-kubelet")
+}
+
+func (certs *fakeCertificates) WaitForAPIServer(_ context.Context) error {
+	return certs.call("wait-apiserver")
+}
+
+func (certs *fakeCertificates) KubeAPIServerRevision() (int, error) {
+	return 7, certs.call("revision")
+}
+
+func (certs *fakeCertificates) TrustClientCA(_ context.Context, kubeconfigPath string, _ *x509.Certificate, clientCert string) error {
+	certs.trustedCert = clientCert
+	kubeconfig, err := os.ReadFile(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	certs.trustedKubeconfig = kubeconfig
+	return certs.call("trust-ca")
+}
+
+func (certs *fakeCertificates) WaitForKubeAPIServerRollout(_ context.Context, previousRevision int) error {
+	if previousRevision != 7 {
+		return errors.New("unexpected revision")
+	}
+	return certs.call("wait-rollout")
+}
+
+func copyTestKubeconfig(t *testing.T) (string, []byte) {
+	kubeconfig, err := os.ReadFile(filepath.Join("testdata", "kubeconfig.in"))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, kubeconfig, 0600))
+	return path, kubeconfig
+}
+
+func TestRotateCertificates(t *testing.T) {
+	kubeconfigPath, _ := copyTestKubeconfig(t)
+	certs := &fakeCertificates{}
+
+	require.NoError(t, rotateCertificates(context.Background(), kubeconfigPath, certs))
+	assert.Equal(t, []string{"renew-kubelet", "wait-apiserver", "revision", "trust-ca", "wait-rollout"}, certs.calls)
+
+	kubeconfig, err := os.ReadFile(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, certs.trustedKubeconfig, kubeconfig)
+	clientCert, err := adminClientCertificate(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, certs.trustedCert, clientCert)
+	assert.NoFileExists(t, kubeconfigPath+".new")
+}
+
+func TestRotateCertificatesKeepsKubeconfigOnFailure(t *testing.T) {
+	for _, tt := range []struct {
+		failing string
+		calls   []string
+	}{
+		{"renew-kubelet", []string{"renew-kubelet"}},
+		{"wait-apiserver", []string{"renew-kubelet", "wait-apiserver"}},
+		{"trust-ca", []string{"renew-kubelet", "wait-apiserver", "revision", "trust-ca"}},
+		{"wait-rollout", []string{"renew-kubelet", "wait-apiserver", "revision", "trust-ca", "wait-rollout"}},
+	} {
+		t.Run(tt.failing, func(t *testing.T) {
+			kubeconfigPath, expected := copyTestKubeconfig(t)
+			certs := &fakeCertificates{failing: tt.failing}
+
+			assert.ErrorContains(t, rotateCertificates(context.Background(), kubeconfigPath, certs), tt.failing+" failed")
+			assert.Equal(t, tt.calls, certs.calls)
+
+			kubeconfig, err := os.ReadFile(kubeconfigPath)
+			require.NoError(t, err)
+			assert.Equal(t, expected, kubeconfig)
+			assert.NoFileExists(t, kubeconfigPath+".new")
+		})
+	}
+}
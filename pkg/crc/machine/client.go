@@ -4,7 +4,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
 	"github.com/crc-org/crc/v2/pkg/crc/network"
@@ -25,8 +27,19 @@ type Client interface {
 	GetClusterLoad() (*types.ClusterLoadResult, error)
 	Stop() (state.State, error)
 	IsRunning() (bool, error)
-	GenerateBundle(forceStop bool) error
+	GenerateBundle(ctx context.Context, config types.GenerateBundleConfig) (*types.GenerateBundleResult, error)
 	GetPreset() crcPreset.Preset
+
+	CreateSnapshot(name string) error
+	ListSnapshots() ([]types.Snapshot, error)
+	RestoreSnapshot(name string) error
+	DeleteSnapshot(name string) error
+
+	CertificatesStatus() ([]types.CertificateStatus, error)
+	RotateCertificates(ctx context.Context) error
+
+	Diagnostics(ctx context.Context, write func(types.DiagnosticFile)) error
+	UpdatePullSecret(ctx context.Context, pullSecret cluster.PullSecretLoader) error
 }
 
 type client struct {
@@ -56,6 +69,26 @@ func (client *client) GetPreset() crcPreset.Preset {
 	return crcConfig.GetPreset(client.config)
 }
 
+func (client *client) publish(phase lifecycle.Phase) {
+	lifecycle.Publish(lifecycle.Event{
+		Phase:    phase,
+		Instance: client.name,
+	})
+}
+
+// publishFailure ends the lifecycle operation of the instance which returned
+// err, if any
+func (client *client) publishFailure(err error) {
+	if err == nil {
+		return
+	}
+	lifecycle.Publish(lifecycle.Event{
+		Phase:    lifecycle.Failed,
+		Instance: client.name,
+		Message:  err.Error(),
+	})
+}
+
 func (client *client) useVSock() bool {
 	return client.networkMode() == network.UserNetworkingMode
 }
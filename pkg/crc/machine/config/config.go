@@ -2,6 +2,7 @@ package config
 
 import (
 	"github.com/crc-org/crc/v2/pkg/crc/network"
+	"github.com/crc-org/crc/v2/pkg/crc/shareddirs"
 	"go.podman.io/common/pkg/strongunits"
 )
 
@@ -20,7 +21,7 @@ type MachineConfig struct {
 	ImageFormat       string
 	SSHKeyPath        string
 	KubeConfig        string
-	SharedDirs        []string
+	SharedDirs        []shareddirs.SharedDir
 	SharedDirPassword string
 	SharedDirUsername string
 
@@ -3,33 +3,52 @@ package machine
 import (
 	"os"
 
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
 	"github.com/crc-org/crc/v2/pkg/crc/ssh"
 	"github.com/pkg/errors"
 )
 
-func (client *client) Delete() error {
+func (client *client) Delete() (err error) {
+	defer func() {
+		client.publishFailure(err)
+	}()
 	vm, err := loadVirtualMachine(client.name, client.useVSock())
 	if err != nil && !errors.Is(err, errInvalidBundleMetadata) {
 		return errors.Wrap(err, "Cannot load machine")
 	}
 	defer vm.Close()
 
+	client.publish(lifecycle.Deleting)
+	vmState, err := vm.State()
+	if err != nil {
+		logging.Debugf("Cannot get VM state before removing it: %v", err)
+	}
 	if err := vm.Remove(); err != nil {
 		return errors.Wrap(err, "Cannot remove machine")
 	}
 
-	// In case usermode networking make sure all the port bind on host should be released
-	if client.useVSock() {
-		if err := unexposePorts(); err != nil {
+	// In case usermode networking make sure all the port bind on host should be released,
+	// the ports of a stopped instance were released when it stopped and
+	// may now be used by another instance with the same address
+	if client.useVSock() && vmState == state.Running {
+		if err := unexposePorts(vm.addresses); err != nil {
 			return err
 		}
 	}
+	if err := releaseInstanceAddresses(client.name); err != nil {
+		logging.Warnf("Failed to release the addresses of the instance: %v", err)
+	}
 
-	if err := cleanKubeconfig(getGlobalKubeConfigPath(), getGlobalKubeConfigPath()); err != nil {
+	if err := cleanKubeconfig(getGlobalKubeConfigPath(), getGlobalKubeConfigPath(), client.name); err != nil {
 		if !errors.Is(err, os.ErrNotExist) {
 			logging.Warnf("Failed to remove crc contexts from kubeconfig: %v", err)
 		}
 	}
-	return ssh.RemoveCRCHostEntriesFromKnownHosts()
+	if err := ssh.RemoveCRCHostEntriesFromKnownHosts(vm.addresses.SSHPort()); err != nil {
+		return err
+	}
+	client.publish(lifecycle.Deleted)
+	return nil
 }
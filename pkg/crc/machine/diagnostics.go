@@ -0,0 +1,200 @@
+package machine
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	crcErr "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
+	"github.com/crc-org/crc/v2/pkg/crc/oc"
+	crcssh "github.com/crc-org/crc/v2/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+const (
+	inspectDir     = "/tmp/crc-inspect"
+	inspectTimeout = "10m"
+)
+
+type diagnosticCommand struct {
+	name    string
+	command string
+}
+
+var vmDiagnosticCommands = []diagnosticCommand{
+	{name: "vm/journal.log", command: "sudo journalctl --no-pager --boot"},
+	{name: "vm/systemctl-units.txt", command: "systemctl --no-pager --all list-units"},
+	{name: "vm/systemctl-failed.txt", command: "systemctl --no-pager --failed"},
+	{name: "vm/disk.txt", command: "df -h"},
+	{name: "vm/memory.txt", command: "free -m"},
+	{name: "vm/network.txt", command: "ip addr"},
+	{name: "vm/os-release", command: "cat /etc/os-release"},
+}
+
+var clusterDiagnosticCommands = []diagnosticCommand{
+	{name: "cluster/clusterversion.yaml", command: "get clusterversion -o yaml"},
+	{name: "cluster/clusteroperators.txt", command: "get clusteroperators -o wide"},
+	{name: "cluster/nodes.txt", command: "get nodes -o wide"},
+	{name: "cluster/pods.txt", command: "get pods --all-namespaces -o wide"},
+	{name: "cluster/events.txt", command: "get events --all-namespaces --sort-by=.lastTimestamp"},
+}
+
+// Diagnostics runs the commands showing the state of the VM and of the
+// cluster of a running instance. The commands keep running when one of them
+// fails, its error is reported in the file. Each file is passed to write as
+// soon as it is collected.
+func (client *client) Diagnostics(ctx context.Context, write func(types.DiagnosticFile)) error {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		if errors.Is(err, errMissingHost(client.name)) {
+			return crcErr.VMNotExist
+		}
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	vmState, err := vm.State()
+	if err != nil {
+		return errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return fmt.Errorf("Instance is %s, the VM and cluster diagnostics require a running instance", strings.ToLower(string(vmState)))
+	}
+
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	services := "crio kubelet"
+	if vm.bundle.IsMicroshift() {
+		services = "crio microshift"
+	}
+	commands := append(vmDiagnosticCommands, diagnosticCommand{
+		name:    "vm/services.txt",
+		command: "systemctl --no-pager status " + services,
+	})
+	for _, command := range commands {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		logging.Debugf("Collecting %s", command.name)
+		// the output is not logged, it is written to the archive
+		stdout, stderr, err := sshRunner.RunPrivate(command.command)
+		write(diagnosticFile(command.name, stdout, stderr, err))
+	}
+	if vm.bundle.IsMicroshift() {
+		return nil
+	}
+
+	ocConfig := oc.UseOCWithSSH(sshRunner).WithFailFast()
+	for _, command := range clusterDiagnosticCommands {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		logging.Debugf("Collecting %s", command.name)
+		stdout, stderr, err := ocConfig.RunOcCommandPrivate(strings.Fields(command.command)...)
+		write(diagnosticFile(command.name, stdout, stderr, err))
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	inspectClusterOperators(sshRunner, write)
+	return nil
+}
+
+func diagnosticFile(name, stdout, stderr string, err error) types.DiagnosticFile {
+	file := types.DiagnosticFile{
+		Name:    name,
+		Content: []byte(stdout),
+	}
+	if err != nil {
+		file.Error = err.Error()
+		if stderr = strings.TrimSpace(stderr); stderr != "" {
+			file.Error = fmt.Sprintf("%s: %s", file.Error, stderr)
+		}
+	}
+	return file
+}
+
+// inspectClusterOperators runs 'oc adm inspect' on the cluster operators,
+// the gathered files are streamed from the VM and passed to write one by one
+func inspectClusterOperators(sshRunner *crcssh.Runner, write func(types.DiagnosticFile)) {
+	const name = "cluster/inspect"
+	logging.Debugf("Collecting %s", name)
+	defer func() {
+		_, _, _ = sshRunner.Run("rm", "-rf", inspectDir)
+	}()
+
+	ocConfig := oc.UseOCWithSSH(sshRunner)
+	ocConfig.Timeout = inspectTimeout
+	if _, stderr, err := ocConfig.RunOcCommandPrivate("adm", "inspect", "clusteroperators", "--dest-dir", inspectDir); err != nil {
+		write(diagnosticFile(name, "", stderr, err))
+		return
+	}
+
+	reader, writer := io.Pipe()
+	type streamResult struct {
+		stderr string
+		err    error
+	}
+	streamed := make(chan streamResult, 1)
+	go func() {
+		stderr, err := sshRunner.StreamPrivate(writer, "tar", "-C", inspectDir, "-cz", ".")
+		writer.CloseWithError(err)
+		streamed <- streamResult{stderr: stderr, err: err}
+	}()
+	err := untarDiagnostics(name, reader, write)
+	if err == nil {
+		// the padding after the end of the tar archive
+		_, err = io.Copy(io.Discard, reader)
+	}
+	// stops the stream when the archive cannot be read
+	reader.CloseWithError(err)
+	if result := <-streamed; result.err != nil {
+		write(diagnosticFile(name, "", result.stderr, result.err))
+		return
+	}
+	if err != nil {
+		write(diagnosticFile(name, "", "", err))
+	}
+}
+
+// untarDiagnostics passes the regular files of the gzipped tar archive to
+// write, with their path prefixed by dir
+func untarDiagnostics(dir string, archive io.Reader, write func(types.DiagnosticFile)) error {
+	gzipReader, err := gzip.NewReader(archive)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		// path.Join cleans the name so that it stays in dir
+		name := path.Join(dir, path.Join("/", header.Name))
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return err
+		}
+		write(types.DiagnosticFile{Name: name, Content: content})
+	}
+}
@@ -0,0 +1,45 @@
+package machine
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUntarDiagnostics(t *testing.T) {
+	var archive bytes.Buffer
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "./namespaces/", Typeflag: tar.TypeDir, Mode: 0755}))
+	for name, content := range map[string]string{
+		"./namespaces/openshift-dns.yaml": "kind: Namespace",
+		"../../escape.txt":                "outside",
+	} {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+		_, err := tarWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	var files []types.DiagnosticFile
+	require.NoError(t, untarDiagnostics("cluster/inspect", &archive, func(file types.DiagnosticFile) {
+		files = append(files, file)
+	}))
+	assert.ElementsMatch(t, []types.DiagnosticFile{
+		{Name: "cluster/inspect/namespaces/openshift-dns.yaml", Content: []byte("kind: Namespace")},
+		{Name: "cluster/inspect/escape.txt", Content: []byte("outside")},
+	}, files)
+}
+
+func TestDiagnosticFile(t *testing.T) {
+	assert.Equal(t, types.DiagnosticFile{Name: "vm/disk.txt", Content: []byte("output")}, diagnosticFile("vm/disk.txt", "output", "", nil))
+	assert.Equal(t, types.DiagnosticFile{Name: "vm/disk.txt", Content: []byte{}, Error: "exit status 1: df: failed"},
+		diagnosticFile("vm/disk.txt", "", "df: failed\n", errors.New("exit status 1")))
+}
@@ -57,3 +57,19 @@ func setDiskSize(host *host.Host, diskSize strongunits.GiB) error {
 
 	return updateDriverValue(host, diskSizeSetter)
 }
+
+// sameSharedDirs compares the shared directories stored in the driver
+// configuration, which does not include the credentials
+func sameSharedDirs(current, desired []libmachine.SharedDir) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for i := range current {
+		if current[i].Source != desired[i].Source || current[i].Target != desired[i].Target ||
+			current[i].Tag != desired[i].Tag || current[i].Type != desired[i].Type ||
+			current[i].ReadOnly != desired[i].ReadOnly {
+			return false
+		}
+	}
+	return true
+}
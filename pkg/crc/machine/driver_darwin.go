@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/config"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/vfkit"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
+	"github.com/crc-org/crc/v2/pkg/crc/shareddirs"
 	machineVf "github.com/crc-org/crc/v2/pkg/drivers/vfkit"
 	"github.com/crc-org/crc/v2/pkg/libmachine"
 	"github.com/crc-org/crc/v2/pkg/libmachine/host"
@@ -34,3 +37,41 @@ func updateDriverConfig(host *host.Host, driver *machineVf.Driver) error {
 
 	return host.UpdateConfig(driverData)
 }
+
+func setSharedDirs(host *host.Host, dirs []shareddirs.SharedDir) error {
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return err
+	}
+	sharedDirs := vfkit.ConfigureShareDirs(config.MachineConfig{SharedDirs: dirs})
+	if sameSharedDirs(driver.SharedDirs, sharedDirs) {
+		return nil
+	}
+	driver.SharedDirs = sharedDirs
+	return updateDriverConfig(host, driver)
+}
+
+// systemNetworkVMAddress returns an empty string as each VM gets its own
+// address from DHCP with system mode networking
+func systemNetworkVMAddress() string {
+	return ""
+}
+
+// setNetworkAddresses connects the VM to the unixgram socket of the instance
+// with the MAC address the daemon leases the address of the instance to
+func setNetworkAddresses(host *host.Host, addresses network.InstanceAddresses) error {
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return err
+	}
+	if driver.UnixgramSockPath == "" {
+		return nil
+	}
+	sockPath := constants.GetUnixgramSocketPath(addresses.Index)
+	if driver.UnixgramSockPath == sockPath && driver.UnixgramMacAddress == addresses.MACAddress() {
+		return nil
+	}
+	driver.UnixgramSockPath = sockPath
+	driver.UnixgramMacAddress = addresses.MACAddress()
+	return updateDriverConfig(host, driver)
+}
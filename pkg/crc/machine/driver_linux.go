@@ -7,9 +7,12 @@ import (
 
 	"github.com/crc-org/crc/v2/pkg/crc/machine/config"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/libvirt"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
+	"github.com/crc-org/crc/v2/pkg/crc/shareddirs"
 	"github.com/crc-org/crc/v2/pkg/libmachine"
 	"github.com/crc-org/crc/v2/pkg/libmachine/host"
 	machineLibvirt "github.com/crc-org/machine/drivers/libvirt"
+	"github.com/crc-org/machine/libmachine/drivers"
 )
 
 func newHost(api libmachine.API, machineConfig config.MachineConfig) (*host.Host, error) {
@@ -43,3 +46,29 @@ func (r *RPCServerDriver) SetConfigRaw(data []byte, _ *struct{}) error {
 	return json.Unmarshal(data, &r.ActualDriver)
 }
 */
+
+// setSharedDirs fails with drivers.ErrNotImplemented when the shared
+// directories change, they are part of the libvirt domain which is only
+// defined when the VM is created
+func setSharedDirs(host *host.Host, dirs []shareddirs.SharedDir) error {
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return err
+	}
+	if sameSharedDirs(driver.SharedDirs, libvirt.ConfigureShareDirs(config.MachineConfig{SharedDirs: dirs})) {
+		return nil
+	}
+	return drivers.ErrNotImplemented
+}
+
+// systemNetworkVMAddress returns the address of the VM with system mode
+// networking, libvirt always gives the same one to all the instances
+func systemNetworkVMAddress() string {
+	return libvirt.IPAddress
+}
+
+// setNetworkAddresses does nothing, the guest picks the MAC address of the VM
+// and it always gets the default address
+func setNetworkAddresses(_ *host.Host, _ network.InstanceAddresses) error {
+	return nil
+}
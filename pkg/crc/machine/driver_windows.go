@@ -6,6 +6,8 @@ import (
 
 	"github.com/crc-org/crc/v2/pkg/crc/machine/config"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/libhvee"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
+	"github.com/crc-org/crc/v2/pkg/crc/shareddirs"
 	machineLibhvee "github.com/crc-org/crc/v2/pkg/drivers/libhvee"
 	"github.com/crc-org/crc/v2/pkg/libmachine"
 	"github.com/crc-org/crc/v2/pkg/libmachine/host"
@@ -33,3 +35,28 @@ func updateDriverConfig(host *host.Host, driver *machineLibhvee.Driver) error {
 	}
 	return host.UpdateConfig(driverData)
 }
+
+func setSharedDirs(host *host.Host, dirs []shareddirs.SharedDir) error {
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return err
+	}
+	sharedDirs := libhvee.ConfigureShareDirs(config.MachineConfig{SharedDirs: dirs})
+	if sameSharedDirs(driver.SharedDirs, sharedDirs) {
+		return nil
+	}
+	driver.SharedDirs = sharedDirs
+	return updateDriverConfig(host, driver)
+}
+
+// systemNetworkVMAddress returns an empty string as each VM gets its own
+// address from DHCP with system mode networking
+func systemNetworkVMAddress() string {
+	return ""
+}
+
+// setNetworkAddresses does nothing, the guest picks the MAC address of the VM
+// and it always gets the default address
+func setNetworkAddresses(_ *host.Host, _ network.InstanceAddresses) error {
+	return nil
+}
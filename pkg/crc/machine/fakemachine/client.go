@@ -3,7 +3,10 @@ package fakemachine
 import (
 	"context"
 	"errors"
+	"path/filepath"
+	"time"
 
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
 	"github.com/crc-org/crc/v2/pkg/crc/network/httpproxy"
@@ -30,6 +33,8 @@ func NewStopFailsInErrorStateClient() *Client {
 type Client struct {
 	Failing      bool
 	StopRetState state.State
+	// PullSecret is the last pull secret set with UpdatePullSecret
+	PullSecret string
 }
 
 var DummyClusterConfig = types.ClusterConfig{
@@ -79,11 +84,16 @@ func (c *Client) PowerOff() error {
 	return nil
 }
 
-func (c *Client) GenerateBundle(_ bool) error {
+func (c *Client) GenerateBundle(_ context.Context, config types.GenerateBundleConfig) (*types.GenerateBundleResult, error) {
 	if c.Failing {
-		return errors.New("bundle generation failed")
+		return nil, errors.New("bundle generation failed")
 	}
-	return nil
+	return &types.GenerateBundleResult{
+		Path:              filepath.Join(config.OutputDir, "crc_libvirt_4.6.1_"+config.Name+".crcbundle"),
+		Sha256sum:         "245a0e5acd4f09000a9a5f37d731082ed1cf3fdcad1b5320cbe9b153c9fd82a4",
+		CompressionFormat: config.Compression.GetFormat(),
+		Restarted:         config.KeepRunningState,
+	}, nil
 }
 
 func (c *Client) Start(_ context.Context, _ types.StartConfig) (*types.StartResult, error) {
@@ -138,3 +148,86 @@ func (c *Client) GetPreset() preset.Preset {
 func (c *Client) GetClusterLoad() (*types.ClusterLoadResult, error) {
 	return nil, errors.New("not implemented")
 }
+
+func (c *Client) CreateSnapshot(_ string) error {
+	if c.Failing {
+		return errors.New("snapshot creation failed")
+	}
+	return nil
+}
+
+func (c *Client) ListSnapshots() ([]types.Snapshot, error) {
+	if c.Failing {
+		return nil, errors.New("snapshot listing failed")
+	}
+	return []types.Snapshot{}, nil
+}
+
+func (c *Client) RestoreSnapshot(_ string) error {
+	if c.Failing {
+		return errors.New("snapshot restore failed")
+	}
+	return nil
+}
+
+func (c *Client) DeleteSnapshot(_ string) error {
+	if c.Failing {
+		return errors.New("snapshot deletion failed")
+	}
+	return nil
+}
+
+func (c *Client) CertificatesStatus() ([]types.CertificateStatus, error) {
+	if c.Failing {
+		return nil, errors.New("certificates status failed")
+	}
+	return []types.CertificateStatus{
+		{
+			Name:      "kubelet-client",
+			Path:      "/var/lib/kubelet/pki/kubelet-client-current.pem",
+			Subject:   "CN=system:node:crc,O=system:nodes",
+			Issuer:    "CN=kube-csr-signer",
+			NotBefore: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			NotAfter:  time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:    "kubelet-server",
+			Path:    "/var/lib/kubelet/pki/kubelet-server-current.pem",
+			Missing: true,
+		},
+	}, nil
+}
+
+func (c *Client) RotateCertificates(_ context.Context) error {
+	if c.Failing {
+		return errors.New("certificates rotation failed")
+	}
+	return nil
+}
+
+func (c *Client) Diagnostics(_ context.Context, write func(types.DiagnosticFile)) error {
+	if c.Failing {
+		return errors.New("diagnostics failed")
+	}
+	write(types.DiagnosticFile{
+		Name:    "vm/journal.log",
+		Content: []byte("kubelet: pulling image with kubeadmin password foobar\n"),
+	})
+	write(types.DiagnosticFile{
+		Name:  "cluster/clusteroperators.txt",
+		Error: "oc get clusteroperators failed",
+	})
+	return nil
+}
+
+func (c *Client) UpdatePullSecret(_ context.Context, pullSecret cluster.PullSecretLoader) error {
+	if c.Failing {
+		return errors.New("pull secret update failed")
+	}
+	value, err := pullSecret.Value()
+	if err != nil {
+		return err
+	}
+	c.PullSecret = value
+	return nil
+}
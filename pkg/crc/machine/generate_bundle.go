@@ -2,44 +2,201 @@ package machine
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/crc-org/crc/v2/pkg/compress"
 	"github.com/crc-org/crc/v2/pkg/crc/cluster"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	crcErr "github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
 	"github.com/crc-org/crc/v2/pkg/crc/oc"
-	crcssh "github.com/crc-org/crc/v2/pkg/crc/ssh"
-	"github.com/crc-org/machine/libmachine/state"
 	"github.com/pkg/errors"
 )
 
-func (client *client) GenerateBundle(forceStop bool) error {
-	bundleMetadata, sshRunner, err := loadVM(client)
+// GenerateBundle creates a custom bundle from the disk image of the instance.
+// The pull secret is removed from the cluster before the VM is stopped, a
+// stopped instance is started for this. Once the disk image is copied, the
+// instance is started to restore its pull secret, and it is stopped again
+// unless it was running and KeepRunningState is set.
+func (client *client) GenerateBundle(ctx context.Context, config types.GenerateBundleConfig) (*types.GenerateBundleResult, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
 	if err != nil {
-		return err
+		if errors.Is(err, errMissingHost(client.name)) {
+			return nil, crcErr.VMNotExist
+		}
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	bundleMetadata := vm.bundle
+	vmState, err := vm.State()
+	vm.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get machine state")
 	}
-	defer sshRunner.Close()
 
+	bundlePath, err := customBundlePath(bundleMetadata, config)
+	if err != nil {
+		return nil, err
+	}
+
+	wasRunning := vmState == state.Running
 	if bundleMetadata.IsOpenShift() {
-		ocConfig := oc.UseOCWithSSH(sshRunner)
-		if err := cluster.RemovePullSecretFromCluster(context.Background(), ocConfig, sshRunner); err != nil {
-			return errors.Wrap(err, "Error removing pull secret from cluster")
+		if !wasRunning {
+			logging.Info("Starting the instance to remove the pull secret from the cluster...")
+			if _, err := client.Start(ctx, config.StartConfig); err != nil {
+				return nil, errors.Wrap(err, "Error starting the instance")
+			}
+		}
+		if err := client.removeClusterPullSecret(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := client.stopForBundle(config.ForceStop); err != nil {
+		if bundleMetadata.IsOpenShift() {
+			if restoreErr := client.restoreClusterPullSecret(ctx, config.StartConfig.PullSecret); restoreErr != nil {
+				return nil, fmt.Errorf("%w, and the pull secret cannot be restored: %v", err, restoreErr)
+			}
 		}
+		return nil, err
+	}
 
-		if err := cluster.RemoveOldRenderedMachineConfig(ocConfig); err != nil {
-			return errors.Wrap(err, "Error removing old rendered machine configs")
+	sha256sum, err := client.writeBundle(bundleMetadata, bundlePath, config.Compression, config.Delta)
+	result := &types.GenerateBundleResult{
+		Path:              bundlePath,
+		Sha256sum:         sha256sum,
+		CompressionFormat: config.Compression.GetFormat(),
+		Restarted:         config.KeepRunningState && wasRunning,
+	}
+	if restoreErr := client.restoreAfterBundle(ctx, config, bundleMetadata.IsOpenShift(), result.Restarted); restoreErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("%w, and %v", err, restoreErr)
 		}
+		return nil, fmt.Errorf("Bundle is generated in %s but %w", bundlePath, restoreErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// restoreAfterBundle starts the instance once its disk image is copied, the
+// start puts the pull secret back in the cluster. The instance is stopped
+// again unless keepRunning is set.
+func (client *client) restoreAfterBundle(ctx context.Context, config types.GenerateBundleConfig, isOpenShift, keepRunning bool) error {
+	if !keepRunning && !isOpenShift {
+		return nil
+	}
+	if keepRunning {
+		logging.Info("Starting the instance again...")
+	} else {
+		logging.Info("Starting the instance to restore its pull secret...")
+	}
+	if _, err := client.Start(ctx, config.StartConfig); err != nil {
+		return errors.Wrap(err, "the instance cannot be started again")
+	}
+	if keepRunning {
+		return nil
+	}
+	if err := client.stopForBundle(config.ForceStop); err != nil {
+		return errors.Wrap(err, "the instance cannot be stopped after restoring its pull secret")
+	}
+	return nil
+}
+
+// customBundlePath returns the absolute path of the generated bundle, it
+// checks the settings of the bundle so that nothing is done to the instance
+// when they are invalid
+func customBundlePath(bundleMetadata *bundle.CrcBundleInfo, config types.GenerateBundleConfig) (string, error) {
+	if err := config.Compression.Validate(); err != nil {
+		return "", err
+	}
+	if config.Delta && bundleMetadata.GetDiskImageFormat() != "qcow2" {
+		return "", fmt.Errorf("cannot generate a delta bundle from a %s disk image", bundleMetadata.GetDiskImageFormat())
+	}
+	name := bundle.GetCustomBundleName(bundleMetadata.GetBundleName())
+	if config.Name != "" {
+		var err error
+		name, err = bundle.GetCustomBundleNameWithSuffix(bundleMetadata.GetBundleName(), config.Name)
+		if err != nil {
+			return "", err
+		}
+	}
+	outputDir := config.OutputDir
+	if outputDir == "" {
+		outputDir = "."
+	}
+	outputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return "", err
+	}
+	fi, err := os.Stat(outputDir)
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot use the output directory")
+	}
+	if !fi.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", outputDir)
+	}
+	bundlePath := filepath.Join(outputDir, name)
+	if _, err := os.Stat(bundlePath); err == nil {
+		return "", fmt.Errorf("%s already exists", bundlePath)
+	}
+	return bundlePath, nil
+}
+
+func (client *client) removeClusterPullSecret(ctx context.Context) error {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	ocConfig := oc.UseOCWithSSH(sshRunner)
+	if err := cluster.RemovePullSecretFromCluster(ctx, ocConfig, sshRunner); err != nil {
+		return errors.Wrap(err, "Error removing pull secret from cluster")
+	}
+
+	if err := cluster.RemoveOldRenderedMachineConfig(ocConfig); err != nil {
+		return errors.Wrap(err, "Error removing old rendered machine configs")
+	}
+	return nil
+}
+
+// restoreClusterPullSecret puts the pull secret back in the cluster of the
+// running instance, this is needed when it cannot be stopped after the pull
+// secret was removed
+func (client *client) restoreClusterPullSecret(ctx context.Context, pullSecret cluster.PullSecretLoader) error {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return errors.Wrap(err, "Error creating the ssh client")
 	}
+	defer sshRunner.Close()
 
-	// Stop the cluster
+	return cluster.EnsurePullSecretPresentInTheCluster(ctx, oc.UseOCWithSSH(sshRunner), pullSecret, false)
+}
+
+func (client *client) stopForBundle(forceStop bool) error {
 	if _, err := client.Stop(); err != nil {
-		if forceStop {
-			if err := client.PowerOff(); err != nil {
-				return err
-			}
-		} else {
+		if !forceStop {
+			return err
+		}
+		if err := client.PowerOff(); err != nil {
 			return err
 		}
 	}
@@ -50,20 +207,22 @@ func (client *client) GenerateBundle(forceStop bool) error {
 	if running {
 		return errors.New("VM is still running")
 	}
+	return nil
+}
 
+func (client *client) writeBundle(bundleMetadata *bundle.CrcBundleInfo, bundlePath string, compression compress.Options, delta bool) (string, error) {
 	tmpBaseDir, err := os.MkdirTemp(constants.MachineCacheDir, "crc_custom_bundle")
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer os.RemoveAll(tmpBaseDir)
 
 	// Create the custom bundle directory which is used as top level directory for tarball during compression
-	customBundleName := bundle.GetCustomBundleName(bundleMetadata.GetBundleName())
-	customBundleNameWithoutExtension := bundle.GetBundleNameWithoutExtension(customBundleName)
+	customBundleNameWithoutExtension := bundle.GetBundleNameWithoutExtension(filepath.Base(bundlePath))
 
 	copier, err := bundle.NewCopier(bundleMetadata, tmpBaseDir, customBundleNameWithoutExtension)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer func() {
 		if err := copier.Cleanup(); err != nil {
@@ -73,60 +232,43 @@ func (client *client) GenerateBundle(forceStop bool) error {
 	customBundleDir := copier.CachedPath()
 
 	if err := copier.CopyKubeConfig(); err != nil {
-		return err
+		return "", err
 	}
 
-	if err := copier.CopyPrivateSSHKey(constants.GetPrivateKeyPath()); err != nil {
-		return err
+	if err := copier.CopyPrivateSSHKey(constants.GetPrivateKeyPath(client.name)); err != nil {
+		return "", err
 	}
 
 	if err := copier.CopyFilesFromFileList(); err != nil {
-		return err
+		return "", err
 	}
 
 	// Copy disk image
 	logging.Infof("Copying the disk image to %s", customBundleNameWithoutExtension)
 	logging.Debugf("Absolute path of custom bundle directory: %s", customBundleDir)
-	diskPath, diskFormat, err := copyDiskImage(customBundleDir)
-	if err != nil {
-		return err
-	}
-
-	if err := copier.SetDiskImage(diskPath, diskFormat); err != nil {
-		return err
-	}
-
-	if err := copier.GenerateBundle(customBundleNameWithoutExtension); err != nil {
-		return err
-	}
-	cwd, err := os.Getwd()
-	if err != nil {
-		return err
+	var baseDiskImage, backingFile string
+	if delta {
+		backingFile, err = copier.SetBaseBundle()
+		if err != nil {
+			return "", err
+		}
+		baseDiskImage = bundleMetadata.GetDiskImagePath()
+		logging.Infof("Generating a delta bundle of %s", bundleMetadata.GetBundleName())
 	}
-	logging.Infof("Bundle is generated in %s", filepath.Join(cwd, customBundleName))
-	logging.Infof("You need to perform 'crc delete' and 'crc start -b %s' to use this bundle", filepath.Join(cwd, customBundleName))
-	return nil
-}
-
-func loadVM(client *client) (*bundle.CrcBundleInfo, *crcssh.Runner, error) {
-	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	diskPath, diskFormat, err := copyDiskImage(client.name, customBundleDir, baseDiskImage, backingFile)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "Cannot load machine")
+		return "", err
 	}
-	defer vm.Close()
 
-	currentState, err := vm.Driver.GetState()
-	if err != nil {
-		return nil, nil, errors.Wrap(err, "Cannot get machine state")
-	}
-	if currentState != state.Running {
-		return nil, nil, errors.New("machine is not running")
+	if err := copier.SetDiskImage(diskPath, diskFormat); err != nil {
+		return "", err
 	}
 
-	sshRunner, err := vm.SSHRunner()
+	sha256sum, err := copier.GenerateBundle(bundlePath, compression)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "Error creating the ssh client")
+		// do not leave a truncated bundle behind
+		_ = os.Remove(bundlePath)
+		return "", err
 	}
-
-	return vm.bundle, sshRunner, nil
+	return sha256sum, nil
 }
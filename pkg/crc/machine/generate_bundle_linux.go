@@ -4,22 +4,36 @@ import (
 	"fmt"
 	"path/filepath"
 
-	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	crcos "github.com/crc-org/crc/v2/pkg/os"
 )
 
-func copyDiskImage(destDir string) (string, string, error) {
+// copyDiskImage copies the disk image of the instance to destDir. When
+// baseDiskImage is set, the copy is a qcow2 overlay with only the changes
+// made to baseDiskImage, and backingFile is recorded as its backing file.
+func copyDiskImage(machineName, destDir, baseDiskImage, backingFile string) (string, string, error) {
 	const destFormat = "qcow2"
 
-	imageName := fmt.Sprintf("%s.qcow2", constants.DefaultName)
+	srcPath := diskImagePath(machineName)
+	destPath := filepath.Join(destDir, filepath.Base(srcPath))
 
-	srcPath := filepath.Join(constants.MachineInstanceDir, constants.DefaultName, imageName)
-	destPath := filepath.Join(destDir, imageName)
-
-	_, _, err := crcos.RunWithDefaultLocale("qemu-img", "convert", "-f", "qcow2", "-O", destFormat, srcPath, destPath)
+	args := []string{"convert", "-f", "qcow2", "-O", destFormat}
+	if baseDiskImage != "" {
+		args = append(args, "-B", baseDiskImage, "-F", "qcow2")
+	}
+	args = append(args, srcPath, destPath)
+	_, _, err := crcos.RunWithDefaultLocale("qemu-img", args...)
 	if err != nil {
 		return "", "", err
 	}
 
+	if backingFile != "" {
+		// only the header is changed, the backing file does not exist until
+		// the bundle is extracted next to its base bundle
+		_, stderr, err := crcos.RunWithDefaultLocale("qemu-img", "rebase", "-u", "-f", "qcow2", "-F", "qcow2", "-b", backingFile, destPath)
+		if err != nil {
+			return "", "", fmt.Errorf("%s: %w", stderr, err)
+		}
+	}
+
 	return destPath, destFormat, nil
 }
@@ -0,0 +1,51 @@
+package machine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/compress"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomBundlePath(t *testing.T) {
+	bundleMetadata := &bundle.CrcBundleInfo{Name: "crc_libvirt_4.7.1_amd64"}
+	outputDir := t.TempDir()
+
+	path, err := customBundlePath(bundleMetadata, types.GenerateBundleConfig{
+		Name:        "my-app",
+		OutputDir:   outputDir,
+		Compression: compress.Options{Level: 19},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(outputDir, "crc_libvirt_4.7.1_amd64_my-app.crcbundle"), path)
+
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+	_, err = customBundlePath(bundleMetadata, types.GenerateBundleConfig{Name: "my-app", OutputDir: outputDir})
+	assert.EqualError(t, err, path+" already exists")
+}
+
+func TestCustomBundlePathInvalidConfig(t *testing.T) {
+	bundleMetadata := &bundle.CrcBundleInfo{Name: "crc_libvirt_4.7.1_amd64"}
+	outputDir := t.TempDir()
+
+	_, err := customBundlePath(bundleMetadata, types.GenerateBundleConfig{Name: "my app", OutputDir: outputDir})
+	assert.Error(t, err)
+	_, err = customBundlePath(bundleMetadata, types.GenerateBundleConfig{OutputDir: outputDir, Compression: compress.Options{Level: 30}})
+	assert.Error(t, err)
+	_, err = customBundlePath(bundleMetadata, types.GenerateBundleConfig{OutputDir: filepath.Join(outputDir, "missing")})
+	assert.Error(t, err)
+
+	bundleMetadata.Storage.DiskImages = []bundle.DiskImage{{Format: "raw"}}
+	_, err = customBundlePath(bundleMetadata, types.GenerateBundleConfig{OutputDir: outputDir, Delta: true})
+	assert.EqualError(t, err, "cannot generate a delta bundle from a raw disk image")
+
+	file := filepath.Join(outputDir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+	_, err = customBundlePath(bundleMetadata, types.GenerateBundleConfig{OutputDir: file})
+	assert.EqualError(t, err, file+" is not a directory")
+}
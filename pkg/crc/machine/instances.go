@@ -0,0 +1,255 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
+	"github.com/pkg/errors"
+)
+
+// ClientFactory creates the client used to manage the instance called name
+type ClientFactory func(name string) Client
+
+// Instances keeps a single client per instance name, so that lifecycle
+// operations targeting the same instance go through the same (synchronized)
+// client.
+type Instances struct {
+	// defaultClient is never modified, it can be read without the lock
+	defaultClient Client
+
+	lock      sync.Mutex
+	clients   map[string]Client
+	newClient ClientFactory
+}
+
+// NewInstances creates a registry containing defaultClient. When newClient is
+// nil, no other instance can be accessed.
+func NewInstances(defaultClient Client, newClient ClientFactory) *Instances {
+	return &Instances{
+		defaultClient: defaultClient,
+		clients: map[string]Client{
+			defaultClient.GetName(): defaultClient,
+		},
+		newClient: newClient,
+	}
+}
+
+// NewSynchronizedClientFactory returns a ClientFactory creating synchronized clients
+func NewSynchronizedClientFactory(debug bool, config crcConfig.Storage) ClientFactory {
+	return func(name string) Client {
+		return NewSynchronizedMachine(NewClient(name, debug, config))
+	}
+}
+
+// Default returns the client for the default instance
+func (i *Instances) Default() Client {
+	return i.defaultClient
+}
+
+// Get returns the client for the instance called name, creating it if needed
+func (i *Instances) Get(name string) (Client, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if client, ok := i.clients[name]; ok {
+		return client, nil
+	}
+	if i.newClient == nil {
+		return nil, fmt.Errorf("unknown instance: %s", name)
+	}
+	client := i.newClient(name)
+	i.clients[name] = client
+	return client, nil
+}
+
+// List returns the names of the instances which can be accessed through i
+func (i *Instances) List() ([]string, error) {
+	if i.newClient != nil {
+		return ListInstances()
+	}
+	exists, err := i.Default().Exists()
+	if err != nil || !exists {
+		return []string{}, err
+	}
+	return []string{i.defaultClient.GetName()}, nil
+}
+
+// ListInstances returns the names of all the instances which have been created
+func ListInstances() ([]string, error) {
+	api, cleanup := createLibMachineClient()
+	defer cleanup()
+	return api.List()
+}
+
+// checkVMAddressAvailable fails when another instance is running with the
+// same virtual machine address, only the addresses allocated by the host can
+// be different for each instance
+func (client *client) checkVMAddressAvailable() error {
+	address, err := sharedVMAddress(client.name, client.useVSock())
+	if err != nil || address == "" {
+		return err
+	}
+	names, err := ListInstances()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name == client.name {
+			continue
+		}
+		running, err := NewClient(name, client.debug, client.config).IsRunning()
+		if err != nil {
+			logging.Debugf("Cannot get the state of instance %s: %v", name, err)
+			continue
+		}
+		if !running {
+			continue
+		}
+		otherAddress, err := sharedVMAddress(name, client.useVSock())
+		if err != nil {
+			return err
+		}
+		if otherAddress == address {
+			return fmt.Errorf("Instance '%s' is running with the same virtual machine address %s. Stop it with 'crc stop --name %s' first", name, address, name)
+		}
+	}
+	return nil
+}
+
+// sharedVMAddress returns the address of the virtual machine of the instance
+// called name when it cannot be different for each instance, and an empty
+// string when the instance gets its own address
+func sharedVMAddress(name string, useVSock bool) (string, error) {
+	if !useVSock {
+		return systemNetworkVMAddress(), nil
+	}
+	addresses, err := getInstanceAddresses(name)
+	if err != nil {
+		return "", err
+	}
+	if addresses.HasOwnVMAddress() {
+		return "", nil
+	}
+	return addresses.VMIP(), nil
+}
+
+var (
+	instanceAddressesPath = filepath.Join(constants.MachineBaseDir, "instance-addresses.json")
+	instanceAddressesLock sync.Mutex
+)
+
+// getInstanceAddresses returns the addresses allocated to the instance called
+// name, they are allocated the first time. The allocations of the instances
+// which do not exist anymore are reused.
+func getInstanceAddresses(name string) (network.InstanceAddresses, error) {
+	if name == constants.DefaultName {
+		return network.InstanceAddresses{}, nil
+	}
+	instanceAddressesLock.Lock()
+	defer instanceAddressesLock.Unlock()
+
+	allocations, err := readInstanceAddresses()
+	if err != nil {
+		return network.InstanceAddresses{}, err
+	}
+	if addresses, ok := allocations[name]; ok {
+		return addresses, nil
+	}
+	names, err := ListInstances()
+	if err != nil {
+		return network.InstanceAddresses{}, err
+	}
+	used := map[int]bool{0: true}
+	for other, addresses := range allocations {
+		if slices.Contains(names, other) {
+			used[addresses.Index] = true
+		} else {
+			delete(allocations, other)
+		}
+	}
+	for i := 1; i < network.MaxInstances; i++ {
+		if used[i] {
+			continue
+		}
+		allocations[name] = network.InstanceAddresses{Index: i}
+		if err := writeInstanceAddresses(allocations); err != nil {
+			return network.InstanceAddresses{}, err
+		}
+		return allocations[name], nil
+	}
+	return network.InstanceAddresses{}, fmt.Errorf("cannot allocate addresses to instance '%s', at most %d instances can exist", name, network.MaxInstances)
+}
+
+// releaseInstanceAddresses frees the addresses allocated to the instance
+// called name
+func releaseInstanceAddresses(name string) error {
+	instanceAddressesLock.Lock()
+	defer instanceAddressesLock.Unlock()
+
+	allocations, err := readInstanceAddresses()
+	if err != nil {
+		return err
+	}
+	if _, ok := allocations[name]; !ok {
+		return nil
+	}
+	delete(allocations, name)
+	return writeInstanceAddresses(allocations)
+}
+
+func readInstanceAddresses() (map[string]network.InstanceAddresses, error) {
+	allocations := map[string]network.InstanceAddresses{}
+	content, err := os.ReadFile(instanceAddressesPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return allocations, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &allocations); err != nil {
+		return nil, errors.Wrapf(err, "invalid instance addresses file %s", instanceAddressesPath)
+	}
+	return allocations, nil
+}
+
+func writeInstanceAddresses(allocations map[string]network.InstanceAddresses) error {
+	content, err := json.Marshal(allocations)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(instanceAddressesPath), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(instanceAddressesPath, content, 0600)
+}
+
+// ListInstanceBundles returns the names of the bundles used by the existing instances
+func ListInstanceBundles() ([]string, error) {
+	api, cleanup := createLibMachineClient()
+	defer cleanup()
+	names, err := api.List()
+	if err != nil {
+		return nil, err
+	}
+	var bundles []string
+	for _, name := range names {
+		host, err := api.Load(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot load machine %s", name)
+		}
+		bundleName, err := host.Driver.GetBundleName()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot get the bundle used by %s", name)
+		}
+		bundles = append(bundles, bundleName)
+	}
+	return bundles, nil
+}
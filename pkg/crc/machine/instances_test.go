@@ -0,0 +1,41 @@
+package machine
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstancesConcurrentAccess(t *testing.T) {
+	defaultClient := fakemachine.NewClient()
+	instances := NewInstances(defaultClient, func(string) Client {
+		return fakemachine.NewClient()
+	})
+
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := instances.Get(fmt.Sprintf("instance-%d-%d", n, j))
+				assert.NoError(t, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.Same(t, defaultClient, instances.Default())
+			}
+		}()
+	}
+	wg.Wait()
+
+	client, err := instances.Get(defaultClient.GetName())
+	require.NoError(t, err)
+	assert.Same(t, defaultClient, client)
+}
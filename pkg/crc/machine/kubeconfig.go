@@ -26,10 +26,17 @@ import (
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
-const (
-	adminContext     = "crc-admin"
-	developerContext = "crc-developer"
-)
+// adminContext returns the name of the kubeadmin context added to the
+// user kubeconfig, 'crc-admin' for the default instance.
+func adminContext(machineName string) string {
+	return fmt.Sprintf("%s-admin", machineName)
+}
+
+// developerContext returns the name of the developer context added to the
+// user kubeconfig, 'crc-developer' for the default instance.
+func developerContext(machineName string) string {
+	return fmt.Sprintf("%s-developer", machineName)
+}
 
 func updateClientCrtAndKeyToKubeconfig(clientKey, clientCrt []byte, srcKubeconfigPath, destKubeconfigPath string) error {
 	cfg, err := clientcmd.LoadFromFile(srcKubeconfigPath)
@@ -50,7 +57,11 @@ func updateClientCrtAndKeyToKubeconfig(clientKey, clientCrt []byte, srcKubeconfi
 	return clientcmd.WriteToFile(*cfg, destKubeconfigPath)
 }
 
-func writeKubeconfig(ip string, clusterConfig *types.ClusterConfig, ingressHTTPSPort uint) error {
+// writeKubeconfig adds the kubeadmin and developer contexts of the instance
+// to the user kubeconfig. The entries of the default instance keep the names
+// derived from the API URL, the ones of the other instances are named after
+// the instance and use apiAddress so that they do not overwrite each other.
+func writeKubeconfig(machineName, ip, apiAddress string, clusterConfig *types.ClusterConfig, ingressHTTPSPort uint) error {
 	kubeconfig, cfg, err := GetGlobalKubeConfig()
 	if err != nil {
 		return err
@@ -59,21 +70,25 @@ func writeKubeconfig(ip string, clusterConfig *types.ClusterConfig, ingressHTTPS
 	if err != nil {
 		return err
 	}
-	host, err := hostname(clusterConfig.ClusterAPI)
+	clusterName, err := clusterEntryName(machineName, clusterConfig.ClusterAPI)
 	if err != nil {
 		return err
 	}
 
-	cfg.Clusters[host] = &api.Cluster{
+	cluster := &api.Cluster{
 		Server:                   clusterConfig.ClusterAPI,
 		CertificateAuthorityData: ca,
 	}
+	if err := useInstanceAPIAddress(machineName, cluster, apiAddress); err != nil {
+		return err
+	}
+	cfg.Clusters[clusterName] = cluster
 
 	kubeadminToken, err := getTokenForUser("kubeadmin", clusterConfig.KubeAdminPass, ip, ca, clusterConfig, ingressHTTPSPort)
 	if err != nil {
 		return err
 	}
-	if err := addContext(cfg, clusterConfig.ClusterAPI, adminContext, "kubeadmin", kubeadminToken, "default"); err != nil {
+	if err := addContext(cfg, machineName, clusterConfig.ClusterAPI, adminContext(machineName), "kubeadmin", kubeadminToken, "default"); err != nil {
 		return err
 	}
 
@@ -81,12 +96,12 @@ func writeKubeconfig(ip string, clusterConfig *types.ClusterConfig, ingressHTTPS
 	if err != nil {
 		return err
 	}
-	if err := addContext(cfg, clusterConfig.ClusterAPI, developerContext, "developer", developerToken, ""); err != nil {
+	if err := addContext(cfg, machineName, clusterConfig.ClusterAPI, developerContext(machineName), "developer", developerToken, ""); err != nil {
 		return err
 	}
 
 	if cfg.CurrentContext == "" {
-		cfg.CurrentContext = adminContext
+		cfg.CurrentContext = adminContext(machineName)
 	}
 
 	return clientcmd.WriteToFile(*cfg, kubeconfig)
@@ -143,14 +158,49 @@ func hostname(clusterAPI string) (string, error) {
 	return strings.ReplaceAll(h, ".", "-"), nil
 }
 
-func addContext(cfg *api.Config, clusterAPI, context, username, token, namespace string) error {
-	host, err := hostname(clusterAPI)
+// clusterEntryName returns the name of the cluster of the instance in the
+// user kubeconfig
+func clusterEntryName(machineName, clusterAPI string) (string, error) {
+	if machineName != constants.DefaultName {
+		return machineName, nil
+	}
+	return hostname(clusterAPI)
+}
+
+// userEntryName returns the name of a user of the instance in the user
+// kubeconfig
+func userEntryName(machineName, username, clusterAPI string) (string, error) {
+	if machineName != constants.DefaultName {
+		return fmt.Sprintf("%s/%s", username, machineName), nil
+	}
+	// append /clustername to AuthInfo
+	return appendClusternameToUser(username, clusterAPI)
+}
+
+// useInstanceAPIAddress makes the cluster of an instance other than the
+// default one connect to apiAddress, the API URL of all the instances is the
+// same and only the default instance can rely on the hosts file
+func useInstanceAPIAddress(machineName string, cluster *api.Cluster, apiAddress string) error {
+	if machineName == constants.DefaultName {
+		return nil
+	}
+	u, err := url.Parse(cluster.Server)
 	if err != nil {
 		return err
 	}
+	cluster.TLSServerName = u.Hostname()
+	u.Host = apiAddress
+	cluster.Server = u.String()
+	return nil
+}
 
-	// append /clustername to AuthInfo
-	clusterUser, err := appendClusternameToUser(username, clusterAPI)
+func addContext(cfg *api.Config, machineName, clusterAPI, context, username, token, namespace string) error {
+	host, err := clusterEntryName(machineName, clusterAPI)
+	if err != nil {
+		return err
+	}
+
+	clusterUser, err := userEntryName(machineName, username, clusterAPI)
 	if err != nil {
 		return err
 	}
@@ -228,7 +278,9 @@ func getGlobalKubeConfigPath() string {
 	return filepath.Join(constants.GetHomeDir(), ".kube", "config")
 }
 
-func cleanKubeconfig(input, output string) error {
+// cleanKubeconfig removes the clusters, contexts and users of the instance
+// called machineName, the entries of the other instances are kept
+func cleanKubeconfig(input, output, machineName string) error {
 	cfg, err := clientcmd.LoadFromFile(input)
 	if err != nil {
 		return err
@@ -236,6 +288,12 @@ func cleanKubeconfig(input, output string) error {
 
 	var clusterNames []string
 	for name, cluster := range cfg.Clusters {
+		if machineName != constants.DefaultName {
+			if name == machineName {
+				clusterNames = append(clusterNames, name)
+			}
+			continue
+		}
 		if cluster.Server == fmt.Sprintf("https://api%s:6443", constants.ClusterDomain) {
 			clusterNames = append(clusterNames, name)
 		}
@@ -271,11 +329,11 @@ func cleanKubeconfig(input, output string) error {
 	return clientcmd.WriteToFile(*cfg, output)
 }
 
-func mergeKubeConfigFile(kubeConfigFile string) error {
-	return mergeConfigHelper(kubeConfigFile, getGlobalKubeConfigPath())
+func mergeKubeConfigFile(machineName, apiAddress, kubeConfigFile string) error {
+	return mergeConfigHelper(machineName, apiAddress, kubeConfigFile, getGlobalKubeConfigPath())
 }
 
-func mergeConfigHelper(kubeConfigFile, globalConfigFile string) error {
+func mergeConfigHelper(machineName, apiAddress, kubeConfigFile, globalConfigFile string) error {
 
 	globalConfigPath, globalConf, err := getKubeConfigFromFile(globalConfigFile)
 	if err != nil {
@@ -291,6 +349,12 @@ func mergeConfigHelper(kubeConfigFile, globalConfigFile string) error {
 	if err != nil {
 		return err
 	}
+	if machineName != constants.DefaultName {
+		cfg, err = renameForInstance(cfg, machineName, apiAddress)
+		if err != nil {
+			return err
+		}
+	}
 	// Merge the currentConf to globalConfig
 	for name, cluster := range cfg.Clusters {
 		globalConf.Clusters[name] = cluster
@@ -331,6 +395,33 @@ func appendClusterToAuthinfos(cfg *api.Config) (*api.Config, error) {
 	return cfg, nil
 }
 
+// renameForInstance names the entries of cfg after the instance so that they
+// do not overwrite the ones of the other instances when they are merged
+func renameForInstance(cfg *api.Config, machineName, apiAddress string) (*api.Config, error) {
+	renamed := api.NewConfig()
+	for _, cluster := range cfg.Clusters {
+		if err := useInstanceAPIAddress(machineName, cluster, apiAddress); err != nil {
+			return cfg, err
+		}
+		renamed.Clusters[machineName] = cluster
+	}
+	for name, authInfo := range cfg.AuthInfos {
+		username, _, _ := strings.Cut(name, "/")
+		renamed.AuthInfos[fmt.Sprintf("%s/%s", username, machineName)] = authInfo
+	}
+	for name, ctx := range cfg.Contexts {
+		username, _, _ := strings.Cut(ctx.AuthInfo, "/")
+		ctx.Cluster = machineName
+		ctx.AuthInfo = fmt.Sprintf("%s/%s", username, machineName)
+		contextName := fmt.Sprintf("%s-%s", machineName, name)
+		renamed.Contexts[contextName] = ctx
+		if cfg.CurrentContext == name {
+			renamed.CurrentContext = contextName
+		}
+	}
+	return renamed, nil
+}
+
 func appendClusternameToUser(username, clusterAPI string) (string, error) {
 	url, err := url.Parse(clusterAPI)
 	if err != nil {
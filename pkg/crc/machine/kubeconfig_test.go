@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
@@ -44,7 +45,7 @@ func TestCertificateAuthority(t *testing.T) {
 func TestCleanKubeconfig(t *testing.T) {
 	dir := t.TempDir()
 
-	assert.NoError(t, cleanKubeconfig(filepath.Join("testdata", "kubeconfig.in"), filepath.Join(dir, "kubeconfig")))
+	assert.NoError(t, cleanKubeconfig(filepath.Join("testdata", "kubeconfig.in"), filepath.Join(dir, "kubeconfig"), constants.DefaultName))
 	actual, err := os.ReadFile(filepath.Join(dir, "kubeconfig"))
 	assert.NoError(t, err)
 	expected, err := os.ReadFile(filepath.Join("testdata", "kubeconfig.out"))
@@ -56,7 +57,7 @@ func TestCleanKubeConfigIdempotency(t *testing.T) {
 	// Given
 	dir := t.TempDir()
 	// When
-	assert.NoError(t, cleanKubeconfig(filepath.Join("testdata", "kubeconfig.out"), filepath.Join(dir, "kubeconfig")))
+	assert.NoError(t, cleanKubeconfig(filepath.Join("testdata", "kubeconfig.out"), filepath.Join(dir, "kubeconfig"), constants.DefaultName))
 	actual, err := os.ReadFile(filepath.Join(dir, "kubeconfig"))
 	// Then
 	assert.NoError(t, err)
@@ -69,7 +70,7 @@ func TestCleanKubeConfigShouldDoNothingWhenClusterDomainIsNotEqualToCrcTesting(t
 	// Given
 	dir := t.TempDir()
 	// When
-	assert.NoError(t, cleanKubeconfig(filepath.Join("testdata", "kubeconfig-without-api-crc-testing-cluster-domain"), filepath.Join(dir, "kubeconfig")))
+	assert.NoError(t, cleanKubeconfig(filepath.Join("testdata", "kubeconfig-without-api-crc-testing-cluster-domain"), filepath.Join(dir, "kubeconfig"), constants.DefaultName))
 	actual, err := os.ReadFile(filepath.Join(dir, "kubeconfig"))
 	// Then
 	assert.NoError(t, err)
@@ -78,6 +79,42 @@ func TestCleanKubeConfigShouldDoNothingWhenClusterDomainIsNotEqualToCrcTesting(t
 	assert.YAMLEq(t, string(expected), string(actual))
 }
 
+func TestCleanKubeconfigKeepsOtherInstances(t *testing.T) {
+	cfg := api.NewConfig()
+	cfg.Clusters["api-crc-testing:6443"] = &api.Cluster{Server: "https://api.crc.testing:6443"}
+	assert.NoError(t, addContext(cfg, constants.DefaultName, "https://api.crc.testing:6443", "crc-admin", "kubeadmin", "token", "default"))
+	for _, name := range []string{"first", "second"} {
+		cluster := &api.Cluster{Server: "https://api.crc.testing:6443"}
+		assert.NoError(t, useInstanceAPIAddress(name, cluster, "127.0.0.1:6444"))
+		cfg.Clusters[name] = cluster
+		assert.NoError(t, addContext(cfg, name, "https://api.crc.testing:6443", adminContext(name), "kubeadmin", "token", "default"))
+	}
+	cfg.CurrentContext = "first-admin"
+	path, err := createTempKubeConfig(cfg)
+	assert.NoError(t, err)
+	defer os.Remove(path)
+
+	assert.NoError(t, cleanKubeconfig(path, path, "first"))
+	cleaned, err := clientcmd.LoadFromFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, cleaned.Clusters, "first")
+	assert.NotContains(t, cleaned.Contexts, "first-admin")
+	assert.NotContains(t, cleaned.AuthInfos, "kubeadmin/first")
+	assert.Empty(t, cleaned.CurrentContext)
+	assert.Contains(t, cleaned.Contexts, "second-admin")
+	assert.Contains(t, cleaned.Contexts, "crc-admin")
+
+	assert.NoError(t, cleanKubeconfig(path, path, constants.DefaultName))
+	cleaned, err = clientcmd.LoadFromFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, cleaned.Contexts, "crc-admin")
+	assert.NotContains(t, cleaned.AuthInfos, "kubeadmin/api-crc-testing:6443")
+	assert.Contains(t, cleaned.Clusters, "second")
+	assert.Equal(t, "https://127.0.0.1:6444", cleaned.Clusters["second"].Server)
+	assert.Equal(t, "api.crc.testing", cleaned.Clusters["second"].TLSServerName)
+	assert.Equal(t, "kubeadmin/second", cleaned.Contexts["second-admin"].AuthInfo)
+}
+
 func TestUpdateUserCaAndKeyToKubeconfig(t *testing.T) {
 	f, err := os.CreateTemp("", "kubeconfig")
 	assert.NoError(t, err, "")
@@ -140,7 +177,7 @@ func Test_mergeKubeConfigFile(t *testing.T) {
 	assert.NoError(t, err, "failed to create temporary kubeconfig file")
 	defer os.Remove(secondaryConfigPath)
 
-	err = mergeConfigHelper(secondaryConfigPath, primaryConfigPath)
+	err = mergeConfigHelper(constants.DefaultName, "", secondaryConfigPath, primaryConfigPath)
 	assert.NoError(t, err, "failed to modify kubeconfig")
 
 	// Load the modified kubeconfig to ensure it was merged correctly
@@ -196,7 +233,7 @@ func Test_addContext(t *testing.T) {
 	cfg := api.NewConfig()
 
 	for _, tt := range tests {
-		err := addContext(cfg, tt.in.clusterAPI, tt.in.context, tt.in.username, tt.in.token, tt.in.namespace)
+		err := addContext(cfg, constants.DefaultName, tt.in.clusterAPI, tt.in.context, tt.in.username, tt.in.token, tt.in.namespace)
 		assert.NoError(t, err)
 		assert.Contains(t, cfg.Contexts, tt.in.context, "Expected context not found")
 		assert.Equal(t, cfg.Contexts[tt.in.context].Namespace, tt.expected.namespace, "Expected namespace not found")
@@ -14,18 +14,25 @@ func CreateHost(machineConfig config.MachineConfig) *libhvee.Driver {
 
 	config.InitVMDriverFromMachineConfig(machineConfig, libhveeDriver.VMDriver)
 
-	libhveeDriver.SharedDirs = configureShareDirs(machineConfig)
+	libhveeDriver.SharedDirs = ConfigureShareDirs(machineConfig)
 	return libhveeDriver
 }
 
-func configureShareDirs(machineConfig config.MachineConfig) []drivers.SharedDir {
+// ConfigureShareDirs returns the shared directories of the driver for the
+// directories of machineConfig
+func ConfigureShareDirs(machineConfig config.MachineConfig) []drivers.SharedDir {
 	var sharedDirs []drivers.SharedDir
 	for i, dir := range machineConfig.SharedDirs {
+		target := dir.Target
+		if target == "" {
+			target = ConvertToUnixPath(dir.Source)
+		}
 		sharedDir := drivers.SharedDir{
-			Source: dir,
-			Target: ConvertToUnixPath(dir),
-			Tag:    fmt.Sprintf("dir%d", i),
-			Type:   "9p",
+			Source:   dir.Source,
+			Target:   target,
+			Tag:      fmt.Sprintf("dir%d", i),
+			Type:     "9p",
+			ReadOnly: dir.ReadOnly,
 		}
 		sharedDirs = append(sharedDirs, sharedDir)
 	}
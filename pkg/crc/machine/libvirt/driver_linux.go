@@ -23,19 +23,26 @@ func CreateHost(machineConfig config.MachineConfig) *libvirt.Driver {
 	}
 
 	libvirtDriver.StoragePool = DefaultStoragePool
-	libvirtDriver.SharedDirs = configureShareDirs(machineConfig)
+	libvirtDriver.SharedDirs = ConfigureShareDirs(machineConfig)
 
 	return libvirtDriver
 }
 
-func configureShareDirs(machineConfig config.MachineConfig) []drivers.SharedDir {
+// ConfigureShareDirs returns the shared directories of the driver for the
+// directories of machineConfig
+func ConfigureShareDirs(machineConfig config.MachineConfig) []drivers.SharedDir {
 	var sharedDirs []drivers.SharedDir
 	for i, dir := range machineConfig.SharedDirs {
+		target := dir.Target
+		if target == "" {
+			target = dir.Source
+		}
 		sharedDir := drivers.SharedDir{
-			Source: dir,
-			Target: dir,
-			Tag:    fmt.Sprintf("dir%d", i),
-			Type:   "virtiofs",
+			Source:   dir.Source,
+			Target:   target,
+			Tag:      fmt.Sprintf("dir%d", i),
+			Type:     "virtiofs",
+			ReadOnly: dir.ReadOnly,
 		}
 		sharedDirs = append(sharedDirs, sharedDir)
 	}
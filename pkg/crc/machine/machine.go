@@ -13,7 +13,8 @@ import (
 	"github.com/crc-org/machine/libmachine/drivers"
 )
 
-func getClusterConfig(bundleInfo *bundle.CrcBundleInfo) (*types.ClusterConfig, error) {
+func getClusterConfig(vm *virtualMachine) (*types.ClusterConfig, error) {
+	machineName, bundleInfo := vm.name, vm.bundle
 	if !bundleInfo.IsOpenShift() {
 		return &types.ClusterConfig{
 			ClusterType: bundleInfo.GetBundleType(),
@@ -21,11 +22,11 @@ func getClusterConfig(bundleInfo *bundle.CrcBundleInfo) (*types.ClusterConfig, e
 		}, nil
 	}
 
-	kubeadminPassword, err := cluster.GetUserPassword(constants.GetKubeAdminPasswordPath())
+	kubeadminPassword, err := cluster.GetUserPassword(constants.GetKubeAdminPasswordPath(machineName))
 	if err != nil {
 		return nil, fmt.Errorf("error reading kubeadmin password from bundle: %w", err)
 	}
-	developerPassword, err := cluster.GetUserPassword(constants.GetDeveloperPasswordPath())
+	developerPassword, err := cluster.GetUserPassword(constants.GetDeveloperPasswordPath(machineName))
 	if err != nil {
 		return nil, fmt.Errorf("error reading developer password from bundle: %w", err)
 	}
@@ -43,8 +44,8 @@ func getClusterConfig(bundleInfo *bundle.CrcBundleInfo) (*types.ClusterConfig, e
 		KubeConfig:    bundleInfo.GetKubeConfigPath(),
 		KubeAdminPass: kubeadminPassword,
 		DeveloperPass: developerPassword,
-		WebConsoleURL: fmt.Sprintf("https://%s", bundleInfo.GetAppHostname("console-openshift-console")),
-		ClusterAPI:    fmt.Sprintf("https://%s:6443", bundleInfo.GetAPIHostname()),
+		WebConsoleURL: fmt.Sprintf("https://%s", vm.webConsoleHost()),
+		ClusterAPI:    fmt.Sprintf("https://%s:%d", bundleInfo.GetAPIHostname(), vm.APIPort()),
 		ProxyConfig:   proxyConfig,
 	}, nil
 }
@@ -0,0 +1,237 @@
+package machine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	crcerrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/oc"
+)
+
+const (
+	provisioningStateFile = "provisioning.json"
+	vmProvisioningDir     = "/tmp/crc-provisioning"
+
+	// subdirectories of the provisioning directory, processed in this order
+	provisioningManifestsDir = "manifests"
+	provisioningVMHooksDir   = "hooks/vm"
+	provisioningHostHooksDir = "hooks/host"
+)
+
+// vmRunner is the subset of ssh.Runner needed to provision the cluster
+type vmRunner interface {
+	Run(command string, args ...string) (string, string, error)
+	CopyData(data []byte, destFilename string, mode os.FileMode) error
+}
+
+// provisioningState records the sha256sum of the manifests and hooks which
+// were successfully applied, indexed by their path in the provisioning directory
+type provisioningState struct {
+	Applied map[string]string `json:"applied"`
+}
+
+type provisioningStep struct {
+	dir       string
+	name      string
+	source    string
+	sha256sum string
+	content   []byte
+}
+
+func (step *provisioningStep) path() string {
+	return path.Join(step.dir, step.name)
+}
+
+type provisioner struct {
+	instanceName string
+	statePath    string
+	runner       vmRunner
+	ocConfig     oc.Config
+	runHostHook  func(ctx context.Context, hookPath string, env []string) (string, error)
+}
+
+// provisionCluster applies the Kubernetes manifests and runs the VM and host
+// hooks found in provisioningDir. Manifests and hooks which succeeded are
+// recorded in the instance directory and are only applied again when their
+// content changes.
+func provisionCluster(ctx context.Context, instanceName, provisioningDir string, runner vmRunner, ocConfig oc.Config) error {
+	p := &provisioner{
+		instanceName: instanceName,
+		statePath:    filepath.Join(constants.GetInstanceDir(instanceName), provisioningStateFile),
+		runner:       runner,
+		ocConfig:     ocConfig,
+		runHostHook:  runHostHook,
+	}
+	return p.provision(ctx, provisioningDir)
+}
+
+func (p *provisioner) provision(ctx context.Context, provisioningDir string) error {
+	steps, err := provisioningSteps(provisioningDir)
+	if err != nil {
+		return err
+	}
+	previous, err := loadProvisioningState(p.statePath)
+	if err != nil {
+		return err
+	}
+
+	state := provisioningState{Applied: map[string]string{}}
+	var mErr crcerrors.MultiError
+	for _, step := range steps {
+		if previous.Applied[step.path()] == step.sha256sum {
+			logging.Debugf("Skipping %s, it is unchanged since it was last applied", step.path())
+			state.Applied[step.path()] = step.sha256sum
+			continue
+		}
+		logging.Infof("Provisioning the cluster... [%s]", step.path())
+		if err := p.run(ctx, step); err != nil {
+			mErr.Collect(fmt.Errorf("%s: %w", step.path(), err))
+			continue
+		}
+		state.Applied[step.path()] = step.sha256sum
+	}
+
+	if err := saveProvisioningState(p.statePath, state); err != nil {
+		mErr.Collect(err)
+	}
+	if len(mErr.Errors) > 0 {
+		return mErr
+	}
+	return nil
+}
+
+func (p *provisioner) run(ctx context.Context, step *provisioningStep) error {
+	switch step.dir {
+	case provisioningManifestsDir:
+		return p.applyManifest(step)
+	case provisioningVMHooksDir:
+		return p.runVMHook(step)
+	case provisioningHostHooksDir:
+		return p.runHostHookStep(ctx, step)
+	}
+	return fmt.Errorf("unexpected provisioning directory %s", step.dir)
+}
+
+func (p *provisioner) copyToVM(step *provisioningStep, mode os.FileMode) (string, error) {
+	vmDir := path.Join(vmProvisioningDir, step.dir)
+	if _, _, err := p.runner.Run("mkdir", "-p", vmDir); err != nil {
+		return "", err
+	}
+	vmPath := path.Join(vmDir, step.name)
+	return vmPath, p.runner.CopyData(step.content, vmPath, mode)
+}
+
+func (p *provisioner) applyManifest(step *provisioningStep) error {
+	vmPath, err := p.copyToVM(step, 0600)
+	if err != nil {
+		return err
+	}
+	if _, stderr, err := p.ocConfig.RunOcCommand("apply", "-f", vmPath); err != nil {
+		return fmt.Errorf("%v: %s", err, stderr)
+	}
+	return nil
+}
+
+func (p *provisioner) runVMHook(step *provisioningStep) error {
+	vmPath, err := p.copyToVM(step, 0700)
+	if err != nil {
+		return err
+	}
+	stdout, stderr, err := p.runner.Run(vmPath)
+	logging.Debugf("Output of %s:\n%s%s", step.path(), stdout, stderr)
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, stderr)
+	}
+	return nil
+}
+
+func (p *provisioner) runHostHookStep(ctx context.Context, step *provisioningStep) error {
+	env := []string{
+		fmt.Sprintf("CRC_INSTANCE_NAME=%s", p.instanceName),
+		fmt.Sprintf("KUBECONFIG=%s", constants.GetKubeconfigFilePath(p.instanceName)),
+	}
+	output, err := p.runHostHook(ctx, step.source, env)
+	logging.Debugf("Output of %s:\n%s", step.path(), output)
+	return err
+}
+
+func runHostHook(ctx context.Context, hookPath string, env []string) (string, error) {
+	cmd := exec.CommandContext(ctx, hookPath) // #nosec G204
+	cmd.Env = append(os.Environ(), env...)
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// provisioningSteps lists the manifests and hooks of provisioningDir, each
+// subdirectory being sorted by filename
+func provisioningSteps(provisioningDir string) ([]*provisioningStep, error) {
+	var steps []*provisioningStep
+	for _, dir := range []string{provisioningManifestsDir, provisioningVMHooksDir, provisioningHostHooksDir} {
+		entries, err := os.ReadDir(filepath.Join(provisioningDir, filepath.FromSlash(dir)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			if dir == provisioningManifestsDir && !slices.Contains([]string{".yaml", ".yml", ".json"}, filepath.Ext(entry.Name())) {
+				continue
+			}
+			source := filepath.Join(provisioningDir, filepath.FromSlash(dir), entry.Name())
+			content, err := os.ReadFile(source)
+			if err != nil {
+				return nil, err
+			}
+			sum := sha256.Sum256(content)
+			steps = append(steps, &provisioningStep{
+				dir:       dir,
+				name:      entry.Name(),
+				source:    source,
+				sha256sum: hex.EncodeToString(sum[:]),
+				content:   content,
+			})
+		}
+	}
+	return steps, nil
+}
+
+func loadProvisioningState(statePath string) (*provisioningState, error) {
+	state := &provisioningState{Applied: map[string]string{}}
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid provisioning state %s: %w", statePath, err)
+	}
+	if state.Applied == nil {
+		state.Applied = map[string]string{}
+	}
+	return state, nil
+}
+
+func saveProvisioningState(statePath string, state provisioningState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statePath, data, 0600)
+}
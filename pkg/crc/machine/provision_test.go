@@ -0,0 +1,120 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/crc/oc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type provisioningRunner struct {
+	commands []string
+	copied   map[string]string
+	failing  string
+}
+
+func (r *provisioningRunner) Run(command string, args ...string) (string, string, error) {
+	cmd := strings.Join(append([]string{command}, args...), " ")
+	if r.failing != "" && strings.Contains(cmd, r.failing) {
+		return "", "failure", errors.New("exit status 1")
+	}
+	if command != "mkdir" {
+		r.commands = append(r.commands, cmd)
+	}
+	return "", "", nil
+}
+
+func (r *provisioningRunner) RunPrivate(command string, args ...string) (string, string, error) {
+	return r.Run(command, args...)
+}
+
+func (r *provisioningRunner) RunPrivileged(_ string, cmdAndArgs ...string) (string, string, error) {
+	return r.Run(cmdAndArgs[0], cmdAndArgs[1:]...)
+}
+
+func (r *provisioningRunner) CopyData(data []byte, destFilename string, _ os.FileMode) error {
+	r.copied[destFilename] = string(data)
+	return nil
+}
+
+func writeProvisioningFile(t *testing.T, dir, name, content string) {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func newTestProvisioner(t *testing.T, runner *provisioningRunner) (*provisioner, *[]string) {
+	var hostHooks []string
+	return &provisioner{
+		instanceName: "crc",
+		statePath:    filepath.Join(t.TempDir(), provisioningStateFile),
+		runner:       runner,
+		ocConfig: oc.Config{
+			Runner:           runner,
+			OcExecutablePath: "oc",
+			Timeout:          "30s",
+		},
+		runHostHook: func(_ context.Context, hookPath string, env []string) (string, error) {
+			assert.Contains(t, env, "CRC_INSTANCE_NAME=crc")
+			hostHooks = append(hostHooks, filepath.Base(hookPath))
+			return "", nil
+		},
+	}, &hostHooks
+}
+
+func TestProvision(t *testing.T) {
+	dir := t.TempDir()
+	writeProvisioningFile(t, dir, "manifests/01-namespace.yaml", "kind: Namespace")
+	writeProvisioningFile(t, dir, "manifests/02-secret.json", `{"kind": "Secret"}`)
+	writeProvisioningFile(t, dir, "manifests/README.md", "not a manifest")
+	writeProvisioningFile(t, dir, "hooks/vm/10-tune.sh", "#!/bin/sh")
+	writeProvisioningFile(t, dir, "hooks/host/10-login.sh", "#!/bin/sh")
+
+	runner := &provisioningRunner{copied: map[string]string{}}
+	p, hostHooks := newTestProvisioner(t, runner)
+	require.NoError(t, p.provision(context.Background(), dir))
+
+	assert.Equal(t, []string{
+		"timeout 30s oc apply -f /tmp/crc-provisioning/manifests/01-namespace.yaml",
+		"timeout 30s oc apply -f /tmp/crc-provisioning/manifests/02-secret.json",
+		"/tmp/crc-provisioning/hooks/vm/10-tune.sh",
+	}, runner.commands)
+	assert.Equal(t, "kind: Namespace", runner.copied["/tmp/crc-provisioning/manifests/01-namespace.yaml"])
+	assert.Equal(t, []string{"10-login.sh"}, *hostHooks)
+
+	state, err := loadProvisioningState(p.statePath)
+	require.NoError(t, err)
+	assert.Len(t, state.Applied, 4)
+
+	// only the modified manifest is applied again
+	writeProvisioningFile(t, dir, "manifests/02-secret.json", `{"kind": "Secret", "data": {}}`)
+	runner.commands = nil
+	*hostHooks = nil
+	require.NoError(t, p.provision(context.Background(), dir))
+	assert.Equal(t, []string{
+		"timeout 30s oc apply -f /tmp/crc-provisioning/manifests/02-secret.json",
+	}, runner.commands)
+	assert.Empty(t, *hostHooks)
+}
+
+func TestProvisionFailure(t *testing.T) {
+	dir := t.TempDir()
+	writeProvisioningFile(t, dir, "manifests/01-namespace.yaml", "kind: Namespace")
+	writeProvisioningFile(t, dir, "hooks/vm/10-tune.sh", "#!/bin/sh")
+
+	runner := &provisioningRunner{copied: map[string]string{}, failing: "10-tune.sh"}
+	p, _ := newTestProvisioner(t, runner)
+	assert.EqualError(t, p.provision(context.Background(), dir), "hooks/vm/10-tune.sh: exit status 1: failure")
+
+	// the failed hook is retried on the next start, the manifest is not applied again
+	runner.commands = nil
+	runner.failing = ""
+	require.NoError(t, p.provision(context.Background(), dir))
+	assert.Equal(t, []string{"/tmp/crc-provisioning/hooks/vm/10-tune.sh"}, runner.commands)
+}
@@ -0,0 +1,55 @@
+package machine
+
+import (
+	"context"
+
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
+	crcErr "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
+	"github.com/crc-org/crc/v2/pkg/crc/oc"
+	"github.com/pkg/errors"
+)
+
+// UpdatePullSecret replaces the pull secret of the running instance, the
+// cluster uses it for the next image pulls without a restart
+func (client *client) UpdatePullSecret(ctx context.Context, pullSecret cluster.PullSecretLoader) error {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		if errors.Is(err, errMissingHost(client.name)) {
+			return crcErr.VMNotExist
+		}
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	vmState, err := vm.State()
+	if err != nil {
+		return errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return errors.New("Instance is not running, run 'crc start' first")
+	}
+
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	content, err := pullSecret.Value()
+	if err != nil {
+		return err
+	}
+	if vm.bundle.IsMicroshift() {
+		logging.Info("Updating the pull secret of cri-o...")
+		// cri-o reads its global auth file for each image pull
+		return sshRunner.CopyDataPrivileged([]byte(content), "/etc/crio/openshift-pull-secret", 0o600)
+	}
+
+	ocConfig := oc.UseOCWithSSH(sshRunner)
+	if err := cluster.EnsurePullSecretPresentInTheCluster(ctx, ocConfig, pullSecret, true); err != nil {
+		return errors.Wrap(err, "Failed to update cluster pull secret")
+	}
+	return cluster.WaitForPullSecretOnInstanceDisk(ctx, sshRunner, content)
+}
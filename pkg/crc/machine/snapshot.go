@@ -0,0 +1,225 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	crcErr "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
+	"github.com/crc-org/crc/v2/pkg/crc/validation"
+	crcos "github.com/crc-org/crc/v2/pkg/os"
+	"github.com/pkg/errors"
+)
+
+const snapshotMetadataFileName = "snapshot.json"
+
+// snapshotFiles returns the files from the instance directory which are
+// saved alongside the disk image, they are tied to the cluster state
+func snapshotFiles(machineName string) []string {
+	return []string{
+		constants.GetKubeconfigFilePath(machineName),
+		constants.GetKubeAdminPasswordPath(machineName),
+		constants.GetDeveloperPasswordPath(machineName),
+	}
+}
+
+// diskSnapshotter saves and restores the disk image of an instance
+type diskSnapshotter interface {
+	Create(snapshotName, snapshotDir string) error
+	Restore(snapshotName, snapshotDir string) error
+	Delete(snapshotName, snapshotDir string) error
+}
+
+// instanceSnapshots stores the snapshots of an instance in dir, each
+// snapshot is a directory with the metadata, a copy of the files and, on
+// some platforms, of the disk image
+type instanceSnapshots struct {
+	dir   string
+	files []string
+	disk  diskSnapshotter
+}
+
+func (client *client) snapshots() *instanceSnapshots {
+	return &instanceSnapshots{
+		dir:   constants.GetInstanceSnapshotsDir(client.name),
+		files: snapshotFiles(client.name),
+		disk:  instanceDisk{machineName: client.name},
+	}
+}
+
+func (client *client) checkSnapshotPreconditions() error {
+	exists, err := client.Exists()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return crcErr.VMNotExist
+	}
+	if running, _ := client.IsRunning(); running {
+		return errors.New("Instance must be stopped, run 'crc stop' first")
+	}
+	return nil
+}
+
+func (client *client) CreateSnapshot(name string) error {
+	if err := validation.ValidateSnapshotName(name); err != nil {
+		return err
+	}
+	if err := client.checkSnapshotPreconditions(); err != nil {
+		return err
+	}
+	return client.snapshots().create(name)
+}
+
+func (client *client) ListSnapshots() ([]types.Snapshot, error) {
+	return client.snapshots().list()
+}
+
+func (client *client) RestoreSnapshot(name string) error {
+	if err := validation.ValidateSnapshotName(name); err != nil {
+		return err
+	}
+	if err := client.checkSnapshotPreconditions(); err != nil {
+		return err
+	}
+	return client.snapshots().restore(name)
+}
+
+func (client *client) DeleteSnapshot(name string) error {
+	if err := validation.ValidateSnapshotName(name); err != nil {
+		return err
+	}
+	return client.snapshots().delete(name)
+}
+
+func (snapshots *instanceSnapshots) create(name string) error {
+	dir := filepath.Join(snapshots.dir, name)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("Snapshot '%s' already exists", name)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "Cannot create snapshot directory")
+	}
+	if err := snapshots.save(name, dir); err != nil {
+		if err := os.RemoveAll(dir); err != nil {
+			logging.Debugf("Failed to remove %s: %v", dir, err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (snapshots *instanceSnapshots) save(name, dir string) error {
+	for _, path := range snapshots.files {
+		if !crcos.FileExists(path) {
+			continue
+		}
+		if err := crcos.CopyFile(path, filepath.Join(dir, filepath.Base(path))); err != nil {
+			return errors.Wrapf(err, "Cannot copy %s", path)
+		}
+	}
+
+	logging.Infof("Creating snapshot %s of the instance disk image...", name)
+	if err := snapshots.disk.Create(name, dir); err != nil {
+		return errors.Wrap(err, "Cannot snapshot disk image")
+	}
+
+	metadata, err := json.Marshal(types.Snapshot{
+		Name:         name,
+		CreationTime: time.Now(),
+	})
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, snapshotMetadataFileName), metadata, 0600)
+	}
+	if err != nil {
+		if err := snapshots.disk.Delete(name, dir); err != nil {
+			logging.Debugf("Failed to delete disk snapshot %s: %v", name, err)
+		}
+		return errors.Wrap(err, "Cannot write snapshot metadata")
+	}
+	return nil
+}
+
+func (snapshots *instanceSnapshots) list() ([]types.Snapshot, error) {
+	entries, err := os.ReadDir(snapshots.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []types.Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	list := []types.Snapshot{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		snapshot, err := snapshots.read(entry.Name())
+		if err != nil {
+			logging.Debugf("Ignoring snapshot %s: %v", entry.Name(), err)
+			continue
+		}
+		list = append(list, *snapshot)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreationTime.Before(list[j].CreationTime)
+	})
+	return list, nil
+}
+
+func (snapshots *instanceSnapshots) read(name string) (*types.Snapshot, error) {
+	content, err := os.ReadFile(filepath.Join(snapshots.dir, name, snapshotMetadataFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("Snapshot '%s' does not exist", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snapshot types.Snapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func (snapshots *instanceSnapshots) restore(name string) error {
+	if _, err := snapshots.read(name); err != nil {
+		return err
+	}
+	dir := filepath.Join(snapshots.dir, name)
+
+	logging.Infof("Restoring snapshot %s of the instance disk image...", name)
+	if err := snapshots.disk.Restore(name, dir); err != nil {
+		return errors.Wrap(err, "Cannot restore disk image")
+	}
+
+	for _, path := range snapshots.files {
+		saved := filepath.Join(dir, filepath.Base(path))
+		if !crcos.FileExists(saved) {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		if err := crcos.CopyFile(saved, path); err != nil {
+			return errors.Wrapf(err, "Cannot restore %s", path)
+		}
+	}
+	return nil
+}
+
+func (snapshots *instanceSnapshots) delete(name string) error {
+	if _, err := snapshots.read(name); err != nil {
+		return err
+	}
+	dir := filepath.Join(snapshots.dir, name)
+	if err := snapshots.disk.Delete(name, dir); err != nil {
+		return errors.Wrap(err, "Cannot delete disk snapshot")
+	}
+	return os.RemoveAll(dir)
+}
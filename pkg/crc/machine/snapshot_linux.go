@@ -0,0 +1,40 @@
+package machine
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	crcos "github.com/crc-org/crc/v2/pkg/os"
+)
+
+// On linux the disk image is a qcow2 file, snapshots are stored as
+// internal snapshots of this file
+
+func diskImagePath(machineName string) string {
+	return filepath.Join(constants.GetInstanceDir(machineName), fmt.Sprintf("%s.qcow2", machineName))
+}
+
+func qemuImgSnapshot(action, machineName, snapshotName string) error {
+	_, stderr, err := crcos.RunWithDefaultLocale("qemu-img", "snapshot", action, snapshotName, diskImagePath(machineName))
+	if err != nil {
+		return fmt.Errorf("%s: %w", stderr, err)
+	}
+	return nil
+}
+
+type instanceDisk struct {
+	machineName string
+}
+
+func (disk instanceDisk) Create(snapshotName, _ string) error {
+	return qemuImgSnapshot("-c", disk.machineName, snapshotName)
+}
+
+func (disk instanceDisk) Restore(snapshotName, _ string) error {
+	return qemuImgSnapshot("-a", disk.machineName, snapshotName)
+}
+
+func (disk instanceDisk) Delete(snapshotName, _ string) error {
+	return qemuImgSnapshot("-d", disk.machineName, snapshotName)
+}
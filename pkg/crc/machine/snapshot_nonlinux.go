@@ -0,0 +1,48 @@
+//go:build !linux
+
+package machine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	crcos "github.com/crc-org/crc/v2/pkg/os"
+)
+
+// On macOS and Windows, snapshots are sparse copies of the disk image stored
+// in the snapshot directory
+
+func diskImagePath(machineName string) string {
+	format := "img"
+	if runtime.GOOS == "windows" {
+		format = "vhdx"
+	}
+	return filepath.Join(constants.GetInstanceDir(machineName), fmt.Sprintf("%s.%s", machineName, format))
+}
+
+func snapshotDiskImagePath(machineName, snapshotDir string) string {
+	return filepath.Join(snapshotDir, filepath.Base(diskImagePath(machineName)))
+}
+
+type instanceDisk struct {
+	machineName string
+}
+
+func (disk instanceDisk) Create(_, snapshotDir string) error {
+	return crcos.CopyFileSparse(diskImagePath(disk.machineName), snapshotDiskImagePath(disk.machineName, snapshotDir))
+}
+
+func (disk instanceDisk) Restore(_, snapshotDir string) error {
+	return crcos.CopyFileSparse(snapshotDiskImagePath(disk.machineName, snapshotDir), diskImagePath(disk.machineName))
+}
+
+func (disk instanceDisk) Delete(_, snapshotDir string) error {
+	err := os.Remove(snapshotDiskImagePath(disk.machineName, snapshotDir))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
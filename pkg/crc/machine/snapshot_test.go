@@ -0,0 +1,150 @@
+package machine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDisk struct {
+	calls []string
+	err   error
+}
+
+func (disk *fakeDisk) record(action, snapshotName, snapshotDir string) error {
+	disk.calls = append(disk.calls, action+" "+snapshotName+" "+filepath.Base(snapshotDir))
+	return disk.err
+}
+
+func (disk *fakeDisk) Create(snapshotName, snapshotDir string) error {
+	return disk.record("create", snapshotName, snapshotDir)
+}
+
+func (disk *fakeDisk) Restore(snapshotName, snapshotDir string) error {
+	return disk.record("restore", snapshotName, snapshotDir)
+}
+
+func (disk *fakeDisk) Delete(snapshotName, snapshotDir string) error {
+	return disk.record("delete", snapshotName, snapshotDir)
+}
+
+func newTestSnapshots(t *testing.T) (*instanceSnapshots, *fakeDisk) {
+	instanceDir := t.TempDir()
+	kubeconfig := filepath.Join(instanceDir, "kubeconfig")
+	password := filepath.Join(instanceDir, "kubeadmin-password")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte("kubeconfig"), 0600))
+	disk := &fakeDisk{}
+	return &instanceSnapshots{
+		dir:   filepath.Join(instanceDir, "snapshots"),
+		files: []string{kubeconfig, password},
+		disk:  disk,
+	}, disk
+}
+
+func TestCreateSnapshot(t *testing.T) {
+	snapshots, disk := newTestSnapshots(t)
+
+	require.NoError(t, snapshots.create("pristine"))
+	assert.Equal(t, []string{"create pristine pristine"}, disk.calls)
+	assert.FileExists(t, filepath.Join(snapshots.dir, "pristine", "kubeconfig"))
+	assert.NoFileExists(t, filepath.Join(snapshots.dir, "pristine", "kubeadmin-password"))
+
+	list, err := snapshots.list()
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "pristine", list[0].Name)
+
+	assert.EqualError(t, snapshots.create("pristine"), "Snapshot 'pristine' already exists")
+	assert.Len(t, disk.calls, 1)
+}
+
+func TestCreateSnapshotRemovesDirectoryOnFailure(t *testing.T) {
+	snapshots, disk := newTestSnapshots(t)
+	disk.err = errors.New("disk full")
+
+	assert.EqualError(t, snapshots.create("pristine"), "Cannot snapshot disk image: disk full")
+	assert.NoDirExists(t, filepath.Join(snapshots.dir, "pristine"))
+
+	list, err := snapshots.list()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestListSnapshots(t *testing.T) {
+	snapshots, _ := newTestSnapshots(t)
+
+	list, err := snapshots.list()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	require.NoError(t, snapshots.create("first"))
+	require.NoError(t, snapshots.create("second"))
+	require.NoError(t, os.MkdirAll(filepath.Join(snapshots.dir, "incomplete"), 0700))
+
+	list, err = snapshots.list()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "first", list[0].Name)
+	assert.Equal(t, "second", list[1].Name)
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	snapshots, disk := newTestSnapshots(t)
+	kubeconfig, password := snapshots.files[0], snapshots.files[1]
+	require.NoError(t, snapshots.create("pristine"))
+
+	require.NoError(t, os.WriteFile(kubeconfig, []byte("modified"), 0600))
+	require.NoError(t, os.WriteFile(password, []byte("password"), 0600))
+
+	require.NoError(t, snapshots.restore("pristine"))
+	assert.Equal(t, []string{"create pristine pristine", "restore pristine pristine"}, disk.calls)
+	content, err := os.ReadFile(kubeconfig)
+	require.NoError(t, err)
+	assert.Equal(t, "kubeconfig", string(content))
+	assert.NoFileExists(t, password)
+}
+
+func TestRestoreSnapshotFailure(t *testing.T) {
+	snapshots, disk := newTestSnapshots(t)
+	kubeconfig := snapshots.files[0]
+	require.NoError(t, snapshots.create("pristine"))
+	require.NoError(t, os.WriteFile(kubeconfig, []byte("modified"), 0600))
+	disk.err = errors.New("corrupted image")
+
+	assert.EqualError(t, snapshots.restore("pristine"), "Cannot restore disk image: corrupted image")
+	content, err := os.ReadFile(kubeconfig)
+	require.NoError(t, err)
+	assert.Equal(t, "modified", string(content))
+}
+
+func TestRestoreUnknownSnapshot(t *testing.T) {
+	snapshots, disk := newTestSnapshots(t)
+
+	assert.EqualError(t, snapshots.restore("unknown"), "Snapshot 'unknown' does not exist")
+	assert.Empty(t, disk.calls)
+}
+
+func TestDeleteSnapshot(t *testing.T) {
+	snapshots, disk := newTestSnapshots(t)
+	require.NoError(t, snapshots.create("pristine"))
+
+	require.NoError(t, snapshots.delete("pristine"))
+	assert.Equal(t, []string{"create pristine pristine", "delete pristine pristine"}, disk.calls)
+	assert.NoDirExists(t, filepath.Join(snapshots.dir, "pristine"))
+
+	assert.EqualError(t, snapshots.delete("pristine"), "Snapshot 'pristine' does not exist")
+	assert.Len(t, disk.calls, 2)
+}
+
+func TestDeleteSnapshotKeepsDirectoryOnFailure(t *testing.T) {
+	snapshots, disk := newTestSnapshots(t)
+	require.NoError(t, snapshots.create("pristine"))
+	disk.err = errors.New("busy")
+
+	assert.EqualError(t, snapshots.delete("pristine"), "Cannot delete disk snapshot: busy")
+	assert.DirExists(t, filepath.Join(snapshots.dir, "pristine"))
+}
@@ -14,8 +14,10 @@ import (
 	"go.podman.io/common/pkg/strongunits"
 
 	"github.com/crc-org/crc/v2/pkg/crc/cluster"
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	crcerrors "github.com/crc-org/crc/v2/pkg/crc/errors"
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/config"
@@ -25,8 +27,10 @@ import (
 	"github.com/crc-org/crc/v2/pkg/crc/network/httpproxy"
 	"github.com/crc-org/crc/v2/pkg/crc/oc"
 	crcPreset "github.com/crc-org/crc/v2/pkg/crc/preset"
+	"github.com/crc-org/crc/v2/pkg/crc/registries"
 	"github.com/crc-org/crc/v2/pkg/crc/services"
 	"github.com/crc-org/crc/v2/pkg/crc/services/dns"
+	"github.com/crc-org/crc/v2/pkg/crc/shareddirs"
 	crcssh "github.com/crc-org/crc/v2/pkg/crc/ssh"
 	"github.com/crc-org/crc/v2/pkg/crc/systemd"
 	"github.com/crc-org/crc/v2/pkg/crc/telemetry"
@@ -43,23 +47,23 @@ import (
 
 const minimumMemoryForMonitoring = strongunits.MiB(14336)
 
-func getCrcBundleInfo(ctx context.Context, preset crcPreset.Preset, bundleName, bundlePath string, enableBundleQuayFallback bool) (*bundle.CrcBundleInfo, error) {
-	bundleInfo, err := bundle.Use(bundleName)
+func getCrcBundleInfo(ctx context.Context, preset crcPreset.Preset, bundleName, bundlePath string, enableBundleQuayFallback bool, mirrors []string, signaturePolicy *bundle.SignaturePolicy) (*bundle.CrcBundleInfo, error) {
+	bundleInfo, err := bundle.Use(bundleName, signaturePolicy)
 	if err == nil {
 		logging.Infof("Loading bundle: %s...", bundleName)
 		return bundleInfo, nil
 	}
 	logging.Debugf("Failed to load bundle %s: %v", bundleName, err)
 	logging.Infof("Downloading bundle: %s...", bundleName)
-	bundlePath, err = bundle.Download(ctx, preset, bundlePath, enableBundleQuayFallback)
+	bundlePath, err = bundle.Download(ctx, preset, bundlePath, enableBundleQuayFallback, mirrors, signaturePolicy)
 	if err != nil {
 		return nil, err
 	}
 	logging.Infof("Extracting bundle: %s...", bundleName)
-	if _, err := bundle.Extract(ctx, bundlePath); err != nil {
+	if _, err := bundle.Extract(ctx, bundlePath, signaturePolicy); err != nil {
 		return nil, err
 	}
-	return bundle.Use(bundleName)
+	return bundle.Use(bundleName, signaturePolicy)
 }
 
 func (client *client) updateVMConfig(startConfig types.StartConfig, vm *virtualMachine) error {
@@ -81,6 +85,19 @@ func (client *client) updateVMConfig(startConfig types.StartConfig, vm *virtualM
 			return err
 		}
 	}
+	if err := setSharedDirs(vm.Host, sharedDirs(startConfig)); err != nil {
+		logging.Debugf("Failed to update CRC VM configuration: %v", err)
+		if err == drivers.ErrNotImplemented {
+			logging.Warn("Shared directories configuration change has been ignored as the machine driver does not support it, run 'crc delete' to apply it")
+		} else {
+			return err
+		}
+	}
+	if client.useVSock() {
+		if err := setNetworkAddresses(vm.Host, vm.addresses); err != nil {
+			return err
+		}
+	}
 	if err := vm.api.Save(vm.Host); err != nil {
 		return err
 	}
@@ -218,7 +235,7 @@ func configureSharedDirs(vm *virtualMachine, sshRunner *crcssh.Runner) error {
 		return nil
 	}
 	logging.Infof("Configuring shared directories")
-	for _, mount := range sharedDirs {
+	for i, mount := range sharedDirs {
 		// Try to create the mount directory and if it fails then
 		// make the file system mutable and again try to create the
 		// mount directory.
@@ -237,7 +254,11 @@ func configureSharedDirs(vm *virtualMachine, sshRunner *crcssh.Runner) error {
 		logging.Debugf("Mounting tag %s at %s", mount.Tag, mount.Target)
 		switch mount.Type {
 		case "virtiofs":
-			if _, _, err := sshRunner.RunPrivileged(fmt.Sprintf("Mounting %s", mount.Target), "mount", "-o", "context=\"system_u:object_r:container_file_t:s0\"", "-t", mount.Type, mount.Tag, mount.Target); err != nil {
+			options := "context=\"system_u:object_r:container_file_t:s0\""
+			if mount.ReadOnly {
+				options = "ro," + options
+			}
+			if _, _, err := sshRunner.RunPrivileged(fmt.Sprintf("Mounting %s", mount.Target), "mount", "-o", options, "-t", mount.Type, mount.Tag, mount.Target); err != nil {
 				return err
 			}
 
@@ -251,10 +272,14 @@ func configureSharedDirs(vm *virtualMachine, sshRunner *crcssh.Runner) error {
 			if _, _, err := sshRunner.RunPrivileged("Changing owner of mount directory", "chown", "core:core", mount.Target); err != nil {
 				return err
 			}
-			if _, _, err := sshRunner.Run("9pfs -V -p", fmt.Sprintf("%d", constants.Plan9HvsockPort), "2", mount.Target); err != nil {
-				logging.Warnf("Failed to connect to 9p server over hvsock: %v", err)
-				logging.Warnf("Falling back to 9p over TCP")
-				if _, _, err := sshRunner.Run("9pfs", constants.VSockGateway, mount.Target); err != nil {
+			if err := mount9p(sshRunner, i, mount.Target); err != nil {
+				return err
+			}
+			// 9pfs has no read-only option, the daemon already rejects
+			// writes to read-only exports, the bind remount makes them
+			// fail early in the VM
+			if mount.ReadOnly {
+				if _, _, err := sshRunner.RunPrivileged(fmt.Sprintf("Making %s read-only", mount.Target), "mount", "-o", "remount,bind,ro", mount.Target); err != nil {
 					return err
 				}
 			}
@@ -267,7 +292,58 @@ func configureSharedDirs(vm *virtualMachine, sshRunner *crcssh.Runner) error {
 	return nil
 }
 
-func (client *client) Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error) {
+// mount9p mounts the share i served by the daemon at target, the share i is
+// served on the TCP port Plan9TcpPort+i and only the first share is also
+// served over hvsock
+func mount9p(sshRunner *crcssh.Runner, i int, target string) error {
+	if i > 0 {
+		_, _, err := sshRunner.Run("9pfs -p", fmt.Sprintf("%d", constants.Plan9TcpPort+i), constants.VSockGateway, target)
+		return err
+	}
+	if _, _, err := sshRunner.Run("9pfs -V -p", fmt.Sprintf("%d", constants.Plan9HvsockPort), "2", target); err != nil {
+		logging.Warnf("Failed to connect to 9p server over hvsock: %v", err)
+		logging.Warnf("Falling back to 9p over TCP")
+		if _, _, err := sshRunner.Run("9pfs", constants.VSockGateway, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewStartConfig returns the configuration to start an instance with the
+// settings of cfg
+func NewStartConfig(cfg crcConfig.Storage, pullSecret cluster.PullSecretLoader) types.StartConfig {
+	return types.StartConfig{
+		BundlePath:               cfg.Get(crcConfig.Bundle).AsString(),
+		Memory:                   strongunits.MiB(cfg.Get(crcConfig.Memory).AsUInt()),
+		DiskSize:                 strongunits.GiB(cfg.Get(crcConfig.DiskSize).AsUInt()),
+		CPUs:                     cfg.Get(crcConfig.CPUs).AsUInt(),
+		NameServer:               cfg.Get(crcConfig.NameServer).AsString(),
+		PullSecret:               pullSecret,
+		KubeAdminPassword:        cfg.Get(crcConfig.KubeAdminPassword).AsString(),
+		DeveloperPassword:        cfg.Get(crcConfig.DeveloperPassword).AsString(),
+		Preset:                   crcConfig.GetPreset(cfg),
+		IngressHTTPPort:          cfg.Get(crcConfig.IngressHTTPPort).AsUInt(),
+		IngressHTTPSPort:         cfg.Get(crcConfig.IngressHTTPSPort).AsUInt(),
+		EnableSharedDirs:         cfg.Get(crcConfig.EnableSharedDirs).AsBool(),
+		SharedDirs:               crcConfig.GetSharedDirs(cfg),
+		EmergencyLogin:           cfg.Get(crcConfig.EmergencyLogin).AsBool(),
+		PersistentVolumeSize:     cfg.Get(crcConfig.PersistentVolumeSize).AsInt(),
+		EnableBundleQuayFallback: cfg.Get(crcConfig.EnableBundleQuayFallback).AsBool(),
+		BundleMirrors:            crcConfig.GetBundleMirrors(cfg),
+		EnforceBundleSignature:   cfg.Get(crcConfig.EnforceBundleSignature).AsBool(),
+		TrustedBundleKeys:        crcConfig.GetTrustedBundleKeys(cfg),
+		ProvisioningDir:          cfg.Get(crcConfig.ProvisioningDir).AsString(),
+		PortForwards:             crcConfig.GetPortForwards(cfg),
+		Registries:               crcConfig.GetRegistriesConfig(cfg),
+		AdditionalTrustedCAFiles: crcConfig.GetAdditionalTrustedCAFiles(cfg),
+	}
+}
+
+func (client *client) Start(ctx context.Context, startConfig types.StartConfig) (_ *types.StartResult, err error) {
+	defer func() {
+		client.publishFailure(err)
+	}()
 	telemetry.SetCPUs(ctx, startConfig.CPUs)
 	telemetry.SetMemory(ctx, uint64(startConfig.Memory.ToBytes()))
 	telemetry.SetDiskSize(ctx, uint64(startConfig.DiskSize.ToBytes()))
@@ -276,6 +352,11 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		return nil, err
 	}
 
+	additionalTrustedCA, err := crctls.LoadCACertificates(startConfig.AdditionalTrustedCAFiles)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read the additional trusted CA files")
+	}
+
 	// Pre-VM start
 	exists, err := client.Exists()
 	if err != nil {
@@ -283,7 +364,7 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 	}
 
 	if exists {
-		if err := checkMachineInstanceDir(); err != nil {
+		if err := checkMachineInstanceDir(client.name); err != nil {
 			return nil, err
 		}
 	}
@@ -293,7 +374,8 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		return nil, errors.Wrap(err, "Error getting bundle name")
 	}
 	bundleName := bundle.GetBundleNameWithoutExtension(bundleNameFromURI)
-	crcBundleMetadata, err := getCrcBundleInfo(ctx, startConfig.Preset, bundleName, startConfig.BundlePath, startConfig.EnableBundleQuayFallback)
+	crcBundleMetadata, err := getCrcBundleInfo(ctx, startConfig.Preset, bundleName, startConfig.BundlePath, startConfig.EnableBundleQuayFallback, startConfig.BundleMirrors,
+		bundle.NewSignaturePolicy(startConfig.EnforceBundleSignature, startConfig.TrustedBundleKeys, startConfig.BundleMirrors))
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting bundle metadata")
 	}
@@ -313,11 +395,6 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 
 		logging.Infof("Creating CRC VM for %s %s...", startConfig.Preset.ForDisplay(), crcBundleMetadata.GetVersion())
 
-		sharedDirs := []string{}
-		if homeDir, err := os.UserHomeDir(); err == nil {
-			sharedDirs = append(sharedDirs, homeDir)
-		}
-
 		machineConfig := config.MachineConfig{
 			Name:              client.name,
 			BundleName:        bundleName,
@@ -328,7 +405,7 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 			ImageSourcePath:   crcBundleMetadata.GetDiskImagePath(),
 			ImageFormat:       crcBundleMetadata.GetDiskImageFormat(),
 			SSHKeyPath:        crcBundleMetadata.GetSSHKeyPath(),
-			SharedDirs:        sharedDirs,
+			SharedDirs:        sharedDirs(startConfig),
 			SharedDirPassword: startConfig.SharedDirPassword,
 			SharedDirUsername: startConfig.SharedDirUsername,
 		}
@@ -362,12 +439,13 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 	}
 	if vmState == state.Running {
 		logging.Infof("A CRC VM for %s %s is already running", startConfig.Preset.ForDisplay(), vm.bundle.GetVersion())
-		clusterConfig, err := getClusterConfig(vm.bundle)
+		clusterConfig, err := getClusterConfig(vm)
 		if err != nil {
 			return nil, errors.Wrap(err, "Cannot create cluster configuration")
 		}
 
 		telemetry.SetStartType(ctx, telemetry.AlreadyRunningStartType)
+		client.publish(lifecycle.Started)
 		return &types.StartResult{
 			Status:         vmState,
 			ClusterConfig:  *clusterConfig,
@@ -375,14 +453,19 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		}, nil
 	}
 
-	if _, err := bundle.Use(currentBundleName); err != nil {
+	if err := client.checkVMAddressAvailable(); err != nil {
+		return nil, err
+	}
+
+	if _, err := bundle.Use(currentBundleName, bundle.NewSignaturePolicy(startConfig.EnforceBundleSignature, startConfig.TrustedBundleKeys, startConfig.BundleMirrors)); err != nil {
 		return nil, err
 	}
 
 	logging.Infof("Starting CRC VM for %s %s...", startConfig.Preset, vm.bundle.GetVersion())
+	client.publish(lifecycle.VMStarting)
 
 	if client.useVSock() {
-		if err := exposePorts(startConfig.Preset, startConfig.IngressHTTPPort, startConfig.IngressHTTPSPort); err != nil {
+		if err := exposePorts(client.name, vm.addresses, startConfig.Preset, startConfig.IngressHTTPPort, startConfig.IngressHTTPSPort, startConfig.PortForwards); err != nil {
 			return nil, err
 		}
 	}
@@ -409,6 +492,10 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		return nil, errors.Wrap(err, "Error getting the IP")
 	}
 	logging.Infof("CRC instance is running with IP %s", instanceIP)
+	apiAddress, err := vm.APIAddress()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the API server address")
+	}
 	sshRunner, err := vm.SSHRunner()
 	if err != nil {
 		return nil, errors.Wrap(err, "Error creating the ssh client")
@@ -420,20 +507,21 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		return nil, errors.Wrap(err, "Failed to connect to the CRC VM with SSH -- virtual machine might be unreachable")
 	}
 	logging.Info("CRC VM is running")
+	client.publish(lifecycle.SSHReachable)
 
 	if startConfig.EmergencyLogin {
-		if err := enableEmergencyLogin(sshRunner); err != nil {
+		if err := enableEmergencyLogin(client.name, sshRunner); err != nil {
 			return nil, errors.Wrap(err, "Error enabling emergency login")
 		}
 	} else {
-		if err := disableEmergencyLogin(sshRunner); err != nil {
+		if err := disableEmergencyLogin(client.name, sshRunner); err != nil {
 			return nil, errors.Wrap(err, "Error deleting the password for core user")
 		}
 	}
 
 	// Post VM start immediately update SSH key and copy kubeconfig to instance
 	// dir and VM
-	if err := updateSSHKeyPair(sshRunner); err != nil {
+	if err := updateSSHKeyPair(client.name, sshRunner); err != nil {
 		return nil, errors.Wrap(err, "Error updating public key")
 	}
 
@@ -470,6 +558,10 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		}
 	}
 
+	if err := ensureAdditionalTrustedCAInVM(sshRunner, string(additionalTrustedCA)); err != nil {
+		return nil, err
+	}
+
 	if _, _, err := sshRunner.RunPrivileged("make root Podman socket accessible", "chmod 777 /run/podman/ /run/podman/podman.sock"); err != nil {
 		return nil, errors.Wrap(err, "Failed to change permissions to root podman socket")
 	}
@@ -493,6 +585,9 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		NetworkMode:     client.networkMode(),
 		ModifyHostsFile: client.modifyHostsFile(),
 	}
+	if client.useVSock() && vm.addresses.HasOwnVMAddress() {
+		servicePostStartConfig.VMIP = vm.addresses.VMIP()
+	}
 
 	// Run the DNS server inside the VM
 	if err := dns.RunPostStart(servicePostStartConfig); err != nil {
@@ -533,7 +628,11 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		ocConfig.Context = "microshift"
 		ocConfig.Cluster = "microshift"
 
-		if err := startMicroshift(ctx, sshRunner, ocConfig, startConfig.PullSecret); err != nil {
+		if err := ensureRegistriesConfInVM(sshRunner, startConfig.Registries); err != nil {
+			return nil, err
+		}
+
+		if err := startMicroshift(ctx, client.name, sshRunner, ocConfig, startConfig.PullSecret); err != nil {
 			return nil, err
 		}
 
@@ -543,9 +642,15 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 			}
 		}
 		logging.Info("Adding microshift context to kubeconfig...")
-		if err := mergeKubeConfigFile(constants.KubeconfigFilePath); err != nil {
+		if err := mergeKubeConfigFile(client.name, apiAddress, constants.GetKubeconfigFilePath(client.name)); err != nil {
 			return nil, err
 		}
+		if startConfig.ProvisioningDir != "" {
+			if err := client.provision(ctx, startConfig.ProvisioningDir, sshRunner, ocConfig); err != nil {
+				return nil, err
+			}
+		}
+		client.publish(lifecycle.Started)
 
 		return &types.StartResult{
 			ClusterConfig: types.ClusterConfig{ClusterType: startConfig.Preset},
@@ -565,6 +670,7 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 	if err := sd.Start("kubelet"); err != nil {
 		return nil, errors.Wrap(err, "Error starting kubelet")
 	}
+	client.publish(lifecycle.KubeletStarted)
 
 	ocConfig := oc.UseOCWithSSH(sshRunner)
 
@@ -577,23 +683,31 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		return nil, errors.Wrap(err, "Error waiting for apiserver")
 	}
 
-	if err := ensureProxyIsConfiguredInOpenShift(ctx, ocConfig, sshRunner, proxyConfig); err != nil {
+	if err := ensureProxyIsConfiguredInOpenShift(ctx, ocConfig, proxyConfig); err != nil {
 		return nil, errors.Wrap(err, "Failed to update cluster proxy configuration")
 	}
 
+	if err := cluster.EnsureRegistriesConfigInCluster(ctx, sshRunner, ocConfig, startConfig.Registries); err != nil {
+		return nil, errors.Wrap(err, "Failed to update cluster registries configuration")
+	}
+
+	if err := ensureTrustedCABundleInOpenShift(ctx, ocConfig, sshRunner, proxyConfig, string(additionalTrustedCA)); err != nil {
+		return nil, errors.Wrap(err, "Failed to update cluster trusted CA bundle")
+	}
+
 	if err := cluster.DeleteMCOLeaderLease(ctx, ocConfig); err != nil {
 		return nil, err
 	}
 
-	if err := cluster.EnsurePullSecretPresentInTheCluster(ctx, ocConfig, startConfig.PullSecret); err != nil {
+	if err := cluster.EnsurePullSecretPresentInTheCluster(ctx, ocConfig, startConfig.PullSecret, false); err != nil {
 		return nil, errors.Wrap(err, "Failed to update cluster pull secret")
 	}
 
-	if err := cluster.EnsureSSHKeyPresentInTheCluster(ctx, ocConfig, constants.GetPublicKeyPath()); err != nil {
+	if err := cluster.EnsureSSHKeyPresentInTheCluster(ctx, ocConfig, constants.GetPublicKeyPath(client.name)); err != nil {
 		return nil, errors.Wrap(err, "Failed to update ssh public key to machine config")
 	}
 
-	if err := cluster.UpdateUserPasswords(ctx, ocConfig, startConfig.KubeAdminPassword, startConfig.DeveloperPassword); err != nil {
+	if err := cluster.UpdateUserPasswords(ctx, ocConfig, client.name, startConfig.KubeAdminPassword, startConfig.DeveloperPassword); err != nil {
 		return nil, errors.Wrap(err, "Failed to update kubeadmin user password")
 	}
 
@@ -614,13 +728,15 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		}
 	}
 
-	if err := updateKubeconfig(ctx, ocConfig, sshRunner, vm.bundle.GetKubeConfigPath()); err != nil {
+	if err := updateKubeconfig(ctx, client.name, ocConfig, sshRunner, vm.bundle.GetKubeConfigPath()); err != nil {
 		return nil, errors.Wrap(err, "Failed to update kubeconfig file")
 	}
 
 	logging.Infof("Starting %s instance... [waiting for the cluster to stabilize]", startConfig.Preset)
-	if err := cluster.WaitForClusterStable(ctx, instanceIP, constants.KubeconfigFilePath, proxyConfig); err != nil {
+	clusterStable := true
+	if err := cluster.WaitForClusterStable(ctx, apiAddress, constants.GetKubeconfigFilePath(client.name), proxyConfig); err != nil {
 		logging.Warnf("Cluster is not ready: %v", err)
+		clusterStable = false
 	}
 
 	if err := cluster.WaitForPullSecretPresentOnInstanceDisk(ctx, sshRunner); err != nil {
@@ -629,16 +745,29 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 
 	waitForProxyPropagation(ctx, ocConfig, proxyConfig)
 
-	clusterConfig, err := getClusterConfig(vm.bundle)
+	clusterConfig, err := getClusterConfig(vm)
 	if err != nil {
 		return nil, errors.Wrap(err, "Cannot get cluster configuration")
 	}
 
-	logging.Info("Adding crc-admin and crc-developer contexts to kubeconfig...")
-	if err := writeKubeconfig(instanceIP, clusterConfig, startConfig.IngressHTTPSPort); err != nil {
+	logging.Infof("Adding %s and %s contexts to kubeconfig...", adminContext(client.name), developerContext(client.name))
+	ingressHTTPSPort := startConfig.IngressHTTPSPort
+	if client.useVSock() {
+		ingressHTTPSPort = vm.addresses.IngressHTTPSPort(ingressHTTPSPort)
+	}
+	if err := writeKubeconfig(client.name, instanceIP, apiAddress, clusterConfig, ingressHTTPSPort); err != nil {
 		logging.Errorf("Cannot update kubeconfig: %v", err)
 	}
 
+	if startConfig.ProvisioningDir != "" {
+		if !clusterStable {
+			logging.Warnf("Skipping the provisioning from %s as the cluster is not stable", startConfig.ProvisioningDir)
+		} else if err := client.provision(ctx, startConfig.ProvisioningDir, sshRunner, ocConfig); err != nil {
+			return nil, err
+		}
+	}
+	client.publish(lifecycle.Started)
+
 	return &types.StartResult{
 		KubeletStarted: true,
 		ClusterConfig:  *clusterConfig,
@@ -646,6 +775,14 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 	}, nil
 }
 
+func (client *client) provision(ctx context.Context, provisioningDir string, sshRunner *crcssh.Runner, ocConfig oc.Config) error {
+	if err := provisionCluster(ctx, client.name, provisioningDir, sshRunner, ocConfig); err != nil {
+		return errors.Wrap(err, "Failed to provision the cluster")
+	}
+	client.publish(lifecycle.Provisioned)
+	return nil
+}
+
 func (client *client) IsRunning() (bool, error) {
 	vm, err := loadVirtualMachine(client.name, client.useVSock())
 	if err != nil {
@@ -671,6 +808,25 @@ func (client *client) validateStartConfig(startConfig types.StartConfig) error {
 			units.BytesSize(float64(startConfig.Memory.ToBytes())),
 			units.BytesSize(float64(minimumMemoryForMonitoring.ToBytes())))
 	}
+	if startConfig.EnableSharedDirs {
+		for _, dir := range startConfig.SharedDirs {
+			if err := dir.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sharedDirs returns the directories shared with the VM, the home directory
+// is shared when none are configured
+func sharedDirs(startConfig types.StartConfig) []shareddirs.SharedDir {
+	if len(startConfig.SharedDirs) > 0 {
+		return startConfig.SharedDirs
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return []shareddirs.SharedDir{{Source: homeDir}}
+	}
 	return nil
 }
 
@@ -700,14 +856,14 @@ func createHost(machineConfig config.MachineConfig, preset crcPreset.Preset) err
 	}
 
 	logging.Info("Generating new SSH key pair...")
-	if err := crcssh.GenerateSSHKey(constants.GetPrivateKeyPath()); err != nil {
+	if err := crcssh.GenerateSSHKey(constants.GetPrivateKeyPath(machineConfig.Name)); err != nil {
 		return fmt.Errorf("error generating ssh key pair: %w", err)
 	}
 	if preset == crcPreset.OpenShift || preset == crcPreset.OKD {
-		if err := cluster.GenerateUserPassword(constants.GetKubeAdminPasswordPath(), "kubeadmin"); err != nil {
+		if err := cluster.GenerateUserPassword(constants.GetKubeAdminPasswordPath(machineConfig.Name), "kubeadmin"); err != nil {
 			return errors.Wrap(err, "Error generating new kubeadmin password")
 		}
-		if err = os.WriteFile(constants.GetDeveloperPasswordPath(), []byte(constants.DefaultDeveloperPassword), 0o600); err != nil {
+		if err = os.WriteFile(constants.GetDeveloperPasswordPath(machineConfig.Name), []byte(constants.DefaultDeveloperPassword), 0o600); err != nil {
 			return errors.Wrap(err, "Error writing developer password")
 		}
 	}
@@ -752,8 +908,9 @@ func addNameServerToInstance(sshRunner *crcssh.Runner, ns string) error {
 	return nil
 }
 
-func enableEmergencyLogin(sshRunner *crcssh.Runner) error {
-	if crcos.FileExists(constants.PasswdFilePath) {
+func enableEmergencyLogin(machineName string, sshRunner *crcssh.Runner) error {
+	passwdFilePath := constants.GetPasswdFilePath(machineName)
+	if crcos.FileExists(passwdFilePath) {
 		return nil
 	}
 	charset := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
@@ -761,23 +918,23 @@ func enableEmergencyLogin(sshRunner *crcssh.Runner) error {
 	for i := range b {
 		b[i] = charset[rand.Intn(len(charset))] //nolint:gosec
 	}
-	if err := os.WriteFile(constants.PasswdFilePath, b, 0o600); err != nil {
+	if err := os.WriteFile(passwdFilePath, b, 0o600); err != nil {
 		return err
 	}
-	logging.Infof("Emergency login password for core user is stored to %s", constants.PasswdFilePath)
+	logging.Infof("Emergency login password for core user is stored to %s", passwdFilePath)
 	_, _, err := sshRunner.Run(fmt.Sprintf("sudo passwd core -f --unlock && echo %s | sudo passwd core --stdin", b))
 	return err
 }
 
-func disableEmergencyLogin(sshRunner *crcssh.Runner) error {
-	defer os.Remove(constants.PasswdFilePath)
+func disableEmergencyLogin(machineName string, sshRunner *crcssh.Runner) error {
+	defer os.Remove(constants.GetPasswdFilePath(machineName))
 	_, _, err := sshRunner.RunPrivileged("disable core user password", "passwd", "--lock", "core")
 	return err
 }
 
-func updateSSHKeyPair(sshRunner *crcssh.Runner) error {
+func updateSSHKeyPair(machineName string, sshRunner *crcssh.Runner) error {
 	// Read generated public key
-	publicKey, err := os.ReadFile(constants.GetPublicKeyPath())
+	publicKey, err := os.ReadFile(constants.GetPublicKeyPath(machineName))
 	if err != nil {
 		return err
 	}
@@ -799,7 +956,7 @@ func updateSSHKeyPair(sshRunner *crcssh.Runner) error {
 }
 
 func copyKubeconfigFileWithUpdatedUserClientCertAndKey(selfSignedCAKey *rsa.PrivateKey, selfSignedCACert *x509.Certificate, srcKubeConfigPath, dstKubeConfigPath string) error {
-	if _, err := os.Stat(constants.KubeconfigFilePath); err == nil {
+	if _, err := os.Stat(dstKubeConfigPath); err == nil {
 		return nil
 	}
 	clientKey, clientCert, err := crctls.GenerateClientCertificate(selfSignedCAKey, selfSignedCACert)
@@ -809,12 +966,20 @@ func copyKubeconfigFileWithUpdatedUserClientCertAndKey(selfSignedCAKey *rsa.Priv
 	return updateClientCrtAndKeyToKubeconfig(clientKey, clientCert, srcKubeConfigPath, dstKubeConfigPath)
 }
 
-func ensureProxyIsConfiguredInOpenShift(ctx context.Context, ocConfig oc.Config, sshRunner *crcssh.Runner, proxy *httpproxy.ProxyConfig) (err error) {
+func ensureProxyIsConfiguredInOpenShift(ctx context.Context, ocConfig oc.Config, proxy *httpproxy.ProxyConfig) (err error) {
 	if !proxy.IsEnabled() {
 		return nil
 	}
 	logging.Info("Adding proxy configuration to the cluster...")
-	return cluster.AddProxyConfigToCluster(ctx, sshRunner, ocConfig, proxy)
+	return cluster.AddProxyConfigToCluster(ctx, ocConfig, proxy)
+}
+
+func ensureTrustedCABundleInOpenShift(ctx context.Context, ocConfig oc.Config, sshRunner *crcssh.Runner, proxy *httpproxy.ProxyConfig, additionalCAs string) error {
+	proxyCA := ""
+	if proxy.IsEnabled() {
+		proxyCA = proxy.ProxyCACert
+	}
+	return cluster.EnsureTrustedCABundleInCluster(ctx, sshRunner, ocConfig, proxyCA, additionalCAs)
 }
 
 func waitForProxyPropagation(ctx context.Context, ocConfig oc.Config, proxyConfig *httpproxy.ProxyConfig) {
@@ -872,25 +1037,54 @@ func ensureRoutesNetworkPolicy(sshRunner *crcssh.Runner, ocConfig oc.Config) err
 	return err
 }
 
-func updateKubeconfig(ctx context.Context, ocConfig oc.Config, sshRunner *crcssh.Runner, kubeconfigFilePath string) error {
+func updateKubeconfig(ctx context.Context, machineName string, ocConfig oc.Config, sshRunner *crcssh.Runner, kubeconfigFilePath string) error {
 	selfSignedCAKey, selfSignedCACert, err := crctls.GetSelfSignedCA()
 	if err != nil {
 		return errors.Wrap(err, "Not able to generate root CA key and Cert")
 	}
-	if err := copyKubeconfigFileWithUpdatedUserClientCertAndKey(selfSignedCAKey, selfSignedCACert, kubeconfigFilePath, constants.KubeconfigFilePath); err != nil {
-		return errors.Wrapf(err, "Failed to copy kubeconfig file: %s", constants.KubeconfigFilePath)
+	instanceKubeconfigFilePath := constants.GetKubeconfigFilePath(machineName)
+	if err := copyKubeconfigFileWithUpdatedUserClientCertAndKey(selfSignedCAKey, selfSignedCACert, kubeconfigFilePath, instanceKubeconfigFilePath); err != nil {
+		return errors.Wrapf(err, "Failed to copy kubeconfig file: %s", instanceKubeconfigFilePath)
 	}
-	adminClientCA, err := adminClientCertificate(constants.KubeconfigFilePath)
+	adminClientCA, err := adminClientCertificate(instanceKubeconfigFilePath)
 	if err != nil {
 		return errors.Wrap(err, "Not able to get user CA")
 	}
-	if err := cluster.EnsureGeneratedClientCAPresentInTheCluster(ctx, ocConfig, sshRunner, selfSignedCACert, adminClientCA); err != nil {
+	if err := cluster.EnsureGeneratedClientCAPresentInTheCluster(ctx, ocConfig, sshRunner, instanceKubeconfigFilePath, selfSignedCACert, adminClientCA); err != nil {
 		return errors.Wrap(err, "Failed to update user CA to cluster")
 	}
 	return nil
 }
 
-func startMicroshift(ctx context.Context, sshRunner *crcssh.Runner, ocConfig oc.Config, pullSec cluster.PullSecretLoader) error {
+// ensureAdditionalTrustedCAInVM updates the trust store of the VM, cri-o
+// reads it when it starts
+func ensureAdditionalTrustedCAInVM(sshRunner *crcssh.Runner, bundle string) error {
+	changed, err := cluster.EnsureAdditionalTrustedCAInVM(sshRunner, bundle)
+	if err != nil {
+		return errors.Wrap(err, "Failed to update the trusted CA certificates of the VM")
+	}
+	if !changed {
+		return nil
+	}
+	sd := systemd.NewInstanceSystemdCommander(sshRunner)
+	return errors.Wrap(sd.Restart("crio"), "Failed to restart cri-o")
+}
+
+// ensureRegistriesConfInVM updates the registries configuration of cri-o,
+// with MicroShift there is no machine-config operator managing it
+func ensureRegistriesConfInVM(sshRunner *crcssh.Runner, cfg registries.Config) error {
+	changed, err := cluster.EnsureRegistriesConfInVM(sshRunner, cfg)
+	if err != nil {
+		return errors.Wrap(err, "Failed to update the registries configuration")
+	}
+	if !changed {
+		return nil
+	}
+	sd := systemd.NewInstanceSystemdCommander(sshRunner)
+	return errors.Wrap(sd.Restart("crio"), "Failed to restart cri-o")
+}
+
+func startMicroshift(ctx context.Context, machineName string, sshRunner *crcssh.Runner, ocConfig oc.Config, pullSec cluster.PullSecretLoader) error {
 	logging.Infof("Starting Microshift service... [takes around 1min]")
 	if err := ensurePullSecretPresentInVM(sshRunner, pullSec); err != nil {
 		return err
@@ -898,20 +1092,21 @@ func startMicroshift(ctx context.Context, sshRunner *crcssh.Runner, ocConfig oc.
 	if _, _, err := sshRunner.RunPrivileged("Starting microshift service", "systemctl", "start", "microshift"); err != nil {
 		return err
 	}
-	if err := sshRunner.CopyFileFromVM(fmt.Sprintf("/var/lib/microshift/resources/kubeadmin/api%s/kubeconfig", constants.ClusterDomain), constants.KubeconfigFilePath, 0o600); err != nil {
+	kubeconfigFilePath := constants.GetKubeconfigFilePath(machineName)
+	if err := sshRunner.CopyFileFromVM(fmt.Sprintf("/var/lib/microshift/resources/kubeadmin/api%s/kubeconfig", constants.ClusterDomain), kubeconfigFilePath, 0o600); err != nil {
 		return err
 	}
-	if err := sshRunner.CopyFile(constants.KubeconfigFilePath, "/opt/kubeconfig", 0o644); err != nil {
+	if err := sshRunner.CopyFile(kubeconfigFilePath, "/opt/kubeconfig", 0o644); err != nil {
 		return err
 	}
 
 	return cluster.WaitForAPIServer(ctx, ocConfig)
 }
 
-func checkMachineInstanceDir() error {
+func checkMachineInstanceDir(machineName string) error {
 	requiredFiles := []string{
-		constants.GetPrivateKeyPath(),
-		constants.GetPublicKeyPath(),
+		constants.GetPrivateKeyPath(machineName),
+		constants.GetPublicKeyPath(machineName),
 	}
 	for _, filePath := range requiredFiles {
 		if !crcos.FileExists(filePath) {
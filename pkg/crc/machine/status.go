@@ -38,9 +38,9 @@ func (client *client) Status() (*types.ClusterStatusResult, error) {
 		return nil, errors.Wrap(err, "Cannot get machine state")
 	}
 
-	ip := ""
+	apiAddress := ""
 	if vmStatus == state.Running {
-		ip, err = vm.IP()
+		apiAddress, err = vm.APIAddress()
 		if err != nil {
 			return nil, errors.Wrap(err, "Error getting ip")
 		}
@@ -49,15 +49,15 @@ func (client *client) Status() (*types.ClusterStatusResult, error) {
 	ramSize, ramUse := client.getRAMStatus(vm)
 	diskSize, diskUse := client.getDiskDetails(vm)
 	pvSize, pvUse := client.getPVCSize(vm)
-	var openShiftStatusSupplier = getOpenShiftStatus
+	var openShiftStatusSupplier = client.getOpenShiftStatus
 	if vm.bundle.IsMicroshift() {
-		openShiftStatusSupplier = getMicroShiftStatus
+		openShiftStatusSupplier = client.getMicroShiftStatus
 	}
 
-	return createClusterStatusResult(vmStatus, vm.bundle.GetBundleType(), vm.bundle.GetVersion(), ip, diskSize, diskUse, ramSize, ramUse, pvUse, pvSize, openShiftStatusSupplier)
+	return createClusterStatusResult(vmStatus, vm.bundle.GetBundleType(), vm.bundle.GetVersion(), apiAddress, diskSize, diskUse, ramSize, ramUse, pvUse, pvSize, openShiftStatusSupplier)
 }
 
-func createClusterStatusResult(vmStatus state.State, bundleType preset.Preset, vmBundleVersion, vmIP string, diskSize, diskUse, ramSize, ramUse strongunits.B, pvUse, pvSize strongunits.B, openShiftStatusSupplier openShiftStatusSupplierFunc) (*types.ClusterStatusResult, error) {
+func createClusterStatusResult(vmStatus state.State, bundleType preset.Preset, vmBundleVersion, apiAddress string, diskSize, diskUse, ramSize, ramUse strongunits.B, pvUse, pvSize strongunits.B, openShiftStatusSupplier openShiftStatusSupplierFunc) (*types.ClusterStatusResult, error) {
 	clusterStatusResult := &types.ClusterStatusResult{
 		CrcStatus:        vmStatus,
 		OpenshiftVersion: vmBundleVersion,
@@ -81,7 +81,7 @@ func createClusterStatusResult(vmStatus state.State, bundleType preset.Preset, v
 	clusterStatusResult.DiskSize = diskSize
 	clusterStatusResult.RAMSize = ramSize
 	clusterStatusResult.RAMUse = ramUse
-	clusterStatusResult.OpenshiftStatus = openShiftStatusSupplier(context.Background(), vmIP)
+	clusterStatusResult.OpenshiftStatus = openShiftStatusSupplier(context.Background(), apiAddress)
 
 	if bundleType == preset.Microshift {
 		clusterStatusResult.PersistentVolumeUse = pvUse
@@ -147,8 +147,8 @@ func (client *client) getDiskDetails(vm *virtualMachine) (strongunits.B, strongu
 	return disk.([]strongunits.B)[0], disk.([]strongunits.B)[1]
 }
 
-func getOpenShiftStatus(ctx context.Context, ip string) types.OpenshiftStatus {
-	status, err := cluster.GetClusterOperatorsStatus(ctx, ip, constants.KubeconfigFilePath)
+func (client *client) getOpenShiftStatus(ctx context.Context, apiAddress string) types.OpenshiftStatus {
+	status, err := cluster.GetClusterOperatorsStatus(ctx, apiAddress, constants.GetKubeconfigFilePath(client.name))
 	if err != nil {
 		logging.Debugf("cannot get OpenShift status: %v", err)
 		return types.OpenshiftUnreachable
@@ -156,8 +156,8 @@ func getOpenShiftStatus(ctx context.Context, ip string) types.OpenshiftStatus {
 	return getStatus(status)
 }
 
-func getMicroShiftStatus(ctx context.Context, ip string) types.OpenshiftStatus {
-	status, err := cluster.GetClusterNodeStatus(ctx, ip, constants.KubeconfigFilePath)
+func (client *client) getMicroShiftStatus(ctx context.Context, apiAddress string) types.OpenshiftStatus {
+	status, err := cluster.GetClusterNodeStatus(ctx, apiAddress, constants.GetKubeconfigFilePath(client.name))
 	if err != nil {
 		logging.Debugf("failed to get microshift node status: %v", err)
 		return types.OpenshiftUnreachable
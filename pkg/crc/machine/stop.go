@@ -3,14 +3,18 @@ package machine
 import (
 	"os"
 
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
 	"github.com/pkg/errors"
 )
 
-func (client *client) Stop() (state.State, error) {
+func (client *client) Stop() (_ state.State, err error) {
+	defer func() {
+		client.publishFailure(err)
+	}()
 	defer func(input, output string) {
-		err := cleanKubeconfig(input, output)
+		err := cleanKubeconfig(input, output, client.name)
 		if err != nil && !errors.Is(err, os.ErrNotExist) {
 			logging.Warnf("Failed to remove crc contexts from kubeconfig: %v", err)
 		}
@@ -24,6 +28,7 @@ func (client *client) Stop() (state.State, error) {
 	}
 	defer vm.Close()
 	logging.Info("Stopping the instance, this may take a few minutes...")
+	client.publish(lifecycle.Stopping)
 	if err := vm.Stop(); err != nil {
 		status, stateErr := vm.State()
 		if stateErr != nil {
@@ -37,7 +42,10 @@ func (client *client) Stop() (state.State, error) {
 	}
 	// In case usermode networking make sure all the port bind on host should be released
 	if client.useVSock() {
-		return status, unexposePorts()
+		if err := unexposePorts(vm.addresses); err != nil {
+			return status, err
+		}
 	}
+	client.publish(lifecycle.Stopped)
 	return status, nil
 }
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
 	crcOs "github.com/crc-org/crc/v2/pkg/os"
 	"github.com/stretchr/testify/assert"
@@ -57,7 +59,7 @@ func TestClient_WhenStopInvoked_ThenKubeConfigUpdatedIfRequired(t *testing.T) {
 			err = os.Setenv("KUBECONFIG", kubeConfigPath)
 			assert.NoError(t, err)
 			crcConfigStorage := crcConfig.New(crcConfig.NewEmptyInMemoryStorage(), crcConfig.NewEmptyInMemorySecretStorage())
-			client := NewClient("test-client", false, crcConfigStorage)
+			client := NewClient(constants.DefaultName, false, crcConfigStorage)
 
 			// When
 			clusterState, _ := client.Stop()
@@ -74,3 +76,22 @@ func TestClient_WhenStopInvoked_ThenKubeConfigUpdatedIfRequired(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_WhenStopFails_ThenPublishFailedEvent(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "kubeconfig"))
+	var events []lifecycle.Event
+	unsubscribe := lifecycle.Subscribe(func(event lifecycle.Event) {
+		if event.Instance == "i-dont-exist" {
+			events = append(events, event)
+		}
+	})
+	defer unsubscribe()
+
+	client := NewClient("i-dont-exist", false, crcConfig.New(crcConfig.NewEmptyInMemoryStorage(), crcConfig.NewEmptyInMemorySecretStorage()))
+	_, err := client.Stop()
+	assert.Error(t, err)
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, lifecycle.Failed, events[0].Phase)
+	assert.Equal(t, "Instance is already stopped", events[0].Message)
+}
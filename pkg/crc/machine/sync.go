@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
@@ -21,6 +22,9 @@ const (
 	Deleting State = "Deleting"
 	Stopping State = "Stopping"
 	Starting State = "Starting"
+	// GeneratingBundle is the state while a bundle is generated, the
+	// instance is stopped and started again during the generation
+	GeneratingBundle State = "GeneratingBundle"
 )
 
 type Synchronized struct {
@@ -124,6 +128,8 @@ func (s *Synchronized) prepareStopDelete(state State) error {
 		break
 	case Deleting, Stopping:
 		return errors.New("cluster is stopping or deleting")
+	case GeneratingBundle:
+		return errors.New("a bundle is being generated from the cluster")
 	default:
 		return errors.New("invalid condition")
 	}
@@ -190,10 +196,79 @@ func (s *Synchronized) IsRunning() (bool, error) {
 	return s.underlying.IsRunning()
 }
 
-func (s *Synchronized) GenerateBundle(forceStop bool) error {
-	return s.underlying.GenerateBundle(forceStop)
+func (s *Synchronized) GenerateBundle(ctx context.Context, config types.GenerateBundleConfig) (*types.GenerateBundleResult, error) {
+	if err := s.prepareGenerateBundle(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.underlying.GenerateBundle(ctx, config)
+	s.syncOperationDone <- GeneratingBundle
+	return result, err
+}
+
+// prepareGenerateBundle holds the start/stop lock during the generation,
+// which stops the instance and may start it again
+func (s *Synchronized) prepareGenerateBundle() error {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	if s.currentStateUnlocked() != Idle {
+		return errors.New("cluster is busy")
+	}
+	s.currentState = GeneratingBundle
+	return nil
 }
 
 func (s *Synchronized) GetPreset() crcPreset.Preset {
 	return s.underlying.GetPreset()
 }
+
+func (s *Synchronized) checkIdle() error {
+	if s.CurrentState() != Idle {
+		return errors.New("cluster is busy")
+	}
+	return nil
+}
+
+func (s *Synchronized) CreateSnapshot(name string) error {
+	if err := s.checkIdle(); err != nil {
+		return err
+	}
+	return s.underlying.CreateSnapshot(name)
+}
+
+func (s *Synchronized) ListSnapshots() ([]types.Snapshot, error) {
+	return s.underlying.ListSnapshots()
+}
+
+func (s *Synchronized) RestoreSnapshot(name string) error {
+	if err := s.checkIdle(); err != nil {
+		return err
+	}
+	return s.underlying.RestoreSnapshot(name)
+}
+
+func (s *Synchronized) DeleteSnapshot(name string) error {
+	return s.underlying.DeleteSnapshot(name)
+}
+
+func (s *Synchronized) CertificatesStatus() ([]types.CertificateStatus, error) {
+	return s.underlying.CertificatesStatus()
+}
+
+func (s *Synchronized) RotateCertificates(ctx context.Context) error {
+	if err := s.checkIdle(); err != nil {
+		return err
+	}
+	return s.underlying.RotateCertificates(ctx)
+}
+
+func (s *Synchronized) Diagnostics(ctx context.Context, write func(types.DiagnosticFile)) error {
+	return s.underlying.Diagnostics(ctx, write)
+}
+
+func (s *Synchronized) UpdatePullSecret(ctx context.Context, pullSecret cluster.PullSecretLoader) error {
+	if err := s.checkIdle(); err != nil {
+		return err
+	}
+	return s.underlying.UpdatePullSecret(ctx, pullSecret)
+}
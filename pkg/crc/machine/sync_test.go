@@ -6,6 +6,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/crc-org/crc/v2/pkg/crc/cluster"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/types"
 	crcPreset "github.com/crc-org/crc/v2/pkg/crc/preset"
@@ -73,6 +74,39 @@ func TestDeleteStop(t *testing.T) {
 	assert.Equal(t, Idle, syncMachine.CurrentState())
 }
 
+func TestGenerateBundleHoldsTheLock(t *testing.T) {
+	isRunning := make(chan struct{}, 1)
+	bundleCh := make(chan struct{}, 1)
+	waitingMachine := &waitingMachine{
+		isRunning:        isRunning,
+		bundleCompleteCh: bundleCh,
+	}
+	syncMachine := NewSynchronizedMachine(waitingMachine)
+
+	lock := &sync.WaitGroup{}
+	lock.Add(1)
+	go func() {
+		defer lock.Done()
+		_, err := syncMachine.GenerateBundle(context.Background(), types.GenerateBundleConfig{})
+		assert.NoError(t, err)
+	}()
+
+	<-isRunning
+	assert.Equal(t, GeneratingBundle, syncMachine.CurrentState())
+	_, err := syncMachine.Start(context.Background(), types.StartConfig{})
+	assert.EqualError(t, err, "cluster is busy")
+	_, err = syncMachine.Stop()
+	assert.EqualError(t, err, "a bundle is being generated from the cluster")
+	assert.EqualError(t, syncMachine.Delete(), "a bundle is being generated from the cluster")
+	_, err = syncMachine.GenerateBundle(context.Background(), types.GenerateBundleConfig{})
+	assert.EqualError(t, err, "cluster is busy")
+
+	bundleCh <- struct{}{}
+	lock.Wait()
+
+	assert.Equal(t, Idle, syncMachine.CurrentState())
+}
+
 func TestCancelStart(t *testing.T) {
 	isRunning := make(chan struct{}, 1)
 	deleteCh := make(chan struct{}, 1)
@@ -112,6 +146,7 @@ type waitingMachine struct {
 	startCompleteCh  chan struct{}
 	stopCompleteCh   chan struct{}
 	deleteCompleteCh chan struct{}
+	bundleCompleteCh chan struct{}
 }
 
 func (m *waitingMachine) IsRunning() (bool, error) {
@@ -167,8 +202,10 @@ func (m *waitingMachine) Stop() (state.State, error) {
 	return state.Stopped, nil
 }
 
-func (m *waitingMachine) GenerateBundle(_ bool) error {
-	return errors.New("not implemented")
+func (m *waitingMachine) GenerateBundle(_ context.Context, _ types.GenerateBundleConfig) (*types.GenerateBundleResult, error) {
+	m.isRunning <- struct{}{}
+	<-m.bundleCompleteCh
+	return &types.GenerateBundleResult{}, nil
 }
 
 func (m *waitingMachine) GetPreset() crcPreset.Preset {
@@ -178,3 +215,35 @@ func (m *waitingMachine) GetPreset() crcPreset.Preset {
 func (m *waitingMachine) GetClusterLoad() (*types.ClusterLoadResult, error) {
 	return nil, errors.New("not implemented")
 }
+
+func (m *waitingMachine) CreateSnapshot(_ string) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) ListSnapshots() ([]types.Snapshot, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) RestoreSnapshot(_ string) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) DeleteSnapshot(_ string) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) CertificatesStatus() ([]types.CertificateStatus, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) RotateCertificates(_ context.Context) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) Diagnostics(_ context.Context, _ func(types.DiagnosticFile)) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) UpdatePullSecret(_ context.Context, _ cluster.PullSecretLoader) error {
+	return errors.New("not implemented")
+}
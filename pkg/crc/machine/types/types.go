@@ -1,10 +1,16 @@
 package types
 
 import (
+	"time"
+
+	"github.com/crc-org/crc/v2/pkg/compress"
 	"github.com/crc-org/crc/v2/pkg/crc/cluster"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 	"github.com/crc-org/crc/v2/pkg/crc/network/httpproxy"
 	crcpreset "github.com/crc-org/crc/v2/pkg/crc/preset"
+	"github.com/crc-org/crc/v2/pkg/crc/registries"
+	"github.com/crc-org/crc/v2/pkg/crc/shareddirs"
 	"go.podman.io/common/pkg/strongunits"
 )
 
@@ -36,6 +42,8 @@ type StartConfig struct {
 	EnableSharedDirs  bool
 	SharedDirPassword string
 	SharedDirUsername string
+	// SharedDirs replaces the sharing of the home directory when not empty
+	SharedDirs []shareddirs.SharedDir
 
 	// Ports to access openshift routes
 	IngressHTTPPort  uint
@@ -49,6 +57,26 @@ type StartConfig struct {
 
 	// Enable bundle quay fallback
 	EnableBundleQuayFallback bool
+
+	// Mirrors to try before mirror.openshift.com when downloading the default bundle
+	BundleMirrors []string
+
+	// Refuse the custom bundles which are not signed with TrustedBundleKeys,
+	// and the default bundles which do not match the release sha256sums
+	EnforceBundleSignature bool
+	TrustedBundleKeys      []string
+
+	// Directory of manifests and hooks applied once the cluster is stable
+	ProvisioningDir string
+
+	// Additional host ports forwarded to the VM with user network mode
+	PortForwards []network.PortForward
+
+	// Container registry mirrors, insecure and blocked registries
+	Registries registries.Config
+
+	// PEM files, or directories of PEM files, of the CAs trusted by the VM and the cluster
+	AdditionalTrustedCAFiles []string
 }
 
 type ClusterConfig struct {
@@ -116,3 +144,68 @@ type ConnectionDetails struct {
 	SSHUsername string
 	SSHKeys     []string
 }
+
+type Snapshot struct {
+	Name         string
+	CreationTime time.Time
+}
+
+type CertificateStatus struct {
+	Name      string
+	Path      string
+	Subject   string
+	Issuer    string
+	NotBefore time.Time
+	NotAfter  time.Time
+	// Missing is set when the certificate file does not exist in the VM,
+	// the other fields are then empty
+	Missing bool
+}
+
+// Expired returns true when the certificate is not valid anymore
+func (c CertificateStatus) Expired() bool {
+	return !c.Missing && time.Now().After(c.NotAfter)
+}
+
+// DiagnosticFile is the output of a command run to diagnose an instance,
+// Name is the slash-separated path of the file in the diagnostics archive
+type DiagnosticFile struct {
+	Name    string
+	Content []byte
+	// Error is set when the command failed, Content may then be partial
+	Error string
+}
+
+type GenerateBundleConfig struct {
+	// Name is the suffix of the bundle name, a timestamp is used when empty
+	Name string
+	// OutputDir is the directory where the bundle is written, the current
+	// directory when empty
+	OutputDir string
+	// Compression are the format, level and concurrency of the compression
+	// of the bundle, the zero value selects the default zstd compression
+	Compression compress.Options
+	// Delta generates a bundle with only the changes made to the disk image
+	// of the bundle of the instance, which is its base bundle
+	Delta bool
+	// ForceStop powers off the VM when it cannot be stopped gracefully
+	ForceStop bool
+	// KeepRunningState leaves the instance running once the bundle is
+	// generated when it was running, it is stopped otherwise
+	KeepRunningState bool
+	// StartConfig is used to start a stopped instance so that its pull
+	// secret is removed, and to start it again to restore the pull secret
+	StartConfig StartConfig
+}
+
+type GenerateBundleResult struct {
+	Path string
+	// Sha256sum of the bundle archive
+	Sha256sum string
+	// CompressionFormat of the bundle archive, the .crcbundle extension
+	// is kept whatever the format is
+	CompressionFormat compress.Format
+	// Restarted is set when the instance is left running with
+	// KeepRunningState
+	Restarted bool
+}
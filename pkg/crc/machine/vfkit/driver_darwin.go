@@ -24,19 +24,26 @@ func CreateHost(machineConfig config.MachineConfig) *vfkit.Driver {
 
 	vfDriver.QemuGAVsockPort = constants.QemuGuestAgentPort
 
-	vfDriver.SharedDirs = configureShareDirs(machineConfig)
+	vfDriver.SharedDirs = ConfigureShareDirs(machineConfig)
 
 	return vfDriver
 }
 
-func configureShareDirs(machineConfig config.MachineConfig) []drivers.SharedDir {
+// ConfigureShareDirs returns the shared directories of the driver for the
+// directories of machineConfig
+func ConfigureShareDirs(machineConfig config.MachineConfig) []drivers.SharedDir {
 	var sharedDirs []drivers.SharedDir
 	for i, dir := range machineConfig.SharedDirs {
+		target := dir.Target
+		if target == "" {
+			target = dir.Source
+		}
 		sharedDir := drivers.SharedDir{
-			Source: dir,
-			Target: dir,
-			Tag:    fmt.Sprintf("dir%d", i),
-			Type:   "virtiofs",
+			Source:   dir.Source,
+			Target:   target,
+			Tag:      fmt.Sprintf("dir%d", i),
+			Type:     "virtiofs",
+			ReadOnly: dir.ReadOnly,
 		}
 		sharedDirs = append(sharedDirs, sharedDir)
 	}
@@ -2,11 +2,14 @@ package machine
 
 import (
 	"fmt"
+	"net"
+	"strconv"
 
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 	"github.com/crc-org/crc/v2/pkg/crc/ssh"
 	"github.com/crc-org/crc/v2/pkg/libmachine"
 	libmachinehost "github.com/crc-org/crc/v2/pkg/libmachine/host"
@@ -19,6 +22,8 @@ type virtualMachine struct {
 	bundle *bundle.CrcBundleInfo
 	api    libmachine.API
 	vsock  bool
+	// addresses are only used with user mode networking
+	addresses network.InstanceAddresses
 }
 
 type MissingHostError struct {
@@ -50,6 +55,14 @@ func loadVirtualMachine(name string, useVSock bool) (*virtualMachine, error) {
 		return nil, errors.Wrap(err, "Cannot load machine")
 	}
 
+	var addresses network.InstanceAddresses
+	if useVSock {
+		addresses, err = getInstanceAddresses(name)
+		if err != nil {
+			return nil, errors.Wrap(err, "Cannot get the addresses of the machine")
+		}
+	}
+
 	crcBundleMetadata, err := getBundleMetadataFromDriver(libmachineHost.Driver)
 	if err != nil {
 		logging.Debugf("Failed to get bundle metadata: %v", err)
@@ -62,6 +75,8 @@ func loadVirtualMachine(name string, useVSock bool) (*virtualMachine, error) {
 		bundle: crcBundleMetadata,
 		api:    apiClient,
 		vsock:  useVSock,
+
+		addresses: addresses,
 	}, err
 }
 
@@ -98,15 +113,42 @@ func (vm *virtualMachine) IP() (string, error) {
 
 func (vm *virtualMachine) SSHPort() int {
 	if vm.vsock {
-		return constants.VsockSSHPort
+		return vm.addresses.SSHPort()
 	}
 	return constants.DefaultSSHPort
 }
 
+func (vm *virtualMachine) APIPort() int {
+	if vm.vsock {
+		return vm.addresses.APIPort()
+	}
+	return constants.OpenShiftAPIPort
+}
+
+// APIAddress returns the address the API server of the VM is reachable at
+// from the host
+func (vm *virtualMachine) APIAddress() (string, error) {
+	ip, err := vm.IP()
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(ip, strconv.Itoa(vm.APIPort())), nil
+}
+
+// webConsoleHost returns the host of the web console URL, it includes the
+// port when the instance does not use the configured ingress port
+func (vm *virtualMachine) webConsoleHost() string {
+	host := vm.bundle.GetAppHostname("console-openshift-console")
+	if !vm.vsock || vm.addresses.Index == 0 {
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(vm.addresses.IngressHTTPSPort(constants.OpenShiftIngressHTTPSPort))))
+}
+
 func (vm *virtualMachine) SSHRunner() (*ssh.Runner, error) {
 	ip, err := vm.IP()
 	if err != nil {
 		return nil, err
 	}
-	return ssh.CreateRunner(ip, vm.SSHPort(), constants.GetPrivateKeyPath(), constants.GetECDSAPrivateKeyPath(), vm.bundle.GetSSHKeyPath())
+	return ssh.CreateRunner(ip, vm.SSHPort(), constants.GetPrivateKeyPath(vm.name), constants.GetECDSAPrivateKeyPath(vm.name), vm.bundle.GetSSHKeyPath())
 }
@@ -12,13 +12,17 @@ import (
 	"github.com/crc-org/crc/v2/pkg/crc/daemonclient"
 	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 	crcPreset "github.com/crc-org/crc/v2/pkg/crc/preset"
 	"github.com/pkg/errors"
 )
 
-func exposePorts(preset crcPreset.Preset, ingressHTTPPort, ingressHTTPSPort uint) error {
-	portsToExpose := vsockPorts(preset, ingressHTTPPort, ingressHTTPSPort)
-	daemonClient := daemonclient.New()
+func exposePorts(machineName string, addresses network.InstanceAddresses, preset crcPreset.Preset, ingressHTTPPort, ingressHTTPSPort uint, portForwards []network.PortForward) error {
+	portsToExpose := vsockPorts(machineName, addresses, preset, ingressHTTPPort, ingressHTTPSPort)
+	for _, forward := range portForwards {
+		portsToExpose = append(portsToExpose, *forward.ExposeRequest())
+	}
+	daemonClient := daemonclient.NewLocal()
 	alreadyOpenedPorts, err := listOpenPorts(daemonClient)
 	if err != nil {
 		return err
@@ -47,14 +51,19 @@ func isOpened(exposed []types.ExposeRequest, port types.ExposeRequest) bool {
 	return false
 }
 
-func unexposePorts() error {
+// unexposePorts removes the forwards to the virtual machine of the instance,
+// the forwards of the other instances are kept
+func unexposePorts(addresses network.InstanceAddresses) error {
 	var mErr crcErrors.MultiError
-	daemonClient := daemonclient.New()
+	daemonClient := daemonclient.NewLocal()
 	alreadyOpenedPorts, err := listOpenPorts(daemonClient)
 	if err != nil {
 		return err
 	}
 	for _, port := range alreadyOpenedPorts {
+		if remoteHost(port) != addresses.VMIP() {
+			continue
+		}
 		if err := daemonClient.NetworkClient.Unexpose(&types.UnexposeRequest{Protocol: port.Protocol, Local: port.Local}); err != nil {
 			mErr.Collect(errors.Wrapf(err, "failed to unexpose port %s ", port.Local))
 		}
@@ -65,6 +74,23 @@ func unexposePorts() error {
 	return mErr
 }
 
+// remoteHost returns the address of the virtual machine a forward goes to
+func remoteHost(port types.ExposeRequest) string {
+	hostPort := port.Remote
+	if port.Protocol == types.UNIX || port.Protocol == types.NPIPE {
+		u, err := url.Parse(port.Remote)
+		if err != nil {
+			return ""
+		}
+		hostPort = u.Host
+	}
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
 func listOpenPorts(daemonClient *daemonclient.Client) ([]types.ExposeRequest, error) {
 	alreadyOpenedPorts, err := daemonClient.NetworkClient.List()
 	if err != nil {
@@ -75,32 +101,35 @@ func listOpenPorts(daemonClient *daemonclient.Client) ([]types.ExposeRequest, er
 }
 
 const (
-	virtualMachineIP = "192.168.127.2"
-	hostVirtualIP    = "192.168.127.254"
-	internalSSHPort  = "22"
-	remoteHTTPPort   = "80"
-	remoteHTTPSPort  = "443"
-	apiPort          = "6443"
-	cockpitPort      = "9090"
+	hostVirtualIP   = "192.168.127.254"
+	internalSSHPort = "22"
+	remoteHTTPPort  = "80"
+	remoteHTTPSPort = "443"
+	apiPort         = "6443"
+	cockpitPort     = "9090"
 )
 
-func vsockPorts(preset crcPreset.Preset, ingressHTTPPort, ingressHTTPSPort uint) []types.ExposeRequest {
+func vsockPorts(machineName string, addresses network.InstanceAddresses, preset crcPreset.Preset, ingressHTTPPort, ingressHTTPSPort uint) []types.ExposeRequest {
+	virtualMachineIP := addresses.VMIP()
 	socketProtocol := types.UNIX
-	socketLocal := constants.GetHostDockerSocketPath()
+	socketLocal := constants.GetHostDockerSocketPath(machineName)
 	if runtime.GOOS == "windows" {
 		socketProtocol = types.NPIPE
 		socketLocal = constants.DefaultPodmanNamedPipe
+		if machineName != constants.DefaultName {
+			socketLocal = fmt.Sprintf("%s-%s", constants.DefaultPodmanNamedPipe, machineName)
+		}
 	}
 	exposeRequest := []types.ExposeRequest{
 		{
 			Protocol: "tcp",
-			Local:    net.JoinHostPort(constants.LocalIP, strconv.Itoa(constants.VsockSSHPort)),
+			Local:    net.JoinHostPort(constants.LocalIP, strconv.Itoa(addresses.SSHPort())),
 			Remote:   net.JoinHostPort(virtualMachineIP, internalSSHPort),
 		},
 		{
 			Protocol: socketProtocol,
 			Local:    socketLocal,
-			Remote:   getSSHTunnelURI(),
+			Remote:   getSSHTunnelURI(machineName, virtualMachineIP),
 		},
 	}
 
@@ -109,17 +138,17 @@ func vsockPorts(preset crcPreset.Preset, ingressHTTPPort, ingressHTTPSPort uint)
 		exposeRequest = append(exposeRequest,
 			types.ExposeRequest{
 				Protocol: "tcp",
-				Local:    net.JoinHostPort(constants.LocalIP, apiPort),
+				Local:    net.JoinHostPort(constants.LocalIP, strconv.Itoa(addresses.APIPort())),
 				Remote:   net.JoinHostPort(virtualMachineIP, apiPort),
 			},
 			types.ExposeRequest{
 				Protocol: "tcp",
-				Local:    fmt.Sprintf(":%d", ingressHTTPSPort),
+				Local:    fmt.Sprintf(":%d", addresses.IngressHTTPSPort(ingressHTTPSPort)),
 				Remote:   net.JoinHostPort(virtualMachineIP, remoteHTTPSPort),
 			},
 			types.ExposeRequest{
 				Protocol: "tcp",
-				Local:    fmt.Sprintf(":%d", ingressHTTPPort),
+				Local:    fmt.Sprintf(":%d", addresses.IngressHTTPPort(ingressHTTPPort)),
 				Remote:   net.JoinHostPort(virtualMachineIP, remoteHTTPPort),
 			})
 	default:
@@ -129,14 +158,14 @@ func vsockPorts(preset crcPreset.Preset, ingressHTTPPort, ingressHTTPSPort uint)
 	return exposeRequest
 }
 
-func getSSHTunnelURI() string {
+func getSSHTunnelURI(machineName, virtualMachineIP string) string {
 	u := url.URL{
 		Scheme:     "ssh-tunnel",
 		User:       url.User("core"),
 		Host:       net.JoinHostPort(virtualMachineIP, internalSSHPort),
 		Path:       "/run/podman/podman.sock",
 		ForceQuery: false,
-		RawQuery:   fmt.Sprintf("key=%s", url.QueryEscape(constants.GetPrivateKeyPath())),
+		RawQuery:   fmt.Sprintf("key=%s", url.QueryEscape(constants.GetPrivateKeyPath(machineName))),
 	}
 	return u.String()
 }
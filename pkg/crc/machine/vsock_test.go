@@ -0,0 +1,33 @@
+package machine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
+	crcPreset "github.com/crc-org/crc/v2/pkg/crc/preset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVsockPortsOfInstancesDoNotOverlap(t *testing.T) {
+	defaultPorts := vsockPorts("crc", network.InstanceAddresses{}, crcPreset.OpenShift, 80, 443)
+	otherAddresses := network.InstanceAddresses{Index: 1}
+	otherPorts := vsockPorts("other", otherAddresses, crcPreset.OpenShift, 80, 443)
+	assert.Len(t, otherPorts, len(defaultPorts))
+
+	locals := map[string]bool{}
+	for _, port := range defaultPorts {
+		locals[port.Local] = true
+		assert.Equal(t, network.DefaultPortForwardGuestIP, remoteHost(port))
+	}
+	for _, port := range otherPorts {
+		assert.False(t, locals[port.Local], "%s is used by both instances", port.Local)
+		assert.Equal(t, otherAddresses.VMIP(), remoteHost(port))
+	}
+	assert.Contains(t, otherPorts, types.ExposeRequest{
+		Protocol: "tcp",
+		Local:    "127.0.0.1:6444",
+		Remote:   fmt.Sprintf("%s:6443", otherAddresses.VMIP()),
+	})
+}
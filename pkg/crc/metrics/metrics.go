@@ -0,0 +1,349 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/state"
+)
+
+var (
+	requestDurationBuckets   = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+	operationDurationBuckets = []float64{5, 10, 30, 60, 120, 300, 600, 900, 1200, 1800}
+)
+
+// unmatchedRoute is the path label of the requests which matched no route,
+// so that arbitrary paths do not create new series
+const unmatchedRoute = "unmatched"
+
+// operation phases, the duration of an operation is measured from its first
+// phase to its last phase
+var operations = map[string]struct {
+	first []lifecycle.Phase
+	last  lifecycle.Phase
+}{
+	"start":  {first: []lifecycle.Phase{lifecycle.BundleDownload, lifecycle.BundleExtraction, lifecycle.VMStarting}, last: lifecycle.Started},
+	"stop":   {first: []lifecycle.Phase{lifecycle.Stopping}, last: lifecycle.Stopped},
+	"delete": {first: []lifecycle.Phase{lifecycle.Deleting}, last: lifecycle.Deleted},
+}
+
+type requestKey struct {
+	method string
+	path   string
+	code   int
+}
+
+type operationKey struct {
+	instance  string
+	operation string
+}
+
+// NetworkStats returns the number of bytes sent to and received from the
+// virtual machines by the virtual network
+type NetworkStats func() (sent uint64, received uint64)
+
+// Collector gathers the metrics served by the daemon on /metrics
+type Collector struct {
+	instances    *machine.Instances
+	networkStats NetworkStats
+
+	lock              sync.Mutex
+	requestCounts     map[requestKey]uint64
+	requestDurations  map[string]*histogram
+	operationStarts   map[operationKey]time.Time
+	operationDuration map[string]*histogram
+
+	unsubscribe func()
+	now         func() time.Time
+}
+
+// NewCollector creates a collector reporting the status of instances and
+// the network statistics. networkStats can be nil when the daemon does not
+// run the virtual network.
+func NewCollector(instances *machine.Instances, networkStats NetworkStats) *Collector {
+	c := &Collector{
+		instances:         instances,
+		networkStats:      networkStats,
+		requestCounts:     map[requestKey]uint64{},
+		requestDurations:  map[string]*histogram{},
+		operationStarts:   map[operationKey]time.Time{},
+		operationDuration: map[string]*histogram{},
+		now:               time.Now,
+	}
+	c.unsubscribe = lifecycle.Subscribe(c.lifecycleEvent)
+	return c
+}
+
+// Close stops recording the duration of lifecycle operations
+func (c *Collector) Close() {
+	c.unsubscribe()
+}
+
+func (c *Collector) lifecycleEvent(event lifecycle.Event) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for name, operation := range operations {
+		if event.Phase == lifecycle.Failed {
+			c.clearOperation(event.Instance, name)
+			continue
+		}
+		key := operationKey{instance: event.Instance, operation: name}
+		for _, phase := range operation.first {
+			if phase != event.Phase {
+				continue
+			}
+			if _, started := c.operationStarts[key]; !started {
+				c.operationStarts[key] = c.now()
+			}
+		}
+		if event.Phase != operation.last {
+			continue
+		}
+		start, ok := c.operationStart(event.Instance, name)
+		c.clearOperation(event.Instance, name)
+		if !ok {
+			continue
+		}
+		h, ok := c.operationDuration[name]
+		if !ok {
+			h = newHistogram(operationDurationBuckets)
+			c.operationDuration[name] = h
+		}
+		h.observe(c.now().Sub(start).Seconds())
+	}
+}
+
+// operationStart returns when the operation of instance started, the bundle
+// phases are published without instance and can start the operation first
+func (c *Collector) operationStart(instance, operation string) (time.Time, bool) {
+	start, ok := c.operationStarts[operationKey{instance: instance, operation: operation}]
+	withoutInstance, found := c.operationStarts[operationKey{operation: operation}]
+	if found && (!ok || withoutInstance.Before(start)) {
+		return withoutInstance, true
+	}
+	return start, ok
+}
+
+func (c *Collector) clearOperation(instance, operation string) {
+	delete(c.operationStarts, operationKey{instance: instance, operation: operation})
+	delete(c.operationStarts, operationKey{operation: operation})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type routeKey struct{}
+
+// SetRoute records the route pattern which matched r, it is the path label
+// of the request in the metrics of the Middleware handling r
+func SetRoute(r *http.Request, route string) {
+	if matched, ok := r.Context().Value(routeKey{}).(*string); ok {
+		*matched = route
+	}
+}
+
+// Middleware counts the requests handled by next and measures their latency,
+// next reports the route handling each request with SetRoute
+func (c *Collector) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := c.now()
+		path := unmatchedRoute
+		recorder := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), routeKey{}, &path)))
+
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		c.requestCounts[requestKey{method: r.Method, path: path, code: recorder.code}]++
+		h, ok := c.requestDurations[r.Method+" "+path]
+		if !ok {
+			h = newHistogram(requestDurationBuckets)
+			c.requestDurations[r.Method+" "+path] = h
+		}
+		h.observe(c.now().Sub(start).Seconds())
+	})
+}
+
+// ServeHTTP writes all the metrics in the Prometheus text format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var buf bytes.Buffer
+	if err := c.write(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (c *Collector) write(w io.Writer) error {
+	t := &textWriter{w: w}
+	c.writeInstanceMetrics(t)
+	c.writeNetworkMetrics(t)
+	c.writeRequestMetrics(t)
+	c.writeOperationMetrics(t)
+	return t.err
+}
+
+func (c *Collector) writeInstanceMetrics(t *textWriter) {
+	names, err := c.instances.List()
+	if err != nil {
+		logging.Debugf("Cannot list instances: %v", err)
+		return
+	}
+
+	type instanceMetrics struct {
+		name   string
+		status []label
+		gauges map[string]float64
+		cpus   []int64
+	}
+	var all []instanceMetrics
+	for _, name := range names {
+		client, err := c.instances.Get(name)
+		if err != nil {
+			logging.Debugf("Cannot get instance %s: %v", name, err)
+			continue
+		}
+		status, err := client.Status()
+		if err != nil {
+			logging.Debugf("Cannot get status of instance %s: %v", name, err)
+			continue
+		}
+		m := instanceMetrics{
+			name: name,
+			status: []label{
+				{"instance", name},
+				{"crc_status", string(status.CrcStatus)},
+				{"openshift_status", string(status.OpenshiftStatus)},
+				{"preset", status.Preset.String()},
+				{"openshift_version", status.OpenshiftVersion},
+			},
+			gauges: map[string]float64{
+				"crc_ram_used_bytes":               float64(status.RAMUse),
+				"crc_ram_size_bytes":               float64(status.RAMSize),
+				"crc_disk_used_bytes":              float64(status.DiskUse),
+				"crc_disk_size_bytes":              float64(status.DiskSize),
+				"crc_persistent_volume_used_bytes": float64(status.PersistentVolumeUse),
+				"crc_persistent_volume_size_bytes": float64(status.PersistentVolumeSize),
+			},
+		}
+		if status.CrcStatus == state.Running {
+			load, err := client.GetClusterLoad()
+			if err != nil {
+				logging.Debugf("Cannot get load of instance %s: %v", name, err)
+			} else {
+				m.cpus = load.CPUUse
+			}
+		}
+		all = append(all, m)
+	}
+
+	t.header("crc_status", "Status of the instance, the value is always 1", "gauge")
+	for _, m := range all {
+		t.sample("crc_status", m.status, 1)
+	}
+	for _, gauge := range []struct{ name, help string }{
+		{"crc_ram_used_bytes", "Memory used by the instance"},
+		{"crc_ram_size_bytes", "Memory allocated to the instance"},
+		{"crc_disk_used_bytes", "Disk space used by the instance"},
+		{"crc_disk_size_bytes", "Disk size of the instance"},
+		{"crc_persistent_volume_used_bytes", "Space used on the persistent volume of the instance"},
+		{"crc_persistent_volume_size_bytes", "Size of the persistent volume of the instance"},
+	} {
+		t.header(gauge.name, gauge.help, "gauge")
+		for _, m := range all {
+			t.sample(gauge.name, []label{{"instance", m.name}}, m.gauges[gauge.name])
+		}
+	}
+	t.header("crc_cpu_usage_percent", "CPU usage of the instance, per CPU", "gauge")
+	for _, m := range all {
+		for cpu, usage := range m.cpus {
+			t.sample("crc_cpu_usage_percent", []label{{"instance", m.name}, {"cpu", fmt.Sprint(cpu)}}, float64(usage))
+		}
+	}
+}
+
+func (c *Collector) writeNetworkMetrics(t *textWriter) {
+	if c.networkStats == nil {
+		return
+	}
+	sent, received := c.networkStats()
+	t.header("crc_network_sent_bytes_total", "Bytes sent to the virtual machines by the virtual network", "counter")
+	t.sample("crc_network_sent_bytes_total", nil, float64(sent))
+	t.header("crc_network_received_bytes_total", "Bytes received from the virtual machines by the virtual network", "counter")
+	t.sample("crc_network_received_bytes_total", nil, float64(received))
+}
+
+func (c *Collector) writeRequestMetrics(t *textWriter) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	keys := make([]requestKey, 0, len(c.requestCounts))
+	for key := range c.requestCounts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].path != keys[j].path {
+			return keys[i].path < keys[j].path
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].code < keys[j].code
+	})
+	t.header("crc_api_requests_total", "Requests handled by the daemon API", "counter")
+	for _, key := range keys {
+		t.sample("crc_api_requests_total", []label{{"method", key.method}, {"path", key.path}, {"code", fmt.Sprint(key.code)}}, float64(c.requestCounts[key]))
+	}
+
+	t.header("crc_api_request_duration_seconds", "Latency of the requests handled by the daemon API", "histogram")
+	for _, methodAndPath := range sortedKeys(c.requestDurations) {
+		method, path, _ := strings.Cut(methodAndPath, " ")
+		t.histogram("crc_api_request_duration_seconds", []label{{"method", method}, {"path", path}}, c.requestDurations[methodAndPath])
+	}
+}
+
+func (c *Collector) writeOperationMetrics(t *textWriter) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	t.header("crc_lifecycle_operation_duration_seconds", "Duration of the start, stop and delete operations", "histogram")
+	for _, operation := range sortedKeys(c.operationDuration) {
+		t.histogram("crc_lifecycle_operation_duration_seconds", []label{{"operation", operation}}, c.operationDuration[operation])
+	}
+}
+
+func sortedKeys(m map[string]*histogram) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crc-org/crc/v2/pkg/crc/lifecycle"
+	"github.com/crc-org/crc/v2/pkg/crc/machine"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, handler http.Handler) string {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestInstanceMetrics(t *testing.T) {
+	collector := NewCollector(machine.NewInstances(fakemachine.NewClient(), nil), func() (uint64, uint64) {
+		return 1234, 5678
+	})
+	defer collector.Close()
+
+	metrics := scrape(t, collector)
+	assert.Contains(t, metrics, `crc_status{instance="crc",crc_status="Running",openshift_status="Running",preset="openshift",openshift_version="4.5.1"} 1`)
+	assert.Contains(t, metrics, "# TYPE crc_ram_used_bytes gauge\n")
+	assert.Contains(t, metrics, `crc_ram_used_bytes{instance="crc"} 1000`)
+	assert.Contains(t, metrics, `crc_disk_size_bytes{instance="crc"} 2e+10`)
+	assert.Contains(t, metrics, "crc_network_sent_bytes_total 1234\n")
+	assert.Contains(t, metrics, "crc_network_received_bytes_total 5678\n")
+}
+
+func TestRequestMetrics(t *testing.T) {
+	collector := NewCollector(machine.NewInstances(fakemachine.NewClient(), nil), nil)
+	defer collector.Close()
+
+	handler := collector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/status":
+			SetRoute(r, "/status")
+		case strings.HasPrefix(r.URL.Path, "/api/instances/"):
+			SetRoute(r, "/instances/{name}/status")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	for _, path := range []string{"/api/status", "/api/status", "/api/instances/foo/status", "/api/instances/bar/status", "/api/missing", "/api/other"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	metrics := scrape(t, collector)
+	assert.Contains(t, metrics, `crc_api_requests_total{method="GET",path="/status",code="200"} 2`)
+	assert.Contains(t, metrics, `crc_api_requests_total{method="GET",path="/instances/{name}/status",code="200"} 2`)
+	assert.Contains(t, metrics, `crc_api_requests_total{method="GET",path="unmatched",code="404"} 2`)
+	assert.Contains(t, metrics, `crc_api_request_duration_seconds_bucket{method="GET",path="/status",le="+Inf"} 2`)
+	assert.Contains(t, metrics, `crc_api_request_duration_seconds_count{method="GET",path="/status"} 2`)
+	assert.NotContains(t, metrics, "missing")
+	assert.NotContains(t, metrics, "crc_network_sent_bytes_total")
+}
+
+func TestOperationMetrics(t *testing.T) {
+	collector := NewCollector(machine.NewInstances(fakemachine.NewClient(), nil), nil)
+	defer collector.Close()
+	now := time.Now()
+	collector.now = func() time.Time {
+		return now
+	}
+
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.VMStarting, Instance: "crc"})
+	now = now.Add(20 * time.Second)
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.SSHReachable, Instance: "crc"})
+	now = now.Add(70 * time.Second)
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.Started, Instance: "crc"})
+	// Stopped without Stopping is not recorded
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.Stopped, Instance: "crc"})
+
+	metrics := scrape(t, collector)
+	assert.Contains(t, metrics, `crc_lifecycle_operation_duration_seconds_bucket{operation="start",le="60"} 0`)
+	assert.Contains(t, metrics, `crc_lifecycle_operation_duration_seconds_bucket{operation="start",le="120"} 1`)
+	assert.Contains(t, metrics, `crc_lifecycle_operation_duration_seconds_sum{operation="start"} 90`)
+	assert.NotContains(t, metrics, `operation="stop"`)
+}
+
+func TestOperationMetricsForgetFinishedOperations(t *testing.T) {
+	collector := NewCollector(machine.NewInstances(fakemachine.NewClient(), nil), nil)
+	defer collector.Close()
+	now := time.Now()
+	collector.now = func() time.Time {
+		return now
+	}
+
+	// the bundle phases are published without instance
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.BundleDownload})
+	now = now.Add(30 * time.Second)
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.VMStarting, Instance: "crc"})
+	now = now.Add(30 * time.Second)
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.Started, Instance: "crc"})
+
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.Stopping, Instance: "crc"})
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.Failed, Instance: "crc", Message: "stop failed"})
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.BundleExtraction})
+	lifecycle.Publish(lifecycle.Event{Phase: lifecycle.Failed, Instance: "crc", Message: "start failed"})
+	assert.Empty(t, collector.operationStarts)
+
+	metrics := scrape(t, collector)
+	assert.Contains(t, metrics, `crc_lifecycle_operation_duration_seconds_sum{operation="start"} 60`)
+	assert.Contains(t, metrics, `crc_lifecycle_operation_duration_seconds_count{operation="start"} 1`)
+	assert.NotContains(t, metrics, `operation="stop"`)
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\nd`, escapeLabelValue("a\"b\\c\nd"))
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// textWriter writes metrics in the Prometheus text exposition format
+// https://prometheus.io/docs/instrumenting/exposition_formats/
+type textWriter struct {
+	w   io.Writer
+	err error
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+func (t *textWriter) printf(format string, args ...interface{}) {
+	if t.err != nil {
+		return
+	}
+	_, t.err = fmt.Fprintf(t.w, format, args...)
+}
+
+func (t *textWriter) header(name, help, metricType string) {
+	t.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func (t *textWriter) sample(name string, labels []label, value float64) {
+	t.printf("%s%s %s\n", name, formatLabels(labels), formatValue(value))
+}
+
+func (t *textWriter) histogram(name string, labels []label, h *histogram) {
+	for i, upperBound := range h.buckets {
+		t.sample(name+"_bucket", append(labels[:len(labels):len(labels)], label{"le", formatValue(upperBound)}), float64(h.counts[i]))
+	}
+	t.sample(name+"_bucket", append(labels[:len(labels):len(labels)], label{"le", "+Inf"}), float64(h.count))
+	t.sample(name+"_sum", labels, h.sum)
+	t.sample(name+"_count", labels, float64(h.count))
+}
+
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, l := range labels {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, l.name, escapeLabelValue(l.value)))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatValue(value float64) string {
+	if math.IsInf(value, +1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// histogram counts observations in cumulative buckets, as expected by the
+// Prometheus histogram type
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+	return &histogram{
+		buckets: sorted,
+		counts:  make([]uint64, len(sorted)),
+	}
+}
+
+func (h *histogram) observe(value float64) {
+	for i, upperBound := range h.buckets {
+		if value <= upperBound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
@@ -0,0 +1,240 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+)
+
+var dnsLabelRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// DNSRecord resolves a hostname to an IPv4 address in the DNS server of the
+// user mode network. A hostname starting with '*.' resolves all the names
+// of its zone which have no record of their own.
+type DNSRecord struct {
+	Hostname string `json:"hostname"`
+	IP       string `json:"ip"`
+}
+
+// DNSServer is the part of the gvisor-tap-vsock API used to update the DNS
+// server of a running virtual network
+type DNSServer interface {
+	ListDNS() ([]types.Zone, error)
+	AddDNS(req *types.Zone) error
+}
+
+// ErrDNSRecordNotRemovable is returned when the running DNS server cannot
+// stop resolving a record, until it restarts
+var ErrDNSRecordNotRemovable = errors.New("the DNS server cannot forget the record of a zone without default IP")
+
+// DNS updates the DNS server of a running virtual network, zones are the
+// zones it was started with, without the records of the configuration
+type DNS struct {
+	server DNSServer
+	zones  []types.Zone
+}
+
+func NewDNS(server DNSServer, zones []types.Zone) *DNS {
+	return &DNS{
+		server: server,
+		zones:  zones,
+	}
+}
+
+// AddRecord adds record to the running DNS server
+func (d *DNS) AddRecord(record DNSRecord) error {
+	return AddDNSRecord(d.server, record)
+}
+
+// RemoveRecord makes the running DNS server stop resolving removed, records
+// are the records left in the configuration. The server can only add
+// records to a zone, so the zone of removed is rebuilt from the initial
+// zones and records and swapped in. Its records come first so they hide the
+// ones the server already has, and the names which are not in the rebuilt
+// zone anymore resolve to its default IP.
+func (d *DNS) RemoveRecord(removed DNSRecord, records []DNSRecord) error {
+	current, err := d.server.ListDNS()
+	if err != nil {
+		return err
+	}
+	_, zoneName := removed.split()
+	i := zoneIndex(current, zoneName)
+	if i < 0 {
+		return nil
+	}
+	zone := types.Zone{Name: zoneName}
+	rebuilt := AddDNSRecords(d.zones, records)
+	if j := zoneIndex(rebuilt, zoneName); j >= 0 {
+		zone = rebuilt[j]
+	}
+	var hidden []types.Record
+	for _, record := range current[i].Records {
+		if record.Regexp != nil || hasRecord(zone, record.Name) || hasRecord(types.Zone{Records: hidden}, record.Name) {
+			continue
+		}
+		if zone.DefaultIP == nil {
+			return ErrDNSRecordNotRemovable
+		}
+		hidden = append(hidden, types.Record{Name: record.Name, IP: zone.DefaultIP})
+	}
+	zone.Records = append(hidden, zone.Records...)
+	return d.server.AddDNS(&zone)
+}
+
+// ParseDNSRecord parses a record in the HOSTNAME=IP format
+func ParseDNSRecord(spec string) (DNSRecord, error) {
+	hostname, ip, ok := strings.Cut(strings.TrimSpace(spec), "=")
+	if !ok {
+		return DNSRecord{}, fmt.Errorf("invalid DNS record '%s', must be HOSTNAME=IP", spec)
+	}
+	return NewDNSRecord(hostname, ip)
+}
+
+// NewDNSRecord validates hostname and ip, the hostname must have at least
+// two labels, the first one is the name of the record and the others are the
+// name of its zone
+func NewDNSRecord(hostname, ip string) (DNSRecord, error) {
+	hostname = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(hostname), "."))
+	labels := strings.Split(hostname, ".")
+	if len(labels) < 2 {
+		return DNSRecord{}, fmt.Errorf("invalid hostname '%s', it must include a domain", hostname)
+	}
+	for i, label := range labels {
+		if i == 0 && label == "*" {
+			continue
+		}
+		if !dnsLabelRegexp.MatchString(label) {
+			return DNSRecord{}, fmt.Errorf("invalid hostname '%s'", hostname)
+		}
+	}
+	parsedIP := net.ParseIP(strings.TrimSpace(ip))
+	if parsedIP == nil || parsedIP.To4() == nil {
+		return DNSRecord{}, fmt.Errorf("invalid IPv4 address '%s' for %s", ip, hostname)
+	}
+	return DNSRecord{Hostname: hostname, IP: parsedIP.String()}, nil
+}
+
+// ParseDNSRecords parses a comma-separated list of DNS records
+func ParseDNSRecords(specs string) ([]DNSRecord, error) {
+	var records []DNSRecord
+	for _, spec := range strings.Split(specs, ",") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		record, err := ParseDNSRecord(spec)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// FormatDNSRecords is the inverse of ParseDNSRecords
+func FormatDNSRecords(records []DNSRecord) string {
+	specs := make([]string, 0, len(records))
+	for _, record := range records {
+		specs = append(specs, record.String())
+	}
+	return strings.Join(specs, ",")
+}
+
+func (r DNSRecord) String() string {
+	return fmt.Sprintf("%s=%s", r.Hostname, r.IP)
+}
+
+// IsWildcard returns true when the record resolves all the names of its zone
+func (r DNSRecord) IsWildcard() bool {
+	return strings.HasPrefix(r.Hostname, "*.")
+}
+
+func (r DNSRecord) split() (string, string) {
+	name, zone, _ := strings.Cut(r.Hostname, ".")
+	return name, zone + "."
+}
+
+// Zone returns the zone to add to the DNS server for this record
+func (r DNSRecord) Zone() types.Zone {
+	name, zone := r.split()
+	if r.IsWildcard() {
+		return types.Zone{Name: zone, DefaultIP: net.ParseIP(r.IP)}
+	}
+	return types.Zone{
+		Name:    zone,
+		Records: []types.Record{{Name: name, IP: net.ParseIP(r.IP)}},
+	}
+}
+
+// AddDNSRecords adds records to zones, the initial configuration of the DNS
+// server. Records of an existing zone take precedence over its records, and
+// the new zones are inserted first, the most specific ones before the others.
+func AddDNSRecords(zones []types.Zone, records []DNSRecord) []types.Zone {
+	zones = append([]types.Zone{}, zones...)
+	var newZones []types.Zone
+	for _, record := range records {
+		zone := record.Zone()
+		if i := zoneIndex(zones, zone.Name); i >= 0 {
+			zones[i] = mergeZone(zones[i], zone)
+			continue
+		}
+		if i := zoneIndex(newZones, zone.Name); i >= 0 {
+			newZones[i] = mergeZone(newZones[i], zone)
+			continue
+		}
+		newZones = append(newZones, zone)
+	}
+	sort.SliceStable(newZones, func(i, j int) bool {
+		return strings.Count(newZones[i].Name, ".") > strings.Count(newZones[j].Name, ".")
+	})
+	return append(newZones, zones...)
+}
+
+// AddDNSRecord adds record to the running DNS server
+func AddDNSRecord(server DNSServer, record DNSRecord) error {
+	zones, err := server.ListDNS()
+	if err != nil {
+		return err
+	}
+	zone := record.Zone()
+	// the server replaces the default IP of an existing zone with the one of
+	// the request, even when it is not set
+	if i := zoneIndex(zones, zone.Name); i >= 0 && zone.DefaultIP == nil {
+		zone.DefaultIP = zones[i].DefaultIP
+	}
+	return server.AddDNS(&zone)
+}
+
+func zoneIndex(zones []types.Zone, name string) int {
+	for i, zone := range zones {
+		if zone.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasRecord(zone types.Zone, name string) bool {
+	for _, record := range zone.Records {
+		if record.Name == name || (record.Regexp != nil && record.Regexp.MatchString(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeZone(zone, other types.Zone) types.Zone {
+	merged := types.Zone{
+		Name:      zone.Name,
+		Records:   append(append([]types.Record{}, other.Records...), zone.Records...),
+		DefaultIP: zone.DefaultIP,
+	}
+	if other.DefaultIP != nil {
+		merged.DefaultIP = other.DefaultIP
+	}
+	return merged
+}
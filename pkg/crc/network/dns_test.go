@@ -0,0 +1,170 @@
+package network
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/client"
+	"github.com/containers/gvisor-tap-vsock/pkg/services/dns"
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	miekgdns "github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDNSRecords(t *testing.T) {
+	records, err := ParseDNSRecords("Registry.corp.test.=192.168.127.254, *.apps.corp.test=192.168.127.2,")
+	require.NoError(t, err)
+	assert.Equal(t, []DNSRecord{
+		{Hostname: "registry.corp.test", IP: "192.168.127.254"},
+		{Hostname: "*.apps.corp.test", IP: "192.168.127.2"},
+	}, records)
+	assert.Equal(t, "registry.corp.test=192.168.127.254,*.apps.corp.test=192.168.127.2", FormatDNSRecords(records))
+
+	for _, spec := range []string{"registry.corp.test", "corp=192.168.127.2", "foo_bar.test=192.168.127.2", "a.*.test=192.168.127.2", "registry.corp.test=::1", "registry.corp.test=foo"} {
+		_, err := ParseDNSRecord(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestDNSRecordZone(t *testing.T) {
+	assert.Equal(t, types.Zone{
+		Name:    "corp.test.",
+		Records: []types.Record{{Name: "registry", IP: net.ParseIP("192.168.127.254")}},
+	}, DNSRecord{Hostname: "registry.corp.test", IP: "192.168.127.254"}.Zone())
+	assert.Equal(t, types.Zone{
+		Name:      "apps.corp.test.",
+		DefaultIP: net.ParseIP("192.168.127.2"),
+	}, DNSRecord{Hostname: "*.apps.corp.test", IP: "192.168.127.2"}.Zone())
+}
+
+func TestAddDNSRecords(t *testing.T) {
+	zones := []types.Zone{
+		{Name: "apps-crc.testing.", DefaultIP: net.ParseIP("192.168.127.2")},
+		{Name: "crc.testing.", Records: []types.Record{{Name: "host", IP: net.ParseIP("192.168.127.254")}}},
+	}
+	merged := AddDNSRecords(zones, []DNSRecord{
+		{Hostname: "registry.corp.test", IP: "192.168.127.254"},
+		{Hostname: "db.crc.testing", IP: "192.168.127.3"},
+		{Hostname: "*.apps.corp.test", IP: "192.168.127.2"},
+		{Hostname: "git.corp.test", IP: "192.168.127.4"},
+	})
+	assert.Equal(t, []types.Zone{
+		{Name: "apps.corp.test.", DefaultIP: net.ParseIP("192.168.127.2")},
+		{Name: "corp.test.", Records: []types.Record{
+			{Name: "git", IP: net.ParseIP("192.168.127.4")},
+			{Name: "registry", IP: net.ParseIP("192.168.127.254")},
+		}},
+		{Name: "apps-crc.testing.", DefaultIP: net.ParseIP("192.168.127.2")},
+		{Name: "crc.testing.", Records: []types.Record{
+			{Name: "db", IP: net.ParseIP("192.168.127.3")},
+			{Name: "host", IP: net.ParseIP("192.168.127.254")},
+		}},
+	}, merged)
+	// the initial zones are not modified
+	assert.Len(t, zones[1].Records, 1)
+}
+
+type fakeDNSServer struct {
+	zones []types.Zone
+	added []types.Zone
+}
+
+func (s *fakeDNSServer) ListDNS() ([]types.Zone, error) {
+	return s.zones, nil
+}
+
+func (s *fakeDNSServer) AddDNS(zone *types.Zone) error {
+	s.added = append(s.added, *zone)
+	return nil
+}
+
+func TestAddDNSRecord(t *testing.T) {
+	server := &fakeDNSServer{zones: []types.Zone{{Name: "apps-crc.testing.", DefaultIP: net.ParseIP("192.168.127.2")}}}
+	require.NoError(t, AddDNSRecord(server, DNSRecord{Hostname: "foo.apps-crc.testing", IP: "192.168.127.3"}))
+	require.NoError(t, AddDNSRecord(server, DNSRecord{Hostname: "registry.corp.test", IP: "192.168.127.254"}))
+	assert.Equal(t, []types.Zone{
+		{
+			Name:      "apps-crc.testing.",
+			Records:   []types.Record{{Name: "foo", IP: net.ParseIP("192.168.127.3")}},
+			DefaultIP: net.ParseIP("192.168.127.2"),
+		},
+		{
+			Name:    "corp.test.",
+			Records: []types.Record{{Name: "registry", IP: net.ParseIP("192.168.127.254")}},
+		},
+	}, server.added)
+}
+
+// startDNSServer runs the DNS server of gvisor-tap-vsock with zones, it
+// returns its address and a client of its HTTP API
+func startDNSServer(t *testing.T, zones []types.Zone) (string, DNSServer) {
+	udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	tcpLn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		udpConn.Close()
+		tcpLn.Close()
+	})
+	server, err := dns.New(udpConn, tcpLn, zones)
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve()
+	}()
+	mux := http.NewServeMux()
+	mux.Handle("/services/dns/", http.StripPrefix("/services/dns", server.Mux()))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return udpConn.LocalAddr().String(), client.New(http.DefaultClient, ts.URL)
+}
+
+// resolve returns the IP of hostname, or the name of the error code of the
+// response
+func resolve(t *testing.T, addr, hostname string) string {
+	msg := new(miekgdns.Msg)
+	msg.SetQuestion(miekgdns.Fqdn(hostname), miekgdns.TypeA)
+	resp, err := miekgdns.Exchange(msg, addr)
+	require.NoError(t, err)
+	if resp.Rcode != miekgdns.RcodeSuccess {
+		return miekgdns.RcodeToString[resp.Rcode]
+	}
+	require.Len(t, resp.Answer, 1)
+	return resp.Answer[0].(*miekgdns.A).A.String()
+}
+
+func TestDNSRemoveRecord(t *testing.T) {
+	zones := []types.Zone{
+		{Name: "apps-crc.testing.", DefaultIP: net.ParseIP("192.168.127.2")},
+		{Name: "crc.testing.", Records: []types.Record{{Name: "api", IP: net.ParseIP("192.168.127.2")}}},
+	}
+	foo := DNSRecord{Hostname: "foo.apps-crc.testing", IP: "192.168.127.3"}
+	corp := DNSRecord{Hostname: "*.corp.test", IP: "192.168.127.4"}
+	registry := DNSRecord{Hostname: "registry.corp.test", IP: "192.168.127.5"}
+	lab := DNSRecord{Hostname: "*.lab.test", IP: "192.168.127.6"}
+	www := DNSRecord{Hostname: "www.lab.test", IP: "192.168.127.7"}
+	db := DNSRecord{Hostname: "db.crc.testing", IP: "192.168.127.8"}
+	addr, server := startDNSServer(t, AddDNSRecords(zones, []DNSRecord{foo, corp, registry, lab, www, db}))
+	running := NewDNS(server, zones)
+	assert.Equal(t, "192.168.127.3", resolve(t, addr, "foo.apps-crc.testing"))
+
+	require.NoError(t, running.RemoveRecord(foo, []DNSRecord{corp, registry, lab, www, db}))
+	assert.Equal(t, "192.168.127.2", resolve(t, addr, "foo.apps-crc.testing"))
+
+	require.NoError(t, running.RemoveRecord(registry, []DNSRecord{corp, lab, www, db}))
+	assert.Equal(t, "192.168.127.4", resolve(t, addr, "registry.corp.test"))
+
+	require.NoError(t, running.RemoveRecord(lab, []DNSRecord{corp, www, db}))
+	assert.Equal(t, "NXDOMAIN", resolve(t, addr, "other.lab.test"))
+	assert.Equal(t, "192.168.127.7", resolve(t, addr, "www.lab.test"))
+
+	// the zone has no default IP for the name of the record
+	assert.ErrorIs(t, running.RemoveRecord(db, []DNSRecord{corp, www}), ErrDNSRecordNotRemovable)
+	assert.Equal(t, "192.168.127.2", resolve(t, addr, "api.crc.testing"))
+
+	// a removed record can be added again
+	require.NoError(t, running.AddRecord(foo))
+	assert.Equal(t, "192.168.127.3", resolve(t, addr, "foo.apps-crc.testing"))
+}
@@ -0,0 +1,87 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+)
+
+// MaxInstances is the number of instances which can get their own addresses
+const MaxInstances = 16
+
+// InstanceAddresses are the addresses allocated to an instance so that
+// several instances can run at the same time. The default instance has the
+// index 0 and keeps the historical addresses.
+type InstanceAddresses struct {
+	Index int `json:"index"`
+}
+
+// HasOwnVMAddress returns true when the virtual machine of the instance gets
+// its own address on the user mode network. Only vfkit lets the host choose
+// the MAC address of the virtual machine, with the other hypervisors the
+// guest always uses the default one and gets the default address.
+func (a InstanceAddresses) HasOwnVMAddress() bool {
+	return a.Index > 0 && runtime.GOOS == "darwin"
+}
+
+// VMIP returns the address of the virtual machine on the user mode network
+func (a InstanceAddresses) VMIP() string {
+	if !a.HasOwnVMAddress() {
+		return DefaultPortForwardGuestIP
+	}
+	ip := net.ParseIP(DefaultPortForwardGuestIP).To4()
+	ip[3] += byte(a.Index)
+	return ip.String()
+}
+
+// MACAddress returns the MAC address the user mode network leases VMIP to
+func (a InstanceAddresses) MACAddress() string {
+	if !a.HasOwnVMAddress() {
+		return constants.VsockMacAddress
+	}
+	return fmt.Sprintf("5a:94:ef:e4:0d:%02x", a.Index)
+}
+
+// SSHPort returns the port of the host forwarded to the SSH port of the
+// virtual machine on the user mode network
+func (a InstanceAddresses) SSHPort() int {
+	return constants.VsockSSHPort + a.Index
+}
+
+// APIPort returns the port of the host forwarded to the API server port of
+// the virtual machine on the user mode network
+func (a InstanceAddresses) APIPort() int {
+	return constants.OpenShiftAPIPort + a.Index
+}
+
+// IngressHTTPPort returns the port of the host forwarded to the HTTP ingress
+// port of the virtual machine, the default instance uses the configured one
+func (a InstanceAddresses) IngressHTTPPort(configured uint) uint {
+	if a.Index == 0 {
+		return configured
+	}
+	return 8080 + uint(a.Index)
+}
+
+// IngressHTTPSPort returns the port of the host forwarded to the HTTPS
+// ingress port of the virtual machine, the default instance uses the
+// configured one
+func (a InstanceAddresses) IngressHTTPSPort(configured uint) uint {
+	if a.Index == 0 {
+		return configured
+	}
+	return 8443 + uint(a.Index)
+}
+
+// UserNetworkLeases returns the static DHCP leases of the user mode network
+// for the virtual machines of all the instances
+func UserNetworkLeases() map[string]string {
+	leases := make(map[string]string, MaxInstances)
+	for i := 0; i < MaxInstances; i++ {
+		addresses := InstanceAddresses{Index: i}
+		leases[addresses.VMIP()] = addresses.MACAddress()
+	}
+	return leases
+}
@@ -0,0 +1,56 @@
+package network
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultInstanceAddresses(t *testing.T) {
+	addresses := InstanceAddresses{}
+	assert.False(t, addresses.HasOwnVMAddress())
+	assert.Equal(t, DefaultPortForwardGuestIP, addresses.VMIP())
+	assert.Equal(t, constants.VsockMacAddress, addresses.MACAddress())
+	assert.Equal(t, constants.VsockSSHPort, addresses.SSHPort())
+	assert.Equal(t, constants.OpenShiftAPIPort, addresses.APIPort())
+	assert.Equal(t, uint(9080), addresses.IngressHTTPPort(9080))
+	assert.Equal(t, uint(9443), addresses.IngressHTTPSPort(9443))
+}
+
+func TestInstanceAddresses(t *testing.T) {
+	addresses := InstanceAddresses{Index: 3}
+	assert.Equal(t, 2225, addresses.SSHPort())
+	assert.Equal(t, 6446, addresses.APIPort())
+	assert.Equal(t, uint(8083), addresses.IngressHTTPPort(80))
+	assert.Equal(t, uint(8446), addresses.IngressHTTPSPort(443))
+	if runtime.GOOS == "darwin" {
+		assert.True(t, addresses.HasOwnVMAddress())
+		assert.Equal(t, "192.168.127.5", addresses.VMIP())
+		assert.Equal(t, "5a:94:ef:e4:0d:03", addresses.MACAddress())
+	} else {
+		assert.False(t, addresses.HasOwnVMAddress())
+		assert.Equal(t, DefaultPortForwardGuestIP, addresses.VMIP())
+		assert.Equal(t, constants.VsockMacAddress, addresses.MACAddress())
+	}
+}
+
+func TestInstanceAddressesDoNotOverlap(t *testing.T) {
+	ports := map[uint]int{}
+	for i := 0; i < MaxInstances; i++ {
+		addresses := InstanceAddresses{Index: i}
+		for _, port := range []uint{uint(addresses.SSHPort()), uint(addresses.APIPort()), addresses.IngressHTTPPort(80), addresses.IngressHTTPSPort(443)} {
+			other, used := ports[port]
+			assert.False(t, used, "port %d of instance %d is used by instance %d", port, i, other)
+			ports[port] = i
+		}
+	}
+	leases := UserNetworkLeases()
+	assert.Equal(t, constants.VsockMacAddress, leases[DefaultPortForwardGuestIP])
+	macs := map[string]bool{}
+	for _, mac := range leases {
+		assert.False(t, macs[mac], "MAC address %s is leased twice", mac)
+		macs[mac] = true
+	}
+}
@@ -0,0 +1,125 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+)
+
+// DefaultPortForwardGuestIP is the address of the virtual machine on the
+// user mode network, forwards target it when no guest address is given
+const DefaultPortForwardGuestIP = "192.168.127.2"
+
+// PortForward forwards a port of the host to a port of the virtual machine,
+// or of any other address reachable from the user mode network
+type PortForward struct {
+	Protocol  types.TransportProtocol `json:"protocol"`
+	HostPort  uint16                  `json:"hostPort"`
+	GuestIP   string                  `json:"guestIP"`
+	GuestPort uint16                  `json:"guestPort"`
+}
+
+// ParsePortForward parses a forward in the HOSTPORT:[GUESTIP:]GUESTPORT[/PROTOCOL]
+// format, PROTOCOL is tcp or udp and defaults to tcp
+func ParsePortForward(spec string) (PortForward, error) {
+	forward := PortForward{
+		Protocol: types.TCP,
+		GuestIP:  DefaultPortForwardGuestIP,
+	}
+	ports, protocol, hasProtocol := strings.Cut(strings.TrimSpace(spec), "/")
+	if hasProtocol {
+		switch types.TransportProtocol(protocol) {
+		case types.TCP, types.UDP:
+			forward.Protocol = types.TransportProtocol(protocol)
+		default:
+			return PortForward{}, fmt.Errorf("invalid protocol '%s' in port forward '%s', must be tcp or udp", protocol, spec)
+		}
+	}
+
+	parts := strings.Split(ports, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return PortForward{}, fmt.Errorf("invalid port forward '%s', must be HOSTPORT:[GUESTIP:]GUESTPORT[/tcp|udp]", spec)
+	}
+	var err error
+	if forward.HostPort, err = parsePort(parts[0]); err != nil {
+		return PortForward{}, fmt.Errorf("invalid host port in port forward '%s': %w", spec, err)
+	}
+	if len(parts) == 3 {
+		ip := net.ParseIP(parts[1])
+		if ip == nil || ip.To4() == nil {
+			return PortForward{}, fmt.Errorf("invalid guest IPv4 address '%s' in port forward '%s'", parts[1], spec)
+		}
+		forward.GuestIP = ip.String()
+	}
+	if forward.GuestPort, err = parsePort(parts[len(parts)-1]); err != nil {
+		return PortForward{}, fmt.Errorf("invalid guest port in port forward '%s': %w", spec, err)
+	}
+	return forward, nil
+}
+
+// ParsePortForwards parses a comma-separated list of port forwards
+func ParsePortForwards(specs string) ([]PortForward, error) {
+	var forwards []PortForward
+	for _, spec := range strings.Split(specs, ",") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		forward, err := ParsePortForward(spec)
+		if err != nil {
+			return nil, err
+		}
+		forwards = append(forwards, forward)
+	}
+	return forwards, nil
+}
+
+// FormatPortForwards is the inverse of ParsePortForwards
+func FormatPortForwards(forwards []PortForward) string {
+	specs := make([]string, 0, len(forwards))
+	for _, forward := range forwards {
+		specs = append(specs, forward.String())
+	}
+	return strings.Join(specs, ",")
+}
+
+func parsePort(port string) (uint16, error) {
+	value, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || value == 0 {
+		return 0, fmt.Errorf("'%s' is not a valid port", port)
+	}
+	return uint16(value), nil
+}
+
+func (f PortForward) String() string {
+	return fmt.Sprintf("%d:%s:%d/%s", f.HostPort, f.GuestIP, f.GuestPort, f.Protocol)
+}
+
+// SameHostPort returns true when both forwards listen on the same host port
+func (f PortForward) SameHostPort(other PortForward) bool {
+	return f.Protocol == other.Protocol && f.HostPort == other.HostPort
+}
+
+func (f PortForward) local() string {
+	return net.JoinHostPort(constants.LocalIP, strconv.Itoa(int(f.HostPort)))
+}
+
+// ExposeRequest is the request sent to the virtual network to start forwarding
+func (f PortForward) ExposeRequest() *types.ExposeRequest {
+	return &types.ExposeRequest{
+		Protocol: f.Protocol,
+		Local:    f.local(),
+		Remote:   net.JoinHostPort(f.GuestIP, strconv.Itoa(int(f.GuestPort))),
+	}
+}
+
+// UnexposeRequest is the request sent to the virtual network to stop forwarding
+func (f PortForward) UnexposeRequest() *types.UnexposeRequest {
+	return &types.UnexposeRequest{
+		Protocol: f.Protocol,
+		Local:    f.local(),
+	}
+}
@@ -0,0 +1,41 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePortForward(t *testing.T) {
+	forward, err := ParsePortForward("8080:80")
+	require.NoError(t, err)
+	assert.Equal(t, PortForward{Protocol: types.TCP, HostPort: 8080, GuestIP: DefaultPortForwardGuestIP, GuestPort: 80}, forward)
+	assert.Equal(t, "8080:192.168.127.2:80/tcp", forward.String())
+	assert.Equal(t, &types.ExposeRequest{Protocol: types.TCP, Local: "127.0.0.1:8080", Remote: "192.168.127.2:80"}, forward.ExposeRequest())
+	assert.Equal(t, &types.UnexposeRequest{Protocol: types.TCP, Local: "127.0.0.1:8080"}, forward.UnexposeRequest())
+
+	forward, err = ParsePortForward("5353:192.168.127.3:53/udp")
+	require.NoError(t, err)
+	assert.Equal(t, PortForward{Protocol: types.UDP, HostPort: 5353, GuestIP: "192.168.127.3", GuestPort: 53}, forward)
+
+	for _, spec := range []string{"8080", "8080:80/sctp", "0:80", "8080:70000", "8080:not-an-ip:80", "8080:::1:80", "a:b:c:d"} {
+		_, err := ParsePortForward(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestParsePortForwards(t *testing.T) {
+	forwards, err := ParsePortForwards("8080:80, 5353:192.168.127.3:53/udp,")
+	require.NoError(t, err)
+	assert.Len(t, forwards, 2)
+	assert.Equal(t, "8080:192.168.127.2:80/tcp,5353:192.168.127.3:53/udp", FormatPortForwards(forwards))
+
+	forwards, err = ParsePortForwards("")
+	require.NoError(t, err)
+	assert.Empty(t, forwards)
+
+	_, err = ParsePortForwards("8080:80,foo")
+	assert.EqualError(t, err, "invalid port forward 'foo', must be HOSTPORT:[GUESTIP:]GUESTPORT[/tcp|udp]")
+}
@@ -6,6 +6,7 @@ import (
 	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
 	"github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
 )
 
 type Flags uint32
@@ -16,6 +17,8 @@ const (
 	NoFix
 	CleanUpOnly
 	StartUpOnly
+	// Indicates the fix of a PreflightCheck needs administrator privileges
+	RequiresPrivileges
 )
 
 type CheckFunc func() error
@@ -157,8 +160,10 @@ func getPreflightChecksHelper(config crcConfig.Storage) []Check {
 	bundlePath := config.Get(crcConfig.Bundle).AsString()
 	preset := crcConfig.GetPreset(config)
 	enableBundleQuayFallback := config.Get(crcConfig.EnableBundleQuayFallback).AsBool()
+	mirrors := crcConfig.GetBundleMirrors(config)
+	signaturePolicy := bundle.NewSignaturePolicy(config.Get(crcConfig.EnforceBundleSignature).AsBool(), crcConfig.GetTrustedBundleKeys(config), mirrors)
 	logging.Infof("Using bundle path %s", bundlePath)
-	return getPreflightChecks(experimentalFeatures, mode, bundlePath, preset, enableBundleQuayFallback)
+	return getPreflightChecks(experimentalFeatures, mode, bundlePath, preset, enableBundleQuayFallback, mirrors, signaturePolicy)
 }
 
 // StartPreflightChecks performs the preflight checks before starting the cluster
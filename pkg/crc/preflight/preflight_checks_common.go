@@ -21,13 +21,13 @@ import (
 	"github.com/pkg/errors"
 )
 
-func bundleCheck(bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool) Check {
+func bundleCheck(bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool, mirrors []string, signaturePolicy *bundle.SignaturePolicy) Check {
 	return Check{
 		configKeySuffix:  "check-bundle-extracted",
 		checkDescription: "Checking if CRC bundle is extracted in '$HOME/.crc'",
 		check:            checkBundleExtracted(bundlePath),
 		fixDescription:   "Getting bundle for the CRC executable",
-		fix:              fixBundleExtracted(bundlePath, preset, enableBundleQuayFallback),
+		fix:              fixBundleExtracted(bundlePath, preset, enableBundleQuayFallback, mirrors, signaturePolicy),
 		flags:            SetupOnly,
 
 		labels: None,
@@ -109,7 +109,7 @@ func checkBundleExtracted(bundlePath string) func() error {
 	}
 }
 
-func fixBundleExtracted(bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool) func() error {
+func fixBundleExtracted(bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool, mirrors []string, signaturePolicy *bundle.SignaturePolicy) func() error {
 	// Should be removed after 1.19 release
 	// This check will ensure correct mode for `~/.crc/cache` directory
 	// in case it exists.
@@ -125,12 +125,12 @@ func fixBundleExtracted(bundlePath string, preset crcpreset.Preset, enableBundle
 		}
 		var err error
 		logging.Infof("Downloading bundle: %s...", bundlePath)
-		if bundlePath, err = bundle.Download(context.Background(), preset, bundlePath, enableBundleQuayFallback); err != nil {
+		if bundlePath, err = bundle.Download(context.Background(), preset, bundlePath, enableBundleQuayFallback, mirrors, signaturePolicy); err != nil {
 			return err
 		}
 
 		logging.Infof("Uncompressing %s", bundlePath)
-		if _, err := bundle.Extract(context.Background(), bundlePath); err != nil {
+		if _, err := bundle.Extract(context.Background(), bundlePath, signaturePolicy); err != nil {
 			if errors.Is(err, os.ErrNotExist) {
 				return errors.Wrap(err, "Use `crc setup -b <bundle-path>`")
 			}
@@ -170,5 +170,5 @@ func removeCrcManPages() error {
 }
 
 func removeCRCHostEntriesFromKnownHosts() error {
-	return ssh.RemoveCRCHostEntriesFromKnownHosts()
+	return ssh.RemoveCRCHostEntriesFromKnownHosts(constants.VsockSSHPort)
 }
@@ -52,6 +52,7 @@ var dnsmasqPreflightChecks = []Check{
 		check:              checkCrcNetworkManagerConfig,
 		fixDescription:     "Writing Network Manager config for crc",
 		fix:                fixCrcNetworkManagerConfig,
+		flags:              RequiresPrivileges,
 		cleanupDescription: "Removing /etc/NetworkManager/conf.d/crc-nm-dnsmasq.conf file",
 		cleanup:            removeCrcNetworkManagerConfig,
 
@@ -63,6 +64,7 @@ var dnsmasqPreflightChecks = []Check{
 		check:              checkCrcDnsmasqConfigFile,
 		fixDescription:     "Writing dnsmasq config for crc",
 		fix:                fixCrcDnsmasqConfigFile,
+		flags:              RequiresPrivileges,
 		cleanupDescription: "Removing /etc/NetworkManager/dnsmasq.d/crc.conf file",
 		cleanup:            removeCrcDnsmasqConfigFile,
 
@@ -118,6 +120,7 @@ var systemdResolvedPreflightChecks = []Check{
 		check:            checkCrcDnsmasqAndNetworkManagerConfigFile,
 		fixDescription:   "Removing dnsmasq configuration file for NetworkManager",
 		fix:              fixCrcDnsmasqAndNetworkManagerConfigFile,
+		flags:            RequiresPrivileges,
 
 		labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
 	},
@@ -136,6 +139,7 @@ var systemdResolvedPreflightChecks = []Check{
 		check:              checkCrcNetworkManagerDispatcherFile,
 		fixDescription:     "Writing NetworkManager dispatcher file for crc",
 		fix:                fixCrcNetworkManagerDispatcherFile,
+		flags:              RequiresPrivileges,
 		cleanupDescription: fmt.Sprintf("Removing %s file", crcNetworkManagerDispatcherPath),
 		cleanup:            removeCrcNetworkManagerDispatcherFile,
 
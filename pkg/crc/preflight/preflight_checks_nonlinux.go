@@ -91,7 +91,7 @@ func checkSSHPortFree() func() error {
 
 		host := net.JoinHostPort(constants.LocalIP, strconv.Itoa(constants.VsockSSHPort))
 
-		daemonClient := daemonclient.New()
+		daemonClient := daemonclient.NewLocal()
 		exposed, err := daemonClient.NetworkClient.List()
 		if err == nil {
 			// if port already exported by vsock we could proceed
@@ -39,6 +39,7 @@ func genericPreflightChecks(_ crcpreset.Preset) []Check {
 			check:            checkAdminHelperExecutableCached,
 			fixDescription:   "Caching crc-admin-helper executable",
 			fix:              fixAdminHelperExecutableCached,
+			flags:            RequiresPrivileges,
 
 			labels: None,
 		},
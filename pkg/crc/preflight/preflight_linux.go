@@ -9,6 +9,7 @@ import (
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	crcErrors "github.com/crc-org/crc/v2/pkg/crc/errors"
 	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
 	"github.com/crc-org/crc/v2/pkg/crc/network"
 	crcpreset "github.com/crc-org/crc/v2/pkg/crc/preset"
 	crcos "github.com/crc-org/crc/v2/pkg/os"
@@ -34,6 +35,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			check:            checkKvmEnabled,
 			fixDescription:   "Setting up KVM",
 			fix:              fixKvmEnabled,
+			flags:            RequiresPrivileges,
 
 			labels: labels{Os: Linux},
 		},
@@ -43,6 +45,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			check:            checkLibvirtInstalled,
 			fixDescription:   "Installing libvirt service and dependencies",
 			fix:              fixLibvirtInstalled(distro),
+			flags:            RequiresPrivileges,
 
 			labels: labels{Os: Linux},
 		},
@@ -52,6 +55,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			check:            checkUserPartOfLibvirtGroup,
 			fixDescription:   "Adding user to libvirt group",
 			fix:              fixUserPartOfLibvirtGroup,
+			flags:            RequiresPrivileges,
 
 			labels: labels{Os: Linux},
 		},
@@ -70,6 +74,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			check:            checkLibvirtServiceRunning,
 			fixDescription:   "Starting libvirt service",
 			fix:              fixLibvirtServiceRunning,
+			flags:            RequiresPrivileges,
 
 			labels: labels{Os: Linux},
 		},
@@ -167,6 +172,7 @@ var vsockPreflightCheck = Check{
 	check:              checkVsock,
 	fixDescription:     "Setting up vsock support",
 	fix:                fixVsock,
+	flags:              RequiresPrivileges,
 	cleanupDescription: "Removing vsock configuration",
 	cleanup:            removeVsockCrcSettings,
 
@@ -342,26 +348,26 @@ func getAllPreflightChecks() []Check {
 	filter.SetDistro(distro())
 	filter.SetSystemdUser(distro())
 
-	return filter.Apply(getChecks(distro(), constants.GetDefaultBundlePath(crcpreset.OpenShift), crcpreset.OpenShift, false))
+	return filter.Apply(getChecks(distro(), constants.GetDefaultBundlePath(crcpreset.OpenShift), crcpreset.OpenShift, false, nil, nil))
 }
 
-func getPreflightChecks(_ bool, networkMode network.Mode, bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool) []Check {
+func getPreflightChecks(_ bool, networkMode network.Mode, bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool, mirrors []string, signaturePolicy *bundle.SignaturePolicy) []Check {
 	usingSystemdResolved := checkSystemdResolvedIsRunning()
 
-	return getPreflightChecksForDistro(distro(), networkMode, usingSystemdResolved == nil, bundlePath, preset, enableBundleQuayFallback)
+	return getPreflightChecksForDistro(distro(), networkMode, usingSystemdResolved == nil, bundlePath, preset, enableBundleQuayFallback, mirrors, signaturePolicy)
 }
 
-func getPreflightChecksForDistro(distro *linux.OsRelease, networkMode network.Mode, usingSystemdResolved bool, bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool) []Check {
+func getPreflightChecksForDistro(distro *linux.OsRelease, networkMode network.Mode, usingSystemdResolved bool, bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool, mirrors []string, signaturePolicy *bundle.SignaturePolicy) []Check {
 	filter := newFilter()
 	filter.SetDistro(distro)
 	filter.SetSystemdUser(distro)
 	filter.SetNetworkMode(networkMode)
 	filter.SetSystemdResolved(usingSystemdResolved)
 
-	return filter.Apply(getChecks(distro, bundlePath, preset, enableBundleQuayFallback))
+	return filter.Apply(getChecks(distro, bundlePath, preset, enableBundleQuayFallback, mirrors, signaturePolicy))
 }
 
-func getChecks(distro *linux.OsRelease, bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool) []Check {
+func getChecks(distro *linux.OsRelease, bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool, mirrors []string, signaturePolicy *bundle.SignaturePolicy) []Check {
 	var checks []Check
 	checks = append(checks, nonWinPreflightChecks...)
 	checks = append(checks, wsl2PreflightCheck)
@@ -375,7 +381,7 @@ func getChecks(distro *linux.OsRelease, bundlePath string, preset crcpreset.Pres
 	checks = append(checks, dnsmasqPreflightChecks...)
 	checks = append(checks, libvirtNetworkPreflightChecks...)
 	checks = append(checks, vsockPreflightCheck)
-	checks = append(checks, bundleCheck(bundlePath, preset, enableBundleQuayFallback))
+	checks = append(checks, bundleCheck(bundlePath, preset, enableBundleQuayFallback, mirrors, signaturePolicy))
 
 	return checks
 }
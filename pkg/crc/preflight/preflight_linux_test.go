@@ -521,7 +521,7 @@ func assertFuncEqual(t *testing.T, func1 interface{}, func2 interface{}) {
 }
 
 func assertExpectedPreflights(t *testing.T, distro *crcos.OsRelease, networkMode network.Mode, systemdResolved bool) {
-	preflights := getPreflightChecksForDistro(distro, networkMode, systemdResolved, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false)
+	preflights := getPreflightChecksForDistro(distro, networkMode, systemdResolved, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, nil, nil)
 	var expected checkListForDistro
 	for _, expected = range checkListForDistros {
 		if expected.distro == distro && expected.networkMode == networkMode && expected.systemdResolved == systemdResolved {
@@ -565,3 +565,13 @@ func TestCountPreflights(t *testing.T) {
 	assertExpectedPreflights(t, &ubuntu, network.SystemNetworkingMode, false)
 	assertExpectedPreflights(t, &ubuntu, network.UserNetworkingMode, false)
 }
+
+func TestAdminHelperCachedFixRequiresPrivileges(t *testing.T) {
+	for _, check := range genericPreflightChecks(preset.OpenShift) {
+		if check.configKeySuffix == "check-admin-helper-cached" {
+			assert.Equal(t, RequiresPrivileges, check.flags&RequiresPrivileges)
+			return
+		}
+	}
+	t.Fatal("check-admin-helper-cached is missing")
+}
@@ -20,6 +20,7 @@ var ubuntuPreflightChecks = []Check{
 		check:              checkAppArmorExceptionIsPresent(os.ReadFile),
 		fixDescription:     "Updating AppArmor configuration",
 		fix:                addAppArmorExceptionForQcowDisks(os.ReadFile, crcos.WriteToFileAsRoot),
+		flags:              RequiresPrivileges,
 		cleanupDescription: "Cleaning up AppArmor configuration",
 		cleanup:            removeAppArmorExceptionForQcowDisks(os.ReadFile, crcos.WriteToFileAsRoot),
 
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
 	"github.com/crc-org/crc/v2/pkg/crc/network"
 	crcpreset "github.com/crc-org/crc/v2/pkg/crc/preset"
 	"github.com/crc-org/crc/v2/pkg/os/windows/powershell"
@@ -162,6 +163,7 @@ var userPartOfCrcUsersAndHypervAdminsGroupCheck = Check{
 	check:            checkUserPartOfCrcUsersAndHypervAdminsGroup,
 	fixDescription:   "Adding logon user to crc-users and Hyper-V admins group",
 	fix:              fixUserPartOfCrcUsersAndHypervAdminsGroup,
+	flags:            RequiresPrivileges,
 
 	labels: labels{Os: Windows},
 }
@@ -212,17 +214,17 @@ func checkVsock() error {
 // Passing 'UserNetworkingMode' to getPreflightChecks currently achieves this
 // as there are no system networking specific checks
 func getAllPreflightChecks() []Check {
-	return getPreflightChecks(true, network.UserNetworkingMode, constants.GetDefaultBundlePath(crcpreset.OpenShift), crcpreset.OpenShift, false)
+	return getPreflightChecks(true, network.UserNetworkingMode, constants.GetDefaultBundlePath(crcpreset.OpenShift), crcpreset.OpenShift, false, nil, nil)
 }
 
-func getChecks(bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool) []Check {
+func getChecks(bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool, mirrors []string, signaturePolicy *bundle.SignaturePolicy) []Check {
 	checks := []Check{}
 	checks = append(checks, memoryCheck(preset))
 	checks = append(checks, hypervPreflightChecks...)
 	checks = append(checks, crcUsersGroupExistsCheck)
 	checks = append(checks, userPartOfCrcUsersAndHypervAdminsGroupCheck)
 	checks = append(checks, vsockChecks...)
-	checks = append(checks, bundleCheck(bundlePath, preset, enableBundleQuayFallback))
+	checks = append(checks, bundleCheck(bundlePath, preset, enableBundleQuayFallback, mirrors, signaturePolicy))
 	checks = append(checks, genericCleanupChecks...)
 	checks = append(checks, cleanupCheckRemoveCrcVM)
 	checks = append(checks, daemonTaskChecks...)
@@ -231,9 +233,9 @@ func getChecks(bundlePath string, preset crcpreset.Preset, enableBundleQuayFallb
 	return checks
 }
 
-func getPreflightChecks(_ bool, networkMode network.Mode, bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool) []Check {
+func getPreflightChecks(_ bool, networkMode network.Mode, bundlePath string, preset crcpreset.Preset, enableBundleQuayFallback bool, mirrors []string, signaturePolicy *bundle.SignaturePolicy) []Check {
 	filter := newFilter()
 	filter.SetNetworkMode(networkMode)
 
-	return filter.Apply(getChecks(bundlePath, preset, enableBundleQuayFallback))
+	return filter.Apply(getChecks(bundlePath, preset, enableBundleQuayFallback, mirrors, signaturePolicy))
 }
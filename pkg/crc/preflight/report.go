@@ -0,0 +1,89 @@
+package preflight
+
+import (
+	crcConfig "github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+)
+
+type CheckStatus string
+
+const (
+	CheckPassed  CheckStatus = "passed"
+	CheckFailed  CheckStatus = "failed"
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// CheckResult is the outcome of a single preflight check
+type CheckResult struct {
+	Name                  string      `json:"name"`
+	Description           string      `json:"description"`
+	Status                CheckStatus `json:"status"`
+	Error                 string      `json:"error,omitempty"`
+	FixDescription        string      `json:"fixDescription,omitempty"`
+	Fixable               bool        `json:"fixable"`
+	FixRequiresPrivileges bool        `json:"fixRequiresPrivileges"`
+	SkipSetting           string      `json:"skipSetting,omitempty"`
+}
+
+// Report is the outcome of all the preflight checks applicable to the host
+type Report struct {
+	Success bool          `json:"success"`
+	Checks  []CheckResult `json:"checks"`
+}
+
+// Failed returns the checks which did not pass
+func (r *Report) Failed() []CheckResult {
+	var failed []CheckResult
+	for _, check := range r.Checks {
+		if check.Status == CheckFailed {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+func (check *Check) report(config crcConfig.Storage) CheckResult {
+	result := CheckResult{
+		Name:                  check.configKeySuffix,
+		Description:           check.checkDescription,
+		Status:                CheckPassed,
+		FixDescription:        check.fixDescription,
+		Fixable:               check.fix != nil && check.flags&NoFix == 0,
+		FixRequiresPrivileges: check.flags&RequiresPrivileges == RequiresPrivileges,
+		SkipSetting:           check.getSkipConfigName(),
+	}
+	if check.shouldSkip(config) {
+		result.Status = CheckSkipped
+		return result
+	}
+	if err := check.check(); err != nil {
+		logging.Debugf("%s: %v", check.checkDescription, err)
+		result.Status = CheckFailed
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func doReportPreflightChecks(config crcConfig.Storage, checks []Check) *Report {
+	report := &Report{
+		Success: true,
+		Checks:  []CheckResult{},
+	}
+	for _, check := range checks {
+		if check.flags&CleanUpOnly == CleanUpOnly {
+			continue
+		}
+		result := check.report(config)
+		if result.Status == CheckFailed {
+			report.Success = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+// ReportHost runs all the setup and start preflight checks applicable to the
+// host, without fixing anything and without stopping at the first failure
+func ReportHost(config crcConfig.Storage) *Report {
+	return doReportPreflightChecks(config, getPreflightChecksHelper(config))
+}
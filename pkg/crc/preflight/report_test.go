@@ -0,0 +1,46 @@
+package preflight
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/crc-org/crc/v2/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportPreflight(t *testing.T) {
+	passing, passingCalls := sampleCheck(nil, nil)
+	failing, failingCalls := sampleCheck(errors.New("check failed"), nil)
+	failing.configKeySuffix = "failing"
+	failing.flags = RequiresPrivileges
+	skipped, skippedCalls := sampleCheck(errors.New("check failed"), nil)
+	skipped.configKeySuffix = "skipped"
+	noFix, _ := sampleCheck(errors.New("not enough memory"), nil)
+	noFix.configKeySuffix = "no-fix"
+	noFix.flags = NoFix
+	cleanup, cleanupCalls := sampleCheck(nil, nil)
+	cleanup.configKeySuffix = ""
+	cleanup.flags = CleanUpOnly
+	checks := []Check{*passing, *failing, *skipped, *noFix, *cleanup}
+
+	cfg := config.New(config.NewEmptyInMemoryStorage(), config.NewEmptyInMemorySecretStorage())
+	doRegisterSettings(cfg, checks)
+	_, err := cfg.Set("skip-skipped", true)
+	assert.NoError(t, err)
+
+	report := doReportPreflightChecks(cfg, checks)
+	assert.False(t, report.Success)
+	assert.Equal(t, []CheckResult{
+		{Name: "sample", Description: "Sample check", Status: CheckPassed, FixDescription: "sample fix", Fixable: true, SkipSetting: "skip-sample"},
+		{Name: "failing", Description: "Sample check", Status: CheckFailed, Error: "check failed", FixDescription: "sample fix", Fixable: true, FixRequiresPrivileges: true, SkipSetting: "skip-failing"},
+		{Name: "skipped", Description: "Sample check", Status: CheckSkipped, FixDescription: "sample fix", Fixable: true, SkipSetting: "skip-skipped"},
+		{Name: "no-fix", Description: "Sample check", Status: CheckFailed, Error: "not enough memory", FixDescription: "sample fix", SkipSetting: "skip-no-fix"},
+	}, report.Checks)
+	assert.Len(t, report.Failed(), 2)
+
+	assert.True(t, passingCalls.checked)
+	assert.True(t, failingCalls.checked)
+	assert.False(t, failingCalls.fixed)
+	assert.False(t, skippedCalls.checked)
+	assert.False(t, cleanupCalls.checked)
+}
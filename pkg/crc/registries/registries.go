@@ -0,0 +1,148 @@
+package registries
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// a registry host with an optional port and an optional repository path,
+// the host of insecure and blocked registries may start with '*.'
+var (
+	registryRegexp         = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?(:[0-9]+)?(/[a-z0-9._/-]*[a-z0-9])?$`)
+	wildcardRegistryRegexp = regexp.MustCompile(`^(\*\.)?[a-z0-9]([a-z0-9.-]*[a-z0-9])?(:[0-9]+)?(/[a-z0-9._/-]*[a-z0-9])?$`)
+)
+
+// Mirror makes image pulls from Source try Mirror first
+type Mirror struct {
+	Source string `json:"source"`
+	Mirror string `json:"mirror"`
+}
+
+// Config is the container registries configuration of the cluster
+type Config struct {
+	Mirrors  []Mirror `json:"mirrors,omitempty"`
+	Insecure []string `json:"insecure,omitempty"`
+	Blocked  []string `json:"blocked,omitempty"`
+}
+
+// IsEmpty returns true when the configuration does not change the defaults
+// of the cluster
+func (c Config) IsEmpty() bool {
+	return len(c.Mirrors) == 0 && len(c.Insecure) == 0 && len(c.Blocked) == 0
+}
+
+// ParseMirror parses a mirror in the SOURCE=MIRROR format
+func ParseMirror(spec string) (Mirror, error) {
+	source, mirror, ok := strings.Cut(strings.TrimSpace(spec), "=")
+	if !ok {
+		return Mirror{}, fmt.Errorf("invalid registry mirror '%s', must be SOURCE=MIRROR", spec)
+	}
+	source = strings.ToLower(strings.TrimSpace(source))
+	mirror = strings.ToLower(strings.TrimSpace(mirror))
+	for _, registry := range []string{source, mirror} {
+		if !registryRegexp.MatchString(registry) {
+			return Mirror{}, fmt.Errorf("invalid registry '%s' in registry mirror '%s'", registry, spec)
+		}
+	}
+	if source == mirror {
+		return Mirror{}, fmt.Errorf("registry '%s' cannot be its own mirror", source)
+	}
+	return Mirror{Source: source, Mirror: mirror}, nil
+}
+
+// ParseMirrors parses a comma-separated list of mirrors, the mirrors of a
+// source are tried in the order of the list
+func ParseMirrors(specs string) ([]Mirror, error) {
+	var mirrors []Mirror
+	for _, spec := range strings.Split(specs, ",") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		mirror, err := ParseMirror(spec)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, mirror)
+	}
+	return mirrors, nil
+}
+
+// ParseRegistries parses a comma-separated list of registries such as
+// 'registry.corp.test:5000' or '*.corp.test'
+func ParseRegistries(specs string) ([]string, error) {
+	var registries []string
+	for _, registry := range strings.Split(specs, ",") {
+		registry = strings.ToLower(strings.TrimSpace(registry))
+		if registry == "" {
+			continue
+		}
+		if !wildcardRegistryRegexp.MatchString(registry) {
+			return nil, fmt.Errorf("invalid registry '%s'", registry)
+		}
+		registries = append(registries, registry)
+	}
+	return registries, nil
+}
+
+func (m Mirror) String() string {
+	return fmt.Sprintf("%s=%s", m.Source, m.Mirror)
+}
+
+// MirrorsBySource groups the mirrors by source, sorted by source
+func (c Config) MirrorsBySource() ([]string, map[string][]string) {
+	bySource := make(map[string][]string)
+	var sources []string
+	for _, mirror := range c.Mirrors {
+		if _, ok := bySource[mirror.Source]; !ok {
+			sources = append(sources, mirror.Source)
+		}
+		bySource[mirror.Source] = append(bySource[mirror.Source], mirror.Mirror)
+	}
+	sort.Strings(sources)
+	return sources, bySource
+}
+
+// RegistriesConf returns the containers-registries.conf(5) drop-in file
+// applying the configuration
+func (c Config) RegistriesConf() string {
+	sources, mirrors := c.MirrorsBySource()
+	insecure := toSet(c.Insecure)
+	blocked := toSet(c.Blocked)
+
+	prefixes := toSet(sources)
+	for prefix := range insecure {
+		prefixes[prefix] = true
+	}
+	for prefix := range blocked {
+		prefixes[prefix] = true
+	}
+	sorted := make([]string, 0, len(prefixes))
+	for prefix := range prefixes {
+		sorted = append(sorted, prefix)
+	}
+	sort.Strings(sorted)
+
+	var conf strings.Builder
+	conf.WriteString("# Generated by crc from the registry-mirrors, insecure-registries and blocked-registries settings\n")
+	for _, prefix := range sorted {
+		fmt.Fprintf(&conf, "\n[[registry]]\nprefix = %q\n", prefix)
+		if !strings.HasPrefix(prefix, "*.") {
+			fmt.Fprintf(&conf, "location = %q\n", prefix)
+		}
+		fmt.Fprintf(&conf, "insecure = %t\nblocked = %t\n", insecure[prefix], blocked[prefix])
+		for _, mirror := range mirrors[prefix] {
+			fmt.Fprintf(&conf, "\n[[registry.mirror]]\nlocation = %q\ninsecure = %t\n", mirror, insecure[mirror])
+		}
+	}
+	return conf.String()
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
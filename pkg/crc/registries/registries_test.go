@@ -0,0 +1,87 @@
+package registries
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMirrors(t *testing.T) {
+	mirrors, err := ParseMirrors("docker.io=mirror.corp.test/docker, quay.io=mirror.corp.test:5000/quay,,docker.io=backup.corp.test/docker")
+	require.NoError(t, err)
+	assert.Equal(t, []Mirror{
+		{Source: "docker.io", Mirror: "mirror.corp.test/docker"},
+		{Source: "quay.io", Mirror: "mirror.corp.test:5000/quay"},
+		{Source: "docker.io", Mirror: "backup.corp.test/docker"},
+	}, mirrors)
+}
+
+func TestParseMirrorsInvalid(t *testing.T) {
+	_, err := ParseMirrors("docker.io")
+	assert.EqualError(t, err, "invalid registry mirror 'docker.io', must be SOURCE=MIRROR")
+	_, err = ParseMirrors("docker.io=https://mirror.corp.test")
+	assert.EqualError(t, err, "invalid registry 'https://mirror.corp.test' in registry mirror 'docker.io=https://mirror.corp.test'")
+	_, err = ParseMirrors("docker.io=docker.io")
+	assert.EqualError(t, err, "registry 'docker.io' cannot be its own mirror")
+	_, err = ParseMirrors("*.docker.io=mirror.corp.test")
+	assert.Error(t, err)
+}
+
+func TestParseRegistries(t *testing.T) {
+	registries, err := ParseRegistries("registry.corp.test:5000, *.corp.test,Quay.io/team")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"registry.corp.test:5000", "*.corp.test", "quay.io/team"}, registries)
+
+	_, err = ParseRegistries("registry.corp.test,registry corp")
+	assert.EqualError(t, err, "invalid registry 'registry corp'")
+}
+
+func TestRegistriesConf(t *testing.T) {
+	cfg := Config{
+		Mirrors: []Mirror{
+			{Source: "quay.io", Mirror: "mirror.corp.test:5000/quay"},
+			{Source: "docker.io", Mirror: "mirror.corp.test:5000/docker"},
+		},
+		Insecure: []string{"mirror.corp.test:5000/docker"},
+		Blocked:  []string{"*.untrusted.test"},
+	}
+	assert.Equal(t, `# Generated by crc from the registry-mirrors, insecure-registries and blocked-registries settings
+
+[[registry]]
+prefix = "*.untrusted.test"
+insecure = false
+blocked = true
+
+[[registry]]
+prefix = "docker.io"
+location = "docker.io"
+insecure = false
+blocked = false
+
+[[registry.mirror]]
+location = "mirror.corp.test:5000/docker"
+insecure = true
+
+[[registry]]
+prefix = "mirror.corp.test:5000/docker"
+location = "mirror.corp.test:5000/docker"
+insecure = true
+blocked = false
+
+[[registry]]
+prefix = "quay.io"
+location = "quay.io"
+insecure = false
+blocked = false
+
+[[registry.mirror]]
+location = "mirror.corp.test:5000/quay"
+insecure = false
+`, cfg.RegistriesConf())
+}
+
+func TestIsEmpty(t *testing.T) {
+	assert.True(t, Config{}.IsEmpty())
+	assert.False(t, Config{Blocked: []string{"docker.io"}}.IsEmpty())
+}
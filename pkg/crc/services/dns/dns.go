@@ -45,7 +45,7 @@ func RunPostStart(serviceConfig services.ServicePostStartConfig) error {
 }
 
 func setupDnsmasq(serviceConfig services.ServicePostStartConfig) error {
-	if serviceConfig.NetworkMode == network.UserNetworkingMode {
+	if serviceConfig.NetworkMode == network.UserNetworkingMode && serviceConfig.VMIP == "" {
 		return nil
 	}
 
@@ -78,6 +78,16 @@ func getResolvFileValues(serviceConfig services.ServicePostStartConfig) (network
 
 func dnsServers(serviceConfig services.ServicePostStartConfig) ([]network.NameServer, error) {
 	if serviceConfig.NetworkMode == network.UserNetworkingMode {
+		if serviceConfig.VMIP != "" {
+			return []network.NameServer{
+				{
+					IPAddress: serviceConfig.VMIP,
+				},
+				{
+					IPAddress: constants.VSockGateway,
+				},
+			}, nil
+		}
 		return []network.NameServer{
 			{
 				IPAddress: constants.VSockGateway,
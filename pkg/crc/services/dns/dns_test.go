@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
 	"github.com/crc-org/crc/v2/pkg/crc/machine/bundle"
+	"github.com/crc-org/crc/v2/pkg/crc/network"
 	"github.com/crc-org/crc/v2/pkg/crc/services"
 	"github.com/stretchr/testify/assert"
 )
@@ -36,3 +38,13 @@ func TestGetApplicableHostnames(t *testing.T) {
 		"default-route-openshift-image-registry.apps.crc.testing",
 	}, hostnames)
 }
+
+func TestUserModeDNSServers(t *testing.T) {
+	servers, err := dnsServers(services.ServicePostStartConfig{NetworkMode: network.UserNetworkingMode})
+	assert.NoError(t, err)
+	assert.Equal(t, []network.NameServer{{IPAddress: constants.VSockGateway}}, servers)
+
+	servers, err = dnsServers(services.ServicePostStartConfig{NetworkMode: network.UserNetworkingMode, VMIP: "192.168.127.3"})
+	assert.NoError(t, err)
+	assert.Equal(t, []network.NameServer{{IPAddress: "192.168.127.3"}, {IPAddress: constants.VSockGateway}}, servers)
+}
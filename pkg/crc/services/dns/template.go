@@ -33,12 +33,17 @@ type dnsmasqConfFileValues struct {
 func createDnsmasqDNSConfig(serviceConfig services.ServicePostStartConfig) error {
 	domain := serviceConfig.BundleMetadata.ClusterInfo.BaseDomain
 
+	ip := serviceConfig.IP
+	if serviceConfig.VMIP != "" {
+		ip = serviceConfig.VMIP
+	}
+
 	dnsmasqConfFileValues := dnsmasqConfFileValues{
 		BaseDomain:  domain,
 		Hostname:    serviceConfig.BundleMetadata.Nodes[0].Hostname,
 		AppsDomain:  serviceConfig.BundleMetadata.ClusterInfo.AppsDomain,
 		ClusterName: serviceConfig.BundleMetadata.ClusterInfo.ClusterName,
-		IP:          serviceConfig.IP,
+		IP:          ip,
 		InternalIP:  serviceConfig.BundleMetadata.Nodes[0].InternalIP,
 	}
 
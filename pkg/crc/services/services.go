@@ -7,10 +7,14 @@ import (
 )
 
 type ServicePostStartConfig struct {
-	Name            string
-	SSHRunner       *ssh.Runner
-	BundleMetadata  bundle.CrcBundleInfo
-	IP              string
+	Name           string
+	SSHRunner      *ssh.Runner
+	BundleMetadata bundle.CrcBundleInfo
+	IP             string
+	// VMIP is the address of the virtual machine on the user mode network
+	// when it is not the default one, the cluster names are then resolved
+	// by dnsmasq in the virtual machine
+	VMIP            string
 	NetworkMode     network.Mode
 	ModifyHostsFile bool
 }
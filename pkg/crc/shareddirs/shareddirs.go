@@ -0,0 +1,93 @@
+package shareddirs
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SharedDir is a directory of the host shared with the virtual machine
+type SharedDir struct {
+	// Source is the path of the directory on the host
+	Source string `json:"source"`
+	// Target is the mount point in the virtual machine, the driver
+	// picks one derived from Source when it is empty
+	Target   string `json:"target,omitempty"`
+	ReadOnly bool   `json:"readOnly,omitempty"`
+}
+
+// Parse parses a shared directory in the HOST:GUEST[:ro|rw] format. The
+// entry is split from the end so that HOST can be a Windows path with a
+// drive letter.
+func Parse(spec string) (SharedDir, error) {
+	spec = strings.TrimSpace(spec)
+	parts := strings.Split(spec, ":")
+	var dir SharedDir
+	if len(parts) > 2 {
+		switch parts[len(parts)-1] {
+		case "ro":
+			dir.ReadOnly = true
+			parts = parts[:len(parts)-1]
+		case "rw":
+			parts = parts[:len(parts)-1]
+		}
+	}
+	if len(parts) < 2 {
+		return SharedDir{}, fmt.Errorf("invalid shared directory '%s', must be HOST:GUEST[:ro|rw]", spec)
+	}
+	dir.Source = strings.Join(parts[:len(parts)-1], ":")
+	dir.Target = parts[len(parts)-1]
+	if !filepath.IsAbs(dir.Source) {
+		return SharedDir{}, fmt.Errorf("host path '%s' of shared directory '%s' must be absolute", dir.Source, spec)
+	}
+	if !path.IsAbs(dir.Target) || path.Clean(dir.Target) != dir.Target {
+		return SharedDir{}, fmt.Errorf("guest path '%s' of shared directory '%s' must be an absolute and clean path", dir.Target, spec)
+	}
+	if dir.Target == "/" {
+		return SharedDir{}, fmt.Errorf("guest path of shared directory '%s' cannot be /", spec)
+	}
+	return dir, nil
+}
+
+// ParseList parses a comma-separated list of shared directories, the guest
+// paths must be distinct
+func ParseList(specs string) ([]SharedDir, error) {
+	var dirs []SharedDir
+	for _, spec := range strings.Split(specs, ",") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		dir, err := Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range dirs {
+			if other.Target == dir.Target {
+				return nil, fmt.Errorf("shared directories %s and %s use the same guest path", other, dir)
+			}
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
+// Validate checks that the host path is an existing directory
+func (d SharedDir) Validate() error {
+	info, err := os.Stat(d.Source)
+	if err != nil {
+		return fmt.Errorf("invalid shared directory %s: %w", d, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid shared directory %s: %s is not a directory", d, d.Source)
+	}
+	return nil
+}
+
+func (d SharedDir) String() string {
+	if d.ReadOnly {
+		return fmt.Sprintf("%s:%s:ro", d.Source, d.Target)
+	}
+	return fmt.Sprintf("%s:%s", d.Source, d.Target)
+}
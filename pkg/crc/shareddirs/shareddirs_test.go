@@ -0,0 +1,80 @@
+package shareddirs
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hostPath() string {
+	if runtime.GOOS == "windows" {
+		return `C:\Users\crc\src`
+	}
+	return "/home/crc/src"
+}
+
+func TestParse(t *testing.T) {
+	host := hostPath()
+
+	dir, err := Parse(host + ":/mnt/src")
+	require.NoError(t, err)
+	assert.Equal(t, SharedDir{Source: host, Target: "/mnt/src"}, dir)
+
+	dir, err = Parse(" " + host + ":/mnt/src:ro ")
+	require.NoError(t, err)
+	assert.Equal(t, SharedDir{Source: host, Target: "/mnt/src", ReadOnly: true}, dir)
+	assert.Equal(t, host+":/mnt/src:ro", dir.String())
+
+	dir, err = Parse(host + ":/mnt/src:rw")
+	require.NoError(t, err)
+	assert.Equal(t, SharedDir{Source: host, Target: "/mnt/src"}, dir)
+}
+
+func TestParseInvalid(t *testing.T) {
+	host := hostPath()
+
+	_, err := Parse(host)
+	assert.Error(t, err)
+	_, err = Parse("src:/mnt/src")
+	assert.EqualError(t, err, "host path 'src' of shared directory 'src:/mnt/src' must be absolute")
+	_, err = Parse(host + ":mnt/src")
+	assert.EqualError(t, err, "guest path 'mnt/src' of shared directory '"+host+":mnt/src' must be an absolute and clean path")
+	_, err = Parse(host + ":/mnt/../src")
+	assert.Error(t, err)
+	_, err = Parse(host + ":/")
+	assert.EqualError(t, err, "guest path of shared directory '"+host+":/' cannot be /")
+	_, err = Parse(host + ":/mnt/src:rx")
+	assert.Error(t, err)
+}
+
+func TestParseList(t *testing.T) {
+	host := hostPath()
+
+	dirs, err := ParseList(host + ":/mnt/src:ro, " + host + ":/mnt/other,")
+	require.NoError(t, err)
+	assert.Equal(t, []SharedDir{
+		{Source: host, Target: "/mnt/src", ReadOnly: true},
+		{Source: host, Target: "/mnt/other"},
+	}, dirs)
+
+	dirs, err = ParseList("")
+	require.NoError(t, err)
+	assert.Empty(t, dirs)
+
+	_, err = ParseList(host + ":/mnt/src," + host + ":/mnt/src:ro")
+	assert.EqualError(t, err, "shared directories "+host+":/mnt/src and "+host+":/mnt/src:ro use the same guest path")
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, SharedDir{Source: dir, Target: "/mnt/src"}.Validate())
+
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+	assert.EqualError(t, SharedDir{Source: file, Target: "/mnt/src"}.Validate(), "invalid shared directory "+file+":/mnt/src: "+file+" is not a directory")
+	assert.Error(t, SharedDir{Source: filepath.Join(dir, "missing"), Target: "/mnt/src"}.Validate())
+}
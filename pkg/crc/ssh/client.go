@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"strconv"
@@ -15,6 +16,9 @@ import (
 
 type Client interface {
 	Run(command string) ([]byte, []byte, error)
+	// Stream runs the command and copies its standard output to stdout
+	// while it runs, it returns the standard error
+	Stream(command string, stdout io.Writer) ([]byte, error)
 	Close()
 }
 
@@ -94,6 +98,12 @@ func (client *NativeClient) session() (*ssh.Session, error) {
 }
 
 func (client *NativeClient) Run(command string) ([]byte, []byte, error) {
+	var stdout bytes.Buffer
+	stderr, err := client.Stream(command, &stdout)
+	return stdout.Bytes(), stderr, err
+}
+
+func (client *NativeClient) Stream(command string, stdout io.Writer) ([]byte, error) {
 	session, err := client.session()
 	if err != nil {
 		if client.conn != nil {
@@ -101,20 +111,17 @@ func (client *NativeClient) Run(command string) ([]byte, []byte, error) {
 			client.conn.Close()
 			client.conn = nil
 		}
-		return nil, nil, err
+		return nil, err
 	}
 	defer session.Close()
 
-	var (
-		stdout bytes.Buffer
-		stderr bytes.Buffer
-	)
-	session.Stdout = &stdout
+	var stderr bytes.Buffer
+	session.Stdout = stdout
 	session.Stderr = &stderr
 
 	err = session.Run(command)
 
-	return stdout.Bytes(), stderr.Bytes(), err
+	return stderr.Bytes(), err
 }
 
 func (client *NativeClient) Close() {
@@ -75,7 +75,10 @@ func GenerateSSHKey(path string) error {
 	return nil
 }
 
-func RemoveCRCHostEntriesFromKnownHosts() error {
+// RemoveCRCHostEntriesFromKnownHosts removes the entries of the VM with
+// system mode networking, and of the VM reachable on the given SSH port of
+// the host with user mode networking
+func RemoveCRCHostEntriesFromKnownHosts(sshPort int) error {
 	knownHostsPath := filepath.Join(constants.GetHomeDir(), ".ssh", "known_hosts")
 	if _, err := os.Stat(knownHostsPath); err != nil {
 		return nil
@@ -125,7 +128,7 @@ func RemoveCRCHostEntriesFromKnownHosts() error {
 	scanner.Split(splitFunc)
 	writer := bufio.NewWriter(tempHostsFile)
 	for scanner.Scan() {
-		if strings.Contains(scanner.Text(), "[127.0.0.1]:2222") || strings.Contains(scanner.Text(), "192.168.130.11") {
+		if strings.Contains(scanner.Text(), fmt.Sprintf("[127.0.0.1]:%d", sshPort)) || strings.Contains(scanner.Text(), "192.168.130.11") {
 			foundCRCEntries = true
 			continue
 		}
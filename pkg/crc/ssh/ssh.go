@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -45,6 +46,24 @@ func (runner *Runner) RunPrivate(cmd string, args ...string) (string, string, er
 	return runner.runSSHCommand(cmd, true)
 }
 
+// StreamPrivate runs the command without logging it nor its output, its
+// standard output is copied to stdout while it runs
+func (runner *Runner) StreamPrivate(stdout io.Writer, cmd string, args ...string) (string, error) {
+	if len(args) != 0 {
+		cmd = fmt.Sprintf("%s %s", cmd, strings.Join(args, " "))
+	}
+	logging.Debugf("Running SSH command: <hidden>")
+	stderr, err := runner.client.Stream(cmd, stdout)
+	if err != nil {
+		logging.Debugf("SSH command failed")
+		return string(stderr), fmt.Errorf(`ssh command error:
+command : %s
+err     : %w`+"\n", cmd, err)
+	}
+	logging.Debugf("SSH command succeeded")
+	return string(stderr), nil
+}
+
 func (runner *Runner) RunPrivileged(reason string, cmdAndArgs ...string) (string, string, error) {
 	logging.Debugf("Using root access: %s", reason)
 	commandline := fmt.Sprintf("sudo %s", strings.Join(cmdAndArgs, " "))
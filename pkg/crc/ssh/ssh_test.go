@@ -48,6 +48,10 @@ func TestRunner(t *testing.T) {
 	assert.NoError(t, runner.CopyDataPrivileged([]byte(`hello world`), "/hello", 0644))
 	assert.NoError(t, runner.CopyDataPrivileged([]byte(`hello world`), "/hello", 0644))
 	assert.NoError(t, runner.CopyData([]byte(`hello world`), "/home/core/hello", 0644))
+	var stdout bytes.Buffer
+	_, err = runner.StreamPrivate(&stdout, "echo", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", stdout.String())
 	assert.Equal(t, 1, *totalConn)
 }
 
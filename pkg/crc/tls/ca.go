@@ -0,0 +1,97 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseCertificates parses PEM data made only of certificates
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected %s PEM block", block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse certificate")
+		}
+		certs = append(certs, cert)
+	}
+	if len(bytes.TrimSpace(data)) != 0 {
+		return nil, errors.New("failed to decode certificate PEM")
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	return certs, nil
+}
+
+// LoadCACertificates reads the certificates of the PEM files of paths, the
+// .pem and .crt files of directories are read. It returns the PEM bundle of
+// all the certificates.
+func LoadCACertificates(paths []string) ([]byte, error) {
+	var bundle []byte
+	for _, path := range paths {
+		files, err := caFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			certs, err := ParseCertificates(data)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid CA file %s", file)
+			}
+			for _, cert := range certs {
+				if !cert.IsCA {
+					return nil, fmt.Errorf("invalid CA file %s: certificate '%s' is not a CA", file, cert.Subject)
+				}
+				bundle = append(bundle, CertToPem(cert)...)
+			}
+		}
+	}
+	return bundle, nil
+}
+
+func caFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.Type().IsRegular() && (ext == ".pem" || ext == ".crt") {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .pem or .crt file in %s", path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,68 @@
+package tls
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCA(t *testing.T, path string) []byte {
+	_, cert, err := GetSelfSignedCA()
+	require.NoError(t, err)
+	data := CertToPem(cert)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return data
+}
+
+func TestLoadCACertificates(t *testing.T) {
+	dir := t.TempDir()
+	caDir := filepath.Join(dir, "cas")
+	require.NoError(t, os.Mkdir(caDir, 0700))
+	first := writeCA(t, filepath.Join(caDir, "b.crt"))
+	second := writeCA(t, filepath.Join(caDir, "a.pem"))
+	require.NoError(t, os.WriteFile(filepath.Join(caDir, "README"), []byte("not a certificate"), 0600))
+	third := writeCA(t, filepath.Join(dir, "ca.pem"))
+
+	bundle, err := LoadCACertificates([]string{caDir, filepath.Join(dir, "ca.pem")})
+	require.NoError(t, err)
+	expected := append(append(append([]byte{}, second...), first...), third...)
+	assert.Equal(t, string(expected), string(bundle))
+}
+
+func TestLoadCACertificatesInvalid(t *testing.T) {
+	dir := t.TempDir()
+	key, err := PrivateKey()
+	require.NoError(t, err)
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(keyFile, PrivateKeyToPem(key), 0600))
+
+	_, err = LoadCACertificates([]string{keyFile})
+	assert.EqualError(t, err, "invalid CA file "+keyFile+": unexpected RSA PRIVATE KEY PEM block")
+
+	emptyDir := filepath.Join(dir, "empty")
+	require.NoError(t, os.Mkdir(emptyDir, 0700))
+	_, err = LoadCACertificates([]string{emptyDir})
+	assert.EqualError(t, err, "no .pem or .crt file in "+emptyDir)
+
+	garbage := filepath.Join(dir, "garbage.crt")
+	require.NoError(t, os.WriteFile(garbage, []byte("garbage"), 0600))
+	_, err = LoadCACertificates([]string{garbage})
+	assert.EqualError(t, err, "invalid CA file "+garbage+": failed to decode certificate PEM")
+}
+
+func TestLoadCACertificatesRejectsLeafCertificates(t *testing.T) {
+	caKey, caCert, err := GetSelfSignedCA()
+	require.NoError(t, err)
+	_, clientCert, err := GenerateClientCertificate(caKey, caCert)
+	require.NoError(t, err)
+	leaf, err := PemToCert(clientCert)
+	require.NoError(t, err)
+	leafFile := filepath.Join(t.TempDir(), "leaf.pem")
+	require.NoError(t, os.WriteFile(leafFile, clientCert, 0600))
+
+	_, err = LoadCACertificates([]string{leafFile})
+	assert.EqualError(t, err, "invalid CA file "+leafFile+": certificate '"+leaf.Subject.String()+"' is not a CA")
+}
@@ -280,3 +280,21 @@ func VerifyCertificateAgainstRootCA(ca, certificate string) (bool, error) {
 	}
 	return true, nil
 }
+
+// PemToPrivateKey is the inverse of PrivateKeyToPem
+func PemToPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "RSA PRIVATE KEY" {
+		return nil, errors.New("failed to decode private key PEM")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+// PemToCert is the inverse of CertToPem
+func PemToCert(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("failed to decode certificate PEM")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -7,6 +7,8 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"go.podman.io/common/pkg/strongunits"
@@ -92,11 +94,14 @@ func ValidateBundlePath(bundlePath string, preset crcpreset.Preset) error {
 	return nil
 }
 
-func ValidateBundle(bundlePath string, preset crcpreset.Preset) error {
+func ValidateBundle(bundlePath string, preset crcpreset.Preset, signaturePolicy *bundle.SignaturePolicy) error {
 	bundleName, err := bundle.GetBundleNameFromURI(bundlePath)
 	if err != nil {
 		return err
 	}
+	if err := validateBundleSignature(bundlePath, preset, signaturePolicy); err != nil {
+		return err
+	}
 	bundleMetadata, err := bundle.Get(bundleName)
 	if err != nil {
 		if bundlePath == constants.GetDefaultBundlePath(preset) {
@@ -129,6 +134,28 @@ func ValidateBundle(bundlePath string, preset crcpreset.Preset) error {
 	return nil
 }
 
+// validateBundleSignature checks the bundle files with signaturePolicy, the
+// bundles downloaded from a URL are checked by bundle.Download and the default
+// bundle is checked against the release sha256sums once it is downloaded
+func validateBundleSignature(bundlePath string, preset crcpreset.Preset, signaturePolicy *bundle.SignaturePolicy) error {
+	if signaturePolicy == nil {
+		return nil
+	}
+	switch {
+	case strings.HasPrefix(bundlePath, "http://"), strings.HasPrefix(bundlePath, "https://"):
+		return nil
+	case strings.HasPrefix(bundlePath, "docker://"):
+		return fmt.Errorf("cannot verify the signature of bundle %s, only bundle files can be signed", bundlePath)
+	}
+	if _, err := os.Stat(bundlePath); err != nil {
+		if os.IsNotExist(err) && bundlePath == constants.GetDefaultBundlePath(preset) {
+			return nil
+		}
+		return fmt.Errorf("cannot verify the signature of bundle %s: %w", filepath.Base(bundlePath), err)
+	}
+	return signaturePolicy.Verify(bundlePath)
+}
+
 func bundleMismatchWarning(userProvidedBundle string, preset crcpreset.Preset) {
 	userProvidedBundle = bundle.GetBundleNameWithExtension(userProvidedBundle)
 	if userProvidedBundle != constants.GetDefaultBundle(preset) {
@@ -162,6 +189,26 @@ func BundleMismatchWithPresetMetadata(preset crcpreset.Preset, bundleMetadata *b
 	return nil
 }
 
+var instanceNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// ValidateInstanceName checks if the provided name can be used for a CRC instance,
+// it is used as VM name and as directory name so it must be a valid DNS label
+func ValidateInstanceName(name string) error {
+	if !instanceNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid instance name '%s': must consist of lower case alphanumeric characters or '-', start and end with an alphanumeric character and be at most 63 characters", name)
+	}
+	return nil
+}
+
+// ValidateSnapshotName checks if the provided name can be used for a snapshot
+// of a CRC instance, it follows the same rules as instance names
+func ValidateSnapshotName(name string) error {
+	if !instanceNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name '%s': must consist of lower case alphanumeric characters or '-', start and end with an alphanumeric character and be at most 63 characters", name)
+	}
+	return nil
+}
+
 // ValidateIPAddress checks if provided IP is valid
 func ValidateIPAddress(ipAddress string) error {
 	ip := net.ParseIP(ipAddress).To4()
@@ -0,0 +1,383 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/crc-org/crc/v2/pkg/crc/logging"
+	"github.com/crc-org/crc/v2/pkg/crc/version"
+	"github.com/crc-org/crc/v2/pkg/os/terminal"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// parallelChunks is the number of ranges fetched concurrently for a
+	// large download
+	parallelChunks = 4
+	// chunkRetries is the number of times a chunk is retried after a
+	// transient failure before the download is aborted
+	chunkRetries = 3
+
+	partialFileSuffix = ".part"
+	stateFileSuffix   = ".part.json"
+)
+
+// smaller files are not worth splitting, tests lower this threshold
+var minSizeForChunkedDownload int64 = minSizeForProgressBar
+
+type chunk struct {
+	Start int64 `json:"start"`
+	// End is inclusive, as in the HTTP Range header
+	End  int64 `json:"end"`
+	Done int64 `json:"done"`
+}
+
+func (c *chunk) complete() bool {
+	return c.Start+c.Done > c.End
+}
+
+// downloadState is persisted next to the partial file so that an
+// interrupted download can be resumed from where it stopped
+type downloadState struct {
+	Size      int64    `json:"size"`
+	Sha256sum string   `json:"sha256sum,omitempty"`
+	Chunks    []*chunk `json:"chunks"`
+
+	lock sync.Mutex
+}
+
+func newDownloadState(size int64, sha256sum string) *downloadState {
+	state := &downloadState{
+		Size:      size,
+		Sha256sum: sha256sum,
+	}
+	chunkSize := size / parallelChunks
+	for i := int64(0); i < parallelChunks; i++ {
+		end := (i+1)*chunkSize - 1
+		if i == parallelChunks-1 {
+			end = size - 1
+		}
+		state.Chunks = append(state.Chunks, &chunk{Start: i * chunkSize, End: end})
+	}
+	return state
+}
+
+func loadDownloadState(statePath string) (*downloadState, error) {
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		return nil, err
+	}
+	var state downloadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// save persists the state after flushing the partial file, the bytes
+// accounted for in the state must not be lost if the system crashes
+func (s *downloadState) save(file *os.File, statePath string) error {
+	s.lock.Lock()
+	data, err := json.Marshal(s)
+	s.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	return os.WriteFile(statePath, data, 0600)
+}
+
+func (s *downloadState) downloaded() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var total int64
+	for _, c := range s.Chunks {
+		total += c.Done
+	}
+	return total
+}
+
+func (s *downloadState) advance(c *chunk, n int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	c.Done += n
+}
+
+func (s *downloadState) offset(c *chunk) int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return c.Start + c.Done
+}
+
+// probe checks with a HEAD request whether the server supports range
+// requests, and returns the size of the remote file
+func probe(ctx context.Context, client *http.Client, uri string) (int64, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, uri, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("User-Agent", version.UserAgent())
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, false, fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, uri)
+	}
+	return resp.ContentLength, resp.Header.Get("Accept-Ranges") == "bytes", nil
+}
+
+func destinationFilename(uri, destination string) (string, error) {
+	fi, err := os.Stat(destination)
+	if err != nil || !fi.IsDir() {
+		return destination, nil
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return "", fmt.Errorf("cannot determine filename from %s", uri)
+	}
+	return filepath.Join(destination, name), nil
+}
+
+// tryChunkedDownload downloads large files from servers supporting range
+// requests using several parallel connections. The download can be resumed
+// after an interruption. The boolean return value is false when the server
+// does not qualify, in which case the caller must fall back to a regular
+// download.
+func tryChunkedDownload(ctx context.Context, client *http.Client, uri, destination string, sha256sum []byte) (string, bool, error) {
+	u, err := url.Parse(uri)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false, nil
+	}
+	size, acceptRanges, err := probe(ctx, client, uri)
+	if err != nil {
+		logging.Debugf("Cannot probe %s for range requests: %v", uri, err)
+		return "", false, nil
+	}
+	if !acceptRanges || size < minSizeForChunkedDownload {
+		return "", false, nil
+	}
+	filename, err := destinationFilename(uri, destination)
+	if err != nil {
+		return "", true, err
+	}
+	if alreadyDownloaded(filename, size, sha256sum) {
+		logging.Debugf("%s is already downloaded to %s", uri, filename)
+		return filename, true, nil
+	}
+	return filename, true, chunkedDownload(ctx, client, uri, filename, size, sha256sum)
+}
+
+func chunkedDownload(ctx context.Context, client *http.Client, uri, filename string, size int64, sha256sum []byte) error {
+	partPath := filename + partialFileSuffix
+	statePath := filename + stateFileSuffix
+	expectedSum := hex.EncodeToString(sha256sum)
+
+	state, err := loadDownloadState(statePath)
+	if err == nil && state.Size == size && state.Sha256sum == expectedSum && fileExists(partPath) {
+		logging.Debugf("Resuming download of %s (%d of %d bytes already downloaded)", uri, state.downloaded(), size)
+	} else {
+		state = newDownloadState(size, expectedSum)
+		if err := os.Remove(partPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(partPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := file.Truncate(size); err != nil {
+		return err
+	}
+
+	if err := fetchChunks(ctx, client, uri, file, state, statePath); err != nil {
+		if saveErr := state.save(file, statePath); saveErr != nil {
+			logging.Debugf("Cannot save download state to %s: %v", statePath, saveErr)
+		}
+		return err
+	}
+
+	if sha256sum != nil {
+		if err := verifyChecksum(file, sha256sum); err != nil {
+			_ = os.Remove(partPath)
+			_ = os.Remove(statePath)
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(partPath, filename); err != nil {
+		return err
+	}
+	if err := os.Remove(statePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func fetchChunks(ctx context.Context, client *http.Client, uri string, file *os.File, state *downloadState, statePath string) error {
+	reportProgress := progressCallback(ctx)
+	var bar *pb.ProgressBar
+	if terminal.IsShowTerminalOutput() {
+		bar = startProgressBar(state.Size, state.downloaded())
+		defer bar.Finish()
+	}
+	report := func() {
+		current := state.downloaded()
+		if bar != nil {
+			bar.SetCurrent(current)
+		}
+		reportProgress(current, state.Size)
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	for _, c := range state.Chunks {
+		if c.complete() {
+			continue
+		}
+		group.Go(func() error {
+			return fetchChunkWithRetries(groupCtx, client, uri, file, state, c)
+		})
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- group.Wait()
+	}()
+
+	progressTicker := time.NewTicker(500 * time.Millisecond)
+	defer progressTicker.Stop()
+	saveTicker := time.NewTicker(2 * time.Second)
+	defer saveTicker.Stop()
+	for {
+		select {
+		case <-progressTicker.C:
+			report()
+		case <-saveTicker.C:
+			if err := state.save(file, statePath); err != nil {
+				logging.Debugf("Cannot save download state to %s: %v", statePath, err)
+			}
+		case err := <-done:
+			report()
+			return err
+		}
+	}
+}
+
+func fetchChunkWithRetries(ctx context.Context, client *http.Client, uri string, file *os.File, state *downloadState, c *chunk) error {
+	var err error
+	for attempt := 1; attempt <= chunkRetries; attempt++ {
+		err = fetchChunk(ctx, client, uri, file, state, c)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		logging.Debugf("Download of bytes %d-%d of %s failed (attempt %d/%d): %v", state.offset(c), c.End, uri, attempt, chunkRetries, err)
+	}
+	return err
+}
+
+func fetchChunk(ctx context.Context, client *http.Client, uri string, file *os.File, state *downloadState, c *chunk) error {
+	offset := state.offset(c)
+	if offset > c.End {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", version.UserAgent())
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, c.End))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("unexpected status code %d for range request to %s", resp.StatusCode, uri)
+	}
+
+	buf := make([]byte, 32*1024)
+	for offset <= c.End {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			if offset+int64(n) > c.End+1 {
+				return fmt.Errorf("server sent more data than requested for %s", uri)
+			}
+			if _, err := file.WriteAt(buf[:n], offset); err != nil {
+				return err
+			}
+			offset += int64(n)
+			state.advance(c, int64(n))
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	if offset <= c.End {
+		return fmt.Errorf("short read for range %d-%d of %s", c.Start, c.End, uri)
+	}
+	return nil
+}
+
+func verifyChecksum(file *os.File, sha256sum []byte) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return err
+	}
+	if sum := hash.Sum(nil); !bytes.Equal(sum, sha256sum) {
+		return fmt.Errorf("checksum mismatch: expected %x, got %x", sha256sum, sum)
+	}
+	return nil
+}
+
+// alreadyDownloaded returns true when filename has the size and the
+// checksum of the remote file, without a checksum it cannot be trusted
+func alreadyDownloaded(filename string, size int64, sha256sum []byte) bool {
+	if sha256sum == nil {
+		return false
+	}
+	fi, err := os.Stat(filename)
+	if err != nil || !fi.Mode().IsRegular() || fi.Size() != size {
+		return false
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	return verifyChecksum(file, sha256sum) == nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,174 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rangeServer struct {
+	content      []byte
+	acceptRanges bool
+
+	lock         sync.Mutex
+	rangeHeaders []string
+	bytesServed  atomic.Int64
+}
+
+func (s *rangeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.acceptRanges {
+		if r.Method == http.MethodGet {
+			s.bytesServed.Add(int64(len(s.content)))
+		}
+		_, _ = w.Write(s.content)
+		return
+	}
+	if r.Header.Get("Range") != "" {
+		s.lock.Lock()
+		s.rangeHeaders = append(s.rangeHeaders, r.Header.Get("Range"))
+		s.lock.Unlock()
+	}
+	counter := &countingWriter{ResponseWriter: w, counter: &s.bytesServed}
+	http.ServeContent(counter, r, "bundle", time.Time{}, bytes.NewReader(s.content))
+}
+
+type countingWriter struct {
+	http.ResponseWriter
+	counter *atomic.Int64
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.counter.Add(int64(n))
+	return n, err
+}
+
+func setupRangeServer(t *testing.T, acceptRanges bool) (*rangeServer, string) {
+	content := make([]byte, 1024*1024+3)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+	server := &rangeServer{content: content, acceptRanges: acceptRanges}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	previous := minSizeForChunkedDownload
+	minSizeForChunkedDownload = 1
+	t.Cleanup(func() {
+		minSizeForChunkedDownload = previous
+	})
+	return server, ts.URL + "/bundles/test.crcbundle"
+}
+
+func TestChunkedDownload(t *testing.T) {
+	server, uri := setupRangeServer(t, true)
+	dir := t.TempDir()
+	sum := sha256.Sum256(server.content)
+
+	filename, err := Download(context.Background(), uri, dir, 0600, sum[:])
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "test.crcbundle"), filename)
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, server.content, data)
+	assert.Len(t, server.rangeHeaders, parallelChunks)
+	assert.NoFileExists(t, filename+partialFileSuffix)
+	assert.NoFileExists(t, filename+stateFileSuffix)
+}
+
+func TestChunkedDownloadResume(t *testing.T) {
+	server, uri := setupRangeServer(t, true)
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.crcbundle")
+	sum := sha256.Sum256(server.content)
+
+	// simulate an interrupted download: the first chunk is complete and
+	// the other chunks are half done
+	size := int64(len(server.content))
+	state := newDownloadState(size, hex.EncodeToString(sum[:]))
+	partial := make([]byte, size)
+	for i, c := range state.Chunks {
+		c.Done = (c.End - c.Start + 1) / 2
+		if i == 0 {
+			c.Done = c.End - c.Start + 1
+		}
+		copy(partial[c.Start:c.Start+c.Done], server.content[c.Start:c.Start+c.Done])
+	}
+	require.NoError(t, os.WriteFile(filename+partialFileSuffix, partial, 0600))
+	file, err := os.Open(filename + partialFileSuffix)
+	require.NoError(t, err)
+	require.NoError(t, state.save(file, filename+stateFileSuffix))
+	require.NoError(t, file.Close())
+
+	_, err = Download(context.Background(), uri, filename, 0600, sum[:])
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, server.content, data)
+	assert.Len(t, server.rangeHeaders, parallelChunks-1)
+	assert.Less(t, server.bytesServed.Load(), size/2+int64(parallelChunks))
+}
+
+func TestChunkedDownloadSkipsExistingFile(t *testing.T) {
+	server, uri := setupRangeServer(t, true)
+	filename := filepath.Join(t.TempDir(), "test.crcbundle")
+	sum := sha256.Sum256(server.content)
+	require.NoError(t, os.WriteFile(filename, server.content, 0600))
+
+	_, err := Download(context.Background(), uri, filename, 0600, sum[:])
+	require.NoError(t, err)
+	assert.Empty(t, server.rangeHeaders)
+	assert.Zero(t, server.bytesServed.Load())
+
+	// same size, different content
+	corrupted := bytes.Clone(server.content)
+	corrupted[0]++
+	require.NoError(t, os.WriteFile(filename, corrupted, 0600))
+
+	_, err = Download(context.Background(), uri, filename, 0600, sum[:])
+	require.NoError(t, err)
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, server.content, data)
+	assert.Len(t, server.rangeHeaders, parallelChunks)
+}
+
+func TestChunkedDownloadChecksumMismatch(t *testing.T) {
+	_, uri := setupRangeServer(t, true)
+	filename := filepath.Join(t.TempDir(), "test.crcbundle")
+	wrongSum := sha256.Sum256([]byte("not the bundle"))
+
+	_, err := Download(context.Background(), uri, filename, 0600, wrongSum[:])
+	assert.ErrorContains(t, err, "checksum mismatch")
+	assert.NoFileExists(t, filename)
+	assert.NoFileExists(t, filename+partialFileSuffix)
+	assert.NoFileExists(t, filename+stateFileSuffix)
+}
+
+func TestDownloadWithoutRangeSupport(t *testing.T) {
+	server, uri := setupRangeServer(t, false)
+	filename := filepath.Join(t.TempDir(), "test.crcbundle")
+	sum := sha256.Sum256(server.content)
+
+	_, err := Download(context.Background(), uri, filename, 0600, sum[:])
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, server.content, data)
+	assert.Empty(t, server.rangeHeaders)
+}
@@ -22,10 +22,41 @@ import (
 	grab "github.com/sebrandon1/grab/lib"
 )
 
-func doRequest(client *grab.Client, req *grab.Request) (string, error) {
-	const minSizeForProgressBar = 100_000_000
+const minSizeForProgressBar = 100_000_000
+
+type progressCallbackKey struct{}
+
+// ProgressCallback is called periodically during large downloads with the
+// number of bytes downloaded so far and the total size of the file
+type ProgressCallback func(current, total int64)
+
+// WithProgressCallback returns a context which makes Download report its
+// progress to callback
+func WithProgressCallback(ctx context.Context, callback ProgressCallback) context.Context {
+	return context.WithValue(ctx, progressCallbackKey{}, callback)
+}
+
+func progressCallback(ctx context.Context) ProgressCallback {
+	if callback, ok := ctx.Value(progressCallbackKey{}).(ProgressCallback); ok {
+		return callback
+	}
+	return func(_, _ int64) {}
+}
+
+func startProgressBar(total, current int64) *pb.ProgressBar {
+	bar := pb.New64(total)
+	bar.SetCurrent(current)
+	bar.Set(pb.Bytes, true)
+	// This is the same as the 'Default' template https://github.com/cheggaaa/pb/blob/224e0746e1e7b9c5309d6e2637264bfeb746d043/v3/preset.go#L8-L10
+	// except that the 'per second' suffix is changed to '/s' (by default it is ' p/s' which is unexpected)
+	progressBarTemplate := `{{with string . "prefix"}}{{.}} {{end}}{{counters . }} {{bar . }} {{percent . }} {{speed . "%s/s" "??/s"}}{{with string . "suffix"}} {{.}}{{end}}`
+	bar.SetTemplateString(progressBarTemplate)
+	return bar.Start()
+}
 
+func doRequest(client *grab.Client, req *grab.Request) (string, error) {
 	resp := client.Do(req)
+	reportProgress := progressCallback(req.Context())
 	if resp.Size() < minSizeForProgressBar {
 		<-resp.Done
 		return resp.Filename, resp.Err()
@@ -35,12 +66,7 @@ func doRequest(client *grab.Client, req *grab.Request) (string, error) {
 	defer t.Stop()
 	var bar *pb.ProgressBar
 	if terminal.IsShowTerminalOutput() {
-		bar = pb.Start64(resp.Size())
-		bar.Set(pb.Bytes, true)
-		// This is the same as the 'Default' template https://github.com/cheggaaa/pb/blob/224e0746e1e7b9c5309d6e2637264bfeb746d043/v3/preset.go#L8-L10
-		// except that the 'per second' suffix is changed to '/s' (by default it is ' p/s' which is unexpected)
-		progressBarTemplate := `{{with string . "prefix"}}{{.}} {{end}}{{counters . }} {{bar . }} {{percent . }} {{speed . "%s/s" "??/s"}}{{with string . "suffix"}} {{.}}{{end}}`
-		bar.SetTemplateString(progressBarTemplate)
+		bar = startProgressBar(resp.Size(), 0)
 		defer bar.Finish()
 	}
 
@@ -51,7 +77,9 @@ loop:
 			if terminal.IsShowTerminalOutput() {
 				bar.SetCurrent(resp.BytesComplete())
 			}
+			reportProgress(resp.BytesComplete(), resp.Size())
 		case <-resp.Done:
+			reportProgress(resp.BytesComplete(), resp.Size())
 			break loop
 		}
 	}
@@ -64,9 +92,10 @@ loop:
 func Download(ctx context.Context, uri, destination string, mode os.FileMode, sha256sum []byte) (string, error) {
 	logging.Debugf("Downloading %s to %s", uri, destination)
 
+	httpClient := &http.Client{Transport: httpproxy.HTTPTransport()}
 	client := grab.NewClient()
 	client.UserAgent = version.UserAgent()
-	client.HTTPClient = &http.Client{Transport: httpproxy.HTTPTransport()}
+	client.HTTPClient = httpClient
 	req, err := grab.NewRequest(destination, uri)
 	if err != nil {
 		return "", errors.Wrapf(err, "unable to get request from %s", uri)
@@ -75,6 +104,19 @@ func Download(ctx context.Context, uri, destination string, mode os.FileMode, sh
 	if ctx == nil {
 		panic("ctx is nil, this should not happen")
 	}
+
+	if filename, ok, err := tryChunkedDownload(ctx, httpClient, uri, destination, sha256sum); ok {
+		if err != nil {
+			return "", err
+		}
+		if err := os.Chmod(filename, mode); err != nil {
+			_ = os.Remove(filename)
+			return "", err
+		}
+		logging.Debugf("Download saved to %v", filename)
+		return filename, nil
+	}
+
 	req = req.WithContext(ctx)
 
 	if sha256sum != nil {
@@ -3,12 +3,12 @@ package extract
 import (
 	"archive/tar"
 	"archive/zip"
-	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/cheggaaa/pb/v3"
@@ -18,23 +18,40 @@ import (
 
 	"github.com/h2non/filetype"
 	"github.com/klauspost/compress/zstd"
+	"github.com/klauspost/pgzip"
 	"github.com/pkg/errors"
-	"github.com/xi2/xz"
+	"github.com/ulikunitz/xz"
 )
 
 const minSizeForProgressBar = 100_000_000
 
+// gzipBlockSize is the size of the blocks decompressed ahead by pgzip
+const gzipBlockSize = 1 << 20
+
 func UncompressWithFilter(ctx context.Context, tarball, targetDir string, fileFilter func(string) bool) ([]string, error) {
-	return uncompress(ctx, tarball, targetDir, fileFilter, false) // never show detailed output
+	return uncompress(ctx, tarball, targetDir, fileFilter, false, 0) // never show detailed output
 }
 
 func Uncompress(ctx context.Context, tarball, targetDir string) ([]string, error) {
-	return uncompress(ctx, tarball, targetDir, nil, terminal.IsShowTerminalOutput())
+	return uncompress(ctx, tarball, targetDir, nil, terminal.IsShowTerminalOutput(), 0)
+}
+
+// UncompressWithConcurrency is Uncompress with the number of goroutines
+// decompressing zstd and gzip archives, 0 uses all the CPUs
+func UncompressWithConcurrency(ctx context.Context, tarball, targetDir string, concurrency int) ([]string, error) {
+	return uncompress(ctx, tarball, targetDir, nil, terminal.IsShowTerminalOutput(), concurrency)
 }
 
-func uncompress(ctx context.Context, tarball, targetDir string, fileFilter func(string) bool, showProgress bool) ([]string, error) {
+func uncompress(ctx context.Context, tarball, targetDir string, fileFilter func(string) bool, showProgress bool, concurrency int) ([]string, error) {
 	logging.Debugf("Uncompressing %s to %s", tarball, targetDir)
 
+	if concurrency < 0 {
+		return nil, fmt.Errorf("invalid decompression concurrency %d, it must be positive, or 0 to use all the CPUs", concurrency)
+	}
+	if concurrency == 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
 	if strings.HasSuffix(tarball, ".zip") {
 		return unzip(ctx, tarball, targetDir, fileFilter, terminal.IsShowTerminalOutput())
 	}
@@ -59,19 +76,20 @@ func uncompress(ctx context.Context, tarball, targetDir string, fileFilter func(
 
 	switch {
 	case filetype.Is(header, "xz"):
-		reader, err := xz.NewReader(file, 0)
+		reader, err := xz.NewReader(file)
 		if err != nil {
 			return nil, err
 		}
 		return untar(ctx, reader, targetDir, fileFilter, showProgress)
 	case filetype.Is(header, "zst"):
-		reader, err := zstd.NewReader(file)
+		reader, err := zstd.NewReader(file, zstd.WithDecoderConcurrency(concurrency))
 		if err != nil {
 			return nil, err
 		}
+		defer reader.Close()
 		return untar(ctx, reader, targetDir, fileFilter, showProgress)
 	case filetype.Is(header, "gz"):
-		reader, err := gzip.NewReader(file)
+		reader, err := pgzip.NewReaderN(file, gzipBlockSize, concurrency)
 		if err != nil {
 			return nil, err
 		}
@@ -58,6 +58,34 @@ func TestDotSlash(t *testing.T) {
 	assert.NoError(t, testUncompress(t, filepath.Join("testdata", "dotslash.tar.gz"), nil, files))
 }
 
+func TestUncompressWithConcurrency(t *testing.T) {
+	for _, archive := range archives {
+		for _, concurrency := range []int{1, 4} {
+			destDir := t.TempDir()
+			fileList, err := UncompressWithConcurrency(context.Background(), filepath.Join("testdata", archive), destDir, concurrency)
+			require.NoError(t, err)
+			assert.NoError(t, checkFileList(destDir, fileList, files))
+			assert.NoError(t, checkFiles(destDir, files))
+		}
+	}
+	_, err := UncompressWithConcurrency(context.Background(), filepath.Join("testdata", "test.tar.zst"), t.TempDir(), -1)
+	assert.EqualError(t, err, "invalid decompression concurrency -1, it must be positive, or 0 to use all the CPUs")
+}
+
+func BenchmarkUncompress(b *testing.B) {
+	for _, archive := range archives {
+		for _, concurrency := range []int{1, 0} {
+			b.Run(fmt.Sprintf("%s/concurrency=%d", archive, concurrency), func(b *testing.B) {
+				for b.Loop() {
+					if _, err := UncompressWithConcurrency(context.Background(), filepath.Join("testdata", archive), b.TempDir(), concurrency); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
 func TestZipSlip(t *testing.T) {
 	archiveName := filepath.Join("testdata", "zipslip.tar.gz")
 	_, err := Uncompress(context.Background(), archiveName, t.TempDir())
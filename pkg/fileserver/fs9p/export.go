@@ -0,0 +1,374 @@
+package fs9p
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/DeedleFake/p9"
+	"github.com/sirupsen/logrus"
+)
+
+var errReadOnly = errors.New("read-only file system")
+
+// Export is a directory tree served by a Server
+type Export struct {
+	// Name is the attach name clients use to access the export, the first
+	// export of a server is also attached with an empty name
+	Name string
+	// Dir is the absolute path of the exported directory
+	Dir string
+	// ReadOnly rejects all the changes to the export
+	ReadOnly bool
+	// Allow lists the path.Match patterns, relative to Dir, of the only
+	// paths clients can access. The directories leading to them stay
+	// visible. Everything is allowed when Allow is empty.
+	Allow []string
+	// Deny lists the path.Match patterns, relative to Dir, of the paths
+	// hidden from clients, such as '.ssh'. Deny has precedence over Allow.
+	Deny []string
+	// LogAccess logs the files opened, created, changed and removed
+	LogAccess bool
+}
+
+func (e Export) validate() error {
+	if e.Name == "" || strings.ContainsAny(e.Name, `/\`) {
+		return fmt.Errorf("invalid export name '%s'", e.Name)
+	}
+	if !filepath.IsAbs(e.Dir) {
+		return fmt.Errorf("path to expose to machine must be absolute: %s", e.Dir)
+	}
+	for _, pattern := range append(append([]string{}, e.Allow...), e.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern '%s' in export %s: %w", pattern, e.Name, err)
+		}
+	}
+	return nil
+}
+
+// visible reports whether the path relative to the export directory can be
+// accessed by clients
+func (e Export) visible(rel string) bool {
+	if rel == "" {
+		return true
+	}
+	segments := strings.Split(rel, "/")
+	for _, pattern := range e.Deny {
+		if matchPrefix(pattern, segments) {
+			return false
+		}
+	}
+	if len(e.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range e.Allow {
+		// the parent directories of the allowed paths are visible too
+		if matchLeadingSegments(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPrefix reports whether the path made of segments matches pattern or
+// is inside a directory matching pattern
+func matchPrefix(pattern string, segments []string) bool {
+	return len(segments) >= len(strings.Split(cleanPattern(pattern), "/")) && matchLeadingSegments(pattern, segments)
+}
+
+// matchLeadingSegments reports whether the segments of pattern and of the
+// path match, up to the shortest of them
+func matchLeadingSegments(pattern string, segments []string) bool {
+	for i, patternSegment := range strings.Split(cleanPattern(pattern), "/") {
+		if i == len(segments) {
+			break
+		}
+		if ok, _ := path.Match(patternSegment, segments[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func cleanPattern(pattern string) string {
+	return strings.TrimPrefix(path.Clean("/"+pattern), "/")
+}
+
+// exportFS is a p9.FileSystem serving several exports, selected by the
+// attach name
+type exportFS struct {
+	exports []Export
+}
+
+func newExportFS(exports []Export) (*exportFS, error) {
+	if len(exports) == 0 {
+		return nil, errors.New("no directory to expose to machine")
+	}
+	names := map[string]bool{}
+	for _, export := range exports {
+		if err := export.validate(); err != nil {
+			return nil, err
+		}
+		if names[export.Name] {
+			return nil, fmt.Errorf("duplicate export name '%s'", export.Name)
+		}
+		names[export.Name] = true
+	}
+	return &exportFS{exports: exports}, nil
+}
+
+func (fsys *exportFS) Auth(_, _ string) (p9.File, error) {
+	return nil, errors.New("auth not supported")
+}
+
+func (fsys *exportFS) Attach(_ p9.File, user, aname string) (p9.Attachment, error) {
+	export, err := fsys.export(aname)
+	if err != nil {
+		return nil, err
+	}
+	attachment, err := p9.Dir(export.Dir).Attach(nil, user, "/")
+	if err != nil {
+		return nil, err
+	}
+	if export.LogAccess {
+		logrus.Infof("9p export %s: attached by %s", export.Name, user)
+	}
+	return &exportAttachment{export: export, aname: aname, dir: attachment}, nil
+}
+
+func (fsys *exportFS) export(aname string) (Export, error) {
+	if aname == "" || aname == "/" {
+		return fsys.exports[0], nil
+	}
+	for _, export := range fsys.exports {
+		if aname == export.Name || aname == "/"+export.Name {
+			return export, nil
+		}
+	}
+	return Export{}, fmt.Errorf("unknown export '%s'", aname)
+}
+
+// exportAttachment confines the paths to the export directory and applies
+// its rules before calling the p9.Dir attachment of the directory
+type exportAttachment struct {
+	export Export
+	aname  string
+	dir    p9.Attachment
+}
+
+// relative returns the path relative to the export directory of p, which
+// begins with the attach name. The paths outside of the attach root are
+// rejected, the client is in charge of '..' at the root of the mount.
+func (a *exportAttachment) relative(p string) (string, error) {
+	switch a.aname {
+	case "":
+		if p == ".." || strings.HasPrefix(p, "../") {
+			return "", fs.ErrNotExist
+		}
+	case "/":
+	default:
+		if p != a.aname && !strings.HasPrefix(p, a.aname+"/") {
+			return "", fs.ErrNotExist
+		}
+		p = strings.TrimPrefix(p, a.aname)
+	}
+	return strings.TrimPrefix(path.Clean("/"+p), "/"), nil
+}
+
+// resolve returns the path relative to the export directory of p when it
+// can be accessed by clients
+func (a *exportAttachment) resolve(p string) (string, error) {
+	rel, err := a.relative(p)
+	if err != nil {
+		return "", err
+	}
+	if !a.export.visible(rel) {
+		logrus.Debugf("9p export %s: access to hidden path %s denied", a.export.Name, rel)
+		return "", fs.ErrNotExist
+	}
+	if err := a.confine(rel); err != nil {
+		return "", err
+	}
+	return rel, nil
+}
+
+// confine checks the target of the symbolic links in the path relative to
+// the export directory, they are followed on the host and must not lead
+// outside of the export directory or to a hidden path
+func (a *exportAttachment) confine(rel string) error {
+	dir, err := filepath.EvalSymlinks(a.export.Dir)
+	if err != nil {
+		return err
+	}
+	target, err := evalExistingSymlinks(filepath.Join(dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+	targetRel, err := filepath.Rel(dir, target)
+	if err != nil || targetRel == ".." || strings.HasPrefix(targetRel, ".."+string(filepath.Separator)) {
+		logrus.Debugf("9p export %s: %s links outside of the export", a.export.Name, rel)
+		return fs.ErrNotExist
+	}
+	if targetRel == "." {
+		targetRel = ""
+	}
+	if !a.export.visible(filepath.ToSlash(targetRel)) {
+		logrus.Debugf("9p export %s: %s links to the hidden path %s", a.export.Name, rel, targetRel)
+		return fs.ErrNotExist
+	}
+	return nil
+}
+
+// evalExistingSymlinks is filepath.EvalSymlinks for paths which do not
+// exist yet, only their existing parent directories are resolved. Dangling
+// symbolic links are rejected since creating them would create their target.
+func evalExistingSymlinks(p string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(p)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return resolved, err
+	}
+	if _, err := os.Lstat(p); err == nil {
+		return "", fs.ErrNotExist
+	}
+	parent := filepath.Dir(p)
+	if parent == p {
+		return p, nil
+	}
+	resolvedParent, err := evalExistingSymlinks(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, filepath.Base(p)), nil
+}
+
+func (a *exportAttachment) logAccess(operation, rel string) {
+	if a.export.LogAccess {
+		logrus.Infof("9p export %s: %s /%s", a.export.Name, operation, rel)
+	}
+}
+
+func (a *exportAttachment) Stat(p string) (p9.DirEntry, error) {
+	rel, err := a.resolve(p)
+	if err != nil {
+		return p9.DirEntry{}, err
+	}
+	return a.dir.Stat(rel)
+}
+
+func (a *exportAttachment) WriteStat(p string, changes p9.StatChanges) error {
+	rel, err := a.resolve(p)
+	if err != nil {
+		return err
+	}
+	if a.export.ReadOnly {
+		return errReadOnly
+	}
+	if name, ok := changes.Name(); ok {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid file name '%s'", name)
+		}
+		if !a.export.visible(path.Join(path.Dir(rel), name)) {
+			return fs.ErrPermission
+		}
+		a.logAccess("rename to "+name, rel)
+	} else {
+		a.logAccess("change", rel)
+	}
+	return a.dir.WriteStat(rel, changes)
+}
+
+func (a *exportAttachment) Open(p string, mode uint8) (p9.File, error) {
+	rel, err := a.resolve(p)
+	if err != nil {
+		return nil, err
+	}
+	if writeMode(mode) {
+		if a.export.ReadOnly {
+			return nil, errReadOnly
+		}
+		a.logAccess("open for writing", rel)
+	} else {
+		a.logAccess("open", rel)
+	}
+	file, err := a.dir.Open(rel, mode)
+	if err != nil {
+		return nil, err
+	}
+	return a.filter(rel, file), nil
+}
+
+func (a *exportAttachment) Create(p string, perm p9.FileMode, mode uint8) (p9.File, error) {
+	rel, err := a.relative(p)
+	if err != nil {
+		return nil, err
+	}
+	if !a.export.visible(rel) {
+		logrus.Debugf("9p export %s: creation of hidden path %s denied", a.export.Name, rel)
+		return nil, fs.ErrPermission
+	}
+	if err := a.confine(rel); err != nil {
+		return nil, err
+	}
+	if a.export.ReadOnly {
+		return nil, errReadOnly
+	}
+	a.logAccess("create", rel)
+	file, err := a.dir.Create(rel, perm, mode)
+	if err != nil {
+		return nil, err
+	}
+	return a.filter(rel, file), nil
+}
+
+func (a *exportAttachment) Remove(p string) error {
+	rel, err := a.resolve(p)
+	if err != nil {
+		return err
+	}
+	if rel == "" {
+		return errors.New("cannot remove the root of the export")
+	}
+	if a.export.ReadOnly {
+		return errReadOnly
+	}
+	a.logAccess("remove", rel)
+	return a.dir.Remove(rel)
+}
+
+// writeMode reports whether the open mode allows changes to the file
+func writeMode(mode uint8) bool {
+	access := mode & 3
+	return access == p9.OWRITE || access == p9.ORDWR || mode&(p9.OTRUNC|p9.ORCLOSE) != 0
+}
+
+func (a *exportAttachment) filter(rel string, file p9.File) p9.File {
+	if len(a.export.Allow) == 0 && len(a.export.Deny) == 0 {
+		return file
+	}
+	return &filteredFile{File: file, export: a.export, rel: rel}
+}
+
+// filteredFile hides the entries of a directory clients cannot access
+type filteredFile struct {
+	p9.File
+	export Export
+	rel    string
+}
+
+func (f *filteredFile) Readdir() ([]p9.DirEntry, error) {
+	entries, err := f.File.Readdir()
+	if err != nil {
+		return nil, err
+	}
+	visible := entries[:0]
+	for _, entry := range entries {
+		if f.export.visible(path.Join(f.rel, entry.EntryName)) {
+			visible = append(visible, entry)
+		}
+	}
+	return visible, nil
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/DeedleFake/p9"
@@ -18,11 +17,11 @@ type Server struct {
 	// Listener this server is bound to
 	Listener net.Listener
 
-	// Plan9 Filesystem type that holds the exposed directory
+	// Plan9 Filesystem type that holds the exposed directories
 	Filesystem p9.FileSystem
 
-	// Directory this server exposes
-	ExposedDir string
+	// Directories this server exposes
+	Exports []Export
 
 	// Errors from the server being started will come out here
 	ErrChan chan error
@@ -32,26 +31,35 @@ type Server struct {
 // and returns the server struct.
 // Directory given must be an absolute path and must exist.
 func New9pServer(listener net.Listener, exposeDir string) (*Server, error) {
-	// verify that exposeDir makes sense
-	if !filepath.IsAbs(exposeDir) {
-		return nil, fmt.Errorf("path to expose to machine must be absolute: %s", exposeDir)
-	}
-	stat, err := os.Stat(exposeDir)
+	return NewMultiExport9pServer(listener, []Export{{Name: "default", Dir: exposeDir}})
+}
+
+// NewMultiExport9pServer exposes several directories via the given
+// net.Listener, clients select one of them with the attach name.
+// Directories given must be absolute paths and must exist.
+func NewMultiExport9pServer(listener net.Listener, exports []Export) (*Server, error) {
+	fs, err := newExportFS(exports)
 	if err != nil {
-		return nil, fmt.Errorf("cannot stat path to expose to machine: %w", err)
+		return nil, err
 	}
-	if !stat.IsDir() {
-		return nil, fmt.Errorf("path to expose to machine must be a directory: %s", exposeDir)
+	// verify that the exported directories make sense
+	for _, export := range exports {
+		stat, err := os.Stat(export.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("cannot stat path to expose to machine: %w", err)
+		}
+		if !stat.IsDir() {
+			return nil, fmt.Errorf("path to expose to machine must be a directory: %s", export.Dir)
+		}
 	}
 
-	fs := p9.FileSystem(p9.Dir(exposeDir))
 	// set size to 1 making channel buffered to prevent proto.Serve blocking
 	errChan := make(chan error, 1)
 
 	toReturn := new(Server)
 	toReturn.Listener = listener
 	toReturn.Filesystem = fs
-	toReturn.ExposedDir = exposeDir
+	toReturn.Exports = exports
 	toReturn.ErrChan = errChan
 
 	return toReturn, nil
@@ -69,7 +77,7 @@ func (s *Server) Start() error {
 	case err := <-s.ErrChan:
 		return fmt.Errorf("starting 9p server: %w", err)
 	default:
-		logrus.Infof("started 9p server on %s for directory %s", s.Listener.Addr().String(), s.ExposedDir)
+		logrus.Infof("started 9p server on %s for %s", s.Listener.Addr().String(), s.exposedDirs())
 		return nil
 	}
 }
@@ -82,10 +90,22 @@ func (s *Server) Stop() error {
 	if err := s.Listener.Close(); err != nil {
 		return err
 	}
-	logrus.Infof("stopped 9p server for directory %s", s.ExposedDir)
+	logrus.Infof("stopped 9p server for %s", s.exposedDirs())
 	return nil
 }
 
+func (s *Server) exposedDirs() string {
+	var dirs []string
+	for _, export := range s.Exports {
+		dir := fmt.Sprintf("%s (%s", export.Dir, export.Name)
+		if export.ReadOnly {
+			dir += ", read-only"
+		}
+		dirs = append(dirs, dir+")")
+	}
+	return "directories " + strings.Join(dirs, ", ")
+}
+
 // WaitForError from a running server.
 func (s *Server) WaitForError() error {
 	err := <-s.ErrChan
@@ -0,0 +1,235 @@
+package fs9p
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/DeedleFake/p9"
+	"github.com/crc-org/crc/v2/pkg/crc/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+// startServer serves the exports on a loopback listener and returns a
+// client connected to it
+func startServer(t *testing.T, exports ...Export) *p9.Client {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server, err := NewMultiExport9pServer(listener, exports)
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		assert.NoError(t, server.Stop())
+	})
+
+	client, err := p9.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	_, err = client.Handshake(constants.Plan9Msize)
+	require.NoError(t, err)
+	return client
+}
+
+func attach(t *testing.T, client *p9.Client, aname string) *p9.Remote {
+	root, err := client.Attach(nil, "crc", aname)
+	require.NoError(t, err)
+	return root
+}
+
+func readFile(t *testing.T, root *p9.Remote, path string) string {
+	file, err := root.Open(path, p9.OREAD)
+	require.NoError(t, err)
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func readDir(t *testing.T, root *p9.Remote, path string) []string {
+	dir, err := root.Open(path, p9.OREAD)
+	require.NoError(t, err)
+	defer dir.Close()
+	entries, err := dir.Readdir()
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.EntryName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestMultipleExports(t *testing.T) {
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, "home.txt"), "home")
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "src.txt"), "src")
+
+	client := startServer(t, Export{Name: "home", Dir: home}, Export{Name: "src", Dir: src})
+
+	// the first export is the default one
+	assert.Equal(t, "home", readFile(t, attach(t, client, ""), "home.txt"))
+	assert.Equal(t, "home", readFile(t, attach(t, client, "/home"), "home.txt"))
+	assert.Equal(t, "src", readFile(t, attach(t, client, "src"), "src.txt"))
+	assert.Equal(t, "src", readFile(t, attach(t, client, "/src"), "src.txt"))
+
+	_, err := client.Attach(nil, "crc", "other")
+	assert.EqualError(t, err, "unknown export 'other'")
+}
+
+func TestExportConfinement(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "outside.txt"), "outside")
+	export := filepath.Join(dir, "export")
+	writeFile(t, filepath.Join(export, "inside.txt"), "inside")
+
+	client := startServer(t, Export{Name: "export", Dir: export})
+	for _, aname := range []string{"", "/", "/export"} {
+		root := attach(t, client, aname)
+		assert.Equal(t, []string{"inside.txt"}, readDir(t, root, ""))
+		_, err := root.Open("../outside.txt", p9.OREAD)
+		assert.Error(t, err, aname)
+	}
+}
+
+func TestReadOnlyExport(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "file.txt"), "content")
+
+	root := attach(t, startServer(t, Export{Name: "ro", Dir: dir, ReadOnly: true}), "")
+	assert.Equal(t, "content", readFile(t, root, "file.txt"))
+
+	_, err := root.Open("file.txt", p9.OWRITE)
+	assert.EqualError(t, err, "read-only file system")
+	_, err = root.Open("file.txt", p9.OREAD|p9.OTRUNC)
+	assert.EqualError(t, err, "read-only file system")
+	_, err = root.Create("new.txt", 0644, p9.OWRITE)
+	assert.EqualError(t, err, "read-only file system")
+	assert.EqualError(t, root.Remove("file.txt"), "read-only file system")
+
+	data, err := os.ReadFile(filepath.Join(dir, "file.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "new.txt"))
+}
+
+func TestReadWriteExport(t *testing.T) {
+	dir := t.TempDir()
+	root := attach(t, startServer(t, Export{Name: "rw", Dir: dir, LogAccess: true}), "")
+
+	file, err := root.Create("new.txt", 0600, p9.OWRITE)
+	require.NoError(t, err)
+	_, err = file.Write([]byte("created"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, "new.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "created", string(data))
+
+	require.NoError(t, root.Remove("new.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "new.txt"))
+}
+
+func TestDeniedPaths(t *testing.T) {
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, ".ssh", "id_rsa"), "key")
+	writeFile(t, filepath.Join(home, "secret.key"), "key")
+	writeFile(t, filepath.Join(home, "notes.txt"), "notes")
+
+	root := attach(t, startServer(t, Export{Name: "home", Dir: home, Deny: []string{".ssh", "*.key"}}), "")
+	assert.Equal(t, []string{"notes.txt"}, readDir(t, root, ""))
+	assert.Equal(t, "notes", readFile(t, root, "notes.txt"))
+
+	for _, path := range []string{".ssh", ".ssh/id_rsa", "secret.key"} {
+		_, err := root.Open(path, p9.OREAD)
+		assert.Error(t, err, path)
+	}
+	_, err := root.Create(".ssh/authorized_keys", 0600, p9.OWRITE)
+	assert.Error(t, err)
+	_, err = root.Create("other.key", 0600, p9.OWRITE)
+	assert.EqualError(t, err, "permission denied")
+	assert.NoFileExists(t, filepath.Join(home, "other.key"))
+}
+
+func TestSymlinks(t *testing.T) {
+	home := t.TempDir()
+	outside := t.TempDir()
+	writeFile(t, filepath.Join(home, ".ssh", "id_rsa"), "key")
+	writeFile(t, filepath.Join(home, "notes.txt"), "notes")
+	writeFile(t, filepath.Join(outside, "secret"), "secret")
+	for link, target := range map[string]string{
+		"notes-link":   "notes.txt",
+		"key-link":     filepath.Join(".ssh", "id_rsa"),
+		"ssh-link":     ".ssh",
+		"outside-file": filepath.Join(outside, "secret"),
+		"outside-dir":  outside,
+		"dangling":     filepath.Join(outside, "created"),
+	} {
+		if err := os.Symlink(target, filepath.Join(home, link)); err != nil {
+			t.Skipf("cannot create symbolic links: %v", err)
+		}
+	}
+
+	root := attach(t, startServer(t, Export{Name: "home", Dir: home, Deny: []string{".ssh"}}), "")
+	assert.Equal(t, "notes", readFile(t, root, "notes-link"))
+	for _, path := range []string{"key-link", "ssh-link/id_rsa", "outside-file", "outside-dir/secret"} {
+		_, err := root.Open(path, p9.OREAD)
+		assert.Error(t, err, path)
+	}
+	for _, path := range []string{"outside-dir/created", "ssh-link/authorized_keys", "dangling"} {
+		_, err := root.Create(path, 0600, p9.OWRITE)
+		assert.Error(t, err, path)
+	}
+	assert.NoFileExists(t, filepath.Join(outside, "created"))
+	assert.NoFileExists(t, filepath.Join(home, ".ssh", "authorized_keys"))
+}
+
+func TestAllowedPaths(t *testing.T) {
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, "src", "project", "main.go"), "package main")
+	writeFile(t, filepath.Join(home, "src", "other", "main.go"), "package other")
+	writeFile(t, filepath.Join(home, "notes.txt"), "notes")
+
+	root := attach(t, startServer(t, Export{Name: "home", Dir: home, Allow: []string{"src/project"}}), "")
+	// the directories leading to the allowed paths are visible
+	assert.Equal(t, []string{"src"}, readDir(t, root, ""))
+	assert.Equal(t, []string{"project"}, readDir(t, root, "src"))
+	assert.Equal(t, "package main", readFile(t, root, "src/project/main.go"))
+
+	_, err := root.Open("notes.txt", p9.OREAD)
+	assert.Error(t, err)
+	_, err = root.Open("src/other/main.go", p9.OREAD)
+	assert.Error(t, err)
+}
+
+func TestInvalidExports(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	dir := t.TempDir()
+
+	_, err = NewMultiExport9pServer(listener, nil)
+	assert.EqualError(t, err, "no directory to expose to machine")
+	_, err = NewMultiExport9pServer(listener, []Export{{Name: "a/b", Dir: dir}})
+	assert.EqualError(t, err, "invalid export name 'a/b'")
+	_, err = NewMultiExport9pServer(listener, []Export{{Name: "dir", Dir: dir}, {Name: "dir", Dir: dir}})
+	assert.EqualError(t, err, "duplicate export name 'dir'")
+	_, err = NewMultiExport9pServer(listener, []Export{{Name: "dir", Dir: dir, Deny: []string{"["}}})
+	assert.EqualError(t, err, "invalid pattern '[' in export dir: syntax error in pattern")
+	_, err = NewMultiExport9pServer(listener, []Export{{Name: "dir", Dir: filepath.Join(dir, "missing")}})
+	assert.ErrorContains(t, err, "cannot stat path to expose to machine")
+	_, err = New9pServer(listener, "relative")
+	assert.EqualError(t, err, "path to expose to machine must be absolute: relative")
+}
@@ -91,6 +91,32 @@ func TestStoreExists(t *testing.T) {
 	assert.False(t, exists)
 }
 
+func TestStoreList(t *testing.T) {
+	store := getTestStore(t)
+
+	names, err := store.List()
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+
+	h := testHost()
+	assert.NoError(t, store.Save(h))
+
+	other := testHost()
+	other.Name = "other-host"
+	assert.NoError(t, store.Save(other))
+	assert.NoError(t, store.SetExists(other.Name))
+
+	names, err = store.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"other-host"}, names)
+
+	assert.NoError(t, store.SetExists(h.Name))
+
+	names, err = store.List()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"other-host", "test-host"}, names)
+}
+
 func TestStoreLoad(t *testing.T) {
 	store := getTestStore(t)
 
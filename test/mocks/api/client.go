@@ -12,6 +12,48 @@ type Client struct {
 	mock.Mock
 }
 
+// AddDNSRecord provides a mock function with given fields: record
+func (_m *Client) AddDNSRecord(record client.DNSRecordRequest) (client.DNSRecordResult, error) {
+	ret := _m.Called(record)
+
+	var r0 client.DNSRecordResult
+	if rf, ok := ret.Get(0).(func(client.DNSRecordRequest) client.DNSRecordResult); ok {
+		r0 = rf(record)
+	} else {
+		r0 = ret.Get(0).(client.DNSRecordResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(client.DNSRecordRequest) error); ok {
+		r1 = rf(record)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DNSRecords provides a mock function with given fields:
+func (_m *Client) DNSRecords() (client.DNSRecordsResult, error) {
+	ret := _m.Called()
+
+	var r0 client.DNSRecordsResult
+	if rf, ok := ret.Get(0).(func() client.DNSRecordsResult); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(client.DNSRecordsResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields:
 func (_m *Client) Delete() error {
 	ret := _m.Called()
@@ -68,6 +110,27 @@ func (_m *Client) IsPullSecretDefined() (bool, error) {
 	return r0, r1
 }
 
+// RemoveDNSRecord provides a mock function with given fields: hostname
+func (_m *Client) RemoveDNSRecord(hostname string) (client.DNSRecordResult, error) {
+	ret := _m.Called(hostname)
+
+	var r0 client.DNSRecordResult
+	if rf, ok := ret.Get(0).(func(string) client.DNSRecordResult); ok {
+		r0 = rf(hostname)
+	} else {
+		r0 = ret.Get(0).(client.DNSRecordResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hostname)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetConfig provides a mock function with given fields: configs
 func (_m *Client) SetConfig(configs client.SetConfigRequest) (client.SetOrUnsetConfigResult, error) {
 	ret := _m.Called(configs)